@@ -0,0 +1,95 @@
+package main
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+)
+
+// dwarfSource finds struct types in the DWARF debug info of a binary
+type dwarfSource struct {
+	data *dwarf.Data
+}
+
+// openDWARF returns the DWARF debug info of an ELF, Mach-O or PE binary
+func openDWARF(path string) (*dwarfSource, error) {
+	var data *dwarf.Data
+	var err error
+	if f, openErr := elf.Open(path); openErr == nil {
+		defer f.Close()
+		data, err = f.DWARF()
+	} else if f, openErr := macho.Open(path); openErr == nil {
+		defer f.Close()
+		data, err = f.DWARF()
+	} else if f, openErr := pe.Open(path); openErr == nil {
+		defer f.Close()
+		data, err = f.DWARF()
+	} else {
+		return nil, fmt.Errorf("not an ELF, Mach-O or PE file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dwarfSource{data: data}, nil
+}
+
+// isStructEntry reports whether a DWARF entry is a struct or class definition
+func isStructEntry(entry *dwarf.Entry) bool {
+	if entry.Tag != dwarf.TagStructType && entry.Tag != dwarf.TagClassType {
+		return false
+	}
+	declaration, _ := entry.Val(dwarf.AttrDeclaration).(bool)
+	return !declaration
+}
+
+func (s *dwarfSource) Lookup(name string) (*dwarf.StructType, error) {
+	reader := s.data.Reader()
+	for {
+		entry, err := reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			break
+		}
+		if entryName, _ := entry.Val(dwarf.AttrName).(string); entryName != name {
+			continue
+		}
+		if !isStructEntry(entry) && entry.Tag != dwarf.TagTypedef {
+			continue
+		}
+
+		t, err := s.data.Type(entry.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read type %s: %w", name, err)
+		}
+		if st, ok := underlyingStruct(t); ok && !st.Incomplete && st.ByteSize > 0 {
+			return st, nil
+		}
+	}
+	return nil, fmt.Errorf("struct %s not found", name)
+}
+
+func (s *dwarfSource) Structs() (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	reader := s.data.Reader()
+	for {
+		entry, err := reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			return sizes, nil
+		}
+		if !isStructEntry(entry) {
+			continue
+		}
+		name, _ := entry.Val(dwarf.AttrName).(string)
+		size, _ := entry.Val(dwarf.AttrByteSize).(int64)
+		if name != "" && size > 0 {
+			sizes[name] = size
+		}
+	}
+}
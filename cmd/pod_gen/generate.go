@@ -0,0 +1,416 @@
+package main
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// genField is one field of a generated struct
+type genField struct {
+	Name    string
+	Type    string
+	Tag     string
+	Offset  int64
+	Comment string
+}
+
+// genStruct is a generated Go struct matching the layout of a C/C++ struct
+type genStruct struct {
+	Name   string
+	CName  string
+	Size   int64
+	Fields []genField
+}
+
+// typeSource finds struct types by name, in DWARF debug info or a symbol table dump. Types
+// are returned in the debug/dwarf representation whatever their origin.
+type typeSource interface {
+	// Lookup returns the complete struct, class or typedef of a struct called name
+	Lookup(name string) (*dwarf.StructType, error)
+
+	// Structs returns the names and sizes of the complete struct types
+	Structs() (map[string]int64, error)
+}
+
+// generator turns struct types into Go structs with explicit padding and pod tags.
+// Structs embedded by value are generated as well, pointers become uint64 fields tagged
+// valid_pointer so they can be followed with ReadT on the address.
+type generator struct {
+	source  typeSource
+	offsets bool // Also declare the field offsets as constants
+	structs []*genStruct
+	byType  map[*dwarf.StructType]*genStruct // Generated struct per DWARF struct type
+	aligns  map[string]int64                 // Go alignment of every generated struct
+	names   map[string]bool                  // Go type names in use
+}
+
+func newGenerator(source typeSource, offsets bool) *generator {
+	return &generator{
+		source:  source,
+		offsets: offsets,
+		byType:  make(map[*dwarf.StructType]*genStruct),
+		aligns:  make(map[string]int64),
+		names:   make(map[string]bool),
+	}
+}
+
+// Add generates the struct, class or typedef of a struct called name
+func (g *generator) Add(name string) error {
+	st, err := g.source.Lookup(name)
+	if err != nil {
+		return err
+	}
+	_, err = g.structType(st, name)
+	return err
+}
+
+// underlyingStruct returns the struct a type refers to through typedefs and qualifiers
+func underlyingStruct(t dwarf.Type) (*dwarf.StructType, bool) {
+	for {
+		switch tt := t.(type) {
+		case *dwarf.TypedefType:
+			t = tt.Type
+		case *dwarf.QualType:
+			t = tt.Type
+		case *dwarf.StructType:
+			return tt, tt.Kind != "union"
+		default:
+			return nil, false
+		}
+	}
+}
+
+// structType returns the Go name of the struct generated for st, generating it first.
+// hint names anonymous structs (typedef name, or parent and field name).
+func (g *generator) structType(st *dwarf.StructType, hint string) (string, error) {
+	if gs, ok := g.byType[st]; ok {
+		return gs.Name, nil
+	}
+
+	cName := st.StructName
+	if cName == "" {
+		cName = hint
+	}
+	name := exportName(cName)
+	for i := 2; g.names[name]; i++ {
+		name = fmt.Sprintf("%s%d", exportName(cName), i)
+	}
+	g.names[name] = true
+
+	gs := &genStruct{Name: name, CName: st.Kind + " " + st.StructName, Size: st.ByteSize}
+	if st.StructName == "" {
+		gs.CName = fmt.Sprintf("anonymous %s %s", st.Kind, hint)
+	}
+	g.byType[st] = gs
+	g.structs = append(g.structs, gs)
+
+	align, err := g.structFields(gs, st)
+	if err != nil {
+		return "", err
+	}
+	g.aligns[name] = align
+	return name, nil
+}
+
+// structFields fills the fields of gs from st, padding every gap explicitly, and returns the
+// alignment of the Go struct
+func (g *generator) structFields(gs *genStruct, st *dwarf.StructType) (int64, error) {
+	fields := make([]*dwarf.StructField, len(st.Field))
+	copy(fields, st.Field)
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fieldStart(fields[i]) < fieldStart(fields[j])
+	})
+
+	used := make(map[string]bool)
+	fieldName := func(cName string, offset int64) string {
+		name := exportName(cName)
+		if cName == "" {
+			name = fmt.Sprintf("Anon%02X", offset)
+		}
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", exportName(cName), i)
+		}
+		used[name] = true
+		return name
+	}
+
+	var cursor, align int64 = 0, 1
+	pad := func(to int64) {
+		if to > cursor {
+			gs.Fields = append(gs.Fields, genField{Name: "_", Type: fmt.Sprintf("[%d]byte", to-cursor), Offset: cursor, Comment: "padding"})
+			cursor = to
+		}
+	}
+
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+
+		if f.BitSize > 0 {
+			// Contiguous bit fields share one field covering their bytes
+			start, end := bitRange(f)
+			bits := []string{fmt.Sprintf("%s:%d", f.Name, f.BitSize)}
+			for i+1 < len(fields) && fields[i+1].BitSize > 0 {
+				nextStart, nextEnd := bitRange(fields[i+1])
+				if nextStart > end {
+					break
+				}
+				end = max(end, nextEnd)
+				bits = append(bits, fmt.Sprintf("%s:%d", fields[i+1].Name, fields[i+1].BitSize))
+				i++
+			}
+			if start < cursor {
+				continue
+			}
+			pad(start)
+
+			size := end - start
+			typ := fmt.Sprintf("[%d]byte", size)
+			if (size == 1 || size == 2 || size == 4 || size == 8) && start%size == 0 {
+				typ = fmt.Sprintf("uint%d", size*8)
+				align = max(align, size)
+			}
+			gs.Fields = append(gs.Fields, genField{
+				Name:    fieldName(fmt.Sprintf("bits_%02X", start), start),
+				Type:    typ,
+				Offset:  start,
+				Comment: "bit fields " + strings.Join(bits, " "),
+			})
+			cursor = end
+			continue
+		}
+
+		offset := f.ByteOffset
+		size := f.Type.Size()
+		if size < 0 {
+			return 0, fmt.Errorf("%s.%s: unknown size of %s", gs.CName, f.Name, f.Type)
+		}
+		if offset < cursor {
+			// Overlapping members (anonymous unions), keep the first one
+			if n := len(gs.Fields); n > 0 {
+				gs.Fields[n-1].Comment += ", overlaps " + f.Name
+			}
+			continue
+		}
+		if size == 0 {
+			// Flexible array members and empty structs take no space
+			continue
+		}
+		pad(offset)
+
+		field := genField{Name: fieldName(f.Name, offset), Offset: offset, Comment: f.Type.String()}
+		typ, tag, fieldAlign, err := g.goType(f.Type, gs.Name+exportName(f.Name))
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case typ == "":
+			// No Go equivalent, keep the bytes
+			field.Type = fmt.Sprintf("[%d]byte", size)
+		case offset%fieldAlign != 0:
+			// Packed struct, Go would insert padding before the field
+			field.Type = fmt.Sprintf("[%d]byte", size)
+			field.Comment += " (unaligned " + typ + ")"
+		default:
+			field.Type, field.Tag = typ, tag
+			align = max(align, fieldAlign)
+		}
+		gs.Fields = append(gs.Fields, field)
+		cursor = offset + size
+	}
+
+	pad(st.ByteSize)
+	if st.ByteSize%align != 0 {
+		// Packed struct whose size is not a multiple of its alignment, Go would add trailing padding
+		return 0, fmt.Errorf("%s: size 0x%x is not a multiple of its alignment %d", gs.CName, st.ByteSize, align)
+	}
+	return align, nil
+}
+
+// fieldStart returns the byte offset a field starts at
+func fieldStart(f *dwarf.StructField) int64 {
+	if f.BitSize > 0 {
+		start, _ := bitRange(f)
+		return start
+	}
+	return f.ByteOffset
+}
+
+// bitRange returns the bytes [start, end) holding a bit field, from DW_AT_data_bit_offset
+// (DWARF 4) or from DW_AT_bit_offset counted from the high bit of the storage (DWARF 2/3,
+// little-endian targets)
+func bitRange(f *dwarf.StructField) (int64, int64) {
+	bit := f.DataBitOffset
+	if bit == 0 && f.BitOffset != 0 {
+		bit = f.ByteOffset*8 + f.ByteSize*8 - f.BitOffset - f.BitSize
+	} else if bit == 0 {
+		bit = f.ByteOffset * 8
+	}
+	return bit / 8, (bit + f.BitSize + 7) / 8
+}
+
+// goType returns the Go type, pod tag and alignment of a DWARF type, an empty type when
+// there is no Go equivalent. hint names anonymous structs.
+func (g *generator) goType(t dwarf.Type, hint string) (string, string, int64, error) {
+	switch tt := t.(type) {
+	case *dwarf.QualType:
+		return g.goType(tt.Type, hint)
+	case *dwarf.TypedefType:
+		if st, ok := tt.Type.(*dwarf.StructType); ok && st.StructName == "" {
+			hint = tt.Name
+		}
+		return g.goType(tt.Type, hint)
+	case *dwarf.StructType:
+		if tt.Kind == "union" || tt.Incomplete {
+			return "", "", 1, nil
+		}
+		name, err := g.structType(tt, hint)
+		if err != nil {
+			return "", "", 0, err
+		}
+		return name, "", g.aligns[name], nil
+	case *dwarf.PtrType:
+		if tt.ByteSize == 4 {
+			return "uint32", "", 4, nil
+		}
+		return "uint64", "valid_pointer", 8, nil
+	case *dwarf.ArrayType:
+		if tt.Count <= 0 {
+			return "", "", 1, nil
+		}
+		if isCharType(tt.Type) {
+			return fmt.Sprintf("[%d]byte", tt.Count), "char_array", 1, nil
+		}
+		elem, _, elemAlign, err := g.goType(tt.Type, hint)
+		if err != nil || elem == "" {
+			return "", "", 1, err
+		}
+		return fmt.Sprintf("[%d]%s", tt.Count, elem), "", elemAlign, nil
+	case *dwarf.BoolType:
+		if tt.ByteSize == 1 {
+			return "bool", "", 1, nil
+		}
+		return intType("uint", tt.ByteSize)
+	case *dwarf.CharType:
+		return "int8", "", 1, nil
+	case *dwarf.UcharType:
+		return "uint8", "", 1, nil
+	case *dwarf.IntType:
+		return intType("int", tt.ByteSize)
+	case *dwarf.UintType:
+		return intType("uint", tt.ByteSize)
+	case *dwarf.EnumType:
+		for _, v := range tt.Val {
+			if v.Val < 0 {
+				return intType("int", tt.ByteSize)
+			}
+		}
+		return intType("uint", tt.ByteSize)
+	case *dwarf.FloatType:
+		switch tt.ByteSize {
+		case 4:
+			return "float32", "", 4, nil
+		case 8:
+			return "float64", "", 8, nil
+		}
+	}
+	return "", "", 1, nil
+}
+
+// intType returns the Go integer type of the given signedness and size in bytes
+func intType(kind string, size int64) (string, string, int64, error) {
+	switch size {
+	case 1, 2, 4, 8:
+		return fmt.Sprintf("%s%d", kind, size*8), "", size, nil
+	}
+	return "", "", 1, nil
+}
+
+// isCharType reports whether t is a character type, arrays of it are C strings
+func isCharType(t dwarf.Type) bool {
+	for {
+		switch tt := t.(type) {
+		case *dwarf.QualType:
+			t = tt.Type
+		case *dwarf.TypedefType:
+			t = tt.Type
+		case *dwarf.CharType, *dwarf.UcharType:
+			return true
+		case *dwarf.IntType:
+			return tt.ByteSize == 1 && strings.Contains(tt.Name, "char")
+		case *dwarf.UintType:
+			return tt.ByteSize == 1 && strings.Contains(tt.Name, "char")
+		default:
+			return false
+		}
+	}
+}
+
+// exportName turns a C/C++ identifier into an exported Go identifier, "m_health" becomes
+// "Health", "ns::player_state<int>" becomes "NsPlayerStateInt"
+func exportName(name string) string {
+	name = strings.TrimPrefix(name, "m_")
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	for _, part := range parts {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+
+	out := sb.String()
+	if out == "" {
+		return "Field"
+	}
+	if unicode.IsDigit(rune(out[0])) {
+		out = "F" + out
+	}
+	return out
+}
+
+// Source returns the gofmt formatted Go file declaring the generated structs
+func (g *generator) Source(pkg, input string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by pod_gen from %s. DO NOT EDIT.\n\n", filepath.Base(input))
+	fmt.Fprintf(&b, "package %s\n", pkg)
+
+	for _, gs := range g.structs {
+		fmt.Fprintf(&b, "\n// %s matches %s (0x%X bytes)\n", gs.Name, gs.CName, gs.Size)
+		fmt.Fprintf(&b, "type %s struct {\n", gs.Name)
+		for _, f := range gs.Fields {
+			fmt.Fprintf(&b, "\t%s %s", f.Name, f.Type)
+			if f.Tag != "" {
+				fmt.Fprintf(&b, " `pod:%q`", f.Tag)
+			}
+			fmt.Fprintf(&b, " // 0x%02X %s\n", f.Offset, f.Comment)
+		}
+		b.WriteString("}\n")
+
+		if g.offsets {
+			g.writeOffsets(&b, gs)
+		}
+	}
+
+	return format.Source(b.Bytes())
+}
+
+// writeOffsets declares the size of gs and the offset of each of its fields, e.g.
+// PlayerSize and PlayerHealthOffset, to be used with ReadPath or plain address arithmetic
+func (g *generator) writeOffsets(b *bytes.Buffer, gs *genStruct) {
+	fmt.Fprintf(b, "\n// Size and field offsets of %s\n", gs.CName)
+	fmt.Fprintf(b, "const (\n\t%sSize = 0x%X\n", gs.Name, gs.Size)
+	for _, f := range gs.Fields {
+		if f.Name != "_" {
+			fmt.Fprintf(b, "\t%s%sOffset = 0x%X\n", gs.Name, f.Name, f.Offset)
+		}
+	}
+	b.WriteString(")\n")
+}
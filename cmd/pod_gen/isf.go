@@ -0,0 +1,244 @@
+package main
+
+import (
+	"compress/gzip"
+	"debug/dwarf"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// isfType is a type descriptor of an ISF symbol table
+type isfType struct {
+	Kind        string   `json:"kind"` // base, pointer, array, struct, union, class, enum, bitfield, function
+	Name        string   `json:"name"`
+	Subtype     *isfType `json:"subtype"` // Pointed to or element type
+	Count       int64    `json:"count"`
+	BitPosition int64    `json:"bit_position"`
+	BitLength   int64    `json:"bit_length"`
+	Type        *isfType `json:"type"` // Storage type of a bit field
+}
+
+type isfField struct {
+	Offset int64   `json:"offset"`
+	Type   isfType `json:"type"`
+}
+
+type isfUserType struct {
+	Kind   string              `json:"kind"`
+	Size   int64               `json:"size"`
+	Fields map[string]isfField `json:"fields"`
+}
+
+type isfBaseType struct {
+	Kind   string `json:"kind"` // int, char, bool, float, void
+	Size   int64  `json:"size"`
+	Signed bool   `json:"signed"`
+}
+
+type isfEnum struct {
+	Size      int64            `json:"size"`
+	Base      string           `json:"base"`
+	Constants map[string]int64 `json:"constants"`
+}
+
+// isfSource finds struct types in a symbol table in the Intermediate Symbol Format of
+// Volatility 3, the JSON its pdbconv.py produces from a PDB. Types are converted to their
+// debug/dwarf equivalent so the DWARF generator applies unchanged.
+type isfSource struct {
+	BaseTypes map[string]isfBaseType `json:"base_types"`
+	UserTypes map[string]isfUserType `json:"user_types"`
+	Enums     map[string]isfEnum     `json:"enums"`
+
+	structs map[string]*dwarf.StructType
+}
+
+// openISF reads an ISF JSON file, gzip compressed when its name ends in .gz
+func openISF(path string) (*isfSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	source := &isfSource{structs: make(map[string]*dwarf.StructType)}
+	if err := json.NewDecoder(r).Decode(source); err != nil {
+		return nil, fmt.Errorf("invalid ISF JSON: %w", err)
+	}
+	if len(source.UserTypes) == 0 {
+		return nil, fmt.Errorf("no user_types in ISF JSON")
+	}
+	return source, nil
+}
+
+func (s *isfSource) Lookup(name string) (*dwarf.StructType, error) {
+	ut, ok := s.UserTypes[name]
+	if !ok || ut.Kind == "union" || ut.Size <= 0 {
+		return nil, fmt.Errorf("struct %s not found", name)
+	}
+	return s.structType(name)
+}
+
+func (s *isfSource) Structs() (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	for name, ut := range s.UserTypes {
+		if ut.Kind != "union" && ut.Size > 0 {
+			sizes[name] = ut.Size
+		}
+	}
+	return sizes, nil
+}
+
+// structType converts the user type called name, each type is converted once so pointers
+// back to a struct being converted end the recursion
+func (s *isfSource) structType(name string) (*dwarf.StructType, error) {
+	if st, ok := s.structs[name]; ok {
+		return st, nil
+	}
+	ut, ok := s.UserTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown user type %s", name)
+	}
+
+	st := &dwarf.StructType{
+		CommonType: dwarf.CommonType{ByteSize: ut.Size, Name: name},
+		StructName: name,
+		Kind:       ut.Kind,
+	}
+	s.structs[name] = st
+
+	names := make([]string, 0, len(ut.Fields))
+	for fieldName := range ut.Fields {
+		names = append(names, fieldName)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := ut.Fields[names[i]], ut.Fields[names[j]]
+		if a.Offset != b.Offset {
+			return a.Offset < b.Offset
+		}
+		if a.Type.Kind == "bitfield" && b.Type.Kind == "bitfield" {
+			return a.Type.BitPosition < b.Type.BitPosition
+		}
+		return names[i] < names[j]
+	})
+
+	for _, fieldName := range names {
+		f := ut.Fields[fieldName]
+		field := &dwarf.StructField{Name: fieldName, ByteOffset: f.Offset}
+
+		desc := f.Type
+		if desc.Kind == "bitfield" {
+			if desc.Type == nil {
+				return nil, fmt.Errorf("%s.%s: bit field without storage type", name, fieldName)
+			}
+			desc = *desc.Type
+			field.BitSize = f.Type.BitLength
+			field.DataBitOffset = f.Offset*8 + f.Type.BitPosition
+		}
+
+		t, err := s.typ(desc)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", name, fieldName, err)
+		}
+		field.Type = t
+		field.ByteSize = t.Size()
+		st.Field = append(st.Field, field)
+	}
+
+	return st, nil
+}
+
+// typ converts a type descriptor
+func (s *isfSource) typ(desc isfType) (dwarf.Type, error) {
+	switch desc.Kind {
+	case "base":
+		return s.baseType(desc.Name)
+	case "pointer":
+		size := int64(8)
+		if bt, ok := s.BaseTypes["pointer"]; ok && bt.Size > 0 {
+			size = bt.Size
+		}
+		pt := &dwarf.PtrType{CommonType: dwarf.CommonType{ByteSize: size}, Type: &dwarf.VoidType{}}
+		if desc.Subtype != nil {
+			sub, err := s.typ(*desc.Subtype)
+			if err != nil {
+				return nil, err
+			}
+			pt.Type = sub
+		}
+		return pt, nil
+	case "array":
+		if desc.Subtype == nil {
+			return nil, fmt.Errorf("array without element type")
+		}
+		elem, err := s.typ(*desc.Subtype)
+		if err != nil {
+			return nil, err
+		}
+		return &dwarf.ArrayType{
+			CommonType: dwarf.CommonType{ByteSize: desc.Count * elem.Size()},
+			Type:       elem,
+			Count:      desc.Count,
+		}, nil
+	case "struct", "class", "union":
+		return s.structType(desc.Name)
+	case "enum":
+		enum, ok := s.Enums[desc.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown enum %s", desc.Name)
+		}
+		et := &dwarf.EnumType{CommonType: dwarf.CommonType{ByteSize: enum.Size, Name: desc.Name}, EnumName: desc.Name}
+		for name, val := range enum.Constants {
+			et.Val = append(et.Val, &dwarf.EnumValue{Name: name, Val: val})
+		}
+		sort.Slice(et.Val, func(i, j int) bool {
+			return et.Val[i].Val < et.Val[j].Val
+		})
+		return et, nil
+	case "function":
+		return &dwarf.FuncType{CommonType: dwarf.CommonType{ByteSize: -1}, ReturnType: &dwarf.VoidType{}}, nil
+	}
+	return nil, fmt.Errorf("unknown type kind %q", desc.Kind)
+}
+
+// baseType converts a base type
+func (s *isfSource) baseType(name string) (dwarf.Type, error) {
+	bt, ok := s.BaseTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown base type %s", name)
+	}
+
+	basic := dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: bt.Size, Name: name}}
+	switch bt.Kind {
+	case "int":
+		if bt.Signed {
+			return &dwarf.IntType{BasicType: basic}, nil
+		}
+		return &dwarf.UintType{BasicType: basic}, nil
+	case "char":
+		if bt.Signed {
+			return &dwarf.CharType{BasicType: basic}, nil
+		}
+		return &dwarf.UcharType{BasicType: basic}, nil
+	case "bool":
+		return &dwarf.BoolType{BasicType: basic}, nil
+	case "float":
+		return &dwarf.FloatType{BasicType: basic}, nil
+	case "void":
+		return &dwarf.VoidType{CommonType: basic.CommonType}, nil
+	}
+	return nil, fmt.Errorf("unknown base type kind %q of %s", bt.Kind, name)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+func main() {
+	binFlag := flag.String("bin", "", "Binary with DWARF debug info (ELF, Mach-O or PE)")
+	isfFlag := flag.String("isf", "", "Symbol table JSON in Volatility ISF format, e.g. converted from a PDB with pdbconv.py (instead of --bin)")
+	typesFlag := flag.String("types", "", "Comma separated struct, class or typedef names to generate (e.g. Player,GameState)")
+	packageFlag := flag.String("package", "main", "Package name of the generated file")
+	outFlag := flag.String("out", "", "Write the generated Go source to this file instead of stdout")
+	offsetsFlag := flag.Bool("offsets", false, "Also declare the size and field offsets of every struct as constants")
+	listFlag := flag.Bool("list", false, "List the struct types instead of generating code")
+	flag.Parse()
+
+	if (*binFlag == "") == (*isfFlag == "") || (*typesFlag == "" && !*listFlag) {
+		fmt.Println("Error: one of --bin or --isf, and --types (or --list) are required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var source typeSource
+	var input string
+	var err error
+	if *binFlag != "" {
+		input = *binFlag
+		source, err = openDWARF(input)
+	} else {
+		input = *isfFlag
+		source, err = openISF(input)
+	}
+	if err != nil {
+		fmt.Printf("Error reading types from %s: %v\n", input, err)
+		os.Exit(1)
+	}
+
+	if *listFlag {
+		listStructs(source)
+		return
+	}
+
+	gen := newGenerator(source, *offsetsFlag)
+	for _, name := range strings.Split(*typesFlag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if err := gen.Add(name); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	code, err := gen.Source(*packageFlag, input)
+	if err != nil {
+		fmt.Printf("Error formatting generated code: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *outFlag == "" {
+		os.Stdout.Write(code)
+		return
+	}
+	if err := os.WriteFile(*outFlag, code, 0644); err != nil {
+		fmt.Printf("Error writing %s: %v\n", *outFlag, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote %d structs to %s\n", len(gen.structs), *outFlag)
+}
+
+// listStructs prints the names and sizes of the complete struct types
+func listStructs(source typeSource) {
+	sizes, err := source.Structs()
+	if err != nil {
+		fmt.Printf("Error reading types: %v\n", err)
+		os.Exit(1)
+	}
+
+	names := make([]string, 0, len(sizes))
+	for name := range sizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%6d  %s\n", sizes[name], name)
+	}
+}
@@ -1,26 +1,29 @@
 package main
 
 import (
-	"encoding/hex"
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
 
 	"gomem/hexdump"
 	"gomem/process"
+	"gomem/search"
 )
 
-// AOBPart represents a part of the AOB pattern
-type AOBPart struct {
-	Value byte
-	Mask  byte // 0xFF for exact match, 0x00 for wildcard
-}
-
 func main() {
 	pidFlag := flag.Int("pid", 0, "Process ID to attach to")
-	aobFlag := flag.String("aob", "", "Array of bytes to scan for (e.g., '00,ba,ad,??,f0')")
+	aobFlag := flag.String("aob", "", "Array of bytes to scan for (e.g., '48 8B ?? 89 05', '4? 8B ?C', '00,ba,ad,??,f0' or '\\x48\\x8b\\x05')")
+	clustersFlag := flag.Bool("clusters", false, "Report runs of matches with a constant stride (probable struct arrays) instead of hexdumping every match")
+	suspendFlag := flag.Bool("suspend", false, "Suspend the process during the scan, so values changing meanwhile aren't missed")
+	writableFlag := flag.Bool("writable", false, "Only scan writable regions")
+	execFlag := flag.Bool("exec", false, "Only scan executable regions")
+	noFilesFlag := flag.Bool("no-files", false, "Skip file-backed regions (executables, libraries, mapped files)")
+	moduleFlag := flag.String("module", "", "Only scan the regions of this module (e.g., libc.so.6 or game.exe)")
+	maxRegionFlag := flag.Uint("max-region", 0, "Skip regions larger than this many bytes (0 = no limit)")
+	chunkFlag := flag.Uint("chunk", 0, "Read regions in chunks of at most this many bytes (0 = 64 MiB)")
+	maxFlag := flag.Int("max", 0, "Stop after this many matches, the ones with the lowest addresses (0 = no limit)")
+	signatureFlag := flag.String("signature", "", "Instead of scanning, print the shortest pattern unique in its module matching at this address (hex or an address expression, e.g. game.exe+0x1234)")
+	sigLenFlag := flag.Int("sig-len", 64, "Maximum length of a signature generated with --signature")
 	flag.Parse()
 
 	if *pidFlag == 0 {
@@ -29,14 +32,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *signatureFlag != "" {
+		generateSignature(*pidFlag, *signatureFlag, *sigLenFlag)
+		return
+	}
+
 	if *aobFlag == "" {
-		fmt.Println("Error: --aob is required")
+		fmt.Println("Error: --aob or --signature is required")
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	// Parse AOB string
-	pattern, err := parseAOB(*aobFlag)
+	pattern, err := process.NewAOBFromString(*aobFlag)
 	if err != nil {
 		fmt.Printf("Error parsing AOB: %v\n", err)
 		os.Exit(1)
@@ -51,12 +59,7 @@ func main() {
 	defer proc.Close()
 
 	fmt.Printf("Attached to process %d\n", *pidFlag)
-	fmt.Printf("Scanning for pattern: %s\n", formatPattern(pattern))
-
-	// Scan memory
-	// Since the Process interface doesn't have a generic Scan method yet,
-	// we'll implement a basic scanner here using ReadMemoryMap and ReadMemory.
-	// In a real implementation, this should be part of the Process interface.
+	fmt.Printf("Scanning for pattern: %s\n", pattern)
 
 	// Update memory map
 	if err := proc.UpdateMemoryMap(); err != nil {
@@ -64,111 +67,74 @@ func main() {
 		os.Exit(1)
 	}
 
-	matches, err := scanMemory(proc, pattern)
+	matches, err := proc.ScanWithOptions(pattern, process.ScanOptions{
+		ContextBefore:     16,
+		ContextAfter:      32,
+		Suspend:           *suspendFlag,
+		WritableOnly:      *writableFlag,
+		ExecutableOnly:    *execFlag,
+		ExcludeFileBacked: *noFilesFlag,
+		Module:            *moduleFlag,
+		MaxRegionSize:     *maxRegionFlag,
+		ChunkSize:         *chunkFlag,
+		MaxResults:        *maxFlag,
+	})
 	if err != nil {
 		fmt.Printf("Error scanning memory: %v\n", err)
 		os.Exit(1)
 	}
 	fmt.Printf("Found %d matches:\n", len(matches))
 
-	for _, match := range matches {
-		fmt.Printf("Match at 0x%x:\n", match)
-
-		// Read context (16 bytes before and 32 bytes after)
-		start := match - 16
-		size := process.ProcessMemorySize(48) // 16 + len(pattern) + padding
-		if len(pattern) > 16 {
-			size = process.ProcessMemorySize(32 + len(pattern))
-		}
-
-		data, err := proc.ReadMemory(start, size)
-		if err == nil {
-			// Highlight the match
-			hlPattern := make([]byte, len(pattern))
-			for i, p := range pattern {
-				hlPattern[i] = p.Value
-			}
-
-			// Use hexdump with highlighting
-			// Note: Highlighting with wildcards is tricky with simple byte matching
-			// For now, we just dump the memory
-			fmt.Println(hexdump.HexdumpBasic(data, uint64(start), uint(size), nil))
+	if *clustersFlag {
+		mm, _ := proc.GetMemoryMap()
+		clusters := search.ClusterByStride(process.ScanMatchAddresses(matches), mm)
+		fmt.Printf("Found %d clusters:\n", len(clusters))
+		for _, cluster := range clusters {
+			fmt.Printf("  %s\n", cluster)
 		}
+		return
 	}
-}
 
-func parseAOB(aob string) ([]AOBPart, error) {
-	// Split by comma or space
-	parts := strings.FieldsFunc(aob, func(r rune) bool {
-		return r == ',' || r == ' '
-	})
-
-	var pattern []AOBPart
-
-	for _, part := range parts {
-		if part == "??" || part == "?" {
-			pattern = append(pattern, AOBPart{Value: 0, Mask: 0})
-			continue
-		}
+	for _, match := range matches {
+		fmt.Printf("Match at 0x%x:\n", match.Address)
 
-		// Handle type:value expansion (e.g., uint32:1234)
-		if strings.Contains(part, ":") {
-			// TODO: Implement type expansion
-			return nil, fmt.Errorf("type expansion not yet implemented: %s", part)
+		// Decode the [type:name] captures from the retained bytes
+		if len(pattern.Captures) > 0 {
+			fields, err := pattern.DecodeCaptures(match.Address, match.Data[match.MatchOffset():], process.ByteOrderOf(proc))
+			if err != nil {
+				fmt.Printf("  Error decoding captures: %v\n", err)
+			}
+			for _, c := range pattern.Captures {
+				if v, ok := fields[c.Name]; ok {
+					fmt.Printf("  %s (%s) = 0x%X\n", c.Name, c.Type, v)
+				}
+			}
 		}
 
-		// Parse hex
-		val, err := strconv.ParseUint(part, 16, 8)
-		if err != nil {
-			return nil, fmt.Errorf("invalid hex byte: %s", part)
-		}
-		pattern = append(pattern, AOBPart{Value: byte(val), Mask: 0xFF})
+		// The scanner retained 16 bytes before and 32 bytes after the match, no need to re-read
+		fmt.Println(hexdump.HexdumpBasic(match.Data, uint64(match.DataAddress), uint(len(match.Data)), nil))
 	}
-
-	return pattern, nil
 }
 
-func formatPattern(pattern []AOBPart) string {
-	var sb strings.Builder
-	for i, p := range pattern {
-		if i > 0 {
-			sb.WriteString(" ")
-		}
-		if p.Mask == 0 {
-			sb.WriteString("??")
-		} else {
-			sb.WriteString(hex.EncodeToString([]byte{p.Value}))
-		}
+// generateSignature prints a signature for the address expr, hex or an address expression
+func generateSignature(pid int, expr string, maxLen int) {
+	proc, err := getProcess(pid)
+	if err != nil {
+		fmt.Printf("Error attaching to process %d: %v\n", pid, err)
+		os.Exit(1)
 	}
-	return sb.String()
-}
+	defer proc.Close()
 
-func scanMemory(proc process.Process, pattern []AOBPart) ([]process.ProcessMemoryAddress, error) {
-	// Create AOB object
-	aobObj, err := process.NewAOB(
-		func() []byte {
-			p := make([]byte, len(pattern))
-			for i, part := range pattern {
-				p[i] = part.Value
-			}
-			return p
-		}(),
-		func() []byte {
-			m := make([]byte, len(pattern))
-			for i, part := range pattern {
-				m[i] = part.Mask
-			}
-			return m
-		}(),
-	)
+	addr, err := process.ParseAddress(proc, expr)
 	if err != nil {
-		return nil, fmt.Errorf("Error creating AOB: %v", err)
+		fmt.Printf("Error resolving %s: %v\n", expr, err)
+		os.Exit(1)
 	}
 
-	matches, err := proc.Scan(aobObj)
+	signature, err := process.GenerateSignature(proc, addr, maxLen)
 	if err != nil {
-		return nil, fmt.Errorf("Scan error: %v", err)
+		fmt.Printf("Error generating signature: %v\n", err)
+		os.Exit(1)
 	}
-
-	return matches, nil
+	fmt.Printf("Signature for 0x%X (%d bytes):\n%s\n", uint64(addr), len(signature.Pattern), signature)
 }
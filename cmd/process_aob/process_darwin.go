@@ -0,0 +1,10 @@
+package main
+
+import (
+	"gomem/process"
+	"gomem/process_darwin"
+)
+
+func getProcess(pid int) (process.Process, error) {
+	return process_darwin.NewWithPID(process.ProcessID(pid))
+}
@@ -1,10 +1,10 @@
 package main
 
 import (
-	"fmt"
 	"gomem/process"
+	"gomem/process_windows"
 )
 
 func getProcess(pid int) (process.Process, error) {
-	return nil, fmt.Errorf("windows not supported on this build")
+	return process_windows.NewWithPID(process.ProcessID(pid))
 }
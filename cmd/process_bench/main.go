@@ -0,0 +1,328 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"gomem/process"
+	"gomem/process_blob"
+)
+
+// benchResult is one row of the comparison table
+type benchResult struct {
+	Name     string
+	Ops      int
+	Bytes    uint64
+	Elapsed  time.Duration
+	Baseline string // Name of the row the speedup is relative to, empty for none
+	Err      error  // Set when the benchmark could not run
+}
+
+func main() {
+	pidFlag := flag.Int("pid", 0, "Process ID to benchmark against (0 for a synthetic in-memory process)")
+	syntheticFlag := flag.Int("synthetic-mb", 64, "Size of the synthetic process in MiB")
+	readsFlag := flag.Int("reads", 20000, "Number of reads per read benchmark")
+	sizeFlag := flag.Int("size", 256, "Size of each read in bytes")
+	batchFlag := flag.Int("batch", 1000, "Number of addresses per ReadBlobs call")
+	maxdopFlag := flag.Uint("maxdop", uint(runtime.NumCPU()), "Workers of the parallel scan")
+	seedFlag := flag.Int64("seed", 1, "Seed of the random addresses and synthetic data")
+	matchFlag := flag.Int("match-mb", 64, "MiB of process memory the pattern matchers search")
+	flag.Parse()
+
+	rng := rand.New(rand.NewSource(*seedFlag))
+
+	var proc process.Process
+	var err error
+	if *pidFlag == 0 {
+		proc, err = syntheticProcess(rng, *syntheticFlag<<20)
+		if err != nil {
+			fmt.Printf("Error creating synthetic process: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Synthetic process: %d MiB\n", *syntheticFlag)
+	} else {
+		proc, err = getProcess(*pidFlag)
+		if err != nil {
+			fmt.Printf("Error attaching to process %d: %v\n", *pidFlag, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Attached to process %d\n", *pidFlag)
+	}
+	defer proc.Close()
+
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		fmt.Printf("Error reading memory map: %v\n", err)
+		os.Exit(1)
+	}
+
+	size := process.ProcessMemorySize(*sizeFlag)
+	addrs := randomAddresses(rng, proc, size, *readsFlag)
+	if len(addrs) == 0 {
+		fmt.Println("Error: no readable region large enough for the read size")
+		os.Exit(1)
+	}
+
+	var readable uint64
+	for _, region := range mm {
+		if region.IsReadable() {
+			readable += uint64(region.Size)
+		}
+	}
+	fmt.Printf("Memory map: %d regions, %.1f MiB readable\n", len(mm), float64(readable)/(1<<20))
+	fmt.Printf("Reads: %d x %d bytes, ReadBlobs batches of %d\n\n", len(addrs), size, *batchFlag)
+
+	results := []benchResult{
+		benchReadMemory(proc, addrs, size),
+		benchReadBlobs(proc, addrs, size, *batchFlag),
+		benchScan(proc, readable, 0),
+		benchScan(proc, readable, *maxdopFlag),
+	}
+
+	data := readSample(proc, *matchFlag<<20)
+	for _, pattern := range matchPatterns {
+		results = append(results,
+			benchMatch(pattern, data, false),
+			benchMatch(pattern, data, true))
+	}
+
+	printResults(results)
+}
+
+// syntheticProcess returns an in-memory process of random data split into regions of 1-8 MiB
+func syntheticProcess(rng *rand.Rand, total int) (process.Process, error) {
+	regions := make(map[process.ProcessMemoryAddress][]byte)
+	addr := process.ProcessMemoryAddress(0x10000000)
+	for total > 0 {
+		n := min(total, (1+rng.Intn(8))<<20)
+		data := make([]byte, n)
+		rng.Read(data)
+		regions[addr] = data
+		addr += process.ProcessMemoryAddress(n + 0x10000) // Keep a gap between regions
+		total -= n
+	}
+	return process_blob.NewProcessFromBytes(regions)
+}
+
+// randomAddresses picks count addresses in readable regions where size bytes can be read,
+// regions weighted by their size
+func randomAddresses(rng *rand.Rand, proc process.Process, size process.ProcessMemorySize, count int) []process.ProcessMemoryAddress {
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		return nil
+	}
+
+	var regions []process.ProcessMemoryAddress
+	var ends []uint64
+	var total uint64
+	for _, region := range mm {
+		if !region.IsReadable() || uint64(region.Size) < uint64(size) || !proc.IsValidAddress(process.ProcessMemoryAddress(region.Address)) {
+			continue
+		}
+		// Skip regions that fail to read, e.g. guard pages reported as readable
+		if _, err := proc.ReadMemory(process.ProcessMemoryAddress(region.Address), size); err != nil {
+			continue
+		}
+		total += uint64(region.Size) - uint64(size) + 1
+		regions = append(regions, process.ProcessMemoryAddress(region.Address))
+		ends = append(ends, total)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	addrs := make([]process.ProcessMemoryAddress, count)
+	for i := range addrs {
+		pick := uint64(rng.Int63n(int64(total)))
+		j := 0
+		for ends[j] <= pick {
+			j++
+		}
+		start := uint64(0)
+		if j > 0 {
+			start = ends[j-1]
+		}
+		addrs[i] = regions[j] + process.ProcessMemoryAddress(pick-start)
+	}
+	return addrs
+}
+
+// benchReadMemory reads every address with its own ReadMemory call
+func benchReadMemory(proc process.Process, addrs []process.ProcessMemoryAddress, size process.ProcessMemorySize) benchResult {
+	result := benchResult{Name: "ReadMemory"}
+	start := time.Now()
+	for _, addr := range addrs {
+		if data, err := proc.ReadMemory(addr, size); err == nil {
+			result.Ops++
+			result.Bytes += uint64(len(data))
+		}
+	}
+	result.Elapsed = time.Since(start)
+	return result
+}
+
+// benchReadBlobs reads the same addresses in ReadBlobs batches
+func benchReadBlobs(proc process.Process, addrs []process.ProcessMemoryAddress, size process.ProcessMemorySize, batch int) benchResult {
+	batch = max(batch, 1)
+	result := benchResult{Name: fmt.Sprintf("ReadBlobs (batch %d)", batch), Baseline: "ReadMemory"}
+	start := time.Now()
+	for i := 0; i < len(addrs); i += batch {
+		for _, r := range proc.ReadBlobs(addrs[i:min(i+batch, len(addrs))], size) {
+			if r.Err == nil {
+				result.Ops++
+				result.Bytes += uint64(size)
+			}
+		}
+	}
+	result.Elapsed = time.Since(start)
+	return result
+}
+
+// benchScan scans the whole process for a pattern that should not match, sequentially when
+// maxdop is 0
+func benchScan(proc process.Process, readable uint64, maxdop uint) benchResult {
+	pattern := process.AOB{
+		Pattern: []byte{0x9E, 0x3C, 0x51, 0xA7, 0x00, 0xD2, 0x6B, 0xF0},
+		Mask:    []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+	}
+
+	result := benchResult{Name: "Scan", Ops: 1, Bytes: readable}
+	start := time.Now()
+	var err error
+	if maxdop == 0 {
+		_, err = proc.Scan(pattern)
+	} else {
+		result.Name = fmt.Sprintf("ScanParallel (maxdop %d)", maxdop)
+		result.Baseline = "Scan"
+		_, err = proc.ScanParallel(pattern, maxdop)
+	}
+	result.Elapsed = time.Since(start)
+	result.Err = err
+	return result
+}
+
+// matchPattern is a pattern searched by the matcher benchmarks
+type matchPattern struct {
+	Name string
+	AOB  process.AOB
+}
+
+// matchPatterns are searched by the matcher benchmarks, exact and with wildcards
+var matchPatterns = []matchPattern{
+	{"exact", process.AOB{
+		Pattern: []byte{0x9E, 0x3C, 0x51, 0xA7, 0x00, 0xD2, 0x6B, 0xF0},
+		Mask:    []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+	}},
+	{"wildcards", process.AOB{
+		Pattern: []byte{0x48, 0x8B, 0x05, 0x00, 0x00, 0x00, 0x00, 0x48, 0x85, 0xC0, 0x74},
+		Mask:    []byte{0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF},
+	}},
+}
+
+// readSample reads up to size bytes of the readable regions into one buffer
+func readSample(proc process.Process, size int) []byte {
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		return nil
+	}
+
+	var data []byte
+	for _, region := range mm {
+		if len(data) >= size {
+			break
+		}
+		if !region.IsReadable() {
+			continue
+		}
+		n := min(uint64(region.Size), uint64(size-len(data)))
+		chunk, err := proc.ReadMemory(process.ProcessMemoryAddress(region.Address), process.ProcessMemorySize(n))
+		if err != nil {
+			continue
+		}
+		data = append(data, chunk...)
+	}
+	return data
+}
+
+// benchMatch searches data for a pattern with the skip table matcher, or with the naive
+// byte by byte comparison it replaced as the baseline
+func benchMatch(pattern matchPattern, data []byte, skipTable bool) benchResult {
+	naiveName := fmt.Sprintf("Match %s (naive)", pattern.Name)
+	result := benchResult{Name: naiveName, Ops: 1, Bytes: uint64(len(data))}
+	if len(data) == 0 {
+		result.Err = fmt.Errorf("no readable memory")
+		return result
+	}
+
+	start := time.Now()
+	if skipTable {
+		result.Name = fmt.Sprintf("Match %s (PatternMatcher)", pattern.Name)
+		result.Baseline = naiveName
+		process.NewPatternMatcher(pattern.AOB.Pattern, pattern.AOB.Mask).Find(data, 0, 1)
+	} else {
+		naiveMatches(data, pattern.AOB.Pattern, pattern.AOB.Mask)
+	}
+	result.Elapsed = time.Since(start)
+	return result
+}
+
+// naiveMatches compares the pattern at every offset of data
+func naiveMatches(data, pattern, mask []byte) []int {
+	var matches []int
+	for i := 0; i <= len(data)-len(pattern); i++ {
+		matched := true
+		for j := range pattern {
+			if data[i+j]&mask[j] != pattern[j]&mask[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// printResults prints the comparison table
+func printResults(results []benchResult) {
+	byName := make(map[string]benchResult, len(results))
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Benchmark\tOps\tTime\tOps/s\tMB/s\tSpeedup\t")
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(w, "%s\tfailed\t-\t-\t-\t-\t\n", r.Name)
+			continue
+		}
+
+		seconds := r.Elapsed.Seconds()
+		if seconds == 0 {
+			seconds = 1e-9
+		}
+
+		speedup := "-"
+		if base, ok := byName[r.Baseline]; ok && base.Err == nil && r.Elapsed > 0 {
+			speedup = fmt.Sprintf("%.2fx", base.Elapsed.Seconds()/r.Elapsed.Seconds())
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%v\t%.0f\t%.1f\t%s\t\n",
+			r.Name, r.Ops, r.Elapsed.Round(time.Microsecond), float64(r.Ops)/seconds,
+			float64(r.Bytes)/seconds/1e6, speedup)
+	}
+	w.Flush()
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("\n%s failed: %v\n", r.Name, r.Err)
+		}
+	}
+}
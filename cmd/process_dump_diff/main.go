@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gomem/coloransi"
+	"gomem/hexdump"
+	"gomem/process"
+	"gomem/process_blob"
+)
+
+func main() {
+	aFlag := flag.String("a", "", "Directory containing the first dump (before)")
+	bFlag := flag.String("b", "", "Directory containing the second dump (after)")
+	pathFlag := flag.String("path", "", "Only show regions whose path matches this pattern (e.g. libc*, [heap])")
+	contextFlag := flag.Int("context", 16, "Bytes of unchanged memory shown around each change")
+	maxFlag := flag.Int("max", 50, "Maximum number of changes to hexdump (0 = no limit)")
+	summaryFlag := flag.Bool("summary", false, "Only list the changed ranges, without hexdumps")
+	flag.Parse()
+
+	if *aFlag == "" || *bFlag == "" {
+		fmt.Println("Error: --a and --b are required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	a, b := loadDump(*aFlag), loadDump(*bFlag)
+
+	diff := process_blob.Diff(a, b)
+	fmt.Println(diff)
+
+	selected := map[uint64]bool(nil)
+	if *pathFlag != "" {
+		regions, err := b.RegionsByPath(*pathFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		selected = make(map[uint64]bool, len(regions))
+		for _, region := range regions {
+			selected[region.Address] = true
+		}
+	}
+
+	for _, region := range diff.Removed {
+		fmt.Printf("- %016x - %016x (%s) %s\n", region.Address, region.Address+uint64(region.Size), region.Perms, region.Path)
+	}
+	for _, region := range diff.Added {
+		fmt.Printf("+ %016x - %016x (%s) %s\n", region.Address, region.Address+uint64(region.Size), region.Perms, region.Path)
+	}
+
+	shown := 0
+	for _, region := range diff.Regions {
+		if selected != nil && !selected[region.Region.Address] {
+			continue
+		}
+
+		var changed uint64
+		for _, r := range region.Changes {
+			changed += r.Size
+		}
+		resized := ""
+		if region.Resized {
+			resized = ", resized"
+		}
+		fmt.Printf("\n%016x - %016x (%s) %s: %d bytes changed in %d ranges%s\n", region.Region.Address,
+			region.Region.Address+uint64(region.Region.Size), region.Region.Perms, region.Region.Path,
+			changed, len(region.Changes), resized)
+
+		for _, w := range windows(region, *contextFlag) {
+			if *maxFlag > 0 && shown >= *maxFlag {
+				break
+			}
+			shown++
+
+			if *summaryFlag {
+				fmt.Printf("  0x%x: %d bytes\n", uint64(w.changes[0].Address), w.changed())
+				continue
+			}
+			render(a, b, w)
+		}
+	}
+	if *maxFlag > 0 && shown >= *maxFlag {
+		fmt.Printf("\nStopped after %d changes, raise --max to see more\n", shown)
+	}
+}
+
+// loadDump loads the dump in dir or exits
+func loadDump(dir string) *process_blob.ProcessDump {
+	dump := process_blob.NewProcessDump()
+	if err := dump.Load(dir); err != nil {
+		fmt.Printf("Error loading dump from %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+	return dump
+}
+
+// window is a range of memory rendered at once, with the changes inside it
+type window struct {
+	start, end uint64
+	changes    []process_blob.ByteRange
+}
+
+// changed returns the number of changed bytes of the window
+func (w window) changed() uint64 {
+	var total uint64
+	for _, r := range w.changes {
+		total += r.Size
+	}
+	return total
+}
+
+// windows groups the changes of a region whose context overlaps, aligned to hexdump lines
+// and clipped to the region
+func windows(region process_blob.RegionDiff, context int) []window {
+	regionStart := region.Region.Address
+	regionEnd := regionStart + uint64(region.Region.Size)
+
+	around := uint64(max(context, 0))
+
+	var result []window
+	for _, r := range region.Changes {
+		start := max((uint64(r.Address)-min(around, uint64(r.Address)-regionStart))&^15, regionStart)
+		end := min((uint64(r.End())+around+15)&^15, regionEnd)
+
+		if last := len(result) - 1; last >= 0 && start <= result[last].end {
+			result[last].end = max(result[last].end, end)
+			result[last].changes = append(result[last].changes, r)
+			continue
+		}
+		result = append(result, window{start: start, end: end, changes: []process_blob.ByteRange{r}})
+	}
+	return result
+}
+
+// render hexdumps a window in both dumps, with the changed bytes highlighted
+func render(a, b *process_blob.ProcessDump, w window) {
+	changed := func(offset uint64) bool {
+		for _, r := range w.changes {
+			if offset >= uint64(r.Address) && offset < uint64(r.End()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, side := range []struct {
+		label string
+		dump  *process_blob.ProcessDump
+		color coloransi.ColorCode
+	}{
+		{"before", a, coloransi.BrightRed},
+		{"after", b, coloransi.BrightGreen},
+	} {
+		data, err := side.dump.ReadMemory(process.ProcessMemoryAddress(w.start), process.ProcessMemorySize(w.end-w.start))
+		if len(data) == 0 {
+			fmt.Printf("  %s: %v\n", side.label, err)
+			continue
+		}
+
+		options := hexdump.DefaultOptions()
+		options.StartOffset = w.start
+		options.OffsetWidth = 16
+		options.ByteColor = func(offset uint64, _ byte) (coloransi.ColorCode, bool) {
+			return side.color, changed(offset)
+		}
+		fmt.Printf("  %s:\n", side.label)
+		fmt.Print(hexdump.Dump(data, options))
+	}
+}
@@ -1,71 +1,241 @@
 package main
 
 import (
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 
 	"gomem/hexdump"
+	"gomem/pod"
 	"gomem/process"
+	"gomem/process/memory_map"
 	"gomem/process_blob"
 )
 
 func main() {
 	fromFlag := flag.String("from", "", "Directory containing the dump")
-	addrFlag := flag.String("addr", "", "Address to read from (hex)")
+	coreFlag := flag.String("core", "", "ELF core file to load instead of a dump directory")
+	noVerifyFlag := flag.Bool("no-verify", false, "Load the blobs without checking them against the checksums of the dump")
+	addrFlag := flag.String("addr", "", "Address to read from (hex or expression, e.g. libc.so.6+0x1000+[0x18])")
 	sizeFlag := flag.Int("size", 256, "Number of bytes to hexdump")
+	pathFlag := flag.String("path", "", "Only list regions whose path matches this pattern (e.g. libc*, [heap])")
+	exportFlag := flag.String("export", "", "Write the raw bytes at --addr to this file instead of hexdumping (whole region if --size is 0)")
+	inferFlag := flag.String("infer", "", "Print a draft pod struct with this name inferred from the --size bytes at --addr instead of hexdumping")
+	byteOrderFlag := flag.String("byte-order", "", "Byte order of the dumped memory (little or big), overrides the one recorded in the dump")
+	exportCoreFlag := flag.String("export-core", "", "Write the dump as an ELF core file for gdb and other tools")
+	writeFlag := flag.String("write", "", "Hex bytes to patch into the dump at --addr (e.g. \"90 90\"), see --output")
+	outputFlag := flag.String("output", "", "Save the dump, with the --write patch, to this directory (e.g. to convert a --core)")
+	compareFlag := flag.String("compare", "", "Compare with the dump in this directory and list the differing ranges (regions limited by --path)")
 	flag.Parse()
 
-	if *fromFlag == "" {
-		fmt.Println("Error: --from is required")
+	if (*fromFlag == "") == (*coreFlag == "") {
+		fmt.Println("Error: one of --from or --core is required")
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	// Load the dump
+	source := *fromFlag
 	dump := process_blob.NewProcessDump()
-	if err := dump.Load(*fromFlag); err != nil {
+	if *coreFlag != "" {
+		source = *coreFlag
+		var err error
+		if dump, err = process_blob.LoadCore(*coreFlag); err != nil {
+			fmt.Printf("Error loading core %s: %v\n", *coreFlag, err)
+			os.Exit(1)
+		}
+	} else if err := dump.LoadWithOptions(*fromFlag, process_blob.LoadOptions{SkipVerify: *noVerifyFlag}); err != nil {
 		fmt.Printf("Error loading dump from %s: %v\n", *fromFlag, err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Loaded dump from %s\n", *fromFlag)
+	if *byteOrderFlag != "" {
+		order, err := process.ParseByteOrder(*byteOrderFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		dump.SetByteOrder(order)
+	}
+
+	fmt.Printf("Loaded dump from %s\n", source)
 	fmt.Printf("Process Name: %s\n", dump.Name)
 	fmt.Printf("PID: %d\n", dump.PID)
+	fmt.Printf("Byte Order: %s\n", process.ByteOrderName(dump.ByteOrder()))
 	fmt.Printf("Memory Regions: %d\n", len(dump.MemoryMap))
+	if len(dump.Modules) > 0 {
+		fmt.Printf("Modules: %d\n", len(dump.Modules))
+	}
+	if len(dump.Threads) > 0 {
+		fmt.Printf("Threads: %d\n", len(dump.Threads))
+	}
+
+	if *exportCoreFlag != "" {
+		if err := dump.ExportCore(*exportCoreFlag); err != nil {
+			fmt.Printf("Error exporting core: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported core to %s\n", *exportCoreFlag)
+		return
+	}
+
+	if *compareFlag != "" {
+		compareDumps(dump, source, *compareFlag, *pathFlag)
+		return
+	}
+
+	if *writeFlag != "" && *addrFlag == "" {
+		fmt.Println("Error: --write needs --addr")
+		os.Exit(1)
+	}
+	if *outputFlag != "" && *addrFlag == "" {
+		saveDump(dump, *outputFlag)
+		return
+	}
 
 	// If no address is specified, just print summary and exit
 	if *addrFlag == "" {
+		regions := dump.MemoryMap
+		if *pathFlag != "" {
+			var err error
+			regions, err = dump.RegionsByPath(*pathFlag)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			fmt.Printf("\n%s", dump.Stats())
+		}
+
 		fmt.Println("\nMemory Map:")
-		for _, region := range dump.MemoryMap {
-			fmt.Printf("  %016x - %016x (%s) %d bytes\n",
-				region.Address, region.Address+uint64(region.Size), region.Perms, region.Size)
+		for _, region := range regions {
+			fmt.Printf("  %016x - %016x (%s) %d bytes %s\n",
+				region.Address, region.Address+uint64(region.Size), region.Perms, region.Size, region.Path)
 		}
 		return
 	}
 
-	// Parse address
-	addrStr := *addrFlag
-	if strings.HasPrefix(addrStr, "0x") {
-		addrStr = addrStr[2:]
-	}
-	addrVal, err := strconv.ParseUint(addrStr, 16, 64)
+	// Parse address, plain hex or an address expression (e.g. libc.so.6+0x1000+[0x18])
+	addr, err := process.ParseAddress(dump, *addrFlag)
 	if err != nil {
 		fmt.Printf("Error parsing address: %v\n", err)
 		os.Exit(1)
 	}
-	addr := process.ProcessMemoryAddress(addrVal)
 
-	// Read memory
-	data, err := dump.ReadMemory(addr, process.ProcessMemorySize(*sizeFlag))
-	if err != nil {
-		fmt.Printf("Error reading memory at 0x%x: %v\n", addr, err)
+	// Patch the dump
+	if *writeFlag != "" {
+		data, err := hex.DecodeString(strings.ReplaceAll(*writeFlag, " ", ""))
+		if err != nil {
+			fmt.Printf("Error parsing --write: %v\n", err)
+			os.Exit(1)
+		}
+		if err := dump.WriteMemory(addr, data); err != nil {
+			fmt.Printf("Error writing 0x%x: %v\n", addr, err)
+			os.Exit(1)
+		}
+		for _, r := range dump.Modifications() {
+			fmt.Printf("Modified 0x%x (%d bytes)\n", uint64(r.Address), r.Size)
+		}
+	}
+	if *outputFlag != "" {
+		saveDump(dump, *outputFlag)
+		return
+	}
+
+	// Export raw bytes
+	if *exportFlag != "" {
+		if *sizeFlag == 0 {
+			err = dump.ExportRegion(addr, *exportFlag)
+		} else {
+			err = dump.ExportRange(addr, process.ProcessMemorySize(*sizeFlag), *exportFlag)
+		}
+		if err != nil {
+			fmt.Printf("Error exporting 0x%x: %v\n", addr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported 0x%x to %s\n", addr, *exportFlag)
+		return
+	}
+
+	if *inferFlag != "" {
+		layout, err := pod.InferLayout(dump, addr, process.ProcessMemorySize(*sizeFlag))
+		if err != nil {
+			fmt.Printf("Error inferring layout at 0x%x: %v\n", addr, err)
+			os.Exit(1)
+		}
+		fmt.Println()
+		fmt.Print(layout.GoStruct(*inferFlag))
+		return
+	}
+
+	// Read memory, keeping what can be read around region edges
+	read := dump.ReadMemoryPartial(addr, process.ProcessMemorySize(*sizeFlag))
+	if read.ValidBytes() == 0 {
+		fmt.Printf("Error reading memory at 0x%x: %v\n", addr, read.Err())
 		os.Exit(1)
 	}
 
-	// Hexdump
+	// Hexdump the readable ranges
 	fmt.Printf("\nHexdump at 0x%x (%d bytes):\n", addr, *sizeFlag)
-	fmt.Println(hexdump.HexdumpBasic(data, uint64(addr), uint(*sizeFlag), dump.MemoryMap))
+	for _, r := range read.Ranges {
+		if r.Err != nil {
+			fmt.Printf("0x%x - 0x%x unreadable: %v\n\n", r.Address, r.End(), r.Err)
+			continue
+		}
+		data := read.Data[r.Address-addr : r.End()-addr]
+		fmt.Println(hexdump.HexdumpBasicByteOrder(data, uint64(r.Address), uint(r.Size), dump.MemoryMap, dump.ByteOrder()))
+	}
+}
+
+// saveDump writes dump to dir
+func saveDump(dump *process_blob.ProcessDump, dir string) {
+	if err := dump.Save(dir); err != nil {
+		fmt.Printf("Error saving dump to %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Saved dump to %s\n", dir)
+}
+
+// compareDumps compares dump (loaded from the from directory or core) with the dump in dir and prints the differing ranges
+func compareDumps(dump *process_blob.ProcessDump, from, dir, pattern string) {
+	other := process_blob.NewProcessDump()
+	if err := other.Load(dir); err != nil {
+		fmt.Printf("Error loading dump from %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+
+	var filter memory_map.RegionFilter
+	if pattern != "" {
+		regions, err := dump.RegionsByPath(pattern)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		selected := make(map[uint64]bool, len(regions))
+		for _, region := range regions {
+			selected[region.Address] = true
+		}
+		filter = func(item memory_map.MemoryMapItem) bool {
+			return selected[item.Address]
+		}
+	}
+
+	result, err := process.CompareProcesses(dump, other, filter)
+	if err != nil {
+		fmt.Printf("Error comparing with %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\nCompared %d bytes with %s: %d ranges, %d bytes differ\n", result.Compared, dir, len(result.Diffs), result.DiffBytes())
+	for _, diff := range result.Diffs {
+		fmt.Printf("  %s\n", diff)
+	}
+	for _, region := range result.OnlyInA {
+		fmt.Printf("  only in %s: %016x %s\n", from, region.Address, region.Path)
+	}
+	for _, region := range result.OnlyInB {
+		fmt.Printf("  only in %s: %016x %s\n", dir, region.Address, region.Path)
+	}
 }
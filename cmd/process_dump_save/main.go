@@ -4,12 +4,16 @@ import (
 	"flag"
 	"fmt"
 	"os"
+
+	"gomem/process"
 )
 
 func main() {
 	pidFlag := flag.Int("pid", 0, "Process ID to attach to")
 	outputFlag := flag.String("output", "", "Output directory for the dump")
 	allFlag := flag.Bool("all", false, "Save all memory regions (including mmapped files)")
+	probeFlag := flag.Bool("probe", false, "Report which regions are readable and why the others are not, without saving a dump")
+	suspendFlag := flag.Bool("suspend", false, "Suspend the process while saving, so the dump is a consistent snapshot")
 	flag.Parse()
 
 	if *pidFlag == 0 {
@@ -18,18 +22,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	if *outputFlag == "" {
+	if *outputFlag == "" && !*probeFlag {
 		fmt.Println("Error: --output is required")
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	// Create output directory
-	if err := os.MkdirAll(*outputFlag, 0755); err != nil {
-		fmt.Printf("Error creating output directory: %v\n", err)
-		os.Exit(1)
-	}
-
 	proc, err := getProcess(*pidFlag)
 
 	if err != nil {
@@ -40,6 +38,22 @@ func main() {
 
 	fmt.Printf("Attached to process %d\n", *pidFlag)
 
+	if *probeFlag {
+		report, err := process.ProbeRegions(proc)
+		if err != nil {
+			fmt.Printf("Error probing regions: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(report)
+		return
+	}
+
+	// Create output directory
+	if err := os.MkdirAll(*outputFlag, 0755); err != nil {
+		fmt.Printf("Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+
 	// In a real implementation, we would pass the 'all' flag to the Save method
 	// or filter the regions here before saving.
 	// For now, the Save method in process_linux/process_save.go saves everything
@@ -55,7 +69,7 @@ func main() {
 	}
 
 	fmt.Printf("Saving dump to %s...\n", *outputFlag)
-	if err := proc.Save(*outputFlag); err != nil {
+	if err := process.SaveWithOptions(proc, *outputFlag, process.SaveOptions{Suspend: *suspendFlag}); err != nil {
 		fmt.Printf("Error saving dump: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"fmt"
+	"gomem/process"
+)
+
+func getProcess(pid int) (process.Process, error) {
+	return nil, fmt.Errorf("darwin not supported on this build")
+}
@@ -0,0 +1,57 @@
+//go:build linux
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gomem/process"
+	"gomem/process_linux"
+)
+
+func main() {
+	pidFlag := flag.Int("pid", 0, "Process ID to load the library into")
+	libFlag := flag.String("lib", "", "Path of the shared object to load")
+	unloadFlag := flag.String("unload", "", "dlopen handle of a previously injected library to unload instead, hex")
+	flag.Parse()
+
+	if *pidFlag == 0 || (*libFlag == "") == (*unloadFlag == "") {
+		fmt.Println("Error: --pid and one of --lib or --unload are required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	proc, err := process_linux.NewWithPID(process.ProcessID(*pidFlag))
+	if err != nil {
+		fmt.Printf("Error attaching to process %d: %v\n", *pidFlag, err)
+		os.Exit(1)
+	}
+	defer proc.Close()
+	linuxProc := proc.(*process_linux.LinuxProcess)
+
+	if *unloadFlag != "" {
+		handle, err := strconv.ParseUint(strings.TrimPrefix(*unloadFlag, "0x"), 16, 64)
+		if err != nil {
+			fmt.Printf("Error parsing handle %s: %v\n", *unloadFlag, err)
+			os.Exit(1)
+		}
+		if err := linuxProc.UnloadLibrary(process.ProcessMemoryAddress(handle)); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Unloaded handle 0x%X\n", handle)
+		return
+	}
+
+	lib, err := linuxProc.InjectLibrary(*libFlag)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Loaded %s\n", lib.Module)
+	fmt.Printf("Handle 0x%X, unload with --unload 0x%X\n", lib.Handle, lib.Handle)
+}
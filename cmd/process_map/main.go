@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gomem/memory_layout"
+	"gomem/process/memory_map"
+	"gomem/process_blob"
+)
+
+func main() {
+	pidFlag := flag.Int("pid", 0, "Process ID to show the memory layout of")
+	fromFlag := flag.String("from", "", "Directory containing a dump to show the memory layout of instead of a process")
+	htmlFlag := flag.String("html", "", "Write an HTML page of the layout to this file instead of printing it")
+	widthFlag := flag.Int("width", 40, "Width of the bar of the largest block")
+	labelFlag := flag.Int("label-width", 48, "Maximum width of the path column")
+	gapsFlag := flag.Bool("gaps", false, "Show the unmapped gaps between blocks")
+	noColorFlag := flag.Bool("no-color", false, "Draw the bars with r/w/x characters instead of colors")
+	flag.Parse()
+
+	var mm []memory_map.MemoryMapItem
+	var title string
+
+	switch {
+	case *fromFlag != "":
+		dump := process_blob.NewProcessDump()
+		if err := dump.Load(*fromFlag); err != nil {
+			fmt.Printf("Error loading dump from %s: %v\n", *fromFlag, err)
+			os.Exit(1)
+		}
+		mm = dump.MemoryMap
+		title = fmt.Sprintf("%s (PID %d, dump %s)", dump.Name, dump.PID, *fromFlag)
+	case *pidFlag != 0:
+		proc, err := getProcess(*pidFlag)
+		if err != nil {
+			fmt.Printf("Error attaching to process %d: %v\n", *pidFlag, err)
+			os.Exit(1)
+		}
+		defer proc.Close()
+
+		mm, err = proc.GetMemoryMap()
+		if err != nil {
+			fmt.Printf("Error reading memory map: %v\n", err)
+			os.Exit(1)
+		}
+		title = fmt.Sprintf("PID %d", *pidFlag)
+	default:
+		fmt.Println("Error: --pid or --from is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	options := memory_layout.DefaultLayoutOptions()
+	options.BarWidth = *widthFlag
+	options.LabelWidth = *labelFlag
+	options.ShowGaps = *gapsFlag
+	options.Color = !*noColorFlag
+
+	if *htmlFlag == "" {
+		fmt.Printf("Memory layout of %s\n\n", title)
+		if err := memory_layout.RenderText(os.Stdout, mm, options); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	f, err := os.Create(*htmlFlag)
+	if err != nil {
+		fmt.Printf("Error creating %s: %v\n", *htmlFlag, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	if err := memory_layout.RenderHTML(f, mm, "Memory layout of "+title, options); err != nil {
+		fmt.Printf("Error writing %s: %v\n", *htmlFlag, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote the layout of %d regions to %s\n", len(mm), *htmlFlag)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gomem/pointerscan"
+	"gomem/process"
+	"gomem/process_blob"
+)
+
+func main() {
+	pidFlag := flag.Int("pid", 0, "Process ID to scan")
+	fromFlag := flag.String("from", "", "Directory containing a dump to scan instead of a process")
+	addrFlag := flag.String("addr", "", "Target address, hex (0x7f3a1c2d5e10) or an address expression (libgame.so+0x1234)")
+	depthFlag := flag.Int("depth", pointerscan.DefaultMaxDepth, "Maximum number of pointers of a path")
+	offsetFlag := flag.Uint64("max-offset", pointerscan.DefaultMaxOffset, "Maximum offset added to a pointer")
+	moduleFlag := flag.String("module", "", "Comma separated modules a path may start in (default: every module)")
+	maxFlag := flag.Int("max", pointerscan.DefaultMaxResults, "Stop after this many paths")
+	suspendFlag := flag.Bool("suspend", false, "Suspend the process while the pointers are read")
+	mapFlag := flag.String("map", "", "Search a pointer map saved with --save instead of reading a process")
+	saveFlag := flag.String("save", "", "Save the pointer map to this file")
+	rebaseFlag := flag.String("rebase", "", "Directory containing a second dump; keep the paths landing on the same value there")
+	sizeFlag := flag.Uint64("size", 4, "Size in bytes of the value compared by --rebase")
+	flag.Parse()
+
+	sources := 0
+	for _, set := range []bool{*pidFlag != 0, *fromFlag != "", *mapFlag != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 || *addrFlag == "" {
+		fmt.Println("Error: --addr and one of --pid, --from or --map are required")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *rebaseFlag != "" && *mapFlag != "" {
+		fmt.Println("Error: --rebase needs --pid or --from to read the value")
+		os.Exit(1)
+	}
+
+	var proc process.Process
+	switch {
+	case *mapFlag != "":
+		// No process to resolve address expressions against
+	case *fromFlag != "":
+		dump := process_blob.NewProcessDump()
+		if err := dump.Load(*fromFlag); err != nil {
+			fmt.Printf("Error loading dump from %s: %v\n", *fromFlag, err)
+			os.Exit(1)
+		}
+		proc = dump
+		defer proc.Close()
+	default:
+		var err error
+		proc, err = getProcess(*pidFlag)
+		if err != nil {
+			fmt.Printf("Error attaching to process %d: %v\n", *pidFlag, err)
+			os.Exit(1)
+		}
+		defer proc.Close()
+	}
+
+	target, err := process.ParseAddress(proc, *addrFlag)
+	if err != nil {
+		fmt.Printf("Error parsing address %s: %v\n", *addrFlag, err)
+		os.Exit(1)
+	}
+
+	options := pointerscan.Options{
+		MaxDepth:   *depthFlag,
+		MaxOffset:  *offsetFlag,
+		MaxResults: *maxFlag,
+		Suspend:    *suspendFlag,
+	}
+	if *moduleFlag != "" {
+		options.Modules = strings.Split(*moduleFlag, ",")
+	}
+
+	start := time.Now()
+	var pm *pointerscan.PointerMap
+	if *mapFlag != "" {
+		pm, err = pointerscan.LoadPointerMapFile(*mapFlag)
+	} else {
+		pm, err = pointerscan.BuildPointerMap(proc, options)
+	}
+	if err != nil {
+		fmt.Printf("Error reading pointers: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Read %d pointers in %v\n", pm.Count(), time.Since(start).Round(time.Millisecond))
+
+	if *saveFlag != "" {
+		if err := pm.SaveFile(*saveFlag); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved the pointer map to %s\n", *saveFlag)
+	}
+
+	scanner := pointerscan.NewFromMap(pm, options)
+
+	chains, err := scanner.Find(target)
+	if err != nil {
+		fmt.Printf("Error searching paths: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *rebaseFlag != "" {
+		second := process_blob.NewProcessDump()
+		if err := second.Load(*rebaseFlag); err != nil {
+			fmt.Printf("Error loading dump from %s: %v\n", *rebaseFlag, err)
+			os.Exit(1)
+		}
+		defer second.Close()
+
+		found := len(chains)
+		chains = process.FilterRebasedChains(proc, second, chains, process.ProcessMemorySize(*sizeFlag))
+		fmt.Printf("%d of %d paths land on the same value in %s\n", len(chains), found, *rebaseFlag)
+	}
+
+	fmt.Printf("Found %d paths to 0x%X:\n", len(chains), uint64(target))
+	for _, chain := range chains {
+		fmt.Printf("  %-60s %s\n", pointerscan.Format(chain), chain.String())
+	}
+}
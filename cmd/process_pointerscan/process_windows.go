@@ -0,0 +1,10 @@
+package main
+
+import (
+	"gomem/process"
+	"gomem/process_windows"
+)
+
+func getProcess(pid int) (process.Process, error) {
+	return process_windows.NewWithPID(process.ProcessID(pid))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gomem/process"
+	"gomem/process_lua"
+)
+
+func main() {
+	pidFlag := flag.Int("pid", 0, "Process ID to attach to")
+	fromFlag := flag.String("from", "", "Directory containing a dump to run the script against")
+	execFlag := flag.String("e", "", "Lua chunk to run instead of a script file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [--pid PID | --from DIR] (script.lua [args...] | -e CHUNK)\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *execFlag == "" && flag.NArg() == 0 {
+		fmt.Println("Error: a script file or -e is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if *pidFlag != 0 && *fromFlag != "" {
+		fmt.Println("Error: --pid and --from are mutually exclusive")
+		os.Exit(1)
+	}
+
+	engine := process_lua.NewEngine(nil)
+	defer engine.Close()
+
+	if *pidFlag != 0 {
+		if err := engine.Attach(process.ProcessID(*pidFlag)); err != nil {
+			fmt.Printf("Error attaching to process %d: %v\n", *pidFlag, err)
+			os.Exit(1)
+		}
+	}
+
+	if *fromFlag != "" {
+		if err := engine.LoadDump(*fromFlag); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var err error
+	if *execFlag != "" {
+		engine.SetArgs("-e", flag.Args())
+		err = engine.RunString(*execFlag)
+	} else {
+		engine.SetArgs(flag.Arg(0), flag.Args()[1:])
+		err = engine.RunFile(flag.Arg(0))
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
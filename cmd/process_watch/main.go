@@ -0,0 +1,165 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"gomem/debugger"
+	"gomem/process"
+	"gomem/process_linux"
+)
+
+func main() {
+	pidFlag := flag.Int("pid", 0, "Process ID to watch")
+	addrFlag := flag.String("addr", "", "Address to watch, hex (0x7f3a1c2d5e10) or an address expression (libgame.so+0x1234)")
+	sizeFlag := flag.Int("size", 4, "Number of bytes to watch: 1, 2, 4 or 8")
+	accessFlag := flag.String("access", "write", "Access to break on: write or rw (reads and writes)")
+	countFlag := flag.Int("count", 0, "Stop after this many hits (0 = until interrupted)")
+	durationFlag := flag.Duration("duration", 0, "Stop after this long (0 = until interrupted)")
+	breakFlag := flag.String("break", "", "Instruction address to set a breakpoint on instead of watching --addr, hex or an address expression (libc.so.6+0xD54E0)")
+	regsFlag := flag.Bool("regs", false, "Print the registers of the thread at every hit")
+	stackFlag := flag.Int("stack", 0, "Hexdump this many bytes from the stack pointer at every hit")
+	flag.Parse()
+
+	if *pidFlag == 0 || (*addrFlag == "") == (*breakFlag == "") {
+		fmt.Println("Error: --pid and one of --addr or --break are required")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var access debugger.Access
+	switch *accessFlag {
+	case "write", "w":
+		access = debugger.AccessWrite
+	case "rw", "readwrite":
+		access = debugger.AccessReadWrite
+	default:
+		fmt.Printf("Error: unknown access %q, use write or rw\n", *accessFlag)
+		os.Exit(1)
+	}
+
+	proc, err := process_linux.NewWithPID(process.ProcessID(*pidFlag))
+	if err != nil {
+		fmt.Printf("Error attaching to process %d: %v\n", *pidFlag, err)
+		os.Exit(1)
+	}
+	defer proc.Close()
+
+	target := *addrFlag
+	if *breakFlag != "" {
+		target = *breakFlag
+	}
+	addr, err := process.ParseAddress(proc, target)
+	if err != nil {
+		fmt.Printf("Error parsing address %s: %v\n", target, err)
+		os.Exit(1)
+	}
+
+	dbg, err := debugger.Attach(proc)
+	if err != nil {
+		fmt.Printf("Error attaching debugger to process %d: %v\n", *pidFlag, err)
+		os.Exit(1)
+	}
+	defer dbg.Detach()
+
+	what := "watchpoint"
+	if *breakFlag != "" {
+		what = "breakpoint"
+		err = dbg.SetBreakpoint(addr)
+	} else {
+		_, err = dbg.SetWatchpoint(addr, *sizeFlag, access)
+	}
+	if err != nil {
+		fmt.Printf("Error setting %s: %v\n", what, err)
+		dbg.Detach()
+		os.Exit(1)
+	}
+	dbg.SetStackSnippet(*stackFlag)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *durationFlag > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *durationFlag)
+		defer cancel()
+	}
+
+	if *breakFlag != "" {
+		fmt.Printf("Breakpoint at %s in %d threads, Ctrl-C to stop\n", process.FormatAddress(proc, addr), len(dbg.Threads()))
+	} else {
+		fmt.Printf("Watching %d bytes at %s for %s access in %d threads, Ctrl-C to stop\n",
+			*sizeFlag, process.FormatAddress(proc, addr), access, len(dbg.Threads()))
+	}
+
+	linuxProc := proc.(*process_linux.LinuxProcess)
+	counts := make(map[process.ProcessMemoryAddress]int)
+	hits := 0
+	for *countFlag == 0 || hits < *countFlag {
+		hit, err := dbg.Wait(ctx)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+				fmt.Printf("Error: %v\n", err)
+			}
+			break
+		}
+		hits++
+
+		timestamp := time.Now().Format("15:04:05.000")
+		if hit.Kind == debugger.HitBreakpoint {
+			fmt.Printf("%s #%d thread %d at %s\n", timestamp, hits, hit.TID, process.FormatAddress(proc, hit.IP))
+		} else {
+			counts[hit.IP]++
+			value := "?"
+			if data, err := proc.ReadMemory(addr, process.ProcessMemorySize(*sizeFlag)); err == nil {
+				value = hex.EncodeToString(data)
+			}
+			fmt.Printf("%s #%d thread %d, after %s, value %s\n",
+				timestamp, hits, hit.TID, process.FormatAddress(proc, hit.IP), value)
+		}
+		if *regsFlag {
+			fmt.Print(linuxProc.FormatRegisters(&hit.Registers))
+		}
+		if len(hit.Stack) > 0 {
+			fmt.Print(hex.Dump(hit.Stack))
+		}
+	}
+
+	if *breakFlag != "" {
+		fmt.Printf("\n%d hits\n", hits)
+		return
+	}
+	printSummary(proc, counts)
+}
+
+// printSummary prints the number of hits of every instruction, most frequent first
+func printSummary(proc process.Process, counts map[process.ProcessMemoryAddress]int) {
+	if len(counts) == 0 {
+		fmt.Println("No hits")
+		return
+	}
+
+	ips := make([]process.ProcessMemoryAddress, 0, len(counts))
+	for ip := range counts {
+		ips = append(ips, ip)
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		if counts[ips[i]] != counts[ips[j]] {
+			return counts[ips[i]] > counts[ips[j]]
+		}
+		return ips[i] < ips[j]
+	})
+
+	fmt.Println("\nHits by instruction (address of the following instruction):")
+	for _, ip := range ips {
+		fmt.Printf("%8d  0x%X  %s\n", counts[ip], ip, process.FormatAddress(proc, ip))
+	}
+}
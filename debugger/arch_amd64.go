@@ -0,0 +1,103 @@
+//go:build linux && amd64
+
+package debugger
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/sys/unix"
+)
+
+// numSlots is the number of address debug registers, DR0 to DR3
+const numSlots = 4
+
+// debugRegOffset is the offset of u_debugreg in struct user, for PTRACE_PEEKUSER/POKEUSER
+const debugRegOffset = 848
+
+// setDebugRegisters programs DR0-DR3 and the control register DR7 of a stopped thread
+func setDebugRegisters(tid int, slots [numSlots]*Watchpoint) error {
+	// The kernel validates DR7 against the addresses, so disable before changing them
+	if err := pokeDebugRegister(tid, 7, 0); err != nil {
+		return err
+	}
+
+	var dr7 uint64
+	for i, wp := range slots {
+		if wp == nil {
+			continue
+		}
+		if err := pokeDebugRegister(tid, i, uint64(wp.Address)); err != nil {
+			return err
+		}
+
+		rw := uint64(0b01) // Data writes
+		if wp.Access == AccessReadWrite {
+			rw = 0b11 // Data reads or writes
+		}
+		var length uint64
+		switch wp.Size {
+		case 1:
+			length = 0b00
+		case 2:
+			length = 0b01
+		case 4:
+			length = 0b11
+		case 8:
+			length = 0b10
+		}
+
+		dr7 |= 1 << (2 * i) // Local enable
+		dr7 |= (rw | length<<2) << (16 + 4*i)
+	}
+	if dr7 == 0 {
+		return nil
+	}
+	return pokeDebugRegister(tid, 7, dr7)
+}
+
+// triggeredSlot reads and clears the status register DR6 of a thread stopped by SIGTRAP,
+// reporting which watchpoint triggered if any
+func triggeredSlot(tid int) (int, bool, error) {
+	dr6, err := peekDebugRegister(tid, 6)
+	if err != nil {
+		return 0, false, err
+	}
+	if err := pokeDebugRegister(tid, 6, 0); err != nil {
+		return 0, false, err
+	}
+	for i := 0; i < numSlots; i++ {
+		if dr6&(1<<i) != 0 {
+			return i, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func peekDebugRegister(tid int, n int) (uint64, error) {
+	var buf [8]byte
+	if _, err := unix.PtracePeekUser(tid, debugRegOffset+uintptr(n)*8, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.NativeEndian.Uint64(buf[:]), nil
+}
+
+func pokeDebugRegister(tid int, n int, value uint64) error {
+	var buf [8]byte
+	binary.NativeEndian.PutUint64(buf[:], value)
+	_, err := unix.PtracePokeUser(tid, debugRegOffset+uintptr(n)*8, buf[:])
+	return err
+}
+
+// breakpointInstruction is int3
+var breakpointInstruction = []byte{0xCC}
+
+// breakpointAddress returns the address of the breakpoint a thread trapped on, int3 traps
+// with the instruction pointer after it
+func breakpointAddress(regs *unix.PtraceRegs) uint64 {
+	return regs.Rip - uint64(len(breakpointInstruction))
+}
+
+// stackPointer returns the stack pointer
+func stackPointer(regs *unix.PtraceRegs) uint64 {
+	return regs.Rsp
+}
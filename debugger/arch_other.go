@@ -0,0 +1,38 @@
+//go:build linux && !amd64
+
+package debugger
+
+import (
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// numSlots is the number of watchpoints, none are implemented on this architecture
+const numSlots = 4
+
+// setDebugRegisters only accepts clearing the watchpoints
+func setDebugRegisters(tid int, slots [numSlots]*Watchpoint) error {
+	for _, wp := range slots {
+		if wp != nil {
+			return fmt.Errorf("hardware watchpoints are not implemented on %s", runtime.GOARCH)
+		}
+	}
+	return nil
+}
+
+func triggeredSlot(tid int) (int, bool, error) {
+	return 0, false, nil
+}
+
+// breakpointInstruction is empty, software breakpoints are not implemented on this architecture
+var breakpointInstruction []byte
+
+func breakpointAddress(regs *unix.PtraceRegs) uint64 {
+	return uint64(regs.PC())
+}
+
+func stackPointer(regs *unix.PtraceRegs) uint64 {
+	return 0
+}
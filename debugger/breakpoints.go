@@ -0,0 +1,194 @@
+//go:build linux
+
+package debugger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+
+	"gomem/process"
+
+	"golang.org/x/sys/unix"
+)
+
+// SetBreakpoint replaces the instruction at addr with a breakpoint instruction (int3). Threads
+// reaching it stop with a HitBreakpoint; when resumed the original instruction is restored
+// for a single step, so the code runs unchanged. addr must be the start of an instruction.
+func (d *Debugger) SetBreakpoint(addr process.ProcessMemoryAddress) error {
+	if len(breakpointInstruction) == 0 {
+		return fmt.Errorf("software breakpoints are not implemented on %s", runtime.GOARCH)
+	}
+	return d.do(func() error {
+		if _, ok := d.breakpoints[addr]; ok {
+			return nil
+		}
+
+		original := make([]byte, len(breakpointInstruction))
+		if err := d.peek(addr, original); err != nil {
+			return fmt.Errorf("failed to read instruction at 0x%X: %w", addr, err)
+		}
+		if bytes.Equal(original, breakpointInstruction) {
+			return fmt.Errorf("0x%X already holds a breakpoint instruction", addr)
+		}
+		if err := d.poke(addr, breakpointInstruction); err != nil {
+			return fmt.Errorf("failed to set breakpoint at 0x%X: %w", addr, err)
+		}
+		d.breakpoints[addr] = original
+		return nil
+	})
+}
+
+// ClearBreakpoint restores the original instruction at addr
+func (d *Debugger) ClearBreakpoint(addr process.ProcessMemoryAddress) error {
+	return d.do(func() error {
+		original, ok := d.breakpoints[addr]
+		if !ok {
+			return fmt.Errorf("no breakpoint at 0x%X", addr)
+		}
+		if err := d.poke(addr, original); err != nil {
+			return fmt.Errorf("failed to clear breakpoint at 0x%X: %w", addr, err)
+		}
+		delete(d.breakpoints, addr)
+		return nil
+	})
+}
+
+// Breakpoints returns the addresses of the breakpoints, sorted
+func (d *Debugger) Breakpoints() []process.ProcessMemoryAddress {
+	var addrs []process.ProcessMemoryAddress
+	d.do(func() error {
+		for addr := range d.breakpoints {
+			addrs = append(addrs, addr)
+		}
+		return nil
+	})
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+// removeBreakpoints restores every original instruction
+func (d *Debugger) removeBreakpoints() error {
+	var errs []error
+	for addr, original := range d.breakpoints {
+		if err := d.poke(addr, original); err != nil {
+			errs = append(errs, fmt.Errorf("failed to clear breakpoint at 0x%X: %w", addr, err))
+			continue
+		}
+		delete(d.breakpoints, addr)
+	}
+	return errors.Join(errs...)
+}
+
+// breakpointHit checks whether a thread stopped by SIGTRAP reached one of the breakpoints.
+// If so its instruction pointer is moved back to the breakpoint address, so the original
+// instruction runs when the thread is resumed (see stepOver).
+func (d *Debugger) breakpointHit(tid int, t *thread) (*Hit, error) {
+	var regs unix.PtraceRegs
+	if err := unix.PtraceGetRegs(tid, &regs); err != nil {
+		return nil, fmt.Errorf("ptrace getregs of thread %d failed: %w", tid, err)
+	}
+	addr := process.ProcessMemoryAddress(breakpointAddress(&regs))
+	if _, ok := d.breakpoints[addr]; !ok {
+		return nil, nil
+	}
+
+	regs.SetPC(uint64(addr))
+	if err := unix.PtraceSetRegs(tid, &regs); err != nil {
+		return nil, fmt.Errorf("ptrace setregs of thread %d failed: %w", tid, err)
+	}
+	t.breakpoint = addr
+	return d.newHit(tid, &Hit{Kind: HitBreakpoint, Address: addr, Slot: -1})
+}
+
+// stepOver runs the original instruction of the breakpoint a stopped thread is at with a
+// single step, and puts the breakpoint back. The other threads must be stopped, they would
+// miss the breakpoint meanwhile.
+func (d *Debugger) stepOver(tid int, t *thread) error {
+	addr := t.breakpoint
+	t.breakpoint = 0
+	original, ok := d.breakpoints[addr]
+	if !ok {
+		return nil // Cleared since
+	}
+
+	if err := d.poke(addr, original); err != nil {
+		return fmt.Errorf("failed to restore instruction at 0x%X: %w", addr, err)
+	}
+	defer d.poke(addr, breakpointInstruction)
+
+	if err := unix.PtraceSingleStep(tid); err != nil {
+		return fmt.Errorf("ptrace singlestep of thread %d failed: %w", tid, err)
+	}
+
+	var status unix.WaitStatus
+	if _, err := unix.Wait4(tid, &status, unix.WALL, nil); err != nil {
+		delete(d.threads, tid)
+		return nil
+	}
+	if status.Exited() || status.Signaled() {
+		delete(d.threads, tid)
+		return nil
+	}
+	if !status.Stopped() {
+		return nil
+	}
+
+	if sig := status.StopSignal(); sig != unix.SIGTRAP {
+		// Interrupted before the step, deliver the signal on the next resume
+		t.signal = int(sig)
+		return nil
+	}
+
+	// The stepped instruction may have accessed a watched address
+	slot, ok, err := triggeredSlot(tid)
+	if err != nil {
+		return fmt.Errorf("failed to read debug status of thread %d: %w", tid, err)
+	}
+	if ok && d.slots[slot] != nil {
+		hit, err := d.watchpointHit(tid, slot)
+		if err != nil {
+			return err
+		}
+		d.pending = append(d.pending, *hit)
+	}
+	return nil
+}
+
+// peek reads traced memory through any stopped thread
+func (d *Debugger) peek(addr process.ProcessMemoryAddress, data []byte) error {
+	tid, err := d.memoryThread()
+	if err != nil {
+		return err
+	}
+	n, err := unix.PtracePeekData(tid, uintptr(addr), data)
+	if err == nil && n < len(data) {
+		err = fmt.Errorf("short read of %d bytes", n)
+	}
+	return err
+}
+
+// poke writes traced memory through any stopped thread, ignoring page protections
+func (d *Debugger) poke(addr process.ProcessMemoryAddress, data []byte) error {
+	tid, err := d.memoryThread()
+	if err != nil {
+		return err
+	}
+	_, err = unix.PtracePokeData(tid, uintptr(addr), data)
+	return err
+}
+
+// memoryThread returns a stopped thread to access the memory of the process with
+func (d *Debugger) memoryThread() (int, error) {
+	if t, ok := d.threads[d.pid]; ok && !t.running {
+		return d.pid, nil
+	}
+	for tid, t := range d.threads {
+		if !t.running {
+			return tid, nil
+		}
+	}
+	return 0, ErrProcessExited
+}
@@ -0,0 +1,297 @@
+//go:build linux
+
+// Package debugger attaches to a Linux process with ptrace and sets hardware watchpoints in
+// the debug registers of its threads, to find the instructions that read or write an address,
+// and software breakpoints, to trace the code paths reaching an instruction.
+//
+//	dbg, err := debugger.Attach(proc)
+//	defer dbg.Detach()
+//	dbg.SetWatchpoint(addr, 4, debugger.AccessWrite)
+//	hit, err := dbg.Wait(ctx)
+//	fmt.Println(process.FormatAddress(proc, hit.IP))
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+
+	"gomem/process"
+	"gomem/process_linux"
+)
+
+var (
+	ErrDetached = errors.New("debugger detached")
+
+	ErrProcessExited = errors.New("process exited")
+
+	ErrNoFreeSlot = errors.New("all debug registers are in use")
+)
+
+// Access is the kind of access a watchpoint breaks on
+type Access int
+
+const (
+	AccessWrite     Access = iota // Break on writes
+	AccessReadWrite               // Break on reads and writes, x86 has no read only watchpoints
+)
+
+func (a Access) String() string {
+	switch a {
+	case AccessWrite:
+		return "write"
+	case AccessReadWrite:
+		return "read/write"
+	}
+	return fmt.Sprintf("Access(%d)", int(a))
+}
+
+// Watchpoint is an address range watched by a debug register
+type Watchpoint struct {
+	Address process.ProcessMemoryAddress
+	Size    int // 1, 2, 4 or 8 bytes, Address must be aligned to it
+	Access  Access
+}
+
+// HitKind is what stopped a thread
+type HitKind int
+
+const (
+	HitWatchpoint HitKind = iota // A watched address was accessed
+	HitBreakpoint                // A breakpoint instruction was reached
+)
+
+func (k HitKind) String() string {
+	switch k {
+	case HitWatchpoint:
+		return "watchpoint"
+	case HitBreakpoint:
+		return "breakpoint"
+	}
+	return fmt.Sprintf("HitKind(%d)", int(k))
+}
+
+// Hit is a watchpoint or breakpoint triggered by a thread
+type Hit struct {
+	Kind    HitKind
+	TID     int
+	Address process.ProcessMemoryAddress // Watched address or breakpoint address
+
+	Slot       int        // Debug register of the watchpoint, -1 for breakpoints
+	Watchpoint Watchpoint // Watchpoint triggered, zero for breakpoints
+
+	// IP is the instruction pointer when the thread stopped. Watchpoints trap after the
+	// accessing instruction has executed, so IP is the address of the instruction following it.
+	// For breakpoints IP is the breakpoint address, the instruction runs when resumed.
+	IP process.ProcessMemoryAddress
+
+	// Registers of the thread when it stopped
+	Registers process_linux.Registers
+
+	// Stack holds the bytes from the stack pointer up, see SetStackSnippet
+	Stack []byte
+}
+
+// Debugger traces every thread of a process, including threads created while attached.
+// The threads are stopped except during Wait, so memory and registers can be inspected
+// between hits. A Debugger is not safe for concurrent use.
+type Debugger struct {
+	pid      int
+	requests chan func()
+
+	// Only accessed on the ptrace thread
+	threads     map[int]*thread
+	slots       [numSlots]*Watchpoint
+	breakpoints map[process.ProcessMemoryAddress][]byte // Original bytes by address
+	pending     []Hit                                   // Hits of other threads while stopping the process
+	stopping    bool
+	stackSize   int
+}
+
+// Attach attaches to every thread of the process and stops them. Tracing needs the same
+// access as a debugger (same user and ptrace_scope permitting, or CAP_SYS_PTRACE), and
+// fails for a process that is already traced, e.g. by gdb.
+func Attach(proc process.Process) (*Debugger, error) {
+	pid := int(proc.GetPID())
+	if pid == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+	if pid == os.Getpid() {
+		return nil, fmt.Errorf("ptrace can't target the calling process")
+	}
+
+	d := &Debugger{
+		pid:         pid,
+		requests:    make(chan func()),
+		threads:     make(map[int]*thread),
+		breakpoints: make(map[process.ProcessMemoryAddress][]byte),
+	}
+	go d.loop()
+
+	if err := d.do(d.attachThreads); err != nil {
+		d.do(d.detachThreads)
+		close(d.requests)
+		return nil, err
+	}
+	return d, nil
+}
+
+// loop runs the ptrace requests, which must all come from the thread that attached
+func (d *Debugger) loop() {
+	// The thread stays locked and exits with the goroutine, it is the tracer
+	runtime.LockOSThread()
+	for fn := range d.requests {
+		fn()
+	}
+}
+
+// do runs fn on the ptrace thread
+func (d *Debugger) do(fn func() error) error {
+	if d.requests == nil {
+		return ErrDetached
+	}
+	errc := make(chan error, 1)
+	d.requests <- func() { errc <- fn() }
+	return <-errc
+}
+
+// PID returns the ID of the traced process
+func (d *Debugger) PID() int {
+	return d.pid
+}
+
+// SetWatchpoint watches size bytes at addr in every thread and returns the debug register used
+func (d *Debugger) SetWatchpoint(addr process.ProcessMemoryAddress, size int, access Access) (int, error) {
+	switch size {
+	case 1, 2, 4, 8:
+	default:
+		return -1, fmt.Errorf("invalid watchpoint size %d, must be 1, 2, 4 or 8", size)
+	}
+	if uint64(addr)%uint64(size) != 0 {
+		return -1, fmt.Errorf("watchpoint address 0x%X is not aligned to its size %d", addr, size)
+	}
+	if access != AccessWrite && access != AccessReadWrite {
+		return -1, fmt.Errorf("invalid watchpoint access %v", access)
+	}
+
+	slot := -1
+	err := d.do(func() error {
+		for i := range d.slots {
+			if d.slots[i] == nil {
+				slot = i
+				break
+			}
+		}
+		if slot < 0 {
+			return ErrNoFreeSlot
+		}
+
+		d.slots[slot] = &Watchpoint{Address: addr, Size: size, Access: access}
+		if err := d.applyWatchpoints(); err != nil {
+			d.slots[slot] = nil
+			d.applyWatchpoints()
+			slot = -1
+			return err
+		}
+		return nil
+	})
+	return slot, err
+}
+
+// ClearWatchpoint removes the watchpoint in a debug register
+func (d *Debugger) ClearWatchpoint(slot int) error {
+	if slot < 0 || slot >= numSlots {
+		return fmt.Errorf("invalid debug register %d", slot)
+	}
+	return d.do(func() error {
+		d.slots[slot] = nil
+		return d.applyWatchpoints()
+	})
+}
+
+// Watchpoints returns the watchpoints by debug register, nil for free registers
+func (d *Debugger) Watchpoints() []*Watchpoint {
+	var watchpoints []*Watchpoint
+	d.do(func() error {
+		for _, wp := range d.slots {
+			if wp != nil {
+				wp2 := *wp
+				wp = &wp2
+			}
+			watchpoints = append(watchpoints, wp)
+		}
+		return nil
+	})
+	return watchpoints
+}
+
+// Threads returns the IDs of the traced threads, sorted
+func (d *Debugger) Threads() []int {
+	var tids []int
+	d.do(func() error {
+		for tid := range d.threads {
+			tids = append(tids, tid)
+		}
+		sort.Ints(tids)
+		return nil
+	})
+	return tids
+}
+
+// Wait resumes the process until a watchpoint is hit and returns the hit with every thread
+// stopped again. When ctx is done first, the threads are stopped and ctx.Err() is returned.
+func (d *Debugger) Wait(ctx context.Context) (*Hit, error) {
+	var hit *Hit
+	err := d.do(func() error {
+		var err error
+		hit, err = d.wait(ctx)
+		return err
+	})
+	return hit, err
+}
+
+// SetStackSnippet captures size bytes from the stack pointer of the thread in every hit
+// (Hit.Stack), 0 disables it
+func (d *Debugger) SetStackSnippet(size int) {
+	d.do(func() error {
+		d.stackSize = max(size, 0)
+		return nil
+	})
+}
+
+// Trace resumes the process and sends every hit to the returned channel, letting the
+// process run on after each one, until ctx is done or the process exits. The channel is
+// closed then and the threads are left stopped. The Debugger must not be used until the
+// channel is closed. A slow receiver keeps the process stopped at the last hit.
+func (d *Debugger) Trace(ctx context.Context) <-chan Hit {
+	hits := make(chan Hit)
+	go func() {
+		defer close(hits)
+		for {
+			hit, err := d.Wait(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case hits <- *hit:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return hits
+}
+
+// Detach removes the watchpoints and breakpoints and lets the process run untraced. The
+// Debugger can't be used afterwards.
+func (d *Debugger) Detach() error {
+	err := d.do(d.detachThreads)
+	if d.requests != nil {
+		close(d.requests)
+		d.requests = nil
+	}
+	return err
+}
@@ -0,0 +1,354 @@
+//go:build linux
+
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gomem/process"
+
+	"golang.org/x/sys/unix"
+)
+
+// pollInterval is how often running threads are checked for stops during Wait
+const pollInterval = 2 * time.Millisecond
+
+// thread is the ptrace state of a traced thread
+type thread struct {
+	running  bool
+	stopping bool // A SIGSTOP sent by the debugger is pending
+	fresh    bool // Created while attached, its watchpoints are set at its first stop
+	signal   int  // Signal to deliver when resumed
+
+	breakpoint process.ProcessMemoryAddress // Breakpoint stopped at, stepped over when resumed
+}
+
+// attachThreads attaches to the threads of the process until no new thread shows up
+func (d *Debugger) attachThreads() error {
+	for {
+		tids, err := taskIDs(d.pid)
+		if err != nil {
+			return err
+		}
+
+		attached := 0
+		for _, tid := range tids {
+			if _, ok := d.threads[tid]; ok {
+				continue
+			}
+			if err := d.attachThread(tid); err != nil {
+				if errors.Is(err, unix.ESRCH) {
+					continue // Exited meanwhile
+				}
+				return fmt.Errorf("ptrace attach to thread %d failed: %w", tid, err)
+			}
+			attached++
+		}
+		if attached == 0 {
+			return nil
+		}
+	}
+}
+
+// attachThread attaches to a thread and waits for it to stop
+func (d *Debugger) attachThread(tid int) error {
+	if err := unix.PtraceAttach(tid); err != nil {
+		return err
+	}
+
+	t := &thread{}
+	var status unix.WaitStatus
+	if _, err := unix.Wait4(tid, &status, unix.WALL, nil); err != nil {
+		unix.PtraceDetach(tid)
+		return err
+	}
+	if !status.Stopped() {
+		return unix.ESRCH
+	}
+	if sig := status.StopSignal(); sig != unix.SIGSTOP {
+		// Another signal arrived first, the SIGSTOP of the attach is still pending
+		t.stopping = true
+		t.signal = int(sig)
+	}
+
+	if err := unix.PtraceSetOptions(tid, unix.PTRACE_O_TRACECLONE); err != nil {
+		unix.PtraceDetach(tid)
+		return err
+	}
+	if err := setDebugRegisters(tid, d.slots); err != nil {
+		unix.PtraceDetach(tid)
+		return err
+	}
+	d.threads[tid] = t
+	return nil
+}
+
+// detachThreads clears the debug registers of the threads and detaches from them
+func (d *Debugger) detachThreads() error {
+	d.stopThreads()
+
+	var errs []error
+	for tid := range d.threads {
+		setDebugRegisters(tid, [numSlots]*Watchpoint{})
+	}
+	if err := d.removeBreakpoints(); err != nil {
+		errs = append(errs, err)
+	}
+	for tid, t := range d.threads {
+		if t.stopping {
+			// Consume the pending SIGSTOP, it would stop the untraced process
+			if err := d.resume(tid, t, 0); err == nil {
+				d.stopThreads()
+			}
+		}
+	}
+	for tid := range d.threads {
+		if err := unix.PtraceDetach(tid); err != nil && !errors.Is(err, unix.ESRCH) {
+			errs = append(errs, fmt.Errorf("ptrace detach from thread %d failed: %w", tid, err))
+		}
+	}
+	d.threads = make(map[int]*thread)
+	return errors.Join(errs...)
+}
+
+// applyWatchpoints sets the debug registers of every thread
+func (d *Debugger) applyWatchpoints() error {
+	for tid, t := range d.threads {
+		if t.fresh {
+			continue // Set at its first stop
+		}
+		if err := setDebugRegisters(tid, d.slots); err != nil {
+			if errors.Is(err, unix.ESRCH) {
+				delete(d.threads, tid)
+				continue
+			}
+			return fmt.Errorf("failed to set debug registers of thread %d: %w", tid, err)
+		}
+	}
+	return nil
+}
+
+// wait resumes the stopped threads and polls them until a watchpoint is hit
+func (d *Debugger) wait(ctx context.Context) (*Hit, error) {
+	if len(d.pending) > 0 {
+		hit := d.pending[0]
+		d.pending = d.pending[1:]
+		return &hit, nil
+	}
+
+	// Step over breakpoints first, while the other threads can't miss them
+	for tid, t := range d.threads {
+		if !t.running && t.breakpoint != 0 {
+			if err := d.stepOver(tid, t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(d.pending) > 0 {
+		hit := d.pending[0]
+		d.pending = d.pending[1:]
+		return &hit, nil
+	}
+	for tid, t := range d.threads {
+		if !t.running {
+			d.resume(tid, t, t.signal)
+		}
+	}
+
+	for {
+		for tid, t := range d.threads {
+			if !t.running {
+				continue
+			}
+
+			var status unix.WaitStatus
+			wpid, err := unix.Wait4(tid, &status, unix.WALL|unix.WNOHANG, nil)
+			if err != nil {
+				delete(d.threads, tid)
+				continue
+			}
+			if wpid == 0 {
+				continue
+			}
+
+			hit, err := d.handleStop(tid, t, status)
+			if err != nil || hit != nil {
+				d.stopThreads()
+				return hit, err
+			}
+		}
+
+		if len(d.threads) == 0 {
+			return nil, ErrProcessExited
+		}
+
+		select {
+		case <-ctx.Done():
+			d.stopThreads()
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// stopThreads stops every running thread with SIGSTOP and waits for them. Watchpoint hits
+// of threads stopping meanwhile are kept for the next wait.
+func (d *Debugger) stopThreads() {
+	d.stopping = true
+	defer func() { d.stopping = false }()
+
+	for {
+		running := false
+		for tid, t := range d.threads {
+			if !t.running {
+				continue
+			}
+			running = true
+
+			if !t.stopping && !t.fresh {
+				if err := unix.Tgkill(d.pid, tid, unix.SIGSTOP); err != nil {
+					delete(d.threads, tid)
+					continue
+				}
+				t.stopping = true
+			}
+
+			var status unix.WaitStatus
+			if _, err := unix.Wait4(tid, &status, unix.WALL, nil); err != nil {
+				delete(d.threads, tid)
+				continue
+			}
+			hit, _ := d.handleStop(tid, t, status)
+			if hit != nil {
+				d.pending = append(d.pending, *hit)
+			}
+		}
+		if !running {
+			return
+		}
+	}
+}
+
+// handleStop handles a wait status of a running thread. Threads stopped for the debugger
+// (watchpoint hits, and SIGSTOPs while stopping the process) stay stopped, others are resumed.
+func (d *Debugger) handleStop(tid int, t *thread, status unix.WaitStatus) (*Hit, error) {
+	if status.Exited() || status.Signaled() {
+		delete(d.threads, tid)
+		if tid == d.pid && len(d.threads) == 0 {
+			return nil, ErrProcessExited
+		}
+		return nil, nil
+	}
+	if !status.Stopped() {
+		return nil, nil
+	}
+	t.running = false
+
+	sig := status.StopSignal()
+	switch {
+	case sig == unix.SIGTRAP && status.TrapCause() == unix.PTRACE_EVENT_CLONE:
+		if msg, err := unix.PtraceGetEventMsg(tid); err == nil {
+			if _, ok := d.threads[int(msg)]; !ok {
+				d.threads[int(msg)] = &thread{running: true, fresh: true}
+			}
+		}
+		return nil, d.resume(tid, t, 0)
+
+	case sig == unix.SIGSTOP && t.fresh:
+		// First stop of a new thread, it doesn't inherit the debug registers
+		t.fresh = false
+		if err := setDebugRegisters(tid, d.slots); err != nil {
+			return nil, fmt.Errorf("failed to set debug registers of thread %d: %w", tid, err)
+		}
+		if d.stopping {
+			return nil, nil
+		}
+		return nil, d.resume(tid, t, 0)
+
+	case sig == unix.SIGSTOP && t.stopping:
+		t.stopping = false
+		if d.stopping {
+			return nil, nil
+		}
+		return nil, d.resume(tid, t, 0)
+
+	case sig == unix.SIGTRAP:
+		slot, ok, err := triggeredSlot(tid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read debug status of thread %d: %w", tid, err)
+		}
+		if ok && d.slots[slot] != nil {
+			return d.watchpointHit(tid, slot)
+		}
+		if hit, err := d.breakpointHit(tid, t); hit != nil || err != nil {
+			return hit, err
+		}
+	}
+
+	// Not caused by the debugger, deliver the signal
+	return nil, d.resume(tid, t, int(sig))
+}
+
+// watchpointHit describes the watchpoint hit of a stopped thread
+func (d *Debugger) watchpointHit(tid int, slot int) (*Hit, error) {
+	wp := *d.slots[slot]
+	return d.newHit(tid, &Hit{Kind: HitWatchpoint, Address: wp.Address, Slot: slot, Watchpoint: wp})
+}
+
+// newHit completes a hit with the registers and stack snippet of the stopped thread
+func (d *Debugger) newHit(tid int, hit *Hit) (*Hit, error) {
+	hit.TID = tid
+	if err := unix.PtraceGetRegs(tid, &hit.Registers); err != nil {
+		return nil, fmt.Errorf("ptrace getregs of thread %d failed: %w", tid, err)
+	}
+	hit.IP = process.ProcessMemoryAddress(hit.Registers.PC())
+
+	if sp := stackPointer(&hit.Registers); d.stackSize > 0 && sp != 0 {
+		// Reading stops at the end of the stack mapping
+		stack := make([]byte, d.stackSize)
+		n, _ := unix.PtracePeekData(tid, uintptr(sp), stack)
+		hit.Stack = stack[:n]
+	}
+	return hit, nil
+}
+
+// resume continues a stopped thread, delivering signal unless it is 0
+func (d *Debugger) resume(tid int, t *thread, signal int) error {
+	t.signal = 0
+	if err := unix.PtraceCont(tid, signal); err != nil {
+		if errors.Is(err, unix.ESRCH) {
+			delete(d.threads, tid)
+			return nil
+		}
+		return fmt.Errorf("ptrace cont of thread %d failed: %w", tid, err)
+	}
+	t.running = true
+	return nil
+}
+
+// taskIDs lists /proc/<pid>/task
+func taskIDs(pid int) ([]int, error) {
+	dir, err := os.Open(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read threads: %w", err)
+	}
+	defer dir.Close()
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read threads: %w", err)
+	}
+
+	tids := make([]int, 0, len(names))
+	for _, name := range names {
+		if tid, err := strconv.Atoi(name); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+	return tids, nil
+}
@@ -4,5 +4,6 @@ go 1.23.0
 
 require (
 	github.com/Moonlight-Companies/gologger v0.0.0-20250405013744-bfa1966699a2
+	github.com/yuin/gopher-lua v1.1.2
 	golang.org/x/sys v0.28.0
 )
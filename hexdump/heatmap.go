@@ -0,0 +1,234 @@
+package hexdump
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"gomem/coloransi"
+)
+
+// DefaultHeatColors is the palette used to render change frequencies, from the coldest
+// (rarely changing) to the hottest (changing on every sample) bytes.
+var DefaultHeatColors = []coloransi.ColorCode{
+	coloransi.CreateRGB(70, 110, 255),
+	coloransi.CreateRGB(60, 190, 220),
+	coloransi.CreateRGB(90, 200, 90),
+	coloransi.CreateRGB(230, 200, 40),
+	coloransi.CreateRGB(255, 140, 0),
+	coloransi.CreateRGB(255, 64, 64),
+}
+
+// Heatmap counts how often every byte of a region changes across samples, telling volatile
+// fields (timers, positions) apart from stable ones (IDs, pointers).
+type Heatmap struct {
+	// Options are the hexdump options used to render the heatmap
+	Options HexDumpOptions
+
+	// HeatColors is the frequency palette, coldest first. Bytes that never changed keep
+	// their regular color.
+	HeatColors []coloransi.ColorCode
+
+	last    []byte
+	changes []int // number of samples the byte changed in
+	samples int
+}
+
+// NewHeatmap creates a Heatmap rendering with the given options
+func NewHeatmap(options HexDumpOptions) *Heatmap {
+	return &Heatmap{
+		Options:    options,
+		HeatColors: DefaultHeatColors,
+	}
+}
+
+// Update records a new sample and returns the number of bytes that changed since the previous one.
+// If the size of the data changes, the counts are reset.
+func (h *Heatmap) Update(data []byte) int {
+	if len(data) != len(h.last) {
+		h.last = append(h.last[:0], data...)
+		h.changes = make([]int, len(data))
+		h.samples = 1
+		return 0
+	}
+
+	changed := 0
+	for i, b := range data {
+		if b != h.last[i] {
+			h.changes[i]++
+			changed++
+		}
+	}
+	copy(h.last, data)
+	h.samples++
+
+	return changed
+}
+
+// Samples returns the number of samples recorded since the last reset
+func (h *Heatmap) Samples() int {
+	return h.samples
+}
+
+// Data returns the most recent sample
+func (h *Heatmap) Data() []byte {
+	return h.last
+}
+
+// Changes returns the number of samples the byte at offset (relative to the region) changed in
+func (h *Heatmap) Changes(offset int) int {
+	if offset < 0 || offset >= len(h.changes) {
+		return 0
+	}
+	return h.changes[offset]
+}
+
+// Frequency returns the fraction of samples the byte at offset changed in, from 0 (never)
+// to 1 (on every sample)
+func (h *Heatmap) Frequency(offset int) float64 {
+	if h.samples < 2 {
+		return 0
+	}
+	return float64(h.Changes(offset)) / float64(h.samples-1)
+}
+
+// HeatRange is a run of consecutive bytes with a change frequency above a threshold
+type HeatRange struct {
+	Offset    int     // Offset of the first byte, relative to the region
+	Size      int     // Number of bytes
+	Frequency float64 // Highest change frequency of the run
+}
+
+// Volatile returns the runs of bytes whose change frequency is above threshold, in offset order.
+// Volatile(0) returns every byte that changed at least once.
+func (h *Heatmap) Volatile(threshold float64) []HeatRange {
+	var ranges []HeatRange
+	for i := range h.changes {
+		f := h.Frequency(i)
+		if h.changes[i] == 0 || f < threshold {
+			continue
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].Offset+ranges[n-1].Size == i {
+			ranges[n-1].Size++
+			ranges[n-1].Frequency = math.Max(ranges[n-1].Frequency, f)
+			continue
+		}
+		ranges = append(ranges, HeatRange{Offset: i, Size: 1, Frequency: f})
+	}
+	return ranges
+}
+
+// FieldHeat is the change frequency of one field of a struct
+type FieldHeat struct {
+	Name      string
+	Offset    int
+	Size      int
+	Frequency float64 // Highest change frequency of the bytes of the field
+}
+
+// Fields returns the change frequency of every field of the struct type t sampled by the
+// heatmap, most volatile first. Fields past the sampled region are omitted.
+func (h *Heatmap) Fields(t reflect.Type) []FieldHeat {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []FieldHeat
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		offset, size := int(field.Offset), int(field.Type.Size())
+		if size == 0 || offset+size > len(h.changes) {
+			continue
+		}
+
+		heat := FieldHeat{Name: field.Name, Offset: offset, Size: size}
+		for j := offset; j < offset+size; j++ {
+			heat.Frequency = math.Max(heat.Frequency, h.Frequency(j))
+		}
+		fields = append(fields, heat)
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Frequency > fields[j].Frequency
+	})
+	return fields
+}
+
+// color returns the palette color of a change frequency
+func (h *Heatmap) color(f float64) coloransi.ColorCode {
+	index := int(f * float64(len(h.HeatColors)))
+	return h.HeatColors[min(index, len(h.HeatColors)-1)]
+}
+
+// Render writes the most recent sample with every byte colored by its change frequency
+func (h *Heatmap) Render(writer io.Writer) {
+	options := h.Options
+	start := options.StartOffset
+	userColor := options.ByteColor
+
+	options.ByteColor = func(offset uint64, b byte) (coloransi.ColorCode, bool) {
+		i := offset - start
+		if i < uint64(len(h.changes)) && h.changes[i] > 0 && len(h.HeatColors) > 0 {
+			return h.color(h.Frequency(int(i))), true
+		}
+		if userColor != nil {
+			return userColor(offset, b)
+		}
+		return 0, false
+	}
+
+	DumpToWriter(writer, h.last, options)
+}
+
+// String renders the heatmap as a string
+func (h *Heatmap) String() string {
+	var buffer bytes.Buffer
+	h.Render(&buffer)
+	return buffer.String()
+}
+
+// SampleHeatmap reads a region samples times, interval apart, and returns the heatmap of
+// its changes. Failed reads are skipped. It returns early with the context error when cancelled.
+func SampleHeatmap(ctx context.Context, read ReadFunc, samples int, interval time.Duration, options HexDumpOptions) (*Heatmap, error) {
+	if samples < 2 {
+		return nil, fmt.Errorf("at least 2 samples are needed, got %d", samples)
+	}
+	if interval <= 0 {
+		interval = DefaultWatchOptions().Interval
+	}
+
+	heatmap := NewHeatmap(options)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for i := 0; i < samples; i++ {
+		if data, err := read(); err == nil {
+			heatmap.Update(data)
+		}
+
+		if i == samples-1 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return heatmap, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	if heatmap.Samples() == 0 {
+		return nil, fmt.Errorf("all %d reads failed", samples)
+	}
+
+	return heatmap, nil
+}
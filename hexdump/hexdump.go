@@ -61,11 +61,20 @@ type HexDumpOptions struct {
 	// MaxLines is the maximum number of lines to show (0 for no limit)
 	MaxLines int
 
-	// ShowPointers determines whether to show potential pointers
+	// ShowPointers determines whether to show potential pointers, described as module+offset
+	// (see memory_map.FormatAddress)
 	ShowPointers bool
 
 	// MemoryMap is the memory map used for pointer validation
 	MemoryMap []memory_map.MemoryMapItem
+
+	// ByteOrder is the byte order potential pointers are decoded in, nil for little-endian
+	ByteOrder binary.ByteOrder
+
+	// ByteColor optionally overrides the color of individual bytes.
+	// It receives the absolute offset of the byte (StartOffset included) and its value,
+	// and returns the color to use and whether the override applies.
+	ByteColor func(offset uint64, b byte) (coloransi.ColorCode, bool)
 }
 
 // DefaultOptions returns the default hexdump options
@@ -138,7 +147,7 @@ func formatLine(writer io.Writer, data []byte, offset uint64, options HexDumpOpt
 	}
 
 	// Build hex groups
-	hexParts := formatHexValues(data, options)
+	hexParts := formatHexValues(data, offset, options)
 
 	// Decide if we show a mid-line divider.
 	// Only show it once the line actually reaches past half of BytesPerLine.
@@ -195,28 +204,32 @@ func formatLine(writer io.Writer, data []byte, offset uint64, options HexDumpOpt
 		if options.BytesPerLine >= 8 && len(data) > options.BytesPerLine/2 {
 			midPoint := options.BytesPerLine / 2
 			if midPoint < len(data) {
-				formatASCII(writer, data[:midPoint], 0, options)
+				formatASCII(writer, data[:midPoint], offset, 0, options)
 				fmt.Fprint(writer, " ")
-				formatASCII(writer, data[midPoint:], midPoint, options)
+				formatASCII(writer, data[midPoint:], offset, midPoint, options)
 			} else {
-				formatASCII(writer, data, 0, options)
+				formatASCII(writer, data, offset, 0, options)
 			}
 		} else {
-			formatASCII(writer, data, 0, options)
+			formatASCII(writer, data, offset, 0, options)
 		}
 	}
 
-	// Optional pointer preview (unchanged)
+	// Optional pointer preview, as module+offset where possible
 	if options.ShowPointers && len(data) >= 8 {
 		fmt.Fprint(writer, " | ")
-		ptr := binary.LittleEndian.Uint64(data[:8])
+		order := options.ByteOrder
+		if order == nil {
+			order = binary.LittleEndian
+		}
+		ptr := order.Uint64(data[:8])
 		if isValidPointer(ptr, options.MemoryMap) {
-			fmt.Fprintf(writer, "%s ", coloransi.Foreground(coloransi.Yellow, fmt.Sprintf("0x%x", ptr)))
+			fmt.Fprintf(writer, "%s ", coloransi.Foreground(coloransi.Yellow, memory_map.FormatAddress(ptr, options.MemoryMap)))
 		}
 		if len(data) >= 16 {
-			ptr2 := binary.LittleEndian.Uint64(data[8:16])
+			ptr2 := order.Uint64(data[8:16])
 			if isValidPointer(ptr2, options.MemoryMap) {
-				fmt.Fprintf(writer, "%s", coloransi.Foreground(coloransi.Yellow, fmt.Sprintf("0x%x", ptr2)))
+				fmt.Fprintf(writer, "%s", coloransi.Foreground(coloransi.Yellow, memory_map.FormatAddress(ptr2, options.MemoryMap)))
 			}
 		}
 	}
@@ -232,11 +245,22 @@ func max(a, b int) int {
 }
 
 // formatASCII formats the ASCII part of a hex dump line
-func formatASCII(writer io.Writer, data []byte, offset int, options HexDumpOptions) {
+func formatASCII(writer io.Writer, data []byte, lineOffset uint64, offset int, options HexDumpOptions) {
 	for i, b := range data {
 		c := rune(b)
 		color := options.ASCIIColor
 
+		// Per-byte color override (e.g. watch mode change highlighting)
+		if options.ByteColor != nil {
+			if override, ok := options.ByteColor(lineOffset+uint64(offset+i), b); ok {
+				if b == 0 || !unicode.IsPrint(c) {
+					c = '.'
+				}
+				fmt.Fprint(writer, coloransi.Foreground(override, string(c)))
+				continue
+			}
+		}
+
 		// Check if this byte is part of the highlight pattern
 		isHighlighted := false
 		if len(options.HighlightPattern) > 0 {
@@ -265,7 +289,7 @@ func formatASCII(writer io.Writer, data []byte, offset int, options HexDumpOptio
 }
 
 // formatHexValues formats the hex values part of the line with proper grouping and highlighting
-func formatHexValues(data []byte, options HexDumpOptions) []string {
+func formatHexValues(data []byte, lineOffset uint64, options HexDumpOptions) []string {
 	var result []string
 	var groupBuffer []string
 
@@ -278,6 +302,13 @@ func formatHexValues(data []byte, options HexDumpOptions) []string {
 			color = options.ZeroColor
 		}
 
+		// Per-byte color override (e.g. watch mode change highlighting)
+		if options.ByteColor != nil {
+			if override, ok := options.ByteColor(lineOffset+uint64(i), b); ok {
+				color = override
+			}
+		}
+
 		// Check if this byte is part of the highlight pattern
 		isHighlighted := false
 		if len(options.HighlightPattern) > 0 {
@@ -446,6 +477,12 @@ func (h *HexDump) EnablePointerChecking(memoryMap []memory_map.MemoryMapItem) *H
 	return h
 }
 
+// SetByteOrder sets the byte order potential pointers are decoded in
+func (h *HexDump) SetByteOrder(order binary.ByteOrder) *HexDump {
+	h.Options.ByteOrder = order
+	return h
+}
+
 // Dump dumps the data with current options
 func (h *HexDump) Dump(data []byte) string {
 	return Dump(data, h.Options)
@@ -466,7 +503,13 @@ func (h *HexDump) DumpToWriter(writer io.Writer, data []byte) {
 // ascii of non-printable characters in red dots
 // check if pointers at byte 0 and byte 8 are valid and put the pointer address to the right of the ascii
 func HexdumpBasic(data []byte, offset uint64, size uint, mm []memory_map.MemoryMapItem) string {
+	return HexdumpBasicByteOrder(data, offset, size, mm, binary.LittleEndian)
+}
+
+// HexdumpBasicByteOrder is HexdumpBasic for memory whose pointers are stored in the given byte order
+func HexdumpBasicByteOrder(data []byte, offset uint64, size uint, mm []memory_map.MemoryMapItem, order binary.ByteOrder) string {
 	options := DefaultOptions()
+	options.ByteOrder = order
 	options.StartOffset = offset
 	options.ShowPointers = true
 	options.MemoryMap = mm
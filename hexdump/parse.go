@@ -0,0 +1,211 @@
+package hexdump
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gomem/process"
+	"gomem/process_blob"
+)
+
+// ErrNoHexdumpData is returned by Parse when the input contains no recognizable hexdump lines
+var ErrNoHexdumpData = errors.New("no hexdump data found")
+
+// ansiEscape matches ANSI SGR/cursor escape sequences
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;?]*[A-Za-z]")
+
+// Block is a contiguous run of bytes reconstructed from a hexdump
+type Block struct {
+	Offset uint64
+	Data   []byte
+}
+
+// Blob returns the block as a process blob based at its offset, so it can be
+// analyzed with the typed readers (ReadUINT32, OffsetPOINTER, ...).
+func (b Block) Blob() *process_blob.ProcessBlob {
+	return process_blob.NewProcessBlob(process.ProcessMemoryAddress(b.Offset), b.Data)
+}
+
+// StripANSI removes ANSI escape sequences from s
+func StripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// ParseString is a convenience wrapper around Parse for in-memory text
+func ParseString(s string) ([]Block, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// Parse reconstructs the bytes of previously generated hexdumps.
+// It understands the output of this package (any BytesPerLine/GroupSize, with or
+// without offsets, ASCII column and pointer preview, colored or not) as well as
+// the encoding/hex Dump format. Lines that are not hexdump lines are ignored, so
+// dumps pasted with surrounding text can be parsed directly.
+// Consecutive lines are merged into one Block; a gap in offsets starts a new one.
+func Parse(reader io.Reader) ([]Block, error) {
+	var blocks []Block
+	next := uint64(0)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(StripANSI(scanner.Text()), "\r")
+
+		offset, data, hasOffset, ok := parseLine(line)
+		if !ok || len(data) == 0 {
+			continue
+		}
+		if !hasOffset {
+			offset = next
+		}
+
+		if n := len(blocks); n > 0 && blocks[n-1].Offset+uint64(len(blocks[n-1].Data)) == offset {
+			blocks[n-1].Data = append(blocks[n-1].Data, data...)
+		} else {
+			blocks = append(blocks, Block{Offset: offset, Data: data})
+		}
+		next = offset + uint64(len(data))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return blocks, err
+	}
+
+	if len(blocks) == 0 {
+		return nil, ErrNoHexdumpData
+	}
+
+	return blocks, nil
+}
+
+// parseLine parses a single (ANSI stripped) hexdump line
+func parseLine(line string) (offset uint64, data []byte, hasOffset bool, ok bool) {
+	rest := strings.TrimLeft(line, " ")
+	if rest == "" {
+		return 0, nil, false, false
+	}
+
+	// An offset column is followed by two spaces, hex groups by one.
+	// Short lines without an offset column are padded with spaces too, so fall back
+	// to parsing the whole line as data if the remainder is not a valid dump line.
+	if idx := strings.Index(rest, "  "); idx > 0 && !strings.ContainsAny(rest[:idx], " |") {
+		if v, err := strconv.ParseUint(rest[:idx], 16, 64); err == nil {
+			if data, ok := parseColumns(strings.TrimLeft(rest[idx:], " ")); ok {
+				return v, data, true, true
+			}
+		}
+	}
+
+	data, ok = parseColumns(rest)
+	return 0, data, false, ok
+}
+
+// parseColumns parses everything after the offset column
+func parseColumns(rest string) ([]byte, bool) {
+	// encoding/hex Dump style: "68 65 6c 6c  6f 20 |hello |"
+	if data, ok := parseGoHexDump(rest); ok {
+		return data, true
+	}
+
+	return parseHexdumpColumns(rest)
+}
+
+// parseGoHexDump parses the hex and ASCII columns of an encoding/hex Dump line
+func parseGoHexDump(rest string) ([]byte, bool) {
+	start := strings.Index(rest, "  |")
+	if start < 0 || !strings.HasSuffix(rest, "|") || len(rest) < start+4 {
+		return nil, false
+	}
+
+	data, ok := parseHexTokens(rest[:start])
+	if !ok {
+		return nil, false
+	}
+
+	ascii := rest[start+3 : len(rest)-1]
+	if utf8.RuneCountInString(ascii) != len(data) {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// parseHexdumpColumns parses the "hex | hex | ascii | pointers" columns produced by this package.
+// The mid-line divider and the ASCII separator look the same, so the split is
+// validated against the length of the ASCII column.
+func parseHexdumpColumns(rest string) ([]byte, bool) {
+	segs := strings.Split(rest, " | ")
+
+	first, ok := parseHexTokens(segs[0])
+	if !ok {
+		return nil, false
+	}
+	if len(segs) == 1 {
+		return first, true
+	}
+
+	second, ok := parseHexTokens(segs[1])
+	if !ok {
+		// Second column is the ASCII column
+		return first, true
+	}
+
+	both := append(first, second...)
+
+	if len(segs) == 2 {
+		// Either two hex halves without an ASCII column, or an ASCII column that happens to look like hex
+		if isASCIIColumn(segs[1:], len(first)) {
+			return first, true
+		}
+		return both, true
+	}
+
+	if isASCIIColumn(segs[2:], len(both)) {
+		return both, true
+	}
+	if isASCIIColumn(segs[1:], len(first)) {
+		return first, true
+	}
+
+	return both, true
+}
+
+// isASCIIColumn reports whether the leading segments form an ASCII column for n bytes.
+// The ASCII column may contain one extra space (mid-line split) and may itself contain " | ".
+func isASCIIColumn(segs []string, n int) bool {
+	for k := 1; k <= len(segs); k++ {
+		length := utf8.RuneCountInString(strings.Join(segs[:k], " | "))
+		if length == n || length == n+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHexTokens decodes whitespace separated groups of hex digits
+func parseHexTokens(s string) ([]byte, bool) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	var data []byte
+	for _, field := range fields {
+		if len(field)%2 != 0 {
+			return nil, false
+		}
+		decoded, err := hex.DecodeString(field)
+		if err != nil {
+			return nil, false
+		}
+		data = append(data, decoded...)
+	}
+
+	return data, true
+}
@@ -0,0 +1,175 @@
+package hexdump
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"gomem/coloransi"
+	"gomem/process"
+)
+
+// DefaultFadeColors is the palette used to highlight changed bytes, from the frame
+// a byte changed in (index 0) to the oldest frame it is still highlighted in.
+var DefaultFadeColors = []coloransi.ColorCode{
+	coloransi.CreateRGB(255, 64, 64),
+	coloransi.CreateRGB(255, 140, 0),
+	coloransi.CreateRGB(230, 200, 40),
+	coloransi.CreateRGB(160, 150, 80),
+}
+
+// ReadFunc returns the current contents of the watched region
+type ReadFunc func() ([]byte, error)
+
+// ProcessReader returns a ReadFunc that reads size bytes at addr from proc
+func ProcessReader(proc process.Process, addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ReadFunc {
+	return func() ([]byte, error) {
+		return proc.ReadMemory(addr, size)
+	}
+}
+
+// Watcher keeps the history of a region across frames and renders each frame
+// with the bytes that changed recently highlighted, fading out over FadeColors.
+type Watcher struct {
+	// Options are the hexdump options used to render each frame
+	Options HexDumpOptions
+
+	// FadeColors is the highlight palette, newest change first
+	FadeColors []coloransi.ColorCode
+
+	previous []byte
+	age      []int // frames since the byte last changed, -1 if it never changed
+	frame    int
+}
+
+// NewWatcher creates a Watcher rendering frames with the given options
+func NewWatcher(options HexDumpOptions) *Watcher {
+	return &Watcher{
+		Options:    options,
+		FadeColors: DefaultFadeColors,
+	}
+}
+
+// Update records a new frame and returns the number of bytes that changed since the previous one.
+// If the size of the data changes, the history is reset.
+func (w *Watcher) Update(data []byte) int {
+	w.frame++
+
+	if len(data) != len(w.previous) {
+		w.previous = append(w.previous[:0], data...)
+		w.age = make([]int, len(data))
+		for i := range w.age {
+			w.age[i] = -1
+		}
+		return 0
+	}
+
+	changed := 0
+	for i, b := range data {
+		if b != w.previous[i] {
+			w.age[i] = 0
+			changed++
+		} else if w.age[i] >= 0 {
+			w.age[i]++
+		}
+	}
+	copy(w.previous, data)
+
+	return changed
+}
+
+// Frame returns the number of frames recorded so far
+func (w *Watcher) Frame() int {
+	return w.frame
+}
+
+// Render writes the most recent frame to the writer
+func (w *Watcher) Render(writer io.Writer) {
+	options := w.Options
+	start := options.StartOffset
+	userColor := options.ByteColor
+
+	options.ByteColor = func(offset uint64, b byte) (coloransi.ColorCode, bool) {
+		i := offset - start
+		if i < uint64(len(w.age)) {
+			if a := w.age[i]; a >= 0 && a < len(w.FadeColors) {
+				return w.FadeColors[a], true
+			}
+		}
+		if userColor != nil {
+			return userColor(offset, b)
+		}
+		return 0, false
+	}
+
+	DumpToWriter(writer, w.previous, options)
+}
+
+// String renders the most recent frame as a string
+func (w *Watcher) String() string {
+	var buffer bytes.Buffer
+	w.Render(&buffer)
+	return buffer.String()
+}
+
+// WatchOptions configures Watch
+type WatchOptions struct {
+	// Interval is the delay between two reads of the region
+	Interval time.Duration
+
+	// Frames is the number of frames to render (0 to run until the context is cancelled)
+	Frames int
+
+	// ClearScreen moves the cursor home and clears the terminal before each frame
+	ClearScreen bool
+}
+
+// DefaultWatchOptions returns the default watch options
+func DefaultWatchOptions() WatchOptions {
+	return WatchOptions{
+		Interval:    500 * time.Millisecond,
+		Frames:      0,
+		ClearScreen: true,
+	}
+}
+
+// Watch re-reads a region at a fixed interval and renders it with the watcher,
+// highlighting the bytes that changed since the previous frames.
+// Read errors are rendered in place of the frame and do not stop the watch.
+// It returns nil once options.Frames frames were rendered, or the context error when cancelled.
+func Watch(ctx context.Context, writer io.Writer, read ReadFunc, watcher *Watcher, options WatchOptions) error {
+	if options.Interval <= 0 {
+		options.Interval = DefaultWatchOptions().Interval
+	}
+
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+
+	for rendered := 1; ; rendered++ {
+		data, err := read()
+
+		if options.ClearScreen {
+			fmt.Fprint(writer, "\033[H\033[2J")
+		}
+
+		if err != nil {
+			fmt.Fprintf(writer, "frame %d: read failed: %v\n", watcher.Frame()+1, err)
+		} else {
+			changed := watcher.Update(data)
+			fmt.Fprintf(writer, "frame %d: %d bytes changed\n", watcher.Frame(), changed)
+			watcher.Render(writer)
+		}
+
+		if options.Frames > 0 && rendered >= options.Frames {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
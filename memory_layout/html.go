@@ -0,0 +1,122 @@
+package memory_layout
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"gomem/process/memory_map"
+)
+
+// htmlRegion is one segment of a bar in the HTML output
+type htmlRegion struct {
+	Width float64 // Percentage of the block bar
+	Color template.CSS
+	Title string
+}
+
+// htmlBlock is one row of the HTML output
+type htmlBlock struct {
+	Range   string
+	Size    string
+	Kind    string
+	Label   string
+	Gap     bool
+	Width   float64 // Percentage of the largest bar
+	Regions []htmlRegion
+}
+
+// htmlLegend is one entry of the legend of the HTML output
+type htmlLegend struct {
+	Color template.CSS
+	Title string
+}
+
+var htmlTemplate = template.Must(template.New("layout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: monospace; font-size: 13px; background: #1e1e1e; color: #ddd; }
+table { border-collapse: collapse; }
+td { padding: 1px 8px; white-space: nowrap; }
+tr.gap td { color: #777; }
+.bar { display: flex; height: 12px; min-width: 2px; }
+.bar span { display: block; height: 100%; min-width: 1px; }
+.legend span { display: inline-block; width: 12px; height: 12px; margin: 0 4px 0 12px; vertical-align: middle; }
+pre { color: #aaa; }
+</style>
+</head>
+<body>
+<h3>{{.Title}}</h3>
+<div class="legend">{{range .Legend}}<span style="background:{{.Color}}"></span>{{.Title}}{{end}}</div>
+<table>
+<tr><th>Range</th><th>Size</th><th>Kind</th><th>Label</th><th style="width:{{.BarWidth}}px"></th></tr>
+{{range .Blocks}}{{if .Gap}}<tr class="gap"><td>{{.Range}}</td><td>{{.Size}}</td><td>gap</td><td>...</td><td></td></tr>
+{{else}}<tr><td>{{.Range}}</td><td>{{.Size}}</td><td>{{.Kind}}</td><td>{{.Label}}</td><td><div class="bar" style="width:{{printf "%.3f" .Width}}%">{{range .Regions}}<span style="width:{{printf "%.3f" .Width}}%;background:{{.Color}}" title="{{.Title}}"></span>{{end}}</div></td></tr>
+{{end}}{{end}}</table>
+<pre>{{.Summary}}</pre>
+</body>
+</html>
+`))
+
+// cssColor returns the CSS color of a region with the given permissions, see PermsColor
+func cssColor(perms string) template.CSS {
+	r, g, b := PermsColor(perms).GetRGB()
+	return template.CSS(fmt.Sprintf("#%02x%02x%02x", r, g, b))
+}
+
+// RenderHTML writes a standalone HTML page of the memory map, the same rows as RenderText
+// with bars scaled to the page and a tooltip per region. options.BarWidth is the width of
+// the largest bar in units of 10 pixels, options.Color is ignored.
+func RenderHTML(w io.Writer, mm []memory_map.MemoryMapItem, title string, options LayoutOptions) error {
+	blocks := Blocks(mm)
+	largest := largestBlock(blocks)
+
+	data := struct {
+		Title    string
+		BarWidth int
+		Legend   []htmlLegend
+		Blocks   []htmlBlock
+		Summary  string
+	}{
+		Title:    title,
+		BarWidth: options.BarWidth * 10,
+		Summary:  Summary(mm),
+	}
+
+	for _, l := range legendPerms {
+		data.Legend = append(data.Legend, htmlLegend{Color: cssColor(l.Perms), Title: l.Name})
+	}
+
+	for _, b := range blocks {
+		view := htmlBlock{
+			Range: fmt.Sprintf("%016x-%016x", b.Start, b.End),
+			Size:  FormatSize(b.Size()),
+			Kind:  b.Kind.String(),
+			Label: b.Label,
+			Gap:   b.Gap,
+		}
+		if b.Gap {
+			if options.ShowGaps {
+				data.Blocks = append(data.Blocks, view)
+			}
+			continue
+		}
+
+		if largest > 0 {
+			view.Width = float64(b.Size()) / float64(largest) * 100
+		}
+		for _, region := range b.Regions {
+			view.Regions = append(view.Regions, htmlRegion{
+				Width: float64(region.Size) / float64(b.Size()) * 100,
+				Color: cssColor(region.Perms),
+				Title: fmt.Sprintf("%016x-%016x %s %s", region.Address, region.Address+uint64(region.Size), region.Perms, FormatSize(uint64(region.Size))),
+			})
+		}
+		data.Blocks = append(data.Blocks, view)
+	}
+
+	return htmlTemplate.Execute(w, data)
+}
@@ -0,0 +1,282 @@
+package memory_layout
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gomem/coloransi"
+	"gomem/process/memory_map"
+)
+
+// Block is a run of adjacent regions shown as one row of the layout: the regions of one
+// module, the heap, a stack, consecutive anonymous regions, or the unmapped gap between two
+// of those
+type Block struct {
+	Label   string // Path of the regions, "[anon]" for anonymous ones, empty for gaps
+	Kind    memory_map.RegionKind
+	Start   uint64
+	End     uint64
+	Regions []memory_map.MemoryMapItem // Regions of the block in address order, nil for gaps
+	Gap     bool                       // Unmapped space between the surrounding blocks
+}
+
+// Size returns the size of the block in bytes
+func (b Block) Size() uint64 {
+	return b.End - b.Start
+}
+
+// label returns the label of a region, the regions of a block share it
+func label(item memory_map.MemoryMapItem) string {
+	if item.Path == "" {
+		return "[anon]"
+	}
+	return item.Path
+}
+
+// Blocks groups the memory map into blocks in address order, adjacent regions of the same
+// kind and path are merged and the holes between them are returned as gap blocks
+func Blocks(mm []memory_map.MemoryMapItem) []Block {
+	items := make([]memory_map.MemoryMapItem, len(mm))
+	copy(items, mm)
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Address < items[j].Address
+	})
+
+	var blocks []Block
+	for _, item := range items {
+		start, end := item.Address, item.Address+uint64(item.Size)
+
+		if n := len(blocks); n > 0 {
+			last := &blocks[n-1]
+			if start == last.End && item.Kind() == last.Kind && label(item) == last.Label {
+				last.End = end
+				last.Regions = append(last.Regions, item)
+				continue
+			}
+			if start > last.End {
+				blocks = append(blocks, Block{Start: last.End, End: start, Gap: true})
+			}
+		}
+
+		blocks = append(blocks, Block{
+			Label:   label(item),
+			Kind:    item.Kind(),
+			Start:   start,
+			End:     end,
+			Regions: []memory_map.MemoryMapItem{item},
+		})
+	}
+	return blocks
+}
+
+// LayoutOptions configures the rendering of a memory map
+type LayoutOptions struct {
+	// BarWidth is the width in characters of the bar of the largest block, the bars of the
+	// other blocks are proportional to their size
+	BarWidth int
+
+	// Color enables ANSI colors in the text output
+	Color bool
+
+	// ShowGaps adds a row for every unmapped gap between blocks
+	ShowGaps bool
+
+	// LabelWidth is the maximum width of the label column, longer paths keep their end
+	LabelWidth int
+}
+
+// DefaultLayoutOptions returns the default layout options
+func DefaultLayoutOptions() LayoutOptions {
+	return LayoutOptions{
+		BarWidth:   40,
+		Color:      true,
+		ShowGaps:   false,
+		LabelWidth: 48,
+	}
+}
+
+// PermsColor returns the color of a region with the given permissions: red for executable,
+// green for writable, blue for read only and gray for no access
+func PermsColor(perms string) coloransi.ColorCode {
+	read, write, exec := permsBits(perms)
+	switch {
+	case exec && write:
+		return coloransi.CreateRGB(220, 80, 220)
+	case exec:
+		return coloransi.CreateRGB(230, 80, 80)
+	case write:
+		return coloransi.CreateRGB(80, 190, 90)
+	case read:
+		return coloransi.CreateRGB(80, 130, 230)
+	}
+	return coloransi.CreateRGB(110, 110, 110)
+}
+
+// permsChar returns the character drawing a region in the uncolored text output
+func permsChar(perms string) byte {
+	read, write, exec := permsBits(perms)
+	switch {
+	case exec:
+		return 'x'
+	case write:
+		return 'w'
+	case read:
+		return 'r'
+	}
+	return '.'
+}
+
+func permsBits(perms string) (read, write, exec bool) {
+	return len(perms) > 0 && perms[0] == 'r',
+		len(perms) > 1 && perms[1] == 'w',
+		len(perms) > 2 && perms[2] == 'x'
+}
+
+// FormatSize formats a size in bytes with a binary unit, e.g. "1.5 MiB"
+func FormatSize(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, exp := float64(size)/unit, 0
+	for value >= unit && exp < 5 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTPE"[exp])
+}
+
+// barWidth returns the width of the bar of a block of the given size
+func barWidth(size, largest uint64, width int) int {
+	if largest == 0 {
+		return 1
+	}
+	return max(1, int(float64(size)/float64(largest)*float64(width)+0.5))
+}
+
+// barRegions returns the region drawn at every character of a bar of width characters
+func barRegions(b Block, width int) []memory_map.MemoryMapItem {
+	out := make([]memory_map.MemoryMapItem, width)
+	j := 0
+	for i := range out {
+		// Sample the middle of the character
+		addr := b.Start + uint64((float64(i)+0.5)/float64(width)*float64(b.Size()))
+		for j < len(b.Regions)-1 && addr >= b.Regions[j].Address+uint64(b.Regions[j].Size) {
+			j++
+		}
+		out[i] = b.Regions[j]
+	}
+	return out
+}
+
+// largestBlock returns the size of the largest non gap block
+func largestBlock(blocks []Block) uint64 {
+	var largest uint64
+	for _, b := range blocks {
+		if !b.Gap {
+			largest = max(largest, b.Size())
+		}
+	}
+	return largest
+}
+
+// truncateLabel keeps the end of labels longer than width, the file name is the useful part
+func truncateLabel(s string, width int) string {
+	if width <= 3 || len(s) <= width {
+		return s
+	}
+	return "..." + s[len(s)-width+3:]
+}
+
+// RenderText writes one row per block of the memory map with its address range, size, kind,
+// label and a bar proportional to its size, drawn per region by permissions, followed by the
+// totals per kind
+func RenderText(w io.Writer, mm []memory_map.MemoryMapItem, options LayoutOptions) error {
+	blocks := Blocks(mm)
+	largest := largestBlock(blocks)
+
+	var sb strings.Builder
+	for _, b := range blocks {
+		if b.Gap {
+			if options.ShowGaps {
+				fmt.Fprintf(&sb, "%016x-%016x %10s %-9s %s\n", b.Start, b.End, FormatSize(b.Size()), "gap", "...")
+			}
+			continue
+		}
+
+		fmt.Fprintf(&sb, "%016x-%016x %10s %-9s %-*s ",
+			b.Start, b.End, FormatSize(b.Size()), b.Kind, options.LabelWidth, truncateLabel(b.Label, options.LabelWidth))
+
+		regions := barRegions(b, barWidth(b.Size(), largest, options.BarWidth))
+		for _, region := range regions {
+			if options.Color {
+				sb.WriteString(coloransi.Background(PermsColor(region.Perms), " "))
+			} else {
+				sb.WriteByte(permsChar(region.Perms))
+			}
+		}
+		sb.WriteByte('\n')
+	}
+
+	sb.WriteByte('\n')
+	sb.WriteString(Summary(mm))
+	sb.WriteString(legend(options.Color))
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// legendPerms are the permissions shown in the legend, one per color
+var legendPerms = []struct {
+	Perms string
+	Name  string
+}{
+	{"r--", "read only"},
+	{"rw-", "writable"},
+	{"r-x", "executable"},
+	{"rwx", "writable+executable"},
+	{"---", "no access"},
+}
+
+// legend returns the legend line of the text output
+func legend(color bool) string {
+	parts := make([]string, 0, len(legendPerms))
+	for _, l := range legendPerms {
+		if color {
+			parts = append(parts, coloransi.Background(PermsColor(l.Perms), "  ")+" "+l.Name)
+		} else if l.Perms != "rwx" { // Drawn like r-x without colors
+			parts = append(parts, string(permsChar(l.Perms))+" "+l.Name)
+		}
+	}
+	return "Legend: " + strings.Join(parts, "  ") + "\n"
+}
+
+// Summary returns the number of regions and total size of each region kind, one kind per line
+func Summary(mm []memory_map.MemoryMapItem) string {
+	counts := make(map[memory_map.RegionKind]int)
+	sizes := make(map[memory_map.RegionKind]uint64)
+	var total uint64
+	for _, item := range mm {
+		counts[item.Kind()]++
+		sizes[item.Kind()] += uint64(item.Size)
+		total += uint64(item.Size)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d regions, %s mapped\n", len(mm), FormatSize(total))
+	for _, kind := range []memory_map.RegionKind{
+		memory_map.RegionImage,
+		memory_map.RegionHeap,
+		memory_map.RegionStack,
+		memory_map.RegionAnonymous,
+		memory_map.RegionSpecial,
+	} {
+		if counts[kind] == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "  %-9s %5d regions %10s\n", kind, counts[kind], FormatSize(sizes[kind]))
+	}
+	return sb.String()
+}
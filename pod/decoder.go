@@ -0,0 +1,142 @@
+package pod
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"unsafe"
+
+	"gomem/process"
+)
+
+// Decoder decodes a value of a registered type from its raw bytes in the target process.
+// data holds exactly sizeof(T) bytes, addr is the address they were read from (0 when unknown,
+// e.g. a blob without a base address), so address-keyed obfuscation can be undone.
+type Decoder[T any] func(data []byte, addr process.ProcessMemoryAddress) (T, error)
+
+// decodeFunc is the type-erased form of a Decoder
+type decodeFunc func(data []byte, addr process.ProcessMemoryAddress) (reflect.Value, error)
+
+var decoders = struct {
+	sync.RWMutex
+	byType map[reflect.Type]decodeFunc
+}{byType: make(map[reflect.Type]decodeFunc)}
+
+// RegisterDecoder registers a custom decoder for T. ReadT, ReadBlob, ReadSliceT and ReadStruct
+// call it instead of copying the raw bytes whenever they read a T, either directly or as a
+// (nested) struct field or array element. Typical uses are packed or encrypted fields:
+//
+//	type XorInt32 int32
+//	pod.RegisterDecoder(func(data []byte, addr process.ProcessMemoryAddress) (XorInt32, error) {
+//		return XorInt32(binary.LittleEndian.Uint32(data) ^ key), nil
+//	})
+//
+// Registering a decoder for a type replaces the previous one.
+func RegisterDecoder[T any](decoder Decoder[T]) {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+
+	decoders.Lock()
+	defer decoders.Unlock()
+	decoders.byType[rt] = func(data []byte, addr process.ProcessMemoryAddress) (reflect.Value, error) {
+		v, err := decoder(data, addr)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&v).Elem(), nil
+	}
+}
+
+// UnregisterDecoder removes the custom decoder registered for T
+func UnregisterDecoder[T any]() {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+
+	decoders.Lock()
+	defer decoders.Unlock()
+	delete(decoders.byType, rt)
+}
+
+// lookupDecoder returns the decoder registered for rt
+func lookupDecoder(rt reflect.Type) (decodeFunc, bool) {
+	decoders.RLock()
+	defer decoders.RUnlock()
+	decode, ok := decoders.byType[rt]
+	return decode, ok
+}
+
+// decodeAs runs decode on the first sizeof(T) bytes of data
+func decodeAs[T any](decode decodeFunc, data []byte, addr process.ProcessMemoryAddress) (T, error) {
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	if len(data) < size {
+		return zero, fmt.Errorf("decoder for %T: buffer too small", zero)
+	}
+	v, err := decode(data[:size], addr)
+	if err != nil {
+		return zero, fmt.Errorf("decoder for %T at 0x%X: %w", zero, addr, err)
+	}
+	return v.Interface().(T), nil
+}
+
+// blobAddress returns the address a blob was read from, 0 if the blob does not know it
+func blobAddress(offset process.ProcessReadOffset) process.ProcessMemoryAddress {
+	if based, ok := offset.(interface {
+		BaseAddress() process.ProcessMemoryAddress
+	}); ok {
+		return based.BaseAddress()
+	}
+	return 0
+}
+
+// hasDecoders reports whether any decoder is registered, so the common case skips the walk
+func hasDecoders() bool {
+	decoders.RLock()
+	defer decoders.RUnlock()
+	return len(decoders.byType) > 0
+}
+
+// applyDecoders walks v (a value decoded from data read at addr) and replaces every value
+// of a registered type, including nested struct fields and array elements, with the
+// output of its decoder
+func applyDecoders(v reflect.Value, data []byte, addr process.ProcessMemoryAddress) error {
+	if decode, ok := lookupDecoder(v.Type()); ok {
+		size := int(v.Type().Size())
+		if len(data) < size {
+			return fmt.Errorf("decoder for %s: buffer too small", v.Type())
+		}
+		decoded, err := decode(data[:size], addr)
+		if err != nil {
+			return fmt.Errorf("decoder for %s at 0x%X: %w", v.Type(), addr, err)
+		}
+		settable(v).Set(decoded)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			offset := v.Type().Field(i).Offset
+			if err := applyDecoders(v.Field(i), data[offset:], addr+process.ProcessMemoryAddress(offset)); err != nil {
+				return err
+			}
+		}
+	case reflect.Array:
+		elemSize := v.Type().Elem().Size()
+		for i := 0; i < v.Len(); i++ {
+			offset := uintptr(i) * elemSize
+			if err := applyDecoders(v.Index(i), data[offset:], addr+process.ProcessMemoryAddress(offset)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// settable returns a settable view of v, which must be addressable; unexported fields are
+// reached through their address
+func settable(v reflect.Value) reflect.Value {
+	if v.CanSet() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,82 @@
+package pod
+
+import (
+	"encoding/binary"
+	"reflect"
+	"strings"
+)
+
+// Byte order tags
+//
+// A field tagged `pod:"be"` or `pod:"le"` is decoded (and written by WriteTToProcess) in
+// big-endian or little-endian byte order regardless of the byte order of the target. The
+// option may appear anywhere in the tag, e.g. `pod:"valid_pointer,be"`, and applies to
+// every scalar below the field: the elements of an array and the fields of a nested
+// struct. A blank field tagged with a byte order sets the default of its struct:
+//
+//	type PacketHeader struct {
+//		_      struct{} `pod:"be"` // network byte order unless a field says otherwise
+//		Length uint16
+//		Flags  uint16
+//		Seq    uint32 `pod:"le"`
+//	}
+//
+// The innermost tag wins: a field tag overrides the default of its struct, which overrides
+// the tags of the enclosing fields. Pointers followed through valid_pointer fields are
+// decoded in the byte order of the target again.
+
+// tagByteOrder returns the byte order set by the be or le option of a pod tag, nil if none
+func tagByteOrder(tag string) binary.ByteOrder {
+	var order binary.ByteOrder
+	for _, part := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(part) {
+		case "be":
+			order = binary.BigEndian
+		case "le":
+			order = binary.LittleEndian
+		}
+	}
+	return order
+}
+
+// structByteOrder returns the default byte order of the fields of struct type t, set by
+// the tag of a blank field, nil if none
+func structByteOrder(t reflect.Type) binary.ByteOrder {
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Name == "_" {
+			if order := tagByteOrder(field.Tag.Get("pod")); order != nil {
+				return order
+			}
+		}
+	}
+	return nil
+}
+
+// fieldByteOrder returns the byte order of field of a struct whose default byte order is
+// structOrder (nil if it has none), inside a value decoded in the byte order current
+func fieldByteOrder(current, structOrder binary.ByteOrder, field reflect.StructField) binary.ByteOrder {
+	if order := tagByteOrder(field.Tag.Get("pod")); order != nil {
+		return order
+	}
+	if structOrder != nil {
+		return structOrder
+	}
+	return current
+}
+
+// typeHasByteOrderTags reports whether rt (recursively) has a field with a be or le tag,
+// such types can't be copied as is
+func typeHasByteOrderTags(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Array:
+		return typeHasByteOrderTags(rt.Elem())
+	case reflect.Struct:
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if tagByteOrder(field.Tag.Get("pod")) != nil || typeHasByteOrderTags(field.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
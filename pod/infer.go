@@ -0,0 +1,283 @@
+package pod
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"go/format"
+	"math"
+	"strconv"
+	"strings"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// FieldKind is the likely type of an inferred field
+type FieldKind int
+
+const (
+	FieldUnknown FieldKind = iota // No heuristic matched, kept as a raw integer
+	FieldPointer                  // Address inside a mapped region
+	FieldFloat32                  // Plausible float32
+	FieldFloat64                  // Plausible float64
+	FieldInt                      // Small signed integer
+	FieldString                   // NUL terminated or long run of printable ASCII
+	FieldPadding                  // Zero bytes
+)
+
+// String returns the name of the kind
+func (k FieldKind) String() string {
+	switch k {
+	case FieldPointer:
+		return "pointer"
+	case FieldFloat32:
+		return "float32"
+	case FieldFloat64:
+		return "float64"
+	case FieldInt:
+		return "int"
+	case FieldString:
+		return "string"
+	case FieldPadding:
+		return "padding"
+	}
+	return "unknown"
+}
+
+// InferredField is one field of an InferredLayout
+type InferredField struct {
+	Offset  uint64
+	Size    int
+	Kind    FieldKind
+	GoType  string                    // Go type of the field in the draft struct
+	Tag     string                    // pod tag of the field, empty for none
+	Region  *memory_map.MemoryMapItem // Region a pointer points into
+	Comment string                    // Observed value
+}
+
+// Name returns the name of the field in the draft struct, "_" for padding
+func (f InferredField) Name() string {
+	if f.Kind == FieldPadding {
+		return "_"
+	}
+	return fmt.Sprintf("Offset%02X", f.Offset)
+}
+
+// InferredLayout is a guess at the layout of the struct at an address, made from the
+// values found there, see InferLayout
+type InferredLayout struct {
+	Address process.ProcessMemoryAddress
+	Size    int
+	Fields  []InferredField
+}
+
+// Heuristic bounds for classifying values
+const (
+	inferSmallInt      = 0x10000 // |v| below this is a small integer
+	inferMinFloat      = 1e-4    // Smallest plausible float magnitude
+	inferMaxFloat      = 1e7     // Largest plausible float magnitude
+	inferMinASCII      = 4       // Shortest NUL terminated ASCII run taken as a string
+	inferMinASCIINoNul = 8       // Shortest unterminated ASCII run taken as a string
+)
+
+// InferLayout reads size bytes at addr and labels the likely type of every field: pointers
+// into mapped regions, floats, small integers, ASCII strings and zero padding. The result
+// is a draft to start modeling a pod struct from, see InferredLayout.GoStruct.
+func InferLayout(proc process.Process, addr process.ProcessMemoryAddress, size process.ProcessMemorySize) (*InferredLayout, error) {
+	data, err := proc.ReadMemory(addr, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory at 0x%X: %w", uint64(addr), err)
+	}
+
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return InferLayoutFromBytes(data, addr, mm, process.ByteOrderOf(proc)), nil
+}
+
+// InferLayoutFromBytes is InferLayout for memory that was already read.
+// Pointers are only recognized when mm is given.
+func InferLayoutFromBytes(data []byte, addr process.ProcessMemoryAddress, mm []memory_map.MemoryMapItem, order binary.ByteOrder) *InferredLayout {
+	if order == nil {
+		order = binary.LittleEndian
+	}
+
+	layout := &InferredLayout{Address: addr, Size: len(data)}
+
+	for off := 0; off < len(data); {
+		field := inferField(data, off, mm, order)
+
+		// Merge consecutive padding
+		if n := len(layout.Fields); n > 0 && field.Kind == FieldPadding && layout.Fields[n-1].Kind == FieldPadding {
+			prev := &layout.Fields[n-1]
+			prev.Size += field.Size
+			prev.GoType = fmt.Sprintf("[%d]byte", prev.Size)
+		} else {
+			layout.Fields = append(layout.Fields, field)
+		}
+		off += field.Size
+	}
+
+	return layout
+}
+
+// inferField classifies the field starting at off
+func inferField(data []byte, off int, mm []memory_map.MemoryMapItem, order binary.ByteOrder) InferredField {
+	field := InferredField{Offset: uint64(off)}
+	rest := data[off:]
+
+	// Tail shorter than a word
+	if len(rest) < 4 || off%4 != 0 {
+		n := min(len(rest), 4-off%4)
+		field.Size = n
+		field.Kind = FieldUnknown
+		field.GoType = fmt.Sprintf("[%d]byte", n)
+		if allZero(rest[:n]) {
+			field.Kind = FieldPadding
+		}
+		return field
+	}
+
+	if s, n := asciiRun(rest); n > 0 {
+		field.Size = n
+		field.Kind = FieldString
+		field.GoType = fmt.Sprintf("[%d]byte", n)
+		field.Tag = "char_array"
+		field.Comment = strconv.Quote(s)
+		return field
+	}
+
+	if off%8 == 0 && len(rest) >= 8 {
+		v := order.Uint64(rest)
+		if v != 0 && mm != nil {
+			if region := memory_map.IsValidAddress2(v, mm); region != nil {
+				field.Size = 8
+				field.Kind = FieldPointer
+				field.GoType = "uint64"
+				field.Tag = "valid_pointer"
+				field.Region = region
+				field.Comment = fmt.Sprintf("-> 0x%X %s", v, regionLabel(region))
+				return field
+			}
+		}
+
+		// The low word of a double is mantissa bits, which rarely look like a small int or a float
+		lo, hi := order.Uint32(rest), order.Uint32(rest[4:])
+		if process.ByteOrderName(order) == "big" {
+			lo, hi = hi, lo
+		}
+		if lo != 0 && !isSmallInt(lo) && !isPlausibleFloat32(lo) && !isSmallInt(hi) {
+			if f := math.Float64frombits(v); isPlausibleFloat(f) {
+				field.Size = 8
+				field.Kind = FieldFloat64
+				field.GoType = "float64"
+				field.Comment = strconv.FormatFloat(f, 'g', -1, 64)
+				return field
+			}
+		}
+	}
+
+	v := order.Uint32(rest)
+	field.Size = 4
+	switch {
+	case v == 0:
+		field.Kind = FieldPadding
+		field.GoType = "[4]byte"
+	case isSmallInt(v):
+		field.Kind = FieldInt
+		field.GoType = "int32"
+		field.Comment = strconv.Itoa(int(int32(v)))
+	case isPlausibleFloat32(v):
+		field.Kind = FieldFloat32
+		field.GoType = "float32"
+		field.Comment = strconv.FormatFloat(float64(math.Float32frombits(v)), 'g', -1, 32)
+	default:
+		field.Kind = FieldUnknown
+		field.GoType = "uint32"
+		field.Comment = fmt.Sprintf("0x%X", v)
+	}
+	return field
+}
+
+// asciiRun returns the printable ASCII string at the start of data and the size of the
+// field holding it (terminator included, rounded up to 4 bytes), 0 when there is none
+func asciiRun(data []byte) (string, int) {
+	n := 0
+	for n < len(data) && data[n] >= 0x20 && data[n] < 0x7F {
+		n++
+	}
+
+	terminated := n < len(data) && data[n] == 0
+	if n < inferMinASCII || (!terminated && n < inferMinASCIINoNul) {
+		return "", 0
+	}
+
+	size := n
+	if terminated {
+		size++
+	}
+	size = min((size+3)&^3, len(data))
+	return string(data[:n]), size
+}
+
+// isSmallInt reports whether v is a small signed integer, zero excluded
+func isSmallInt(v uint32) bool {
+	i := int32(v)
+	return i != 0 && i > -inferSmallInt && i < inferSmallInt
+}
+
+// isPlausibleFloat32 reports whether v looks like a float32 a program would store
+func isPlausibleFloat32(v uint32) bool {
+	return isPlausibleFloat(float64(math.Float32frombits(v)))
+}
+
+func isPlausibleFloat(f float64) bool {
+	a := math.Abs(f)
+	return !math.IsNaN(f) && a >= inferMinFloat && a <= inferMaxFloat
+}
+
+func allZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// regionLabel describes a region for comments
+func regionLabel(region *memory_map.MemoryMapItem) string {
+	if region.Path != "" {
+		return region.Path
+	}
+	return fmt.Sprintf("anonymous %s", region.Perms)
+}
+
+// GoStruct returns the layout as a gofmt formatted Go struct declaration with pod tags and
+// the observed values as comments
+func (l *InferredLayout) GoStruct(name string) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s was inferred from 0x%X (0x%X bytes)\n", name, uint64(l.Address), l.Size)
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	for _, f := range l.Fields {
+		fmt.Fprintf(&b, "\t%s %s", f.Name(), f.GoType)
+		if f.Tag != "" {
+			fmt.Fprintf(&b, " `pod:%q`", f.Tag)
+		}
+		comment := fmt.Sprintf("0x%02X", f.Offset)
+		if f.Comment != "" {
+			comment += " " + strings.ReplaceAll(f.Comment, "\n", " ")
+		}
+		fmt.Fprintf(&b, " // %s\n", comment)
+	}
+	b.WriteString("}\n")
+
+	formatted, err := format.Source(b.Bytes())
+	if err != nil {
+		return b.String()
+	}
+	return string(formatted)
+}
@@ -0,0 +1,397 @@
+package pod
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Bitfields
+//
+// A field tagged `pod:"bits=START:WIDTH"` takes no memory in the target: it is decoded from
+// WIDTH bits starting at bit START (0 is the least significant bit) of an integer field of
+// the same struct: the last integer field declared before it, skipping bitfields, arrays and
+// structs, unless the tag names one with from=NAME. `pod:"bits=N"` is the single bit N. Bitfields may be bool (set when any bit is) or
+// integers, signed ones are sign extended:
+//
+//	type Unit struct {
+//		Flags  uint32
+//		Alive  bool  `pod:"bits=0"`
+//		Team   uint8 `pod:"bits=1:3"`
+//		Level  int16 `pod:"bits=4:8"`
+//		Health int32 // at offset 4, the bitfields take no room
+//	}
+//
+// WriteTToProcess merges the bitfields that differ from the target into their word.
+
+// Explicit offsets
+//
+// A field tagged `pod:"offset=0x38"` is placed at that offset of the struct in the target
+// instead of its Go offset, so a struct only declares the fields of interest:
+//
+//	type Entity struct {
+//		ID     uint32   `pod:"offset=0x08"`
+//		Health int32    `pod:"offset=0x38"`
+//		Armor  int32    // follows Health, at 0x3C
+//		Name   [16]byte `pod:"char_array,offset=0x100"`
+//	}
+//
+// Fields without an offset follow the field declared before them with the Go alignment
+// rules. The struct takes up to the end of its last field, rounded to its alignment; fields
+// may overlap. Like the other options, offset= comes after the type of the tag.
+
+// bitRange is the bits of an integer a bitfield is decoded from
+type bitRange struct {
+	start, width int
+}
+
+// mask returns the bits of the range, shifted to bit 0
+func (b bitRange) mask() uint64 {
+	if b.width >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<b.width - 1
+}
+
+// layoutField places a field of a struct in the memory of the target
+type layoutField struct {
+	offset uintptr // Offset in the target memory
+	size   uintptr // Bytes the field takes in the target memory, see memSize
+
+	bitfield bool     // The field takes no memory and is decoded from bits of source
+	source   int      // Index of the field holding the bits of a bitfield
+	bits     bitRange // The bits of source a bitfield is decoded from
+
+	union *unionMember // The union the field is a member of, nil if none
+}
+
+// structLayout is the layout of a struct type in the memory of the target
+type structLayout struct {
+	fields []layoutField // One per field of the struct, by field index
+	size   uintptr
+	align  uintptr
+	native bool // The layout matches the Go layout, values can be copied as is
+}
+
+// layouts caches the layout (or the error) of every struct type seen
+var layouts sync.Map // reflect.Type -> layoutResult
+
+type layoutResult struct {
+	layout *structLayout
+	err    error
+}
+
+// layoutOf returns the layout of struct type t in the memory of the target. Structs without
+// bitfields, offset tags or unions (nor nested structs with them) use the Go layout;
+// otherwise the fields without an offset tag are laid out after the field before them with
+// the Go alignment rules, and the members of a union share the offset of the first one.
+func layoutOf(t reflect.Type) (*structLayout, error) {
+	if cached, ok := layouts.Load(t); ok {
+		result := cached.(layoutResult)
+		return result.layout, result.err
+	}
+	layout, err := computeLayout(t)
+	layouts.Store(t, layoutResult{layout: layout, err: err})
+	return layout, err
+}
+
+func computeLayout(t reflect.Type) (*structLayout, error) {
+	layout := &structLayout{fields: make([]layoutField, t.NumField()), align: 1, native: true}
+	fixed := make([]bool, t.NumField()) // The field has an offset tag
+
+	lastWord := -1
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("pod")
+
+		offset, hasOffset, err := tagOffset(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+
+		bits, ok, err := tagBits(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+		member, err := tagUnion(t, field)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			if hasOffset || member != nil {
+				return nil, fmt.Errorf("%s.%s: a bitfield takes no memory and can't have an offset or be a union member", t, field.Name)
+			}
+			source := lastWord
+			if name, named := tagOption(tag, "from"); named {
+				sourceField, found := t.FieldByName(name)
+				if !found || len(sourceField.Index) != 1 {
+					return nil, fmt.Errorf("%s.%s: no field %s to take the bits from", t, field.Name, name)
+				}
+				source = sourceField.Index[0]
+			}
+			if err := checkBitfield(t, field, source, bits); err != nil {
+				return nil, err
+			}
+			layout.fields[i] = layoutField{bitfield: true, source: source, bits: bits}
+			layout.native = false
+			continue
+		}
+
+		layout.fields[i] = layoutField{size: memSize(field.Type), union: member}
+		layout.align = max(layout.align, memAlign(field.Type))
+		if member != nil {
+			layout.native = false
+		}
+		if hasOffset {
+			layout.fields[i].offset = offset
+			fixed[i] = true
+			layout.native = false
+		}
+		if !nativeLayout(field.Type) {
+			layout.native = false
+		}
+		if isInteger(field.Type.Kind()) {
+			lastWord = i
+		}
+	}
+
+	if layout.native {
+		for i := range layout.fields {
+			layout.fields[i].offset = t.Field(i).Offset
+		}
+		layout.size = t.Size()
+		return layout, nil
+	}
+
+	// The members of a union share the offset of the first one, aligned for all of them
+	unionAlign := make(map[int]uintptr)
+	for i, lf := range layout.fields {
+		if lf.union != nil {
+			unionAlign[lf.union.discriminant] = max(unionAlign[lf.union.discriminant], memAlign(t.Field(i).Type))
+		}
+	}
+	unionOffset := make(map[int]uintptr)
+
+	var next, end uintptr
+	for i := range layout.fields {
+		lf := &layout.fields[i]
+		if lf.bitfield {
+			continue
+		}
+
+		if lf.union == nil {
+			if !fixed[i] {
+				lf.offset = alignUp(next, memAlign(t.Field(i).Type))
+			}
+			next = lf.offset + lf.size
+		} else {
+			offset, placed := unionOffset[lf.union.discriminant]
+			switch {
+			case fixed[i]:
+				offset = lf.offset
+			case !placed:
+				offset = alignUp(next, unionAlign[lf.union.discriminant])
+			}
+			if !placed {
+				unionOffset[lf.union.discriminant] = offset
+			}
+			lf.offset = offset
+			next = max(next, lf.offset+lf.size)
+		}
+		end = max(end, lf.offset+lf.size)
+	}
+	layout.size = alignUp(end, layout.align)
+	return layout, nil
+}
+
+// checkBitfield validates that field can be decoded from bits of the field at source
+func checkBitfield(t reflect.Type, field reflect.StructField, source int, bits bitRange) error {
+	if source < 0 {
+		return fmt.Errorf("%s.%s: bitfield without an integer field before it, use from=NAME", t, field.Name)
+	}
+	sourceField := t.Field(source)
+	if !isInteger(sourceField.Type.Kind()) {
+		return fmt.Errorf("%s.%s: bits of %s, which is not an integer", t, field.Name, sourceField.Name)
+	}
+	if _, bitfield, _ := tagBits(sourceField.Tag.Get("pod")); bitfield {
+		return fmt.Errorf("%s.%s: bits of %s, which is a bitfield", t, field.Name, sourceField.Name)
+	}
+	if bits.start+bits.width > sourceField.Type.Bits() {
+		return fmt.Errorf("%s.%s: bits %d-%d out of the %d bits of %s", t, field.Name, bits.start, bits.start+bits.width-1, sourceField.Type.Bits(), sourceField.Name)
+	}
+	switch kind := field.Type.Kind(); {
+	case kind == reflect.Bool:
+	case isInteger(kind):
+		if bits.width > field.Type.Bits() {
+			return fmt.Errorf("%s.%s: %d bits don't fit in %s", t, field.Name, bits.width, field.Type)
+		}
+	default:
+		return fmt.Errorf("%s.%s: bitfield of type %s, must be bool or an integer", t, field.Name, field.Type)
+	}
+	return nil
+}
+
+// tagBits parses the bits=START[:WIDTH] option of a pod tag
+func tagBits(tag string) (bitRange, bool, error) {
+	value, ok := tagOption(tag, "bits")
+	if !ok {
+		return bitRange{}, false, nil
+	}
+
+	startStr, widthStr, hasWidth := strings.Cut(value, ":")
+	start, err := strconv.ParseUint(startStr, 0, 8)
+	if err != nil {
+		return bitRange{}, false, fmt.Errorf("invalid bits=%s: %w", value, err)
+	}
+	width := uint64(1)
+	if hasWidth {
+		if width, err = strconv.ParseUint(widthStr, 0, 8); err != nil {
+			return bitRange{}, false, fmt.Errorf("invalid bits=%s: %w", value, err)
+		}
+	}
+	if width == 0 || start+width > 64 {
+		return bitRange{}, false, fmt.Errorf("invalid bits=%s: must be within 64 bits", value)
+	}
+	return bitRange{start: int(start), width: int(width)}, true, nil
+}
+
+// tagOffset parses the offset=N option of a pod tag
+func tagOffset(tag string) (uintptr, bool, error) {
+	value, ok := tagOption(tag, "offset")
+	if !ok {
+		return 0, false, nil
+	}
+	offset, err := strconv.ParseUint(value, 0, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid offset=%s: %w", value, err)
+	}
+	return uintptr(offset), true, nil
+}
+
+// tagOption returns the value of the key=value option of a pod tag
+func tagOption(tag, key string) (string, bool) {
+	for _, part := range strings.Split(tag, ",") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(part), "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// memSize returns the number of bytes a value of type rt takes in the target memory
+func memSize(rt reflect.Type) uintptr {
+	switch rt.Kind() {
+	case reflect.Struct:
+		if layout, err := layoutOf(rt); err == nil {
+			return layout.size
+		}
+	case reflect.Array:
+		return uintptr(rt.Len()) * memSize(rt.Elem())
+	}
+	return rt.Size()
+}
+
+// memAlign returns the alignment of type rt in the target memory
+func memAlign(rt reflect.Type) uintptr {
+	switch rt.Kind() {
+	case reflect.Struct:
+		if layout, err := layoutOf(rt); err == nil {
+			return layout.align
+		}
+	case reflect.Array:
+		return memAlign(rt.Elem())
+	}
+	return uintptr(rt.Align())
+}
+
+// nativeLayout reports whether values of type rt have the Go layout in the target memory
+func nativeLayout(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Struct:
+		layout, err := layoutOf(rt)
+		return err == nil && layout.native
+	case reflect.Array:
+		return nativeLayout(rt.Elem())
+	}
+	return true
+}
+
+// fieldOffset returns the offset of field i of struct type t in the target memory, and
+// whether the field takes memory at all (bitfields don't)
+func fieldOffset(t reflect.Type, i int) (uintptr, bool) {
+	layout, err := layoutOf(t)
+	if err != nil {
+		return t.Field(i).Offset, true
+	}
+	return layout.fields[i].offset, !layout.fields[i].bitfield
+}
+
+// decodeBitfields sets the bitfields of struct v from the integer fields they are bits of
+func decodeBitfields(v reflect.Value, layout *structLayout) {
+	for i, lf := range layout.fields {
+		if !lf.bitfield {
+			continue
+		}
+		bits := integerBits(v.Field(lf.source)) >> lf.bits.start & lf.bits.mask()
+		setBitfield(settable(v.Field(i)), bits, lf.bits.width)
+	}
+}
+
+// bitfieldValue returns the bits bitfield v holds
+func bitfieldValue(v reflect.Value, bits bitRange) uint64 {
+	if v.Kind() == reflect.Bool {
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	}
+	return integerBits(v) & bits.mask()
+}
+
+// setBitfield sets bitfield v to the given bits of a range of width bits
+func setBitfield(v reflect.Value, bits uint64, width int) {
+	switch {
+	case v.Kind() == reflect.Bool:
+		v.SetBool(bits != 0)
+	case v.CanInt():
+		// Sign extend from the top bit of the range
+		shift := 64 - width
+		v.SetInt(int64(bits<<shift) >> shift)
+	default:
+		v.SetUint(bits)
+	}
+}
+
+// integerBits returns the bits of integer v
+func integerBits(v reflect.Value) uint64 {
+	if v.CanInt() {
+		return uint64(v.Int()) & (^uint64(0) >> (64 - v.Type().Bits()))
+	}
+	return v.Uint()
+}
+
+// setIntegerBits sets integer v to the given bits
+func setIntegerBits(v reflect.Value, bits uint64) {
+	if v.CanInt() {
+		shift := 64 - v.Type().Bits()
+		v.SetInt(int64(bits<<shift) >> shift)
+		return
+	}
+	v.SetUint(bits)
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func alignUp(n, align uintptr) uintptr {
+	return (n + align - 1) &^ (align - 1)
+}
@@ -1,24 +1,36 @@
 package pod
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"gomem/process"
+	"iter"
 	"reflect"
 	"strings"
 	"unsafe"
 )
 
+// SizeOf returns the number of bytes a T takes in the target memory: its Go size unless its
+// structs have bitfields or offset tags, see layoutOf
 func SizeOf[T any]() process.ProcessMemorySize {
 	var t T
-	return process.ProcessMemorySize(unsafe.Sizeof(t))
+	return process.ProcessMemorySize(memSize(reflect.TypeOf(&t).Elem()))
 }
 
 func ReadT[T any](proc process.Process, addr process.ProcessMemoryAddress) (T, error) {
 	var t T
+	if decode, ok := lookupDecoder(reflect.TypeOf(&t).Elem()); ok {
+		data, err := proc.ReadMemory(addr, SizeOf[T]())
+		if err != nil {
+			return t, err
+		}
+		return decodeAs[T](decode, data, addr)
+	}
+
 	if hasPointers[T]() {
-		// Use reflection-based reader for structs with pointers
-		err := ReadStruct(proc, addr, &t)
+		// Use reflection-based reader for types with pointers, see ReadStruct
+		err := readValue(proc, addr, reflect.ValueOf(&t).Elem())
 		return t, err
 	}
 
@@ -37,8 +49,21 @@ func ReadT[T any](proc process.Process, addr process.ProcessMemoryAddress) (T, e
 
 // WriteT serializes a POD struct T into a raw byte slice using the in-memory layout.
 // T must be POD (no pointers or Go-managed references) for the bytes to be meaningful
-// outside the process. This function uses unsafe to copy the raw bytes directly.
+// outside the process. This function uses unsafe to copy the raw bytes directly, unless T
+// has bitfields, offset or union tags or be/le tags: it is then encoded field by field into
+// SizeOf[T]() bytes like WriteTToProcess, with the bytes no field covers left zero, and
+// WriteT returns nil if T has fields that can't be encoded.
 func WriteT[T any](v T) []byte {
+	rt := reflect.TypeOf(&v).Elem()
+	if !nativeLayout(rt) || typeHasByteOrderTags(rt) {
+		buf := make([]byte, memSize(rt))
+		w := &structWriter{order: binary.NativeEndian}
+		if err := w.encodeValue(reflect.ValueOf(&v).Elem(), buf, make([]byte, len(buf)), reflect.StructField{}); err != nil {
+			return nil
+		}
+		return buf
+	}
+
 	// Take the address of v and make a byte slice view of its memory.
 	size := int(unsafe.Sizeof(v))
 	if size == 0 {
@@ -95,6 +120,91 @@ func ReadSliceT[T any](proc process.Process, addr process.ProcessMemoryAddress,
 	return result, nil
 }
 
+// iterSliceWindow is the number of bytes IterSliceT reads at once
+const iterSliceWindow = 0x10000
+
+// IterSliceT iterates over count consecutive T at addr like ReadSliceT, reading them in
+// windows of about 64 KiB instead of one blob of count elements, so huge arrays are decoded
+// without a single huge allocation. A read or decode error is yielded once and ends the
+// iteration.
+func IterSliceT[T any](proc process.Process, addr process.ProcessMemoryAddress, count int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if count < 0 {
+			yield(zero, errors.New("IterSliceT: count must be positive"))
+			return
+		}
+
+		size := SizeOf[T]()
+		if size == 0 {
+			return
+		}
+		perWindow := max(1, iterSliceWindow/int(size))
+
+		for start := 0; start < count; start += perWindow {
+			n := min(perWindow, count-start)
+			base := addr + process.ProcessMemoryAddress(start)*process.ProcessMemoryAddress(size)
+
+			blob, err := proc.ReadBlob(base, size*process.ProcessMemorySize(n))
+			if err != nil {
+				yield(zero, fmt.Errorf("IterSliceT: failed to read elements %d-%d: %w", start, start+n-1, err))
+				return
+			}
+
+			for i := range n {
+				elementBlob, err := blob.OffsetBlob(process.ProcessMemoryAddress(i)*process.ProcessMemoryAddress(size), size)
+				if err != nil {
+					yield(zero, fmt.Errorf("IterSliceT: failed to read element %d: %w", start+i, err))
+					return
+				}
+				element, err := ReadBlob[T](proc, elementBlob)
+				if err != nil {
+					yield(zero, fmt.Errorf("IterSliceT: failed to parse element %d: %w", start+i, err))
+					return
+				}
+				if !yield(element, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ReadManyResult is the value of T read at Address by ReadManyT, or why it could not be read
+type ReadManyResult[T any] struct {
+	Address process.ProcessMemoryAddress
+	Value   T
+	Err     error
+}
+
+// ReadManyT reads a T at each address of addrs, batching the reads through proc.ReadBlobs
+// (which clusters nearby addresses into fewer reads) and decoding each blob like ReadT.
+// It returns one result per address, in the order of addrs.
+func ReadManyT[T any](proc process.Process, addrs []process.ProcessMemoryAddress) []ReadManyResult[T] {
+	results := make([]ReadManyResult[T], len(addrs))
+	for i, addr := range addrs {
+		results[i].Address = addr
+	}
+
+	size := SizeOf[T]()
+	if size == 0 {
+		for i := range results {
+			results[i].Err = errors.New("ReadManyT: size of T is zero")
+		}
+		return results
+	}
+
+	for i, blob := range proc.ReadBlobs(addrs, size) {
+		if blob.Err != nil {
+			results[i].Err = blob.Err
+			continue
+		}
+		results[i].Value, results[i].Err = ReadBlob[T](proc, blob.Blob)
+	}
+
+	return results
+}
+
 func ReadPointerList(proc process.Process, addr uint64, count int) (results []process.ProcessMemoryAddress, err error) {
 	blob, blob_err := proc.ReadBlob(process.ProcessMemoryAddress(addr), process.ProcessMemorySize(count*8))
 	if blob_err != nil {
@@ -112,23 +222,37 @@ func ReadPointerList(proc process.Process, addr uint64, count int) (results []pr
 }
 
 // ReadBlob copies the first sizeof(T) bytes from data into a new T.
-// T must be "POD": it and all of its fields/element types contain no pointers.
+// When T contains pointers the bytes are decoded field by field instead, valid_pointer
+// fields are followed in proc and every other pointer is left nil, see ReadStruct.
+// Memory in a foreign byte order (see process.ByteOrderer), and types with be or le tags,
+// are also decoded field by field, with every scalar swapped to the host byte order.
 func ReadBlob[T any](proc process.Process, offset process.ProcessReadOffset) (T, error) {
 	data := offset.Data()
 	var zero T
 
-	// Optional runtime guard: reject types that contain pointers.
-	if hasPointers[T]() {
-		return zero, errors.New("BytesInto: T contains pointers; not POD-safe")
+	// Registered decoders take precedence over the raw copy
+	if decode, ok := lookupDecoder(reflect.TypeOf(&zero).Elem()); ok {
+		return decodeAs[T](decode, data, blobAddress(offset))
 	}
 
 	// Size check
 	var tmp T
-	size := int(unsafe.Sizeof(tmp))
+	size := int(SizeOf[T]())
 	if len(data) < size {
 		return zero, errors.New("BytesInto: buffer too small")
 	}
 
+	// Raw bytes must never land in Go pointers, foreign byte orders need swapping and
+	// bitfields and offset tags move fields, decode such types field by field
+	order := blobByteOrder(offset, proc)
+	rt := reflect.TypeOf(&tmp).Elem()
+	if hasPointers[T]() || !process.IsNativeByteOrder(order) || typeHasByteOrderTags(rt) || !nativeLayout(rt) {
+		if err := newStructReader(proc, order).decodeValue(reflect.ValueOf(&tmp).Elem(), data[:size], blobAddress(offset), reflect.StructField{}); err != nil {
+			return zero, err
+		}
+		return tmp, nil
+	}
+
 	// Copy into the stack-allocated tmp
 	dst := unsafe.Slice((*byte)(unsafe.Pointer(&tmp)), size)
 	copy(dst, data[:size])
@@ -137,9 +261,25 @@ func ReadBlob[T any](proc process.Process, offset process.ProcessReadOffset) (T,
 	if err := validateAndCleanPointers(&tmp, proc); err != nil {
 	}
 
+	// Decode the fields of registered types
+	if hasDecoders() {
+		if err := applyDecoders(reflect.ValueOf(&tmp).Elem(), data[:size], blobAddress(offset)); err != nil {
+			return zero, err
+		}
+	}
+
 	return tmp, nil
 }
 
+// blobByteOrder returns the byte order of the memory a blob was read from, falling back
+// to the byte order of proc for blobs that don't record one
+func blobByteOrder(offset process.ProcessReadOffset, proc process.Process) binary.ByteOrder {
+	if _, ok := offset.(process.ByteOrderer); ok {
+		return process.ByteOrderOf(offset)
+	}
+	return process.ByteOrderOf(proc)
+}
+
 // hasPointers reports whether T (recursively) contains any pointer-like fields.
 func hasPointers[T any]() bool {
 	var t T
@@ -168,6 +308,9 @@ func typeHasPointers(rt reflect.Type) bool {
 // validateAndCleanPointers validates pointers and cleans invalid ones
 func validateAndCleanPointers(structPtr interface{}, proc process.Process) error {
 	v := reflect.ValueOf(structPtr).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
 	t := v.Type()
 
 	for i := 0; i < t.NumField(); i++ {
@@ -187,6 +330,9 @@ func validateAndCleanPointers(structPtr interface{}, proc process.Process) error
 // validatePointersStrict validates pointers and returns error on invalid ones
 func validatePointersStrict(structPtr interface{}, proc process.Process) error {
 	v := reflect.ValueOf(structPtr).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
 	t := v.Type()
 
 	for i := 0; i < t.NumField(); i++ {
@@ -208,6 +354,17 @@ func processField(field reflect.Value, fieldType reflect.StructField, tag string
 		return nil
 	}
 
+	// Decode obfuscated pointers before they are validated
+	if field.Kind() == reflect.Uint64 && field.CanSet() {
+		transform, err := tagPointerTransform(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", fieldType.Name, err)
+		}
+		if transform != nil {
+			field.SetUint(uint64(transform(process.ProcessMemoryAddress(field.Uint()))))
+		}
+	}
+
 	tags := parsePodTags(tag)
 
 	// Handle valid_pointer tag
@@ -253,7 +410,7 @@ func validatePointerField(field reflect.Value, fieldType reflect.StructField, ta
 	addr := process.ProcessMemoryAddress(ptr)
 	if !proc.IsValidAddress(addr) {
 		if strict {
-			return errors.New("invalid pointer in field " + fieldType.Name + ": 0x" + string(ptr))
+			return fmt.Errorf("invalid pointer in field %s: 0x%X", fieldType.Name, ptr)
 		}
 		// In non-strict mode, clean the invalid pointer
 		if field.CanSet() {
@@ -291,6 +448,22 @@ func parsePodTags(tagStr string) map[string]string {
 	return tags
 }
 
+// tagPointerTransform builds the pointer transform described by the ptr_ options of a pod tag
+// (ptr_xor=KEY, ptr_rol=N, ptr_ror=N, ptr_transform=NAME), applied in tag order.
+// It returns nil when the tag has no such option.
+func tagPointerTransform(tag string) (process.PointerTransform, error) {
+	var steps []string
+	for _, part := range strings.Split(tag, ",") {
+		if strings.HasPrefix(part, "ptr_") && strings.Contains(part, "=") {
+			steps = append(steps, part)
+		}
+	}
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	return process.ParsePointerTransform(strings.Join(steps, ","))
+}
+
 // cleanInvalidField sets invalid fields to safe values
 func cleanInvalidField(field reflect.Value, tag string) {
 	tags := parsePodTags(tag)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"gomem/coloransi"
 	"gomem/process"
+	"gomem/process/memory_map"
 	"io"
 	"os"
 	"reflect"
@@ -114,28 +115,50 @@ func formatScalarWithStringer(fv reflect.Value, hexIfUint bool) string {
 	return raw
 }
 
-func asPtrString(isValidPtr func(uint64) bool, fv reflect.Value) string {
+// asPtrString returns the AsPtr column of an integral or pointer value: the address described
+// by formatPtr with ✓ when it points into the process, the bare address with × otherwise
+func asPtrString(isValidPtr func(uint64) bool, formatPtr func(uint64) string, fv reflect.Value) string {
+	var addr uint64
 	switch fv.Kind() {
 	case reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		addr := fv.Uint()
-		if addr == 0 {
-			return ""
-		}
-		if isValidPtr(uint64(addr)) {
-			return fmt.Sprintf("0x%X ✓", addr)
-		}
-		return fmt.Sprintf("0x%X ×", addr)
+		addr = fv.Uint()
 	case reflect.Pointer:
 		if fv.IsNil() {
 			return ""
 		}
-		addr := uint64(fv.Pointer())
-		if isValidPtr(addr) {
-			return fmt.Sprintf("0x%X ✓", addr)
-		}
-		return fmt.Sprintf("0x%X ×", addr)
+		addr = uint64(fv.Pointer())
+	default:
+		return ""
 	}
-	return ""
+	if addr == 0 {
+		return ""
+	}
+	if isValidPtr(addr) {
+		return formatPtr(addr) + " ✓"
+	}
+	return fmt.Sprintf("0x%X ×", addr)
+}
+
+// ptrFormatter returns a function describing addresses of proc as module+offset, see
+// process.FormatAddress. The memory map is read once per printed struct.
+func ptrFormatter(proc process.Process) func(uint64) string {
+	var mm []memory_map.MemoryMapItem
+	if proc != nil {
+		mm, _ = proc.GetMemoryMap()
+	}
+	return func(addr uint64) string {
+		return memory_map.FormatAddress(addr, mm)
+	}
+}
+
+// formatFieldOffset formats the offset of field i of struct type rt, bitfields show the
+// offset of their word and their first bit as 0xOFFSET.BIT
+func formatFieldOffset(rt reflect.Type, i int, offset uintptr) string {
+	if layout, err := layoutOf(rt); err == nil && layout.fields[i].bitfield {
+		lf := layout.fields[i]
+		return fmt.Sprintf("0x%04X.%d", layout.fields[lf.source].offset, lf.bits.start)
+	}
+	return fmt.Sprintf("0x%04X", offset)
 }
 
 func PrintPodStruct[T any](proc process.Process, v T, w io.Writer) {
@@ -146,6 +169,7 @@ func PrintPodStruct[T any](proc process.Process, v T, w io.Writer) {
 		}
 		return proc.IsValidAddress(process.ProcessMemoryAddress(addr))
 	}
+	formatPtr := ptrFormatter(proc)
 
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Pointer {
@@ -164,7 +188,7 @@ func PrintPodStruct[T any](proc process.Process, v T, w io.Writer) {
 
 	// Header
 	fmt.Fprintf(w, "=== %s ===\n", rt.Name())
-	fmt.Fprintf(w, "Size: 0x%X (%d bytes)\n\n", rt.Size(), rt.Size())
+	fmt.Fprintf(w, "Size: 0x%X (%d bytes)\n\n", memSize(rt), memSize(rt))
 
 	// Create table with column specs
 	table := NewTable(
@@ -173,6 +197,7 @@ func PrintPodStruct[T any](proc process.Process, v T, w io.Writer) {
 		ColumnSpec{
 			Header:   "Value",
 			MinWidth: 6,
+			Priority: 1,
 			FormatFunc: func(s string) string {
 				if s == "0 (0x0)" {
 					return coloransi.Foreground(coloransi.CreateRGB(64, 64, 64), s)
@@ -208,8 +233,13 @@ func PrintPodStruct[T any](proc process.Process, v T, w io.Writer) {
 			continue
 		}
 
+		// Only the selected member of a union is shown
+		if layout, err := layoutOf(rt); err == nil && !unionActive(rv, layout, i) {
+			continue
+		}
+
 		fv := rv.Field(i)
-		offset := field.Offset
+		offset, _ := fieldOffset(rt, i)
 
 		// Format primary value
 		var valueStr string
@@ -262,7 +292,7 @@ func PrintPodStruct[T any](proc process.Process, v T, w io.Writer) {
 				for j := 0; j < fv.Len(); j++ {
 					elem := fv.Index(j)
 					elemVal := fmt.Sprintf("0x%02X", elem.Uint())
-					elemPtr := asPtrString(isValidPtr, elem) // mostly empty for bytes
+					elemPtr := asPtrString(isValidPtr, formatPtr, elem) // mostly empty for bytes
 					table.AddRow(
 						fmt.Sprintf("  %s[%d]", field.Name, j),
 						fmt.Sprintf("+%d", j),
@@ -331,7 +361,7 @@ func PrintPodStruct[T any](proc process.Process, v T, w io.Writer) {
 				}
 
 				// AsPtr check per element (works for integral/pointer elements)
-				elemPtr := asPtrString(isValidPtr, elem)
+				elemPtr := asPtrString(isValidPtr, formatPtr, elem)
 
 				// Offset column shows +idx (not bytes) as requested
 				table.AddRow(
@@ -356,30 +386,10 @@ func PrintPodStruct[T any](proc process.Process, v T, w io.Writer) {
 		}
 
 		// Format offset
-		offsetStr := fmt.Sprintf("0x%04X", offset)
+		offsetStr := formatFieldOffset(rt, i, offset)
 
 		// Determine AsPtr column value
-		asPtr := ""
-		switch fv.Kind() {
-		case reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			addr := fv.Uint()
-			if addr != 0 {
-				if isValidPtr(uint64(addr)) {
-					asPtr = fmt.Sprintf("0x%X ✓", addr)
-				} else {
-					asPtr = fmt.Sprintf("0x%X ×", addr)
-				}
-			}
-		case reflect.Pointer:
-			if !fv.IsNil() {
-				addr := uint64(fv.Pointer())
-				if isValidPtr(addr) {
-					asPtr = fmt.Sprintf("0x%X ✓", addr)
-				} else {
-					asPtr = fmt.Sprintf("0x%X ×", addr)
-				}
-			}
-		}
+		asPtr := asPtrString(isValidPtr, formatPtr, fv)
 
 		// Get tags
 		tag := field.Tag.Get("pod")
@@ -449,6 +459,7 @@ func PrintPodStructWithColors[T any](proc process.Process, v T, w io.Writer) {
 		}
 		return proc.IsValidAddress(process.ProcessMemoryAddress(addr))
 	}
+	formatPtr := ptrFormatter(proc)
 
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Pointer {
@@ -467,13 +478,13 @@ func PrintPodStructWithColors[T any](proc process.Process, v T, w io.Writer) {
 
 	// Header with color
 	fmt.Fprintf(w, "\033[1m=== %s ===\033[0m\n", rt.Name())
-	fmt.Fprintf(w, "Size: \033[36m0x%X\033[0m (%d bytes)\n\n", rt.Size(), rt.Size())
+	fmt.Fprintf(w, "Size: \033[36m0x%X\033[0m (%d bytes)\n\n", memSize(rt), memSize(rt))
 
 	// Create table with colored column specs
 	table := NewTable(
 		ColumnSpec{Header: "Field", MinWidth: 20},
 		ColumnSpec{Header: "Offset", MinWidth: 10},
-		ColumnSpec{Header: "Value", MinWidth: 20},
+		ColumnSpec{Header: "Value", MinWidth: 20, Priority: 1},
 		ColumnSpec{
 			Header:     "AsPtr",
 			MinWidth:   20,
@@ -517,8 +528,13 @@ func PrintPodStructWithColors[T any](proc process.Process, v T, w io.Writer) {
 			continue
 		}
 
+		// Only the selected member of a union is shown
+		if layout, err := layoutOf(rt); err == nil && !unionActive(rv, layout, i) {
+			continue
+		}
+
 		fv := rv.Field(i)
-		offset := field.Offset
+		offset, _ := fieldOffset(rt, i)
 
 		// Format value (same logic as before)
 		var valueStr string
@@ -544,30 +560,10 @@ func PrintPodStructWithColors[T any](proc process.Process, v T, w io.Writer) {
 			valueStr = fmt.Sprintf("%v", fv.Interface())
 		}
 
-		offsetStr := fmt.Sprintf("0x%04X", offset)
+		offsetStr := formatFieldOffset(rt, i, offset)
 
 		// Determine AsPtr
-		asPtr := ""
-		switch fv.Kind() {
-		case reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			addr := fv.Uint()
-			if addr != 0 {
-				if isValidPtr(uint64(addr)) {
-					asPtr = fmt.Sprintf("0x%X ✓", addr)
-				} else {
-					asPtr = fmt.Sprintf("0x%X ×", addr)
-				}
-			}
-		case reflect.Pointer:
-			if !fv.IsNil() {
-				addr := uint64(fv.Pointer())
-				if isValidPtr(addr) {
-					asPtr = fmt.Sprintf("0x%X ✓", addr)
-				} else {
-					asPtr = fmt.Sprintf("0x%X ×", addr)
-				}
-			}
-		}
+		asPtr := asPtrString(isValidPtr, formatPtr, fv)
 
 		tag := field.Tag.Get("pod")
 		table.AddRow(field.Name, offsetStr, valueStr, asPtr, tag)
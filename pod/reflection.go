@@ -1,7 +1,6 @@
 package pod
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
 	"reflect"
@@ -11,8 +10,19 @@ import (
 	"gomem/process"
 )
 
+// maxPointerDepth bounds how many valid_pointer fields are followed in a row from a single
+// read, deeper pointers are left nil
+const maxPointerDepth = 64
+
 // ReadStruct reads a struct from process memory at the given address.
 // It handles fields with "pod" tags.
+//
+// Remote bytes are only ever copied into memory that holds no Go pointers, so the garbage
+// collector never sees a target process address. Pointer fields tagged valid_pointer are
+// replaced by Go-allocated copies of the value they point to (a target read twice is
+// allocated once, so cyclic structures become Go cycles), every other pointer-like field
+// (untagged pointers, unsafe.Pointer, strings, slices, maps, interfaces, funcs and chans)
+// is left zero. Use a uint64 field to keep a raw address.
 func ReadStruct(proc process.Process, addr process.ProcessMemoryAddress, v interface{}) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
@@ -20,159 +30,286 @@ func ReadStruct(proc process.Process, addr process.ProcessMemoryAddress, v inter
 	}
 
 	elem := rv.Elem()
-	if elem.Kind() != reflect.Struct {
+	if _, ok := lookupDecoder(elem.Type()); !ok && elem.Kind() != reflect.Struct {
 		return fmt.Errorf("v must point to a struct")
 	}
 
-	// 1. Read the raw bytes of the struct
-	size := int(elem.Type().Size())
-	data, err := proc.ReadMemory(addr, process.ProcessMemorySize(size))
+	return readValue(proc, addr, elem)
+}
+
+// readValue reads the value of any type at addr into v, which must be settable
+func readValue(proc process.Process, addr process.ProcessMemoryAddress, v reflect.Value) error {
+	data, err := proc.ReadMemory(addr, process.ProcessMemorySize(memSize(v.Type())))
 	if err != nil {
 		return fmt.Errorf("failed to read struct memory at %v: %w", addr, err)
 	}
 
-	// 2. Deserialize basic fields
-	// We use a temporary struct or direct memory copy if possible.
-	// However, because we need to handle pointers specially, we can't just copy bytes if the struct contains pointers.
-	// But we can copy bytes to the struct first (assuming layout matches) and then fix up pointers.
-	// WARNING: Copying raw bytes into a struct with pointers is dangerous if the GC sees invalid pointers.
-	// But since we are constructing it, maybe it's okay if we fix them immediately?
-	// Actually, if we copy random remote addresses into Go pointers, the GC might crash if it traces them.
-	// So we should NOT copy raw bytes directly into fields that are pointers.
+	return newStructReader(proc, process.ByteOrderOf(proc)).decodeValue(v, data, addr, reflect.StructField{})
+}
 
-	// Safer approach: Iterate over fields.
+// pointeeKey identifies a value followed through a valid_pointer field
+type pointeeKey struct {
+	addr process.ProcessMemoryAddress
+	typ  reflect.Type
+}
 
-	for i := 0; i < elem.NumField(); i++ {
-		field := elem.Field(i)
-		fieldType := elem.Type().Field(i)
+// structReader decodes values from their raw bytes, following valid_pointer fields.
+// ReadT, ReadBlob, ReadSliceT and ReadStruct all decode pointer-containing types, and any
+// type read from memory in a foreign byte order, through it.
+type structReader struct {
+	proc    process.Process
+	target  binary.ByteOrder // Byte order of the memory of proc
+	order   binary.ByteOrder // Byte order of the value being decoded, see the be and le tags
+	swap    bool             // order is not the host byte order, scalars are swapped
+	depth   int
+	visited map[pointeeKey]reflect.Value
+}
 
-		// Skip unexported fields? Or try to set them if possible (using unsafe).
-		// For now assume exported.
+// newStructReader returns a reader for memory of proc stored in the given byte order
+func newStructReader(proc process.Process, order binary.ByteOrder) *structReader {
+	return &structReader{
+		proc:    proc,
+		target:  order,
+		order:   order,
+		swap:    !process.IsNativeByteOrder(order),
+		visited: make(map[pointeeKey]reflect.Value),
+	}
+}
 
-		// Calculate offset of the field
-		offset := fieldType.Offset
-		fieldSize := fieldType.Type.Size()
+// decodeValue decodes data, read at addr, into v. field is the struct field v belongs to
+// (v itself or the array holding it), its pod tag applies to v.
+func (r *structReader) decodeValue(v reflect.Value, data []byte, addr process.ProcessMemoryAddress, field reflect.StructField) error {
+	v = settable(v)
 
-		if offset+fieldSize > uintptr(len(data)) {
-			return fmt.Errorf("field %s out of bounds", fieldType.Name)
+	size := int(memSize(v.Type()))
+	if len(data) < size {
+		return fmt.Errorf("field %s out of bounds", field.Name)
+	}
+	data = data[:size]
+
+	// Values of a registered type are decoded by their decoder
+	if decode, ok := lookupDecoder(v.Type()); ok {
+		decoded, err := decode(data, addr)
+		if err != nil {
+			return fmt.Errorf("decoder for %s at 0x%X: %w", v.Type(), addr, err)
 		}
+		v.Set(decoded)
+		return nil
+	}
 
-		fieldData := data[offset : offset+fieldSize]
+	switch v.Kind() {
+	case reflect.Struct:
+		return r.decodeStruct(v, data, addr)
 
-		if !field.CanSet() {
-			continue
-		}
+	case reflect.Ptr:
+		return r.decodePointer(v, data, field)
 
-		if field.Kind() == reflect.Ptr {
-			// It's a pointer. The data in memory is the address (uint64 on 64-bit).
-			// We read the address.
-			var ptrAddr uint64
-			if len(fieldData) == 4 {
-				ptrAddr = uint64(binary.LittleEndian.Uint32(fieldData))
-			} else if len(fieldData) == 8 {
-				ptrAddr = binary.LittleEndian.Uint64(fieldData)
-			} else {
-				// Unknown pointer size
-				continue
+	case reflect.Array:
+		elemType := v.Type().Elem()
+		if elemType.Kind() == reflect.Uint8 || elemType.Kind() == reflect.Int8 {
+			if _, ok := lookupDecoder(elemType); !ok {
+				copyRaw(v, data)
+				return r.applyTag(v, field)
 			}
+		}
 
-			// Check tags
-			tag := fieldType.Tag.Get("pod")
-			if strings.Contains(tag, "valid_pointer") {
-				// Recursively read the object
-				if ptrAddr == 0 {
-					field.Set(reflect.Zero(field.Type()))
-					continue
-				}
-
-				// Check if address is valid
-				if !proc.IsValidAddress(process.ProcessMemoryAddress(ptrAddr)) {
-					if strings.Contains(tag, "err_failure") {
-						return fmt.Errorf("invalid pointer address %x for field %s", ptrAddr, fieldType.Name)
-					}
-					field.Set(reflect.Zero(field.Type()))
-					continue
-				}
-
-				// Allocate new object of the pointed-to type
-				newObj := reflect.New(fieldType.Type.Elem())
-
-				// Recursively read
-				err := ReadStruct(proc, process.ProcessMemoryAddress(ptrAddr), newObj.Interface())
-				if err != nil {
-					if strings.Contains(tag, "err_failure") {
-						return fmt.Errorf("failed to read pointed struct for field %s: %w", fieldType.Name, err)
-					}
-					field.Set(reflect.Zero(field.Type()))
-					continue
-				}
-
-				field.Set(newObj)
-			} else {
-				// Just a pointer, but we can't set a remote address to a Go pointer.
-				// If the user didn't ask to read it (no valid_pointer tag), we probably should leave it nil
-				// or we can't really do anything useful with it in a Go pointer field.
-				// Unless the field type is uintptr or uint64, but here it is reflect.Ptr.
-				// We leave it as nil (or whatever it was).
-			}
-		} else if field.Kind() == reflect.Struct {
-			// Nested struct. Recursively read?
-			// Since it's embedded (not a pointer), it's part of the memory block we just read.
-			// We can just decode it from the data we already have.
-			// But we need to handle its fields (which might have pointers).
-			// So we call ReadStruct logic on the field, but we don't need to read from process memory again,
-			// we just need to process the bytes we already have?
-			// Actually, ReadStruct takes an address.
-			// So we can call ReadStruct with (addr + offset).
-			// This will re-read memory, which is slightly inefficient but correct.
-			// Or we can implement a `readFromBytes` helper.
-			// For simplicity, let's recurse with address.
-			err := ReadStruct(proc, addr+process.ProcessMemoryAddress(offset), field.Addr().Interface())
-			if err != nil {
+		elemSize := int(memSize(elemType))
+		for i := 0; i < v.Len(); i++ {
+			offset := i * elemSize
+			if err := r.decodeValue(v.Index(i), data[offset:], addr+process.ProcessMemoryAddress(offset), field); err != nil {
 				return err
 			}
-		} else {
-			// POD type (int, uint, float, etc.)
-			// We can use binary.Read or unsafe copy.
-			// Since we have the bytes, we can use unsafe to set the value.
-			// Or use binary.Read on the field address?
-			// reflect.NewAt can create a pointer to the field.
-
-			// Simple approach for common types:
-			switch field.Kind() {
-			case reflect.Uint8:
-				field.SetUint(uint64(fieldData[0]))
-			case reflect.Uint16:
-				field.SetUint(uint64(binary.LittleEndian.Uint16(fieldData)))
-			case reflect.Uint32:
-				field.SetUint(uint64(binary.LittleEndian.Uint32(fieldData)))
-			case reflect.Uint64:
-				field.SetUint(binary.LittleEndian.Uint64(fieldData))
-			case reflect.Int8:
-				field.SetInt(int64(int8(fieldData[0])))
-			case reflect.Int16:
-				field.SetInt(int64(int16(binary.LittleEndian.Uint16(fieldData))))
-			case reflect.Int32:
-				field.SetInt(int64(int32(binary.LittleEndian.Uint32(fieldData))))
-			case reflect.Int64:
-				field.SetInt(int64(binary.LittleEndian.Uint64(fieldData)))
-			case reflect.Float32:
-				bits := binary.LittleEndian.Uint32(fieldData)
-				field.SetFloat(float64(*(*float32)(unsafe.Pointer(&bits))))
-			case reflect.Float64:
-				bits := binary.LittleEndian.Uint64(fieldData)
-				field.SetFloat(*(*float64)(unsafe.Pointer(&bits)))
-			case reflect.Bool:
-				field.SetBool(fieldData[0] != 0)
-			// Add array/slice handling if needed
-			default:
-				// Try binary.Read for other types
-				if err := binary.Read(bytes.NewReader(fieldData), binary.LittleEndian, field.Addr().Interface()); err != nil {
-					// Ignore error or log?
-				}
-			}
 		}
+		return nil
+
+	case reflect.UnsafePointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.String, reflect.Func, reflect.Chan:
+		// Remote bytes are never valid Go references
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+
+	default:
+		// bool, ints, uints, uintptr, floats, complex
+		if r.swap {
+			data = append([]byte(nil), data...)
+			process.SwapScalarBytes(v.Kind(), data)
+		}
+		copyRaw(v, data)
+		return r.applyTag(v, field)
+	}
+}
+
+// decodeStruct decodes every field of v from data, read at addr, then the members of its
+// unions selected by those fields and its bitfields
+func (r *structReader) decodeStruct(v reflect.Value, data []byte, addr process.ProcessMemoryAddress) error {
+	t := v.Type()
+	layout, err := layoutOf(t)
+	if err != nil {
+		return err
+	}
+
+	if err := r.decodeFields(v, data, addr, layout, false); err != nil {
+		return err
+	}
+	if err := r.decodeFields(v, data, addr, layout, true); err != nil {
+		return err
+	}
+
+	decodeBitfields(v, layout)
+	return nil
+}
+
+// decodeFields decodes the fields of struct v that take memory, either the union members
+// (the inactive ones are zeroed) or every other field
+func (r *structReader) decodeFields(v reflect.Value, data []byte, addr process.ProcessMemoryAddress, layout *structLayout, members bool) error {
+	t := v.Type()
+	structOrder := structByteOrder(t)
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		lf := layout.fields[i]
+		if lf.bitfield || (lf.union != nil) != members {
+			continue
+		}
+		if !unionActive(v, layout, i) {
+			field := settable(v.Field(i))
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+		offset := lf.offset
+
+		if offset+lf.size > uintptr(len(data)) {
+			return fmt.Errorf("field %s out of bounds", fieldType.Name)
+		}
+
+		previous := r.setOrder(fieldByteOrder(r.order, structOrder, fieldType))
+		err := r.decodeValue(v.Field(i), data[offset:], addr+process.ProcessMemoryAddress(offset), fieldType)
+		r.setOrder(previous)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setOrder switches the byte order scalars are decoded in and returns the previous one
+func (r *structReader) setOrder(order binary.ByteOrder) binary.ByteOrder {
+	previous := r.order
+	r.order = order
+	r.swap = !process.IsNativeByteOrder(order)
+	return previous
+}
+
+// decodePointer sets the Go pointer v to a copy of the value its target address points to
+// when field is tagged valid_pointer, and to nil otherwise
+func (r *structReader) decodePointer(v reflect.Value, data []byte, field reflect.StructField) error {
+	v.Set(reflect.Zero(v.Type()))
+
+	// The data in memory is the address (uint64 on 64-bit)
+	var ptrAddr uint64
+	switch len(data) {
+	case 4:
+		ptrAddr = uint64(r.order.Uint32(data))
+	case 8:
+		ptrAddr = r.order.Uint64(data)
+	default:
+		// Unknown pointer size
+		return nil
+	}
+
+	// Check tags
+	tag := field.Tag.Get("pod")
+
+	// Decode obfuscated pointers before they are validated and followed
+	transform, err := tagPointerTransform(tag)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", field.Name, err)
+	}
+	addr := transform.Apply(process.ProcessMemoryAddress(ptrAddr))
+
+	// Without valid_pointer the remote address can't be stored in a Go pointer, leave it nil
+	if !strings.Contains(tag, "valid_pointer") || addr == 0 || r.proc == nil {
+		return nil
+	}
+
+	strict := strings.Contains(tag, "err_failure")
+
+	// Check if address is valid
+	if !r.proc.IsValidAddress(addr) {
+		if strict {
+			return fmt.Errorf("invalid pointer address %x for field %s", uint64(addr), field.Name)
+		}
+		return nil
+	}
+
+	// A value already read for this address is shared
+	key := pointeeKey{addr: addr, typ: v.Type().Elem()}
+	if obj, ok := r.visited[key]; ok {
+		v.Set(obj)
+		return nil
+	}
+
+	if r.depth >= maxPointerDepth {
+		if strict {
+			return fmt.Errorf("field %s: pointers nested deeper than %d", field.Name, maxPointerDepth)
+		}
+		return nil
 	}
 
+	// Allocate new object of the pointed-to type
+	obj := reflect.New(key.typ)
+	r.visited[key] = obj
+
+	// The pointee is decoded in the byte order of the target, not the one of the pointer
+	r.depth++
+	previous := r.setOrder(r.target)
+	err = r.readPointee(obj.Elem(), addr)
+	r.setOrder(previous)
+	r.depth--
+
+	if err != nil {
+		delete(r.visited, key)
+		if strict {
+			return fmt.Errorf("failed to read pointed struct for field %s: %w", field.Name, err)
+		}
+		return nil
+	}
+
+	v.Set(obj)
 	return nil
 }
+
+// readPointee reads the value a valid_pointer field points to
+func (r *structReader) readPointee(v reflect.Value, addr process.ProcessMemoryAddress) error {
+	data, err := r.proc.ReadMemory(addr, process.ProcessMemorySize(memSize(v.Type())))
+	if err != nil {
+		return fmt.Errorf("failed to read struct memory at %v: %w", addr, err)
+	}
+	return r.decodeValue(v, data, addr, reflect.StructField{})
+}
+
+// applyTag processes the pod tag of field on the decoded value v, cleaning invalid values
+// unless the tag asks for err_failure
+func (r *structReader) applyTag(v reflect.Value, field reflect.StructField) error {
+	tag := field.Tag.Get("pod")
+	if tag == "" || r.proc == nil {
+		return nil
+	}
+
+	strict := strings.Contains(tag, "err_failure")
+	if err := processField(v, field, tag, r.proc, strict); err != nil {
+		if strict {
+			return err
+		}
+		cleanInvalidField(v, tag)
+	}
+	return nil
+}
+
+// copyRaw copies the raw bytes of a value without Go pointers into v, which must be addressable
+func copyRaw(v reflect.Value, data []byte) {
+	size := int(v.Type().Size())
+	if size == 0 {
+		return
+	}
+	dst := unsafe.Slice((*byte)(unsafe.Pointer(v.UnsafeAddr())), size)
+	copy(dst, data[:size])
+}
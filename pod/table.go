@@ -3,9 +3,13 @@ package pod
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 )
 
+// ellipsis marks a truncated cell
+const ellipsis = "…"
+
 // FormatFunc is a callback to format/colorize cell values
 type FormatFunc func(value string) string
 
@@ -15,6 +19,7 @@ type ColumnSpec struct {
 	BlankValue string     // Value to show for empty cells (default: "-")
 	FormatFunc FormatFunc // Optional formatter/colorizer
 	MinWidth   int        // Minimum column width
+	Priority   int        // Columns with a higher priority are truncated last when the table is too wide
 }
 
 // Table represents a formatted table
@@ -23,6 +28,7 @@ type Table struct {
 	rows      [][]string
 	widths    []int
 	separator string
+	maxWidth  int // 0 detects the terminal width, negative disables truncation
 }
 
 // NewTable creates a new table with the given column specifications
@@ -93,12 +99,21 @@ func (t *Table) SetSeparatorChar(char string) {
 	t.separator = char
 }
 
+// SetMaxWidth sets the maximum total width of a rendered line.
+// 0 (the default) uses the terminal width of the writer and doesn't truncate when the writer
+// is not a terminal; a negative width disables truncation.
+func (t *Table) SetMaxWidth(width int) {
+	t.maxWidth = width
+}
+
 // Render writes the table to the given writer
 func (t *Table) Render(w io.Writer) error {
+	widths := t.fitWidths(t.resolveMaxWidth(w))
+
 	// Print header
 	headers := make([]string, len(t.columns))
 	for i, col := range t.columns {
-		headers[i] = t.pad(col.Header, t.widths[i])
+		headers[i] = t.pad(t.truncate(col.Header, widths[i]), widths[i])
 	}
 	if _, err := fmt.Fprintln(w, strings.Join(headers, " ")); err != nil {
 		return err
@@ -107,7 +122,7 @@ func (t *Table) Render(w io.Writer) error {
 	// Print header separator
 	sep := make([]string, len(t.columns))
 	for i := range sep {
-		sep[i] = strings.Repeat("-", t.widths[i])
+		sep[i] = strings.Repeat("-", widths[i])
 	}
 	if _, err := fmt.Fprintln(w, strings.Join(sep, " ")); err != nil {
 		return err
@@ -119,14 +134,21 @@ func (t *Table) Render(w io.Writer) error {
 		for i, val := range row {
 			// Check if this is a separator row
 			if i < len(t.columns) && strings.TrimSpace(strings.Trim(val, t.separator)) == "" && val != "" && val != t.columns[i].BlankValue {
+				if t.visibleLength(val) > widths[i] {
+					val = strings.Repeat(t.separator, widths[i])
+				}
 				formatted[i] = val
 			} else if i < len(t.columns) {
-				// Apply formatting if available
+				// Format the whole value, then truncate it to a shrunk column keeping the
+				// color codes of the formatter
 				displayVal := val
 				if t.columns[i].FormatFunc != nil {
 					displayVal = t.columns[i].FormatFunc(val)
 				}
-				formatted[i] = t.pad(displayVal, t.widths[i])
+				if widths[i] < t.widths[i] {
+					displayVal = t.truncate(displayVal, widths[i])
+				}
+				formatted[i] = t.pad(displayVal, widths[i])
 			} else {
 				// Shouldn't happen, but handle gracefully
 				formatted[i] = t.pad(val, t.widths[i])
@@ -140,6 +162,104 @@ func (t *Table) Render(w io.Writer) error {
 	return nil
 }
 
+// resolveMaxWidth returns the maximum line width for rendering to w, 0 meaning unlimited
+func (t *Table) resolveMaxWidth(w io.Writer) int {
+	if t.maxWidth != 0 {
+		return max(t.maxWidth, 0)
+	}
+
+	return terminalWidth(w)
+}
+
+// fitWidths returns the column widths shrunk so that a line fits in maxWidth.
+// Columns are shrunk from the lowest priority to the highest (widest first within
+// a priority), down to the width of their header, but never below 3 characters.
+func (t *Table) fitWidths(maxWidth int) []int {
+	widths := make([]int, len(t.widths))
+	copy(widths, t.widths)
+
+	if maxWidth <= 0 || len(widths) == 0 {
+		return widths
+	}
+
+	total := len(widths) - 1 // single space between columns
+	for _, width := range widths {
+		total += width
+	}
+	excess := total - maxWidth
+	if excess <= 0 {
+		return widths
+	}
+
+	order := make([]int, len(widths))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ca, cb := order[a], order[b]
+		if t.columns[ca].Priority != t.columns[cb].Priority {
+			return t.columns[ca].Priority < t.columns[cb].Priority
+		}
+		return widths[ca] > widths[cb]
+	})
+
+	// First pass keeps the headers readable, second pass shrinks everything to the bare minimum
+	floors := []func(i int) int{
+		func(i int) int { return max(len(t.columns[i].Header), 3) },
+		func(i int) int { return 3 },
+	}
+	for _, floor := range floors {
+		for _, i := range order {
+			if excess <= 0 {
+				return widths
+			}
+			if shrink := min(widths[i]-floor(i), excess); shrink > 0 {
+				widths[i] -= shrink
+				excess -= shrink
+			}
+		}
+	}
+
+	return widths
+}
+
+// truncate shortens s to width visible characters, ending it with an ellipsis.
+// ANSI escape sequences are kept and the color is reset after a truncated colored value.
+func (t *Table) truncate(s string, width int) string {
+	if t.visibleLength(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	length := 0
+	inEscape := false
+	hasEscape := false
+	for _, r := range s {
+		if r == '\033' {
+			inEscape = true
+			hasEscape = true
+			b.WriteRune(r)
+		} else if inEscape {
+			if r == 'm' {
+				inEscape = false
+			}
+			b.WriteRune(r)
+		} else if length < width-1 {
+			b.WriteRune(r)
+			length++
+		}
+	}
+	b.WriteString(ellipsis)
+	if hasEscape {
+		b.WriteString("\033[0m")
+	}
+
+	return b.String()
+}
+
 // pad pads a string to the given width
 func (t *Table) pad(s string, width int) string {
 	// Account for ANSI color codes if present
@@ -211,6 +331,11 @@ func (t *Table) WithSeparator(char string) *Table {
 	return t
 }
 
+func (t *Table) WithMaxWidth(width int) *Table {
+	t.SetMaxWidth(width)
+	return t
+}
+
 func (t *Table) WithRow(data ...string) *Table {
 	t.AddRow(data...)
 	return t
@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package pod
+
+import "io"
+
+// terminalWidth is not supported on this platform, the COLUMNS environment variable is used instead
+func terminalWidth(w io.Writer) int {
+	return 0
+}
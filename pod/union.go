@@ -0,0 +1,116 @@
+package pod
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Unions
+//
+// Fields tagged `pod:"union=KIND,case=N"` are the members of a union selected by the integer
+// field KIND of the same struct: they share one offset in the target, the first aligned
+// offset after the field before the first member (or its offset tag), and the union takes
+// the size of its largest member. Only the member whose case matches the value of KIND is
+// decoded, the others are left zero; case=N|M matches several values and a member without
+// case= is decoded when no other one matches:
+//
+//	type Event struct {
+//		Kind   uint32
+//		Damage DamageEvent `pod:"union=Kind,case=1"`
+//		Move   MoveEvent   `pod:"union=Kind,case=2|3"`
+//		Raw    [16]byte    `pod:"union=Kind"`
+//		Time   uint64      // follows the union
+//	}
+//
+// WriteTToProcess only writes the member selected by the value of KIND in v, the bytes of
+// the others are left as they are, and PrintPodStruct only shows that member.
+
+// unionMember describes a field that is a member of a union
+type unionMember struct {
+	discriminant int      // Index of the field selecting the member
+	cases        []uint64 // Values of the discriminant selecting the member, none for the default
+}
+
+// tagUnion parses the union=NAME and case=N|M options of a pod tag of a field of struct t
+func tagUnion(t reflect.Type, field reflect.StructField) (*unionMember, error) {
+	tag := field.Tag.Get("pod")
+	name, ok := tagOption(tag, "union")
+	if !ok {
+		if _, hasCase := tagOption(tag, "case"); hasCase {
+			return nil, fmt.Errorf("%s.%s: case= without union=", t, field.Name)
+		}
+		return nil, nil
+	}
+
+	discriminant, found := t.FieldByName(name)
+	if !found || len(discriminant.Index) != 1 {
+		return nil, fmt.Errorf("%s.%s: no field %s to select the union member", t, field.Name, name)
+	}
+	if !isInteger(discriminant.Type.Kind()) {
+		return nil, fmt.Errorf("%s.%s: union selected by %s, which is not an integer", t, field.Name, name)
+	}
+	discriminantTag := discriminant.Tag.Get("pod")
+	if _, member := tagOption(discriminantTag, "union"); member {
+		return nil, fmt.Errorf("%s.%s: union selected by %s, which is a union member", t, field.Name, name)
+	}
+	if _, bitfield := tagOption(discriminantTag, "bits"); bitfield {
+		return nil, fmt.Errorf("%s.%s: union selected by %s, which is a bitfield", t, field.Name, name)
+	}
+
+	member := &unionMember{discriminant: discriminant.Index[0]}
+	if cases, ok := tagOption(tag, "case"); ok {
+		for _, c := range strings.Split(cases, "|") {
+			value, err := parseCase(c)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: invalid case=%s: %w", t, field.Name, cases, err)
+			}
+			member.cases = append(member.cases, value)
+		}
+	}
+	return member, nil
+}
+
+// parseCase parses a case value, negative values are stored in two's complement
+func parseCase(s string) (uint64, error) {
+	if strings.HasPrefix(s, "-") {
+		v, err := strconv.ParseInt(s, 0, 64)
+		return uint64(v), err
+	}
+	return strconv.ParseUint(s, 0, 64)
+}
+
+// matches reports whether the member is selected by the discriminant value d of bits bits
+func (m *unionMember) matches(d uint64, bits int) bool {
+	mask := ^uint64(0) >> (64 - bits)
+	for _, c := range m.cases {
+		if c&mask == d {
+			return true
+		}
+	}
+	return false
+}
+
+// unionActive reports whether field i of struct v is decoded: it is not a union member, or
+// it is the member selected by the value of its discriminant
+func unionActive(v reflect.Value, layout *structLayout, i int) bool {
+	member := layout.fields[i].union
+	if member == nil {
+		return true
+	}
+
+	discriminant := v.Field(member.discriminant)
+	d, bits := integerBits(discriminant), discriminant.Type().Bits()
+	if len(member.cases) > 0 {
+		return member.matches(d, bits)
+	}
+
+	// The default member is selected when no other member of the union is
+	for _, lf := range layout.fields {
+		if other := lf.union; other != nil && other.discriminant == member.discriminant && other.matches(d, bits) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,244 @@
+package pod
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"gomem/process"
+)
+
+// WriteTToProcess writes v over the T at addr in proc, the reverse of ReadT.
+//
+// The current bytes at addr are read first and only the fields v can represent are
+// serialized over them: Go pointers (valid_pointer fields), strings, slices and other Go
+// references and blank "_" fields keep the original bytes of the target. Values that ReadT
+// cleaned up are not written back either: a valid_pointer field read as 0 keeps its
+// original invalid pointer, and a char_array keeps the bytes after its terminator while
+// its text is unchanged. Bitfields that differ from the target are merged into their word,
+// see the bits tag, and only the selected member of a union is written, see the union tag.
+// Fields of a type with a registered decoder and fields with a ptr_ pointer transform
+// can't be encoded and must be left unchanged.
+//
+// Scalars are encoded in the byte order of proc (or of their be or le tag), and only the
+// byte ranges that differ from the target are written, so fields the target changes
+// concurrently are not reverted.
+func WriteTToProcess[T any](proc process.Process, addr process.ProcessMemoryAddress, v T) error {
+	size := SizeOf[T]()
+	if size == 0 {
+		return errors.New("WriteTToProcess: size of T is zero")
+	}
+
+	original, err := proc.ReadMemory(addr, size)
+	if err != nil {
+		return fmt.Errorf("WriteTToProcess: failed to read memory at 0x%X: %w", uint64(addr), err)
+	}
+
+	order := process.ByteOrderOf(proc)
+	w := &structWriter{
+		proc:  proc,
+		order: order,
+		swap:  !process.IsNativeByteOrder(order),
+	}
+
+	buf := bytes.Clone(original)
+	if err := w.encodeValue(reflect.ValueOf(&v).Elem(), buf, original, reflect.StructField{}); err != nil {
+		return fmt.Errorf("WriteTToProcess: %w", err)
+	}
+
+	// Write the changed spans only
+	for start := 0; start < len(buf); {
+		if buf[start] == original[start] {
+			start++
+			continue
+		}
+		end := start + 1
+		for end < len(buf) && buf[end] != original[end] {
+			end++
+		}
+		if err := proc.WriteMemory(addr+process.ProcessMemoryAddress(start), buf[start:end]); err != nil {
+			return fmt.Errorf("WriteTToProcess: failed to write memory at 0x%X: %w", uint64(addr)+uint64(start), err)
+		}
+		start = end
+	}
+
+	return nil
+}
+
+// structWriter serializes values over the original bytes of the target, see WriteTToProcess
+type structWriter struct {
+	proc  process.Process
+	order binary.ByteOrder
+	swap  bool // order is not the host byte order, scalars are swapped
+}
+
+// encodeValue serializes v into buf, which starts as a copy of original. field is the struct
+// field v belongs to (v itself or the array holding it).
+func (w *structWriter) encodeValue(v reflect.Value, buf, original []byte, field reflect.StructField) error {
+	v = settable(v)
+	size := int(memSize(v.Type()))
+	buf, original = buf[:size], original[:size]
+
+	// Decoders have no encoder, the value must be the one read from the target
+	if decode, ok := lookupDecoder(v.Type()); ok {
+		current, err := decode(original, 0)
+		if err != nil || !reflect.DeepEqual(current.Interface(), v.Interface()) {
+			return fmt.Errorf("field %s: %s has a custom decoder and can't be encoded", field.Name, v.Type())
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		layout, err := layoutOf(t)
+		if err != nil {
+			return err
+		}
+		structOrder := structByteOrder(t)
+		w.mergeBitfields(v, original, layout, structOrder)
+		for i := 0; i < t.NumField(); i++ {
+			fieldType := t.Field(i)
+			if fieldType.Name == "_" || layout.fields[i].bitfield || !unionActive(v, layout, i) {
+				continue
+			}
+			off := layout.fields[i].offset
+			previous := w.setOrder(fieldByteOrder(w.order, structOrder, fieldType))
+			err := w.encodeValue(v.Field(i), buf[off:], original[off:], fieldType)
+			w.setOrder(previous)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Array:
+		elemType := v.Type().Elem()
+		if elemType.Kind() == reflect.Uint8 || elemType.Kind() == reflect.Int8 {
+			if _, ok := lookupDecoder(elemType); !ok {
+				w.encodeBytes(v, buf, original, field)
+				return nil
+			}
+		}
+
+		elemSize := int(memSize(elemType))
+		for i := 0; i < v.Len(); i++ {
+			off := i * elemSize
+			if err := w.encodeValue(v.Index(i), buf[off:], original[off:], field); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Ptr, reflect.UnsafePointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.String, reflect.Func, reflect.Chan:
+		// Go references have no meaning in the target, keep the original bytes
+		return nil
+
+	default:
+		return w.encodeScalar(v, buf, original, field)
+	}
+}
+
+// mergeBitfields sets the bits of the bitfields of struct v that differ from the original
+// bytes of the target into the words they are bits of, so they are written with them
+func (w *structWriter) mergeBitfields(v reflect.Value, original []byte, layout *structLayout, structOrder binary.ByteOrder) {
+	t := v.Type()
+	for i, lf := range layout.fields {
+		if !lf.bitfield {
+			continue
+		}
+		source := layout.fields[lf.source]
+		order := fieldByteOrder(w.order, structOrder, t.Field(lf.source))
+		current := decodeWord(original[source.offset:source.offset+source.size], order) >> lf.bits.start & lf.bits.mask()
+
+		bits := bitfieldValue(v.Field(i), lf.bits)
+		if bits == current {
+			continue
+		}
+		word := settable(v.Field(lf.source))
+		setIntegerBits(word, integerBits(word)&^(lf.bits.mask()<<lf.bits.start)|bits<<lf.bits.start)
+	}
+}
+
+// decodeWord decodes an unsigned integer of 1, 2, 4 or 8 bytes
+func decodeWord(data []byte, order binary.ByteOrder) uint64 {
+	switch len(data) {
+	case 1:
+		return uint64(data[0])
+	case 2:
+		return uint64(order.Uint16(data))
+	case 4:
+		return uint64(order.Uint32(data))
+	case 8:
+		return order.Uint64(data)
+	}
+	return 0
+}
+
+// setOrder switches the byte order scalars are encoded in and returns the previous one
+func (w *structWriter) setOrder(order binary.ByteOrder) binary.ByteOrder {
+	previous := w.order
+	w.order = order
+	w.swap = !process.IsNativeByteOrder(order)
+	return previous
+}
+
+// encodeScalar serializes a bool, integer, float or complex value
+func (w *structWriter) encodeScalar(v reflect.Value, buf, original []byte, field reflect.StructField) error {
+	tag := field.Tag.Get("pod")
+
+	if v.Kind() == reflect.Uint64 && tag != "" {
+		transform, err := tagPointerTransform(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		current := transform.Apply(process.ProcessMemoryAddress(w.order.Uint64(original)))
+
+		// An invalid pointer cleaned to NULL by the read keeps its original value
+		invalid := current != 0 && w.proc != nil && !w.proc.IsValidAddress(current)
+		if v.Uint() == uint64(current) || (v.Uint() == 0 && invalid && parsePodTags(tag)["type"] == "valid_pointer") {
+			return nil
+		}
+
+		// Obfuscated pointers can't be encoded again
+		if transform != nil {
+			return fmt.Errorf("field %s: pointer with a ptr_ transform can't be encoded", field.Name)
+		}
+	}
+
+	copy(buf, rawBytes(v))
+	if w.swap {
+		process.SwapScalarBytes(v.Kind(), buf)
+	}
+	return nil
+}
+
+// encodeBytes serializes a byte array, a char_array whose text is unchanged keeps the
+// original bytes after its terminator
+func (w *structWriter) encodeBytes(v reflect.Value, buf, original []byte, field reflect.StructField) {
+	data := rawBytes(v)
+
+	if parsePodTags(field.Tag.Get("pod"))["type"] == "char_array" {
+		cleaned := bytes.Clone(original)
+		if i := bytes.IndexByte(cleaned, 0); i >= 0 {
+			clear(cleaned[i:])
+		}
+		if bytes.Equal(cleaned, data) {
+			return
+		}
+	}
+
+	copy(buf, data)
+}
+
+// rawBytes returns a copy of the bytes of a value without Go pointers, v must be addressable
+func rawBytes(v reflect.Value) []byte {
+	size := int(v.Type().Size())
+	if size == 0 {
+		return nil
+	}
+	return bytes.Clone(unsafe.Slice((*byte)(unsafe.Pointer(v.UnsafeAddr())), size))
+}
@@ -0,0 +1,332 @@
+package pointerscan
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// pointerMapMagic starts the files written by PointerMap.Save
+const pointerMapMagic = "GMPTRMAP"
+
+// pointerMapVersion is the version of the format written by PointerMap.Save
+const pointerMapVersion = 1
+
+// maxPointerMapHeader bounds the size of the header LoadPointerMap accepts
+const maxPointerMapHeader = 64 << 20
+
+// maxPointerMapPrealloc is the most pointers LoadPointerMap allocates before reading them,
+// larger maps grow as their pointers are read so a corrupt count can't exhaust memory
+const maxPointerMapPrealloc = 1 << 20
+
+// pointer is an aligned pointer of the map
+type pointer struct {
+	Address uint64 // Where the pointer is stored
+	Value   uint64 // Where it points to
+}
+
+// PointerMap is an index of every aligned pointer stored in the writable memory of a process
+// that points into mapped memory, with the memory map it was built with. Pointer path
+// searches and "who points here" queries run from the index instead of rescanning memory;
+// save it to query it again later, e.g. once the process has exited.
+type PointerMap struct {
+	PID       process.ProcessID
+	Created   time.Time
+	MemoryMap []memory_map.MemoryMapItem
+
+	pointers []pointer // Sorted by value, then address
+}
+
+// BuildPointerMap reads the writable memory of proc with options.MaxDOP workers, suspended
+// if options.Suspend is set. The map takes 16 bytes per pointer in memory.
+func BuildPointerMap(proc process.Process, options Options) (*PointerMap, error) {
+	maxdop := options.MaxDOP
+	if maxdop == 0 {
+		maxdop = uint(runtime.NumCPU())
+	}
+
+	pm := &PointerMap{PID: proc.GetPID(), Created: time.Now()}
+	build := func() error {
+		if err := proc.UpdateMemoryMap(); err != nil {
+			return err
+		}
+		mm, err := proc.GetMemoryMap()
+		if err != nil {
+			return err
+		}
+		pm.MemoryMap = mm
+		pm.pointers, err = readPointers(proc, mm, maxdop)
+		return err
+	}
+
+	var err error
+	if options.Suspend {
+		err = process.WhileSuspended(proc, build)
+	} else {
+		err = build()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pointer map: %w", err)
+	}
+
+	slices.SortFunc(pm.pointers, func(a, b pointer) int {
+		return cmp.Or(cmp.Compare(a.Value, b.Value), cmp.Compare(a.Address, b.Address))
+	})
+	return pm, nil
+}
+
+// Count returns the number of pointers in the map
+func (m *PointerMap) Count() int {
+	return len(m.pointers)
+}
+
+// PointersTo returns the pointers of the map whose value is in [target-tolerance, target],
+// like process.ScanPointersTo, sorted by address
+func (m *PointerMap) PointersTo(target process.ProcessMemoryAddress, tolerance process.ProcessMemorySize) []process.PointerReference {
+	hi := uint64(target)
+	found := m.pointersIn(hi-min(uint64(tolerance), hi), hi)
+
+	refs := make([]process.PointerReference, len(found))
+	for i, p := range found {
+		refs[i] = process.PointerReference{
+			Address: process.ProcessMemoryAddress(p.Address),
+			Offset:  process.ProcessMemorySize(hi - p.Value),
+		}
+	}
+	slices.SortFunc(refs, func(a, b process.PointerReference) int {
+		return cmp.Compare(a.Address, b.Address)
+	})
+	return refs
+}
+
+// pointersIn returns the pointers whose value is in [lo, hi]
+func (m *PointerMap) pointersIn(lo, hi uint64) []pointer {
+	i := sort.Search(len(m.pointers), func(i int) bool { return m.pointers[i].Value >= lo })
+	j := sort.Search(len(m.pointers), func(i int) bool { return m.pointers[i].Value > hi })
+	return m.pointers[i:j]
+}
+
+// pointerMapHeader is the JSON header of a saved map
+type pointerMapHeader struct {
+	Version   int                        `json:"version"`
+	PID       process.ProcessID          `json:"pid"`
+	Created   time.Time                  `json:"created"`
+	MemoryMap []memory_map.MemoryMapItem `json:"memory_map"`
+	Count     int                        `json:"count"`
+}
+
+// Save writes the map in a compact binary form: a magic string, the length prefixed JSON
+// header with the memory map, then one record per pointer, the difference to the previous
+// value and the address divided by the alignment, both as uvarints. Maps typically take
+// 8 to 10 bytes per pointer.
+func (m *PointerMap) Save(w io.Writer) error {
+	header, err := json.Marshal(pointerMapHeader{
+		Version:   pointerMapVersion,
+		PID:       m.PID,
+		Created:   m.Created,
+		MemoryMap: m.MemoryMap,
+		Count:     len(m.pointers),
+	})
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	var buf [binary.MaxVarintLen64]byte
+	bw.WriteString(pointerMapMagic)
+	bw.Write(buf[:binary.PutUvarint(buf[:], uint64(len(header)))])
+	bw.Write(header)
+
+	var previous uint64
+	for _, p := range m.pointers {
+		bw.Write(buf[:binary.PutUvarint(buf[:], p.Value-previous)])
+		bw.Write(buf[:binary.PutUvarint(buf[:], p.Address/pointerSize)])
+		previous = p.Value
+	}
+	return bw.Flush()
+}
+
+// SaveFile writes the map to filename, see Save
+func (m *PointerMap) SaveFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := m.Save(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save pointer map to %s: %w", filename, err)
+	}
+	return f.Close()
+}
+
+// LoadPointerMap reads a map written by Save
+func LoadPointerMap(r io.Reader) (*PointerMap, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(pointerMapMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != pointerMapMagic {
+		return nil, errors.New("not a pointer map")
+	}
+
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pointer map header: %w", err)
+	}
+	if size > maxPointerMapHeader {
+		return nil, fmt.Errorf("invalid pointer map header size %d", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return nil, fmt.Errorf("failed to read pointer map header: %w", err)
+	}
+	var header pointerMapHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode pointer map header: %w", err)
+	}
+	if header.Version != pointerMapVersion {
+		return nil, fmt.Errorf("unsupported pointer map version %d", header.Version)
+	}
+
+	// Pointers are aligned and stored in mapped memory
+	var mapped uint64
+	for _, region := range header.MemoryMap {
+		mapped += uint64(region.Size)
+	}
+	if header.Count < 0 || uint64(header.Count) > mapped/pointerSize {
+		return nil, fmt.Errorf("invalid pointer map: %d pointers in %d bytes of mapped memory", header.Count, mapped)
+	}
+
+	pm := &PointerMap{
+		PID:       header.PID,
+		Created:   header.Created,
+		MemoryMap: header.MemoryMap,
+		pointers:  make([]pointer, 0, min(header.Count, maxPointerMapPrealloc)),
+	}
+
+	var value uint64
+	for i := range header.Count {
+		delta, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("pointer map truncated at pointer %d: %w", i, err)
+		}
+		address, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("pointer map truncated at pointer %d: %w", i, err)
+		}
+		value += delta
+		pm.pointers = append(pm.pointers, pointer{Address: address * pointerSize, Value: value})
+	}
+	return pm, nil
+}
+
+// LoadPointerMapFile reads a map from filename, see LoadPointerMap
+func LoadPointerMapFile(filename string) (*PointerMap, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	pm, err := LoadPointerMap(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pointer map from %s: %w", filename, err)
+	}
+	return pm, nil
+}
+
+// readPointers reads the aligned values of the writable regions of mm that point into a
+// readable region, with up to maxdop workers
+func readPointers(proc process.Process, mm []memory_map.MemoryMapItem, maxdop uint) ([]pointer, error) {
+	// Readable regions sorted by address, to check the values
+	var readable []memory_map.MemoryMapItem
+	for _, region := range mm {
+		if region.IsReadable() {
+			readable = append(readable, region)
+		}
+	}
+	slices.SortFunc(readable, func(a, b memory_map.MemoryMapItem) int {
+		return cmp.Compare(a.Address, b.Address)
+	})
+	if len(readable) == 0 {
+		return nil, nil
+	}
+	lowest := readable[0].Address
+	highest := readable[len(readable)-1].Address + uint64(readable[len(readable)-1].Size)
+
+	isMapped := func(v uint64) bool {
+		if v < lowest || v >= highest {
+			return false
+		}
+		i := sort.Search(len(readable), func(i int) bool { return readable[i].Address > v }) - 1
+		return i >= 0 && v < readable[i].Address+uint64(readable[i].Size)
+	}
+
+	limits := process.ReadLimits{MaxReadSize: process.DefaultScanChunkSize}
+	if limiter, ok := proc.(process.ReadLimiter); ok {
+		if limit := limiter.GetReadLimits().MaxReadSize; limit > 0 && limit < limits.MaxReadSize {
+			limits.MaxReadSize = limit
+		}
+	}
+	order := process.ByteOrderOf(proc)
+	options := process.ScanOptions{WritableOnly: true}
+
+	sem := make(chan struct{}, max(maxdop, 1))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var pointers []pointer
+
+	for _, region := range mm {
+		if !options.Accepts(region) {
+			continue
+		}
+		chunks, err := limits.ScanChunks(region.Address, region.Size, pointerSize, pointerSize)
+		if err != nil {
+			wg.Wait()
+			return nil, err
+		}
+		for _, chunk := range chunks {
+			sem <- struct{}{}
+			wg.Add(1)
+
+			go func(chunk process.ScanChunk) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				data, err := proc.ReadMemory(process.ProcessMemoryAddress(chunk.Address), process.ProcessMemorySize(chunk.Size))
+				if err != nil && !(errors.Is(err, process.ErrPartialRead) && len(data) > 0) {
+					return
+				}
+
+				var found []pointer
+				start := int((pointerSize - chunk.Address%pointerSize) % pointerSize)
+				for i := start; i+pointerSize <= len(data) && i < chunk.Keep; i += pointerSize {
+					if v := order.Uint64(data[i:]); isMapped(v) {
+						found = append(found, pointer{Address: chunk.Address + uint64(i), Value: v})
+					}
+				}
+
+				mu.Lock()
+				pointers = append(pointers, found...)
+				mu.Unlock()
+			}(chunk)
+		}
+	}
+
+	wg.Wait()
+	return pointers, nil
+}
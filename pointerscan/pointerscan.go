@@ -0,0 +1,219 @@
+// Package pointerscan finds static pointer paths to an address, so a value in dynamically
+// allocated memory can be found again after the target restarts: a path starts at a pointer
+// in the writable data of a module, stable relative to the module base, and follows
+// pointers and field offsets down to the target.
+//
+// The scanner first builds a pointer map of every aligned pointer of the writable memory,
+// then walks the map backwards from the target: the pointers to the target or up to MaxOffset bytes
+// before it, the pointers to those, ... up to MaxDepth levels.
+//
+//	chains, err := pointerscan.Scan(proc, target, pointerscan.Options{Modules: []string{"game.exe"}})
+//	for _, chain := range chains {
+//		fmt.Println(pointerscan.Format(chain)) // game.exe+0x1A2B30 -> [0x0, 0x18, 0x40]
+//	}
+//	// After a restart, keep the chains that still resolve to the value
+//	chains = pointerscan.Filter(restarted, chains, newTarget)
+//
+// The pointer map can be saved with PointerMap.SaveFile and searched again later with
+// LoadPointerMapFile and NewFromMap, without rescanning memory.
+//
+// Chains are process.PointerChain values: walk them with process.ValidatePointerChain, or
+// resolve the base with BaseAddress and pass the offsets to ReadPointerChain.
+package pointerscan
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// pointerSize is the size of the pointers the scanner follows, as read by ReadPOINTER
+const pointerSize = 8
+
+// Defaults of the zero Options fields
+const (
+	DefaultMaxDepth   = 4
+	DefaultMaxOffset  = 0x1000
+	DefaultMaxResults = 1000
+)
+
+// Options configures a pointer scan
+type Options struct {
+	// MaxDepth is the largest number of pointers a path reads, DefaultMaxDepth if 0
+	MaxDepth int
+
+	// MaxOffset is the largest offset added to a pointer, i.e. the largest distance between
+	// a structure and the field of it the path continues from, DefaultMaxOffset if 0
+	MaxOffset uint64
+
+	// Modules are the modules whose writable data can start a path, matched against the
+	// base name and the full path of the regions, case-insensitive. Empty allows every
+	// file-backed region.
+	Modules []string
+
+	// MaxResults stops the search after this many paths, DefaultMaxResults if 0
+	MaxResults int
+
+	// MaxDOP is the number of workers building the pointer map, the number of CPUs if 0
+	MaxDOP uint
+
+	// Suspend pauses the target while the pointer map is built, so it is consistent
+	Suspend bool
+}
+
+// Scanner searches the pointer map of a process for paths. Searching is safe for concurrent
+// use; the map is not updated when the process changes.
+type Scanner struct {
+	options Options
+	pm      *PointerMap
+}
+
+// Scan builds the pointer map of proc and returns the paths to target, see Scanner.Find
+func Scan(proc process.Process, target process.ProcessMemoryAddress, options Options) ([]process.PointerChain, error) {
+	s, err := New(proc, options)
+	if err != nil {
+		return nil, err
+	}
+	return s.Find(target)
+}
+
+// New builds the pointer map of proc, see BuildPointerMap, and returns a scanner searching
+// it. Search several targets with one scanner instead of calling Scan for each.
+func New(proc process.Process, options Options) (*Scanner, error) {
+	pm, err := BuildPointerMap(proc, options)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromMap(pm, options), nil
+}
+
+// NewFromMap returns a scanner searching a pointer map built earlier or loaded from disk
+func NewFromMap(pm *PointerMap, options Options) *Scanner {
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = DefaultMaxDepth
+	}
+	if options.MaxOffset == 0 {
+		options.MaxOffset = DefaultMaxOffset
+	}
+	if options.MaxResults <= 0 {
+		options.MaxResults = DefaultMaxResults
+	}
+	return &Scanner{options: options, pm: pm}
+}
+
+// Map returns the pointer map the scanner searches
+func (s *Scanner) Map() *PointerMap {
+	return s.pm
+}
+
+// Count returns the number of pointers in the map
+func (s *Scanner) Count() int {
+	return s.pm.Count()
+}
+
+// node is an address the search reached, with the offsets from it to the target
+type node struct {
+	address uint64
+	offsets []process.ProcessMemorySize
+}
+
+// Find returns the paths from a pointer in the writable data of a module to target, shortest
+// first. Every address is expanded once, at the shallowest level it is reached, so each
+// structure contributes its shortest path to the target.
+func (s *Scanner) Find(target process.ProcessMemoryAddress) ([]process.PointerChain, error) {
+	if memory_map.IsValidAddress2(uint64(target), s.pm.MemoryMap) == nil {
+		return nil, fmt.Errorf("target 0x%X is not mapped", uint64(target))
+	}
+
+	var chains []process.PointerChain
+	visited := map[uint64]bool{uint64(target): true}
+	frontier := []node{{address: uint64(target)}}
+
+	for depth := 1; depth <= s.options.MaxDepth && len(frontier) > 0; depth++ {
+		var next []node
+		for _, n := range frontier {
+			for _, p := range s.pointersTo(n.address) {
+				offsets := append([]process.ProcessMemorySize{process.ProcessMemorySize(n.address - p.Value)}, n.offsets...)
+
+				if s.isStatic(p.Address) {
+					chain := process.NormalizePointerChain(process.ProcessMemoryAddress(p.Address),
+						append([]process.ProcessMemorySize{0}, offsets...), s.pm.MemoryMap)
+					chains = append(chains, chain)
+					if len(chains) >= s.options.MaxResults {
+						return chains, nil
+					}
+					continue
+				}
+
+				if !visited[p.Address] {
+					visited[p.Address] = true
+					next = append(next, node{address: p.Address, offsets: offsets})
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return chains, nil
+}
+
+// pointersTo returns the pointers of the map whose value is in [addr-MaxOffset, addr]
+func (s *Scanner) pointersTo(addr uint64) []pointer {
+	return s.pm.pointersIn(addr-min(s.options.MaxOffset, addr), addr)
+}
+
+// isStatic reports whether addr is in a file-backed region of one of the modules, where a
+// path can start
+func (s *Scanner) isStatic(addr uint64) bool {
+	region := memory_map.IsValidAddress2(addr, s.pm.MemoryMap)
+	if region == nil || region.Kind() != memory_map.RegionImage {
+		return false
+	}
+	if len(s.options.Modules) == 0 {
+		return true
+	}
+	for _, module := range s.options.Modules {
+		if strings.EqualFold(region.Path, module) || strings.EqualFold(filepath.Base(region.Path), module) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the chains that resolve to target on proc, e.g. the chains of a previous
+// scan checked against the restarted target
+func Filter(proc process.Process, chains []process.PointerChain, target process.ProcessMemoryAddress) []process.PointerChain {
+	var kept []process.PointerChain
+	for _, chain := range chains {
+		if result := process.ValidatePointerChain(proc, chain); result.Valid && result.Final == target {
+			kept = append(kept, chain)
+		}
+	}
+	return kept
+}
+
+// Format returns a chain in the "module+offset -> [o1, o2, ...]" notation of pointer
+// scanners, e.g. "game.exe+0x1A2B30 -> [0x0, 0x18, 0x40]"
+func Format(chain process.PointerChain) string {
+	chain = chain.Normalized()
+
+	var sb strings.Builder
+	if chain.Module != "" {
+		fmt.Fprintf(&sb, "%s+0x%X", chain.Module, chain.Base)
+	} else {
+		fmt.Fprintf(&sb, "0x%X", chain.Base)
+	}
+
+	sb.WriteString(" -> [")
+	for i, off := range chain.Offsets {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "0x%X", uint64(off))
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
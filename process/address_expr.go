@@ -0,0 +1,428 @@
+package process
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gomem/process/memory_map"
+)
+
+// AddressResolver returns the base address of a module named in an address expression
+type AddressResolver func(module string) (ProcessMemoryAddress, error)
+
+// ModuleResolver resolves module names against the memory map of proc, see memory_map.ModuleBase
+func ModuleResolver(proc Process) AddressResolver {
+	return func(module string) (ProcessMemoryAddress, error) {
+		mm, err := proc.GetMemoryMap()
+		if err != nil {
+			return 0, err
+		}
+		base, ok := memory_map.ModuleBase(module, mm)
+		if !ok {
+			return 0, fmt.Errorf("module %s not found", module)
+		}
+		return ProcessMemoryAddress(base), nil
+	}
+}
+
+// AddressExpr is a parsed address expression, see ParseAddressExpr
+type AddressExpr struct {
+	root exprNode
+}
+
+// PathSpec is the ReadPath form of an address expression: starting at Base, a pointer is
+// read at the current address plus each offset but the last, the last offset is added
+// to the final pointer. It can be passed as ReadPath[T](proc, spec.Base, spec.Offsets...).
+type PathSpec struct {
+	Base    ProcessMemoryAddress
+	Offsets []ProcessMemorySize
+}
+
+// ParseAddressExpr parses an address expression such as "game.exe+0x1A2B30+[0x18]+0x40".
+//
+//   - Numbers are hex with a 0x prefix, decimal otherwise.
+//   - Names (e.g. game.exe, libc.so.6) are module bases looked up with resolver when parsing;
+//     names containing other characters can be quoted ("libc-2.31.so").
+//   - +, -, * and parentheses have their usual meaning.
+//   - [x] reads the pointer at x. Following a +, the brackets are relative to the address
+//     accumulated so far: "a+[y]" reads the pointer at a+y, so pointer paths read left to right.
+//
+// Expressions without brackets are constant; otherwise Resolve walks the pointers on a process.
+func ParseAddressExpr(expr string, resolver AddressResolver) (*AddressExpr, error) {
+	p := &exprParser{input: expr, resolver: resolver}
+	p.next()
+
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+
+	return &AddressExpr{root: root}, nil
+}
+
+// ResolveAddressExpr parses expr with the modules of proc and resolves it on proc
+func ResolveAddressExpr(proc Process, expr string) (ProcessMemoryAddress, error) {
+	e, err := ParseAddressExpr(expr, ModuleResolver(proc))
+	if err != nil {
+		return 0, err
+	}
+	return e.Resolve(proc)
+}
+
+// ParseAddress parses an address given on a command line: a plain number is hex, with or
+// without the 0x prefix ("7ffd1000" and "0x7ffd1000" are the same address), anything else
+// is an address expression resolved on proc, see ResolveAddressExpr. Unlike in expressions,
+// where numbers without 0x are decimal, "1000" is 0x1000. proc may be nil when only plain
+// numbers are expected.
+func ParseAddress(proc Process, s string) (ProcessMemoryAddress, error) {
+	if v, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64); err == nil {
+		return ProcessMemoryAddress(v), nil
+	}
+	if proc == nil {
+		return 0, fmt.Errorf("address expression %q needs a process", s)
+	}
+	return ResolveAddressExpr(proc, s)
+}
+
+// Address returns the value of a constant expression (one without pointer reads)
+func (e *AddressExpr) Address() (ProcessMemoryAddress, bool) {
+	if c, ok := e.root.(constNode); ok {
+		return ProcessMemoryAddress(c), true
+	}
+	return 0, false
+}
+
+// Path returns the expression as a ReadPath spec, if it has that shape
+// (a constant base followed by relative pointer reads and a final offset)
+func (e *AddressExpr) Path() (PathSpec, bool) {
+	base, derefs, final, ok := pathOf(e.root)
+	if !ok {
+		return PathSpec{}, false
+	}
+
+	spec := PathSpec{Base: ProcessMemoryAddress(base)}
+	for _, off := range derefs {
+		spec.Offsets = append(spec.Offsets, ProcessMemorySize(off))
+	}
+	spec.Offsets = append(spec.Offsets, ProcessMemorySize(final))
+	return spec, true
+}
+
+// Resolve evaluates the expression, reading the pointers from proc
+func (e *AddressExpr) Resolve(proc Process) (ProcessMemoryAddress, error) {
+	v, err := e.root.eval(proc)
+	return ProcessMemoryAddress(v), err
+}
+
+// String returns the normalized form of the expression, module bases resolved
+func (e *AddressExpr) String() string {
+	return e.root.String()
+}
+
+// pathOf decomposes a node into base, relative pointer offsets and final offset
+func pathOf(n exprNode) (base uint64, derefs []uint64, final uint64, ok bool) {
+	switch n := n.(type) {
+	case constNode:
+		return uint64(n), nil, 0, true
+	case binaryNode:
+		if n.op == '*' {
+			return 0, nil, 0, false
+		}
+		k, isConst := n.right.(constNode)
+		if !isConst {
+			if n.op == '-' {
+				return 0, nil, 0, false
+			}
+			// Addition is commutative
+			k, isConst = n.left.(constNode)
+			if !isConst {
+				return 0, nil, 0, false
+			}
+			base, derefs, final, ok = pathOf(n.right)
+		} else {
+			base, derefs, final, ok = pathOf(n.left)
+		}
+		if !ok {
+			return 0, nil, 0, false
+		}
+		if n.op == '-' {
+			return base, derefs, final - uint64(k), true
+		}
+		return base, derefs, final + uint64(k), true
+	case derefNode:
+		base, derefs, final, ok = pathOf(n.addr)
+		if !ok {
+			return 0, nil, 0, false
+		}
+		return base, append(derefs, final), 0, true
+	}
+	return 0, nil, 0, false
+}
+
+// exprNode is a node of a parsed address expression
+type exprNode interface {
+	eval(proc Process) (uint64, error)
+	String() string
+}
+
+type constNode uint64
+
+func (n constNode) eval(Process) (uint64, error) { return uint64(n), nil }
+func (n constNode) String() string               { return fmt.Sprintf("0x%X", uint64(n)) }
+
+type binaryNode struct {
+	op          byte
+	left, right exprNode
+}
+
+func (n binaryNode) eval(proc Process) (uint64, error) {
+	l, err := n.left.eval(proc)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.right.eval(proc)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	}
+	return l * r, nil
+}
+
+func (n binaryNode) String() string {
+	if n.op == '*' {
+		return fmt.Sprintf("(%s*%s)", n.left, n.right)
+	}
+	return fmt.Sprintf("%s%c%s", n.left, n.op, n.right)
+}
+
+type derefNode struct {
+	addr exprNode
+}
+
+func (n derefNode) eval(proc Process) (uint64, error) {
+	addr, err := n.addr.eval(proc)
+	if err != nil {
+		return 0, err
+	}
+	if proc == nil {
+		return 0, fmt.Errorf("expression reads memory, a process is required")
+	}
+	ptr, err := proc.ReadPOINTER(ProcessMemoryAddress(addr))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pointer at 0x%X: %w", addr, err)
+	}
+	return uint64(ptr), nil
+}
+
+func (n derefNode) String() string { return "[" + n.addr.String() + "]" }
+
+// fold evaluates constant binary nodes at parse time
+func fold(op byte, left, right exprNode) exprNode {
+	l, lok := left.(constNode)
+	r, rok := right.(constNode)
+	if lok && rok {
+		v, _ := binaryNode{op, l, r}.eval(nil)
+		return constNode(v)
+	}
+	return binaryNode{op, left, right}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokName
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type exprParser struct {
+	input    string
+	pos      int
+	tok      token
+	resolver AddressResolver
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("address expression %q at %d: %s", p.input, p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+// next advances to the next token
+func (p *exprParser) next() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+
+	start := p.pos
+	if p.pos >= len(p.input) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case strings.IndexByte("+-*()[]", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokOp, text: string(c), pos: start}
+	case c == '"':
+		end := strings.IndexByte(p.input[start+1:], '"')
+		if end < 0 {
+			p.pos = len(p.input)
+			p.tok = token{kind: tokName, text: p.input[start+1:], pos: start}
+			return
+		}
+		p.pos = start + 1 + end + 1
+		p.tok = token{kind: tokName, text: p.input[start+1 : start+1+end], pos: start}
+	default:
+		for p.pos < len(p.input) && strings.IndexByte("+-*()[] ", p.input[p.pos]) < 0 {
+			p.pos++
+		}
+		kind := tokName
+		if c >= '0' && c <= '9' {
+			kind = tokNumber
+		}
+		p.tok = token{kind: kind, text: p.input[start:p.pos], pos: start}
+	}
+}
+
+// parseSum parses term (('+'|'-') term)*
+func (p *exprParser) parseSum() (exprNode, error) {
+	acc, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.tok.kind == tokOp && (p.tok.text == "+" || p.tok.text == "-") {
+		op := p.tok.text[0]
+		p.next()
+
+		// "acc+[y]" reads the pointer at acc+y
+		if op == '+' && p.tok.kind == tokOp && p.tok.text == "[" {
+			inner, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			acc = derefNode{binaryNode{'+', acc, inner}}
+			continue
+		}
+
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		acc = fold(op, acc, right)
+	}
+
+	return acc, nil
+}
+
+// parseTerm parses factor ('*' factor)*
+func (p *exprParser) parseTerm() (exprNode, error) {
+	acc, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.tok.kind == tokOp && p.tok.text == "*" {
+		p.next()
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		acc = fold('*', acc, right)
+	}
+
+	return acc, nil
+}
+
+// parseBracket parses '[' sum ']' and returns the inner expression
+func (p *exprParser) parseBracket() (exprNode, error) {
+	p.next()
+	inner, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokOp || p.tok.text != "]" {
+		return nil, p.errorf("missing ]")
+	}
+	p.next()
+	return inner, nil
+}
+
+// parseFactor parses a number, a module name, '-' factor, '(' sum ')' or '[' sum ']'
+func (p *exprParser) parseFactor() (exprNode, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		p.next()
+		var v uint64
+		var err error
+		if strings.HasPrefix(tok.text, "0x") || strings.HasPrefix(tok.text, "0X") {
+			v, err = strconv.ParseUint(tok.text[2:], 16, 64)
+		} else {
+			v, err = strconv.ParseUint(tok.text, 10, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("address expression %q at %d: invalid number %q", p.input, tok.pos, tok.text)
+		}
+		return constNode(v), nil
+
+	case tokName:
+		p.next()
+		if p.resolver == nil {
+			return nil, fmt.Errorf("address expression %q at %d: no resolver for module %q", p.input, tok.pos, tok.text)
+		}
+		base, err := p.resolver(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("address expression %q at %d: %w", p.input, tok.pos, err)
+		}
+		return constNode(base), nil
+
+	case tokOp:
+		switch tok.text {
+		case "-":
+			p.next()
+			operand, err := p.parseFactor()
+			if err != nil {
+				return nil, err
+			}
+			return fold('-', constNode(0), operand), nil
+		case "(":
+			p.next()
+			inner, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			if p.tok.kind != tokOp || p.tok.text != ")" {
+				return nil, p.errorf("missing )")
+			}
+			p.next()
+			return inner, nil
+		case "[":
+			inner, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			return derefNode{inner}, nil
+		}
+	case tokEOF:
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	return nil, p.errorf("unexpected %q", tok.text)
+}
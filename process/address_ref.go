@@ -0,0 +1,119 @@
+package process
+
+import (
+	"fmt"
+
+	"gomem/process/memory_map"
+)
+
+// AddressRef is an ASLR-stable description of an address, meant to be persisted instead of a
+// raw ProcessMemoryAddress: an offset from a module base, or a pointer chain starting there.
+// Absolute references (no module) are supported for memory that does not move.
+//
+// The text form is the normalized address expression of the reference, e.g. "libc.so.6+0x1D8F40"
+// or "game.exe+0x1A2B30+[0x18]+0x40", and is what JSON and other text encodings store.
+type AddressRef struct {
+	Module string              // Module the offset is relative to (base name), empty for an absolute address
+	Offset uint64              // Offset from the module base, or the absolute address
+	Path   []ProcessMemorySize // ReadPath offsets applied from Offset, nil for a plain module+offset reference
+}
+
+// ModuleRef returns a reference to offset from the base of module
+func ModuleRef(module string, offset uint64) AddressRef {
+	return AddressRef{Module: module, Offset: offset}.normalized()
+}
+
+// ChainRef returns a reference following a pointer chain
+func ChainRef(chain PointerChain) AddressRef {
+	chain = chain.Normalized()
+	return AddressRef{Module: chain.Module, Offset: chain.Base, Path: chain.Offsets}.normalized()
+}
+
+// NewAddressRef describes addr relative to the module containing it, or as an absolute
+// address when it is not inside a module
+func NewAddressRef(addr ProcessMemoryAddress, mm []memory_map.MemoryMapItem) AddressRef {
+	return ChainRef(NormalizePointerChain(addr, nil, mm))
+}
+
+// ParseAddressRef parses the text form of a reference, see ParsePointerChain for the syntax
+func ParseAddressRef(s string) (AddressRef, error) {
+	chain, err := ParsePointerChain(s)
+	if err != nil {
+		return AddressRef{}, err
+	}
+	return ChainRef(chain), nil
+}
+
+// normalized folds a chain without pointer reads into a plain reference
+func (r AddressRef) normalized() AddressRef {
+	if r.Module != "" {
+		r.Module = PointerChain{Module: r.Module}.Normalized().Module
+	}
+	if len(r.Path) <= 1 {
+		for _, off := range r.Path {
+			r.Offset += uint64(off)
+		}
+		r.Path = nil
+	}
+	return r
+}
+
+// IsChain reports whether resolving the reference reads pointers
+func (r AddressRef) IsChain() bool {
+	return len(r.Path) > 1
+}
+
+// Chain returns the reference as a pointer chain
+func (r AddressRef) Chain() PointerChain {
+	return PointerChain{Module: r.Module, Base: r.Offset, Offsets: r.Path}.Normalized()
+}
+
+// String returns the text form of the reference
+func (r AddressRef) String() string {
+	return r.Chain().String()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (r AddressRef) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (r *AddressRef) UnmarshalText(text []byte) error {
+	ref, err := ParseAddressRef(string(text))
+	if err != nil {
+		return err
+	}
+	*r = ref
+	return nil
+}
+
+// ResolveIn resolves a plain reference against a memory map.
+// Chain references read memory and must be resolved with Resolve.
+func (r AddressRef) ResolveIn(mm []memory_map.MemoryMapItem) (ProcessMemoryAddress, error) {
+	if r.IsChain() {
+		return 0, fmt.Errorf("address %s reads pointers, a process is required", r)
+	}
+	base, err := r.Chain().BaseAddress(mm)
+	if err != nil {
+		return 0, fmt.Errorf("address %s: %w", r, err)
+	}
+	return base, nil
+}
+
+// Resolve returns the current address of the reference in proc, a live process or a dump
+func (r AddressRef) Resolve(proc Process) (ProcessMemoryAddress, error) {
+	if !r.IsChain() {
+		mm, err := proc.GetMemoryMap()
+		if err != nil {
+			return 0, err
+		}
+		return r.ResolveIn(mm)
+	}
+
+	result := ValidatePointerChain(proc, r.Chain())
+	if !result.Valid {
+		return 0, fmt.Errorf("address %s: %w", r, result.Err)
+	}
+	return result.Final, nil
+}
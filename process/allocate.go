@@ -0,0 +1,37 @@
+package process
+
+import (
+	"fmt"
+)
+
+// MemoryAllocator is implemented by processes that can allocate memory inside the target,
+// e.g. to stage payload data or detour trampolines
+type MemoryAllocator interface {
+	// AllocateMemory allocates at least size bytes of zeroed memory with the permissions perms
+	// ("rw-", "rwx", ...) and returns its page aligned address
+	AllocateMemory(size ProcessMemorySize, perms string) (ProcessMemoryAddress, error)
+
+	// FreeMemory releases memory returned by AllocateMemory, size is the size it was
+	// allocated with
+	FreeMemory(addr ProcessMemoryAddress, size ProcessMemorySize) error
+}
+
+// AllocateMemory allocates memory inside proc, see MemoryAllocator.
+// It fails for processes that can't allocate memory (dumps), ErrReadOnly for read-only
+// wrappers.
+func AllocateMemory(proc Process, size ProcessMemorySize, perms string) (ProcessMemoryAddress, error) {
+	allocator, ok := proc.(MemoryAllocator)
+	if !ok {
+		return 0, fmt.Errorf("%T does not support allocating memory", proc)
+	}
+	return allocator.AllocateMemory(size, perms)
+}
+
+// FreeMemory releases memory allocated with AllocateMemory
+func FreeMemory(proc Process, addr ProcessMemoryAddress, size ProcessMemorySize) error {
+	allocator, ok := proc.(MemoryAllocator)
+	if !ok {
+		return fmt.Errorf("%T does not support allocating memory", proc)
+	}
+	return allocator.FreeMemory(addr, size)
+}
@@ -0,0 +1,148 @@
+package process
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// CaptureType selects how the bytes of an AOBCapture are decoded
+type CaptureType string
+
+const (
+	CaptureRel8    CaptureType = "rel8"  // 8-bit relative displacement, decoded to the absolute target
+	CaptureRel32   CaptureType = "rel32" // 32-bit relative displacement, decoded to the absolute target
+	CapturePointer CaptureType = "ptr"   // 64-bit absolute address
+	CaptureUINT8   CaptureType = "u8"
+	CaptureUINT16  CaptureType = "u16"
+	CaptureUINT32  CaptureType = "u32"
+	CaptureUINT64  CaptureType = "u64"
+	CaptureINT8    CaptureType = "i8"
+	CaptureINT16   CaptureType = "i16"
+	CaptureINT32   CaptureType = "i32"
+	CaptureINT64   CaptureType = "i64"
+)
+
+// Size returns the number of bytes of the capture, 0 for unknown types
+func (t CaptureType) Size() int {
+	switch t {
+	case CaptureRel8, CaptureUINT8, CaptureINT8:
+		return 1
+	case CaptureUINT16, CaptureINT16:
+		return 2
+	case CaptureRel32, CaptureUINT32, CaptureINT32:
+		return 4
+	case CapturePointer, CaptureUINT64, CaptureINT64:
+		return 8
+	}
+	return 0
+}
+
+// signed reports whether the capture is sign-extended
+func (t CaptureType) signed() bool {
+	switch t {
+	case CaptureRel8, CaptureRel32, CaptureINT8, CaptureINT16, CaptureINT32, CaptureINT64:
+		return true
+	}
+	return false
+}
+
+// AOBCapture is a named field of a pattern, written "[rel32:disp]" in pattern strings. The
+// captured bytes are wildcards of the pattern.
+type AOBCapture struct {
+	Name   string
+	Type   CaptureType
+	Offset int // Offset of the field in the pattern
+}
+
+// CaptureMatch is a scan hit with its decoded captures
+type CaptureMatch struct {
+	Address ProcessMemoryAddress
+	Fields  map[string]uint64 // Decoded captures by name, see AOB.DecodeCaptures
+}
+
+// AddressOf returns a capture as an address, e.g. the target of a rel32
+func (m CaptureMatch) AddressOf(name string) ProcessMemoryAddress {
+	return ProcessMemoryAddress(m.Fields[name])
+}
+
+// Int returns a capture as a signed integer
+func (m CaptureMatch) Int(name string) int64 {
+	return int64(m.Fields[name])
+}
+
+// DecodeCaptures decodes the captures of a match at addr, data holds the matched bytes in
+// the byte order order. Relative displacements are converted to the absolute address they
+// point to, relative to the end of the field: the next instruction when the displacement is
+// the last operand, as in "48 8B 05 [rel32:disp]". Signed values are sign-extended.
+func (aob AOB) DecodeCaptures(addr ProcessMemoryAddress, data []byte, order binary.ByteOrder) (map[string]uint64, error) {
+	fields := make(map[string]uint64, len(aob.Captures))
+	for _, c := range aob.Captures {
+		size := c.Type.Size()
+		if size == 0 {
+			return nil, fmt.Errorf("capture %s: unknown type %q", c.Name, c.Type)
+		}
+		if c.Offset < 0 || c.Offset+size > len(data) {
+			return nil, fmt.Errorf("capture %s: offset %d out of the %d matched bytes", c.Name, c.Offset, len(data))
+		}
+
+		v := decodeUint(data[c.Offset:c.Offset+size], order)
+		if c.Type.signed() {
+			shift := 64 - 8*uint(size)
+			v = uint64(int64(v<<shift) >> shift)
+		}
+		if c.Type == CaptureRel8 || c.Type == CaptureRel32 {
+			v += uint64(addr) + uint64(c.Offset+size)
+		}
+		fields[c.Name] = v
+	}
+	return fields, nil
+}
+
+// ScanCaptures scans proc for aob like ScanWithOptions and decodes the captures of every
+// match, re-reading the matched bytes
+func ScanCaptures(proc Process, aob AOB, options ScanOptions) ([]CaptureMatch, error) {
+	matches, err := proc.ScanWithOptions(aob, options)
+	if err != nil {
+		return nil, err
+	}
+
+	order := ByteOrderOf(proc)
+	results := make([]CaptureMatch, 0, len(matches))
+	for _, match := range matches {
+		data, err := proc.ReadMemory(match.Address, ProcessMemorySize(len(aob.Pattern)))
+		if err != nil {
+			return results, fmt.Errorf("failed to read match at 0x%X: %w", uint64(match.Address), err)
+		}
+		fields, err := aob.DecodeCaptures(match.Address, data, order)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, CaptureMatch{Address: match.Address, Fields: fields})
+	}
+	return results, nil
+}
+
+// ResolveCapture returns the named capture of the first match of aob, e.g. the global a
+// RIP-relative load refers to:
+//
+//	aob, _ := process.NewAOBFromString("48 8B 05 [rel32:players] 48 85 C0")
+//	players, err := process.ResolveCapture(proc, aob, "players", process.ScanOptions{Module: "game.exe"})
+func ResolveCapture(proc Process, aob AOB, name string, options ScanOptions) (ProcessMemoryAddress, error) {
+	found := false
+	for _, c := range aob.Captures {
+		found = found || c.Name == name
+	}
+	if !found {
+		return 0, fmt.Errorf("pattern has no capture %q", name)
+	}
+
+	options.MaxResults = 1
+	matches, err := ScanCaptures(proc, aob, options)
+	if err != nil {
+		return 0, err
+	}
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("pattern not found")
+	}
+	return matches[0].AddressOf(name), nil
+}
@@ -0,0 +1,254 @@
+package process
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NewAOBFromString parses a pattern string in one of the notations of disassemblers, debuggers
+// and cheat tools:
+//
+//   - IDA / x64dbg style hex bytes separated by spaces or commas: "48 8B ?? 89 05 ? ? ? ?",
+//     "00,ba,ad,??,f0" or "0x48, 0x8B". "?" and "??" are wildcard bytes, "4?" and "?8"
+//     wildcard one nibble. Bytes may also be written without separators: "488B??05".
+//     "[type:name]" is a named capture of wildcard bytes decoded from each match, e.g.
+//     "48 8B 05 [rel32:disp] C3", see AOBCapture and ScanCaptures.
+//   - C-escaped strings, optionally quoted: "\x48\x8B\x05" or `"name\x00"`. Besides \xHH, the
+//     escapes \0, \a, \b, \f, \n, \r, \t, \v, \\, \" and \' are recognized, other characters
+//     match their UTF-8 bytes, and \x?? is a wildcard byte.
+func NewAOBFromString(s string) (AOB, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return AOB{}, fmt.Errorf("empty pattern")
+	}
+
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, `\`) {
+		return parseEscapedAOB(s)
+	}
+	return parseHexAOB(s)
+}
+
+// ParseAOB parses a pattern such as "48 8B 05 ?? ?? ?? ??" or "00,ba,ad,??,f0", see
+// NewAOBFromString for every notation
+func ParseAOB(s string) (AOB, error) {
+	return NewAOBFromString(s)
+}
+
+// String formats the pattern in the IDA notation read by NewAOBFromString, e.g.
+// "48 8B ?? 4? [rel32:disp]". Masks other than whole bytes and nibbles can't be written and
+// are shown as the masked byte.
+func (aob AOB) String() string {
+	captures := make(map[int]AOBCapture, len(aob.Captures))
+	for _, c := range aob.Captures {
+		captures[c.Offset] = c
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(aob.Pattern); i++ {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		if c, ok := captures[i]; ok && c.Type.Size() > 0 {
+			fmt.Fprintf(&sb, "[%s:%s]", c.Type, c.Name)
+			i += c.Type.Size() - 1
+			continue
+		}
+
+		b := aob.Pattern[i]
+		mask := byte(0xFF)
+		if i < len(aob.Mask) {
+			mask = aob.Mask[i]
+		}
+
+		hex := fmt.Sprintf("%02X", b&mask)
+		switch mask {
+		case 0x00:
+			hex = "??"
+		case 0xF0:
+			hex = hex[:1] + "?"
+		case 0x0F:
+			hex = "?" + hex[1:]
+		}
+		sb.WriteString(hex)
+	}
+	return sb.String()
+}
+
+// parseHexAOB parses hex bytes separated by whitespace or commas
+func parseHexAOB(s string) (AOB, error) {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	var aob AOB
+	for _, part := range parts {
+		token := part
+		if strings.HasPrefix(token, "0x") || strings.HasPrefix(token, "0X") {
+			token = token[2:]
+		}
+
+		if strings.HasPrefix(part, "[") {
+			capture, err := parseCapture(part, len(aob.Pattern))
+			if err != nil {
+				return AOB{}, err
+			}
+			for _, c := range aob.Captures {
+				if c.Name == capture.Name {
+					return AOB{}, fmt.Errorf("duplicate capture %q", c.Name)
+				}
+			}
+			aob.Captures = append(aob.Captures, capture)
+			for range capture.Type.Size() {
+				aob.Pattern = append(aob.Pattern, 0)
+				aob.Mask = append(aob.Mask, 0x00)
+			}
+			continue
+		}
+
+		switch {
+		case token == "?":
+			aob.Pattern = append(aob.Pattern, 0)
+			aob.Mask = append(aob.Mask, 0x00)
+			continue
+		case len(token) == 1:
+			// A single digit byte, e.g. the 5 of "0x5"
+			token = "0" + token
+		case len(token) == 0 || len(token)%2 != 0:
+			return AOB{}, fmt.Errorf("invalid hex byte: %s", part)
+		}
+
+		// Tokens may hold several bytes without separators
+		for i := 0; i < len(token); i += 2 {
+			value, mask, ok := parseHexByte(token[i : i+2])
+			if !ok {
+				return AOB{}, fmt.Errorf("invalid hex byte: %s", part)
+			}
+			aob.Pattern = append(aob.Pattern, value)
+			aob.Mask = append(aob.Mask, mask)
+		}
+	}
+
+	if len(aob.Pattern) == 0 {
+		return AOB{}, fmt.Errorf("empty pattern")
+	}
+	return aob, nil
+}
+
+// parseCapture parses a "[type:name]" capture at offset
+func parseCapture(token string, offset int) (AOBCapture, error) {
+	if !strings.HasSuffix(token, "]") {
+		return AOBCapture{}, fmt.Errorf("invalid capture %s, expected [type:name]", token)
+	}
+	typ, name, ok := strings.Cut(token[1:len(token)-1], ":")
+	if !ok || name == "" {
+		return AOBCapture{}, fmt.Errorf("invalid capture %s, expected [type:name]", token)
+	}
+
+	capture := AOBCapture{Name: name, Type: CaptureType(strings.ToLower(typ)), Offset: offset}
+	if capture.Type.Size() == 0 {
+		return AOBCapture{}, fmt.Errorf("invalid capture type %s, expected rel8, rel32, ptr, u8-u64 or i8-i64", typ)
+	}
+	return capture, nil
+}
+
+// parseHexByte parses two hex digits, either of which may be a '?' wildcard nibble
+func parseHexByte(s string) (value, mask byte, ok bool) {
+	for _, c := range []byte(s) {
+		value <<= 4
+		mask <<= 4
+		if c == '?' {
+			continue
+		}
+		digit, err := strconv.ParseUint(string(c), 16, 8)
+		if err != nil {
+			return 0, 0, false
+		}
+		value |= byte(digit)
+		mask |= 0x0F
+	}
+	return value, mask, true
+}
+
+// parseEscapedAOB parses a C string with escapes, optionally enclosed in double quotes
+func parseEscapedAOB(s string) (AOB, error) {
+	if strings.HasPrefix(s, `"`) {
+		if len(s) < 2 || !strings.HasSuffix(s, `"`) {
+			return AOB{}, fmt.Errorf("unterminated string: %s", s)
+		}
+		s = s[1 : len(s)-1]
+	}
+
+	var aob AOB
+	literal := func(b byte) {
+		aob.Pattern = append(aob.Pattern, b)
+		aob.Mask = append(aob.Mask, 0xFF)
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			literal(s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			return AOB{}, fmt.Errorf("trailing backslash")
+		}
+		i++
+
+		switch c := s[i]; c {
+		case 'x':
+			// One or two hex digits, or ?? for a wildcard byte
+			end := i + 1
+			for end < len(s) && end < i+3 && (isHexDigit(s[end]) || s[end] == '?') {
+				end++
+			}
+			if end == i+1 {
+				return AOB{}, fmt.Errorf(`invalid escape \x at offset %d`, i-1)
+			}
+			digits := s[i+1 : end]
+			if digits == "?" {
+				return AOB{}, fmt.Errorf(`invalid escape \x? at offset %d, use \x??`, i-1)
+			}
+			if len(digits) == 1 {
+				digits = "0" + digits
+			}
+			value, mask, ok := parseHexByte(digits)
+			if !ok {
+				return AOB{}, fmt.Errorf(`invalid escape \x%s`, s[i+1:end])
+			}
+			aob.Pattern = append(aob.Pattern, value)
+			aob.Mask = append(aob.Mask, mask)
+			i = end - 1
+		case '0':
+			literal(0)
+		case 'a':
+			literal('\a')
+		case 'b':
+			literal('\b')
+		case 'f':
+			literal('\f')
+		case 'n':
+			literal('\n')
+		case 'r':
+			literal('\r')
+		case 't':
+			literal('\t')
+		case 'v':
+			literal('\v')
+		case '\\', '"', '\'':
+			literal(c)
+		default:
+			return AOB{}, fmt.Errorf(`invalid escape \%c`, c)
+		}
+	}
+
+	if len(aob.Pattern) == 0 {
+		return AOB{}, fmt.Errorf("empty pattern")
+	}
+	return aob, nil
+}
+
+// isHexDigit reports whether c is a hex digit
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
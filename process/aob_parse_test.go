@@ -0,0 +1,91 @@
+package process
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewAOBFromString(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		pattern []byte
+		mask    []byte
+	}{
+		{"ida", "48 8B ?? 05", []byte{0x48, 0x8B, 0x00, 0x05}, []byte{0xFF, 0xFF, 0x00, 0xFF}},
+		{"single wildcard", "89 05 ? ?", []byte{0x89, 0x05, 0x00, 0x00}, []byte{0xFF, 0xFF, 0x00, 0x00}},
+		{"commas", "00,ba,ad,??,f0", []byte{0x00, 0xBA, 0xAD, 0x00, 0xF0}, []byte{0xFF, 0xFF, 0xFF, 0x00, 0xFF}},
+		{"0x prefix", "0x48, 0X8b, 0x5", []byte{0x48, 0x8B, 0x05}, []byte{0xFF, 0xFF, 0xFF}},
+		{"no separators", "488B??05", []byte{0x48, 0x8B, 0x00, 0x05}, []byte{0xFF, 0xFF, 0x00, 0xFF}},
+		{"whitespace", " 48\t8B\n05 ", []byte{0x48, 0x8B, 0x05}, []byte{0xFF, 0xFF, 0xFF}},
+		{"high nibble", "4? 8B", []byte{0x40, 0x8B}, []byte{0xF0, 0xFF}},
+		{"low nibble", "?8 8B", []byte{0x08, 0x8B}, []byte{0x0F, 0xFF}},
+		{"escaped", `\x48\x8B\x??\x5`, []byte{0x48, 0x8B, 0x00, 0x05}, []byte{0xFF, 0xFF, 0x00, 0xFF}},
+		{"escaped nibble", `\x4?`, []byte{0x40}, []byte{0xF0}},
+		{"quoted", `"ab\x00\n"`, []byte{'a', 'b', 0x00, '\n'}, []byte{0xFF, 0xFF, 0xFF, 0xFF}},
+		{"escapes", `"\0\t\\\"\'"`, []byte{0x00, '\t', '\\', '"', '\''}, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aob, err := NewAOBFromString(tt.in)
+			if err != nil {
+				t.Fatalf("NewAOBFromString(%q): %v", tt.in, err)
+			}
+			if !bytes.Equal(aob.Pattern, tt.pattern) {
+				t.Errorf("pattern = % X, want % X", aob.Pattern, tt.pattern)
+			}
+			if !bytes.Equal(aob.Mask, tt.mask) {
+				t.Errorf("mask = % X, want % X", aob.Mask, tt.mask)
+			}
+		})
+	}
+}
+
+func TestNewAOBFromStringErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"48 8G",
+		"48 8B0",
+		"0x",
+		"???",
+		`"unterminated`,
+		`\x`,
+		`\x?`,
+		`\q`,
+		`"ab\"`,
+		`""`,
+	}
+
+	for _, in := range tests {
+		if aob, err := NewAOBFromString(in); err == nil {
+			t.Errorf("NewAOBFromString(%q) = %v, want an error", in, aob)
+		}
+	}
+}
+
+func TestAOBString(t *testing.T) {
+	tests := []struct {
+		aob  AOB
+		want string
+	}{
+		{AOB{Pattern: []byte{0x48, 0x8B, 0x00}, Mask: []byte{0xFF, 0xFF, 0x00}}, "48 8B ??"},
+		{AOB{Pattern: []byte{0xAB, 0xCD}}, "AB CD"},
+		{AOB{Pattern: []byte{0x4F, 0xF8}, Mask: []byte{0xF0, 0x0F}}, "4? ?8"},
+		{AOB{Pattern: []byte{0xFF}, Mask: []byte{0x3C}}, "3C"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.aob.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		parsed, err := NewAOBFromString(tt.want)
+		if err != nil {
+			t.Fatalf("NewAOBFromString(%q): %v", tt.want, err)
+		}
+		if got := parsed.String(); got != tt.want {
+			t.Errorf("NewAOBFromString(%q).String() = %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gomem/process/memory_map"
+)
+
+// Bookmark is a named address kept as an AddressRef so it survives ASLR and process restarts
+type Bookmark struct {
+	Name    string               `json:"name"`
+	Ref     AddressRef           `json:"ref"`
+	Address ProcessMemoryAddress `json:"-"` // Resolved address, 0 while the reference does not resolve
+}
+
+// Bookmarks is a concurrent-safe set of bookmarks that can be rebased onto a new memory map
+type Bookmarks struct {
+	mu    sync.RWMutex
+	items map[string]Bookmark
+}
+
+// NewBookmarks creates an empty bookmark set
+func NewBookmarks() *Bookmarks {
+	return &Bookmarks{
+		items: make(map[string]Bookmark),
+	}
+}
+
+// Set adds or replaces a bookmark at offset from the base of module and resolves it against the memory map.
+// It returns the resolved address, or an error if the module is not mapped (the bookmark is kept
+// and resolved on the next Rebase).
+func (b *Bookmarks) Set(name, module string, offset uint64, mm []memory_map.MemoryMapItem) (ProcessMemoryAddress, error) {
+	bookmark := Bookmark{Name: name, Ref: ModuleRef(module, offset)}
+	err := bookmark.resolveIn(mm)
+
+	b.mu.Lock()
+	b.items[name] = bookmark
+	b.mu.Unlock()
+
+	return bookmark.Address, err
+}
+
+// SetRef adds or replaces a bookmark for any reference, pointer chains included, and resolves it on proc.
+// As with Set, the bookmark is kept when it does not resolve yet.
+func (b *Bookmarks) SetRef(name string, ref AddressRef, proc Process) (ProcessMemoryAddress, error) {
+	bookmark := Bookmark{Name: name, Ref: ref}
+	err := bookmark.resolve(proc)
+
+	b.mu.Lock()
+	b.items[name] = bookmark
+	b.mu.Unlock()
+
+	return bookmark.Address, err
+}
+
+// Remove deletes a bookmark
+func (b *Bookmarks) Remove(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.items, name)
+}
+
+// Get returns the resolved address of a bookmark
+func (b *Bookmarks) Get(name string) (ProcessMemoryAddress, error) {
+	b.mu.RLock()
+	bookmark, ok := b.items[name]
+	b.mu.RUnlock()
+
+	if !ok {
+		return 0, fmt.Errorf("bookmark %s not found", name)
+	}
+	if bookmark.Address == 0 {
+		return 0, fmt.Errorf("bookmark %s: %s not resolved", name, bookmark.Ref)
+	}
+	return bookmark.Address, nil
+}
+
+// List returns all bookmarks sorted by name
+func (b *Bookmarks) List() []Bookmark {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	result := make([]Bookmark, 0, len(b.items))
+	for _, bookmark := range b.items {
+		result = append(result, bookmark)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Rebase resolves every bookmark against a new memory map, e.g. after the process restarted
+// or a module was reloaded. It returns the names of the bookmarks that do not resolve;
+// pointer chain bookmarks read memory and are only resolved by Refresh.
+func (b *Bookmarks) Rebase(mm []memory_map.MemoryMapItem) []string {
+	return b.resolveAll(func(bookmark *Bookmark) error {
+		return bookmark.resolveIn(mm)
+	})
+}
+
+// Refresh resolves every bookmark, pointer chains included, on proc.
+// It returns the names of the bookmarks that do not resolve.
+func (b *Bookmarks) Refresh(proc Process) []string {
+	return b.resolveAll(func(bookmark *Bookmark) error {
+		return bookmark.resolve(proc)
+	})
+}
+
+// MarshalJSON stores the bookmarks as a list of name and reference pairs
+func (b *Bookmarks) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.List())
+}
+
+// UnmarshalJSON replaces the bookmarks with the stored ones, unresolved until the next Rebase or Refresh
+func (b *Bookmarks) UnmarshalJSON(data []byte) error {
+	var list []Bookmark
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.items = make(map[string]Bookmark, len(list))
+	for _, bookmark := range list {
+		bookmark.Address = 0
+		b.items[bookmark.Name] = bookmark
+	}
+	return nil
+}
+
+// resolveAll re-resolves every bookmark with resolve and returns the sorted names of those that failed
+func (b *Bookmarks) resolveAll(resolve func(*Bookmark) error) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var unresolved []string
+	for name, bookmark := range b.items {
+		if err := resolve(&bookmark); err != nil {
+			unresolved = append(unresolved, name)
+		}
+		b.items[name] = bookmark
+	}
+	sort.Strings(unresolved)
+	return unresolved
+}
+
+// resolveIn computes the address of a bookmark in the given memory map
+func (bookmark *Bookmark) resolveIn(mm []memory_map.MemoryMapItem) error {
+	address, err := bookmark.Ref.ResolveIn(mm)
+	bookmark.Address = address
+	if err != nil {
+		return fmt.Errorf("bookmark %s: %w", bookmark.Name, err)
+	}
+	return nil
+}
+
+// resolve computes the address of a bookmark on proc
+func (bookmark *Bookmark) resolve(proc Process) error {
+	address, err := bookmark.Ref.Resolve(proc)
+	bookmark.Address = address
+	if err != nil {
+		return fmt.Errorf("bookmark %s: %w", bookmark.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package process
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ByteOrderer is implemented by processes, dumps and blobs that know the byte order of the
+// target memory. Typed reads, pod decoding and pointer detection follow it, so big-endian
+// memory (e.g. console dumps or an emulator's guest memory) reads correctly.
+type ByteOrderer interface {
+	ByteOrder() binary.ByteOrder
+}
+
+// ByteOrderOf returns the byte order of v, little-endian when v does not implement ByteOrderer
+func ByteOrderOf(v any) binary.ByteOrder {
+	if bo, ok := v.(ByteOrderer); ok {
+		if order := bo.ByteOrder(); order != nil {
+			return order
+		}
+	}
+	return binary.LittleEndian
+}
+
+// IsNativeByteOrder reports whether order matches the byte order of the host, i.e. whether
+// memory in that order can be copied into Go values as is
+func IsNativeByteOrder(order binary.ByteOrder) bool {
+	probe := []byte{1, 2}
+	return order.Uint16(probe) == binary.NativeEndian.Uint16(probe)
+}
+
+// ParseByteOrder parses "little" ("le") or "big" ("be"), case-insensitive.
+// The empty string is little-endian.
+func ParseByteOrder(s string) (binary.ByteOrder, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "little", "le", "littleendian":
+		return binary.LittleEndian, nil
+	case "big", "be", "bigendian":
+		return binary.BigEndian, nil
+	}
+	return nil, fmt.Errorf("unknown byte order: %q", s)
+}
+
+// ByteOrderName returns the name ParseByteOrder accepts for order, "little" or "big"
+func ByteOrderName(order binary.ByteOrder) string {
+	if order != nil && order.Uint16([]byte{0, 1}) == 1 {
+		return "big"
+	}
+	return "little"
+}
+
+// SwapScalarBytes converts the raw bytes of a value of kind k between the target byte order
+// and the host byte order in place. Complex numbers swap their two halves separately, every
+// other kind (structs, arrays, pointers) is left untouched.
+func SwapScalarBytes(k reflect.Kind, data []byte) {
+	switch k {
+	case reflect.Int16, reflect.Uint16, reflect.Int32, reflect.Uint32, reflect.Int64, reflect.Uint64,
+		reflect.Int, reflect.Uint, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		reverseBytes(data)
+	case reflect.Complex64, reflect.Complex128:
+		half := len(data) / 2
+		reverseBytes(data[:half])
+		reverseBytes(data[half:])
+	}
+}
+
+func reverseBytes(data []byte) {
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+}
@@ -0,0 +1,518 @@
+package process
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the zero CacheOptions fields
+const (
+	DefaultCacheTTL      = 100 * time.Millisecond
+	DefaultCachePageSize = 0x1000
+	DefaultCacheMaxPages = 4096
+	DefaultCacheMaxRead  = 0x10000
+)
+
+// CacheOptions configures a CachedProcess
+type CacheOptions struct {
+	// TTL is how long a cached page is returned before it is read again, DefaultCacheTTL if 0
+	TTL time.Duration
+
+	// PageSize is the unit read and cached, a power of two, DefaultCachePageSize if 0
+	PageSize uint64
+
+	// MaxPages bounds the number of cached pages, DefaultCacheMaxPages if 0. When the cache
+	// is full the expired pages are dropped, then arbitrary ones.
+	MaxPages int
+
+	// MaxRead is the largest read served through the cache, DefaultCacheMaxRead if 0; larger
+	// reads go straight to the process
+	MaxRead ProcessMemorySize
+}
+
+// CacheStats counts the page lookups of a CachedProcess
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Pages  int // Pages currently cached
+}
+
+// cachedPage is a page of memory and when it was read
+type cachedPage struct {
+	data []byte
+	read time.Time
+}
+
+// CachedProcess wraps a Process and caches the pages it reads for a short TTL, so code doing
+// many small reads of the same structures, e.g. pod decoding nested structs or validating the
+// pointers of a struct, reads each page once. ReadMemory, ReadMemoryPartial, the typed reads
+// and ReadBlob(s) go through the cache; writes through the wrapper invalidate the pages they
+// touch. Writes made by the target itself are only seen once the TTL expires or after
+// Invalidate. The remaining methods are passed through; use Unwrap to reach platform specific
+// methods of the wrapped process.
+type CachedProcess struct {
+	Process
+	options CacheOptions
+	order   binary.ByteOrder
+
+	mu    sync.Mutex
+	pages map[ProcessMemoryAddress]cachedPage
+
+	hits, misses atomic.Uint64
+}
+
+// NewCached wraps proc with a read cache configured by options
+func NewCached(proc Process, options CacheOptions) *CachedProcess {
+	if options.TTL <= 0 {
+		options.TTL = DefaultCacheTTL
+	}
+	if options.PageSize == 0 || options.PageSize&(options.PageSize-1) != 0 {
+		options.PageSize = DefaultCachePageSize
+	}
+	if options.MaxPages <= 0 {
+		options.MaxPages = DefaultCacheMaxPages
+	}
+	if options.MaxRead == 0 {
+		options.MaxRead = DefaultCacheMaxRead
+	}
+	return &CachedProcess{
+		Process: proc,
+		options: options,
+		order:   ByteOrderOf(proc),
+		pages:   make(map[ProcessMemoryAddress]cachedPage),
+	}
+}
+
+// Unwrap returns the wrapped process
+func (p *CachedProcess) Unwrap() Process {
+	return p.Process
+}
+
+// ByteOrder returns the byte order of the wrapped process
+func (p *CachedProcess) ByteOrder() binary.ByteOrder {
+	return p.order
+}
+
+// ProtectMemory changes the protection of a range of the wrapped process, see process.ProtectMemory
+func (p *CachedProcess) ProtectMemory(addr ProcessMemoryAddress, size ProcessMemorySize, perms string) (string, error) {
+	return ProtectMemory(p.Process, addr, size, perms)
+}
+
+// GetModules returns the modules of the wrapped process, see process.GetModules
+func (p *CachedProcess) GetModules() ([]Module, error) {
+	return GetModules(p.Process)
+}
+
+// GetThreads returns the threads of the wrapped process, see process.GetThreads
+func (p *CachedProcess) GetThreads() ([]Thread, error) {
+	return GetThreads(p.Process)
+}
+
+// AllocateMemory allocates memory in the wrapped process, see process.AllocateMemory
+func (p *CachedProcess) AllocateMemory(size ProcessMemorySize, perms string) (ProcessMemoryAddress, error) {
+	addr, err := AllocateMemory(p.Process, size, perms)
+	if err == nil {
+		p.InvalidateRange(addr, size)
+	}
+	return addr, err
+}
+
+// FreeMemory releases memory of the wrapped process, see process.FreeMemory
+func (p *CachedProcess) FreeMemory(addr ProcessMemoryAddress, size ProcessMemorySize) error {
+	p.InvalidateRange(addr, size)
+	return FreeMemory(p.Process, addr, size)
+}
+
+// Suspend pauses the wrapped process, see process.Suspend
+func (p *CachedProcess) Suspend() error {
+	return Suspend(p.Process)
+}
+
+// Resume lets the wrapped process run again, see process.Resume
+func (p *CachedProcess) Resume() error {
+	return Resume(p.Process)
+}
+
+// CallFunction calls a function inside the wrapped process, see process.CallFunction. The
+// function may write anywhere, so the cache is dropped.
+func (p *CachedProcess) CallFunction(addr ProcessMemoryAddress, args ...uint64) (CallResult, error) {
+	defer p.Invalidate()
+	return CallFunction(p.Process, addr, args...)
+}
+
+// Invalidate drops every cached page, e.g. after the target ran for a while
+func (p *CachedProcess) Invalidate() {
+	p.mu.Lock()
+	clear(p.pages)
+	p.mu.Unlock()
+}
+
+// InvalidateRange drops the cached pages overlapping the size bytes at addr
+func (p *CachedProcess) InvalidateRange(addr ProcessMemoryAddress, size ProcessMemorySize) {
+	if size == 0 {
+		return
+	}
+	mask := ProcessMemoryAddress(p.options.PageSize - 1)
+	last := (addr + ProcessMemoryAddress(size) - 1) &^ mask
+
+	p.mu.Lock()
+	for page := addr &^ mask; page <= last; page += ProcessMemoryAddress(p.options.PageSize) {
+		delete(p.pages, page)
+		if page == last {
+			break // Don't wrap around at the top of the address space
+		}
+	}
+	p.mu.Unlock()
+}
+
+// Stats returns the number of page hits and misses since the cache was created
+func (p *CachedProcess) Stats() CacheStats {
+	p.mu.Lock()
+	pages := len(p.pages)
+	p.mu.Unlock()
+	return CacheStats{Hits: p.hits.Load(), Misses: p.misses.Load(), Pages: pages}
+}
+
+// page returns the cached page at the page aligned address, reading it if it is not cached
+// or expired
+func (p *CachedProcess) page(addr ProcessMemoryAddress) ([]byte, error) {
+	now := time.Now()
+
+	p.mu.Lock()
+	cached, ok := p.pages[addr]
+	p.mu.Unlock()
+	if ok && now.Sub(cached.read) < p.options.TTL {
+		p.hits.Add(1)
+		return cached.data, nil
+	}
+	p.misses.Add(1)
+
+	data, err := p.Process.ReadMemory(addr, ProcessMemorySize(p.options.PageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	if len(p.pages) >= p.options.MaxPages {
+		p.evict(now)
+	}
+	p.pages[addr] = cachedPage{data: data, read: now}
+	p.mu.Unlock()
+	return data, nil
+}
+
+// evict makes room for a page, dropping the expired pages and then arbitrary ones. It is
+// called with mu held.
+func (p *CachedProcess) evict(now time.Time) {
+	for addr, page := range p.pages {
+		if now.Sub(page.read) >= p.options.TTL {
+			delete(p.pages, addr)
+		}
+	}
+	for addr := range p.pages {
+		if len(p.pages) < p.options.MaxPages {
+			break
+		}
+		delete(p.pages, addr)
+	}
+}
+
+// ReadMemory reads size bytes at addr from the cached pages. Reads larger than MaxRead, and
+// reads touching a page that can't be read whole (e.g. a region ending mid-page in a dump),
+// are passed to the wrapped process.
+func (p *CachedProcess) ReadMemory(addr ProcessMemoryAddress, size ProcessMemorySize) ([]byte, error) {
+	if size == 0 || size > p.options.MaxRead {
+		return p.Process.ReadMemory(addr, size)
+	}
+
+	mask := ProcessMemoryAddress(p.options.PageSize - 1)
+	end := addr + ProcessMemoryAddress(size)
+	if end < addr {
+		return p.Process.ReadMemory(addr, size) // Wraps around, let the process fail it
+	}
+
+	result := make([]byte, 0, size)
+	for cur := addr; cur < end; {
+		page := cur &^ mask
+		data, err := p.page(page)
+		if err != nil {
+			return p.Process.ReadMemory(addr, size)
+		}
+		n := min(end, page+ProcessMemoryAddress(len(data))) - cur
+		result = append(result, data[cur-page:cur-page+n]...)
+		cur += n
+	}
+	return result, nil
+}
+
+// ReadMemoryPartial reads size bytes at addr through the cache, see ReadPartial
+func (p *CachedProcess) ReadMemoryPartial(addr ProcessMemoryAddress, size ProcessMemorySize) PartialRead {
+	return ReadPartial(p.ReadMemory, addr, size)
+}
+
+// WriteMemory invalidates the pages of the range and writes it to the wrapped process
+func (p *CachedProcess) WriteMemory(addr ProcessMemoryAddress, data []byte) error {
+	p.InvalidateRange(addr, ProcessMemorySize(len(data)))
+	return p.Process.WriteMemory(addr, data)
+}
+
+// UpdateMemoryMap refreshes the memory map of the wrapped process and drops the cached pages,
+// which may belong to regions that were unmapped
+func (p *CachedProcess) UpdateMemoryMap() error {
+	p.Invalidate()
+	return p.Process.UpdateMemoryMap()
+}
+
+// Open opens pid in the wrapped process and drops the cached pages
+func (p *CachedProcess) Open(pid ProcessID) error {
+	p.Invalidate()
+	return p.Process.Open(pid)
+}
+
+// Close drops the cached pages and closes the wrapped process
+func (p *CachedProcess) Close() error {
+	p.Invalidate()
+	return p.Process.Close()
+}
+
+// readUint reads an unsigned integer of size bytes in the byte order of the process
+func (p *CachedProcess) readUint(addr ProcessMemoryAddress, size int) (uint64, error) {
+	data, err := p.ReadMemory(addr, ProcessMemorySize(size))
+	if err != nil {
+		return 0, err
+	}
+	return decodeUint(data, p.order), nil
+}
+
+func (p *CachedProcess) ReadUINT8(addr ProcessMemoryAddress) (uint8, error) {
+	v, err := p.readUint(addr, 1)
+	return uint8(v), err
+}
+
+func (p *CachedProcess) ReadUINT16(addr ProcessMemoryAddress) (uint16, error) {
+	v, err := p.readUint(addr, 2)
+	return uint16(v), err
+}
+
+func (p *CachedProcess) ReadUINT32(addr ProcessMemoryAddress) (uint32, error) {
+	v, err := p.readUint(addr, 4)
+	return uint32(v), err
+}
+
+func (p *CachedProcess) ReadUINT64(addr ProcessMemoryAddress) (uint64, error) {
+	return p.readUint(addr, 8)
+}
+
+func (p *CachedProcess) ReadINT8(addr ProcessMemoryAddress) (int8, error) {
+	v, err := p.readUint(addr, 1)
+	return int8(v), err
+}
+
+func (p *CachedProcess) ReadINT16(addr ProcessMemoryAddress) (int16, error) {
+	v, err := p.readUint(addr, 2)
+	return int16(v), err
+}
+
+func (p *CachedProcess) ReadINT32(addr ProcessMemoryAddress) (int32, error) {
+	v, err := p.readUint(addr, 4)
+	return int32(v), err
+}
+
+func (p *CachedProcess) ReadINT64(addr ProcessMemoryAddress) (int64, error) {
+	v, err := p.readUint(addr, 8)
+	return int64(v), err
+}
+
+func (p *CachedProcess) ReadFLOAT32(addr ProcessMemoryAddress) (float32, error) {
+	v, err := p.readUint(addr, 4)
+	return math.Float32frombits(uint32(v)), err
+}
+
+func (p *CachedProcess) ReadFLOAT64(addr ProcessMemoryAddress) (float64, error) {
+	v, err := p.readUint(addr, 8)
+	return math.Float64frombits(v), err
+}
+
+func (p *CachedProcess) ReadNTS(addr ProcessMemoryAddress, maxLength ProcessMemorySize) (string, error) {
+	if maxLength == 0 {
+		return "", nil
+	}
+	data, err := p.ReadMemory(addr, maxLength)
+	if err != nil {
+		return "", err
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	return string(data), nil
+}
+
+func (p *CachedProcess) ReadPOINTER(addr ProcessMemoryAddress) (ProcessMemoryAddress, error) {
+	v, err := p.readUint(addr, 8)
+	return ProcessMemoryAddress(v), err
+}
+
+func (p *CachedProcess) ReadPOINTER2(addr ProcessMemoryAddress) ProcessMemoryAddress {
+	v, _ := p.ReadPOINTER(addr)
+	return v
+}
+
+// ReadPointers reads count pointers at base through the cache and returns the valid ones
+func (p *CachedProcess) ReadPointers(base ProcessMemoryAddress, count int) ([]ProcessMemoryAddress, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid count for pointers")
+	}
+	data, err := p.ReadMemory(base, ProcessMemorySize(count*8))
+	if err != nil {
+		return nil, err
+	}
+	var results []ProcessMemoryAddress
+	for i := range count {
+		ptr := ProcessMemoryAddress(p.order.Uint64(data[i*8:]))
+		if p.IsValidAddress(ptr) {
+			results = append(results, ptr)
+		}
+	}
+	return results, nil
+}
+
+// ReadBlob reads size bytes at addr through the cache. The Offset methods of the blob decode
+// its bytes; its Read methods read the process through the cache.
+func (p *CachedProcess) ReadBlob(addr ProcessMemoryAddress, size ProcessMemorySize) (ProcessReadOffset, error) {
+	data, err := p.ReadMemory(addr, size)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedBlob{CachedProcess: p, base: addr, data: data}, nil
+}
+
+func (p *CachedProcess) ReadBlobs(list []ProcessMemoryAddress, size ProcessMemorySize) []ReadBlobsResult {
+	results := make([]ReadBlobsResult, len(list))
+	for i, addr := range list {
+		blob, err := p.ReadBlob(addr, size)
+		results[i] = ReadBlobsResult{Address: addr, Blob: blob, Err: err}
+	}
+	return results
+}
+
+// ReadPointerChain follows the pointers at base+offsets[0], ... through the cache and reads
+// size bytes at the last address, see ReadPointerChainTransform
+func (p *CachedProcess) ReadPointerChain(base ProcessMemoryAddress, size ProcessMemorySize, offsets ...ProcessMemorySize) (ProcessReadOffset, error) {
+	return ReadPointerChainTransform(p, nil, base, size, offsets...)
+}
+
+func (p *CachedProcess) ReadPointerChainDebug(base ProcessMemoryAddress, size ProcessMemorySize, offsets ...ProcessMemorySize) (ProcessReadOffset, error) {
+	return p.ReadPointerChain(base, size, offsets...)
+}
+
+// cachedBlob is a ProcessReadOffset over bytes read through a CachedProcess
+type cachedBlob struct {
+	*CachedProcess
+	base ProcessMemoryAddress
+	data []byte
+}
+
+func (b *cachedBlob) Data() []byte {
+	return b.data
+}
+
+// at returns the n bytes at offset of the blob
+func (b *cachedBlob) at(offset ProcessMemoryAddress, n int) ([]byte, error) {
+	if uint64(offset)+uint64(n) > uint64(len(b.data)) {
+		return nil, fmt.Errorf("offset 0x%x out of bounds of blob of %d bytes", uint64(offset), len(b.data))
+	}
+	return b.data[offset : int(offset)+n], nil
+}
+
+// offsetUint decodes an unsigned integer of size bytes at offset
+func (b *cachedBlob) offsetUint(offset ProcessMemoryAddress, size int) (uint64, error) {
+	data, err := b.at(offset, size)
+	if err != nil {
+		return 0, err
+	}
+	return decodeUint(data, b.order), nil
+}
+
+func (b *cachedBlob) OffsetUINT8(offset ProcessMemoryAddress) (uint8, error) {
+	v, err := b.offsetUint(offset, 1)
+	return uint8(v), err
+}
+
+func (b *cachedBlob) OffsetUINT16(offset ProcessMemoryAddress) (uint16, error) {
+	v, err := b.offsetUint(offset, 2)
+	return uint16(v), err
+}
+
+func (b *cachedBlob) OffsetUINT32(offset ProcessMemoryAddress) (uint32, error) {
+	v, err := b.offsetUint(offset, 4)
+	return uint32(v), err
+}
+
+func (b *cachedBlob) OffsetUINT64(offset ProcessMemoryAddress) (uint64, error) {
+	return b.offsetUint(offset, 8)
+}
+
+func (b *cachedBlob) OffsetINT8(offset ProcessMemoryAddress) (int8, error) {
+	v, err := b.offsetUint(offset, 1)
+	return int8(v), err
+}
+
+func (b *cachedBlob) OffsetINT16(offset ProcessMemoryAddress) (int16, error) {
+	v, err := b.offsetUint(offset, 2)
+	return int16(v), err
+}
+
+func (b *cachedBlob) OffsetINT32(offset ProcessMemoryAddress) (int32, error) {
+	v, err := b.offsetUint(offset, 4)
+	return int32(v), err
+}
+
+func (b *cachedBlob) OffsetINT64(offset ProcessMemoryAddress) (int64, error) {
+	v, err := b.offsetUint(offset, 8)
+	return int64(v), err
+}
+
+func (b *cachedBlob) OffsetFLOAT32(offset ProcessMemoryAddress) (float32, error) {
+	v, err := b.offsetUint(offset, 4)
+	return math.Float32frombits(uint32(v)), err
+}
+
+func (b *cachedBlob) OffsetFLOAT64(offset ProcessMemoryAddress) (float64, error) {
+	v, err := b.offsetUint(offset, 8)
+	return math.Float64frombits(v), err
+}
+
+func (b *cachedBlob) OffsetNTS(offset ProcessMemoryAddress, maxLength ProcessMemorySize) (string, error) {
+	if offset > ProcessMemoryAddress(len(b.data)) {
+		return "", fmt.Errorf("offset 0x%x out of bounds of blob of %d bytes", uint64(offset), len(b.data))
+	}
+	data := b.data[offset:]
+	data = data[:min(len(data), int(maxLength))]
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	return string(data), nil
+}
+
+func (b *cachedBlob) OffsetPOINTER(offset ProcessMemoryAddress) (ProcessMemoryAddress, error) {
+	v, err := b.offsetUint(offset, 8)
+	return ProcessMemoryAddress(v), err
+}
+
+func (b *cachedBlob) OffsetPOINTER2(offset ProcessMemoryAddress) ProcessMemoryAddress {
+	v, _ := b.OffsetPOINTER(offset)
+	return v
+}
+
+func (b *cachedBlob) OffsetBlob(offset ProcessMemoryAddress, size ProcessMemorySize) (ProcessReadOffset, error) {
+	data, err := b.at(offset, int(size))
+	if err != nil {
+		return nil, err
+	}
+	return &cachedBlob{CachedProcess: b.CachedProcess, base: b.base + offset, data: data}, nil
+}
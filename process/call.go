@@ -0,0 +1,68 @@
+package process
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// MaxCallArgs is the number of arguments CallFunction passes
+const MaxCallArgs = 6
+
+// CallResult holds the return registers of a function called in the target
+type CallResult struct {
+	Int   uint64 // Integer and pointer return register (RAX)
+	Float uint64 // Low 64 bits of the floating point return register (XMM0)
+}
+
+// FunctionCaller is implemented by processes that can call a function inside the target, e.g.
+// an existing getter, instead of decoding every structure it reads
+type FunctionCaller interface {
+	// CallFunction calls the function at addr with up to MaxCallArgs integer or pointer
+	// arguments in the C calling convention of the platform and returns its return registers
+	CallFunction(addr ProcessMemoryAddress, args ...uint64) (CallResult, error)
+}
+
+// CallFunction calls a function inside proc, see FunctionCaller.
+// It fails for processes that can't run code (dumps), ErrReadOnly for read-only wrappers.
+func CallFunction(proc Process, addr ProcessMemoryAddress, args ...uint64) (CallResult, error) {
+	caller, ok := proc.(FunctionCaller)
+	if !ok {
+		return CallResult{}, fmt.Errorf("%T does not support calling functions", proc)
+	}
+	if len(args) > MaxCallArgs {
+		return CallResult{}, fmt.Errorf("at most %d arguments can be passed, got %d", MaxCallArgs, len(args))
+	}
+	return caller.CallFunction(addr, args...)
+}
+
+// CallReturn are the return types CallFunctionAs converts to
+type CallReturn interface {
+	~bool | ~int8 | ~int16 | ~int32 | ~int64 | ~int | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uint | ~uintptr | ~float32 | ~float64
+}
+
+// CallFunctionAs calls a function inside proc like CallFunction and returns its result as T:
+// floats from the floating point return register, integers truncated to the size of T
+// (ProcessMemoryAddress for returned pointers), bools from the low byte
+func CallFunctionAs[T CallReturn](proc Process, addr ProcessMemoryAddress, args ...uint64) (T, error) {
+	var v T
+	result, err := CallFunction(proc, addr, args...)
+	if err != nil {
+		return v, err
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	switch rv.Kind() {
+	case reflect.Bool:
+		rv.SetBool(uint8(result.Int) != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		rv.SetInt(int64(result.Int))
+	case reflect.Float32:
+		rv.SetFloat(float64(math.Float32frombits(uint32(result.Float))))
+	case reflect.Float64:
+		rv.SetFloat(math.Float64frombits(result.Float))
+	default:
+		rv.SetUint(result.Int)
+	}
+	return v, nil
+}
@@ -0,0 +1,174 @@
+package process
+
+import (
+	"fmt"
+	"sort"
+
+	"gomem/process/memory_map"
+)
+
+// compareChunkSize is the size of the reads CompareProcesses makes from each process
+const compareChunkSize = 1 << 20
+
+// MemoryDiff is a range of bytes that differs between two processes
+type MemoryDiff struct {
+	Module   string               // Base name of the region path, e.g. "game.exe" or "[heap]"
+	Offset   uint64               // Offset of the range from the lowest address mapped from Module
+	AddressA ProcessMemoryAddress // Start of the range in the first process
+	AddressB ProcessMemoryAddress // Start of the range in the second process
+	Size     uint64
+}
+
+// String returns a one line description of the range
+func (d MemoryDiff) String() string {
+	return fmt.Sprintf("%s+0x%X (0x%X / 0x%X) 0x%X bytes", d.Module, d.Offset, uint64(d.AddressA), uint64(d.AddressB), d.Size)
+}
+
+// MemoryComparison is the result of CompareProcesses
+type MemoryComparison struct {
+	Diffs      []MemoryDiff               // Differing ranges, in module and offset order
+	Compared   uint64                     // Number of bytes compared
+	Unreadable uint64                     // Bytes of aligned regions that could not be read in either process
+	OnlyInA    []memory_map.MemoryMapItem // Regions of the first process without a counterpart in the second
+	OnlyInB    []memory_map.MemoryMapItem // Regions of the second process without a counterpart in the first
+}
+
+// DiffBytes returns the total size of the differing ranges
+func (c *MemoryComparison) DiffBytes() uint64 {
+	var total uint64
+	for _, d := range c.Diffs {
+		total += d.Size
+	}
+	return total
+}
+
+// regionKey places a region relative to the lowest address mapped from the same path,
+// which is stable across two instances of a program despite ASLR
+type regionKey struct {
+	module string
+	offset uint64
+}
+
+// CompareProcesses compares the memory of two processes running the same program (or two
+// dumps of it), e.g. a modded and a clean instance, and reports the ranges that differ.
+//
+// Regions are aligned by module and offset: a region of procA is compared with the region of
+// procB mapped from the same path at the same offset from that path's lowest address, so
+// executables, libraries, [heap] and [stack] line up despite ASLR. Anonymous regions have no
+// stable identity and are not compared. When aligned regions differ in size the common prefix
+// is compared. filter selects the regions of procA to compare, nil for all readable regions.
+func CompareProcesses(procA, procB Process, filter memory_map.RegionFilter) (*MemoryComparison, error) {
+	mmA, err := procA.GetMemoryMap()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory map of the first process: %w", err)
+	}
+	mmB, err := procB.GetMemoryMap()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory map of the second process: %w", err)
+	}
+
+	regionsA := alignedRegions(mmA)
+	regionsB := alignedRegions(mmB)
+
+	keys := make([]regionKey, 0, len(regionsA))
+	for key, region := range regionsA {
+		if filter != nil && !filter(region) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].module != keys[j].module {
+			return keys[i].module < keys[j].module
+		}
+		return keys[i].offset < keys[j].offset
+	})
+
+	result := &MemoryComparison{}
+	for _, key := range keys {
+		regionA := regionsA[key]
+		regionB, ok := regionsB[key]
+		if !ok {
+			result.OnlyInA = append(result.OnlyInA, regionA)
+			continue
+		}
+		compareRegion(procA, procB, key, regionA, regionB, result)
+	}
+
+	for key, region := range regionsB {
+		if _, ok := regionsA[key]; !ok && (filter == nil || filter(region)) {
+			result.OnlyInB = append(result.OnlyInB, region)
+		}
+	}
+	sort.Slice(result.OnlyInB, func(i, j int) bool {
+		return result.OnlyInB[i].Address < result.OnlyInB[j].Address
+	})
+
+	return result, nil
+}
+
+// alignedRegions keys the readable, path-backed regions of a memory map by module and offset
+func alignedRegions(mm []memory_map.MemoryMapItem) map[regionKey]memory_map.MemoryMapItem {
+	bases := make(map[string]uint64)
+	for _, item := range mm {
+		if item.Path == "" {
+			continue
+		}
+		if base, ok := bases[item.Path]; !ok || item.Address < base {
+			bases[item.Path] = item.Address
+		}
+	}
+
+	regions := make(map[regionKey]memory_map.MemoryMapItem)
+	for _, item := range mm {
+		if item.Path == "" || len(item.Perms) == 0 || !item.IsReadable() {
+			continue
+		}
+		module := PointerChain{Module: item.Path}.Normalized().Module
+		regions[regionKey{module: module, offset: item.Address - bases[item.Path]}] = item
+	}
+	return regions
+}
+
+// compareRegion compares two aligned regions chunk by chunk and appends the differing ranges
+func compareRegion(procA, procB Process, key regionKey, regionA, regionB memory_map.MemoryMapItem, result *MemoryComparison) {
+	size := uint64(min(regionA.Size, regionB.Size))
+
+	for off := uint64(0); off < size; off += compareChunkSize {
+		n := min(size-off, compareChunkSize)
+
+		dataA, errA := procA.ReadMemory(ProcessMemoryAddress(regionA.Address+off), ProcessMemorySize(n))
+		dataB, errB := procB.ReadMemory(ProcessMemoryAddress(regionB.Address+off), ProcessMemorySize(n))
+		if errA != nil || errB != nil {
+			result.Unreadable += n
+			continue
+		}
+		n = uint64(min(len(dataA), len(dataB)))
+		result.Compared += n
+
+		for i := uint64(0); i < n; i++ {
+			if dataA[i] == dataB[i] {
+				continue
+			}
+			diffOffset := off + i
+
+			// Extend the previous range when it ends right here, ranges may span chunks
+			if last := len(result.Diffs) - 1; last >= 0 {
+				prev := &result.Diffs[last]
+				if prev.Module == key.module && prev.Offset+prev.Size == key.offset+diffOffset &&
+					prev.AddressB+ProcessMemoryAddress(prev.Size) == ProcessMemoryAddress(regionB.Address+diffOffset) {
+					prev.Size++
+					continue
+				}
+			}
+
+			result.Diffs = append(result.Diffs, MemoryDiff{
+				Module:   key.module,
+				Offset:   key.offset + diffOffset,
+				AddressA: ProcessMemoryAddress(regionA.Address + diffOffset),
+				AddressB: ProcessMemoryAddress(regionB.Address + diffOffset),
+				Size:     1,
+			})
+		}
+	}
+}
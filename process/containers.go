@@ -0,0 +1,98 @@
+package process
+
+import (
+	"fmt"
+)
+
+// containerPointerAlignment is the alignment required of node and element pointers.
+// Heap allocations are at least pointer aligned, anything else is treated as garbage.
+const containerPointerAlignment = 8
+
+// isPlausiblePointer reports whether ptr can be the address of a node or element
+func isPlausiblePointer(proc Process, ptr ProcessMemoryAddress) bool {
+	return ptr != 0 && ptr%containerPointerAlignment == 0 && proc.IsValidAddress(ptr)
+}
+
+// ReadLinkedList walks a singly linked list starting at the node at head, reading a T at each
+// node and following the next pointer stored at node+nextOffset.
+// The walk stops at a null next pointer, when it comes back to a node already visited (so
+// circular lists and lists with a sentinel head are read once), or after max nodes (max <= 0
+// means no limit other than the cycle check). A next pointer that is unaligned or outside
+// mapped memory stops the walk with an error; the nodes read so far are returned with it.
+// It returns the values and the address of each node.
+func ReadLinkedList[T any](proc Process, head ProcessMemoryAddress, nextOffset ProcessMemorySize, max int) ([]T, []ProcessMemoryAddress, error) {
+	var values []T
+	var addresses []ProcessMemoryAddress
+
+	if head == 0 {
+		return nil, nil, nil
+	}
+	if !isPlausiblePointer(proc, head) {
+		return nil, nil, fmt.Errorf("invalid list head 0x%X", head)
+	}
+
+	visited := make(map[ProcessMemoryAddress]struct{})
+	for node := head; node != 0; {
+		if max > 0 && len(values) >= max {
+			break
+		}
+		if _, seen := visited[node]; seen {
+			break
+		}
+		visited[node] = struct{}{}
+
+		value, err := Read[T](proc, node)
+		if err != nil {
+			return values, addresses, fmt.Errorf("failed to read list node at 0x%X: %w", node, err)
+		}
+		values = append(values, value)
+		addresses = append(addresses, node)
+
+		next, err := proc.ReadPOINTER(node + ProcessMemoryAddress(nextOffset))
+		if err != nil {
+			return values, addresses, fmt.Errorf("failed to read next pointer of node 0x%X: %w", node, err)
+		}
+		if next != 0 && !isPlausiblePointer(proc, next) {
+			return values, addresses, fmt.Errorf("node 0x%X has invalid next pointer 0x%X", node, next)
+		}
+		node = next
+	}
+
+	return values, addresses, nil
+}
+
+// ReadPtrArray reads count pointers at base and a T at each of them.
+// Null entries are skipped, as are entries that are unaligned, point outside mapped memory
+// or cannot be read, so sparse and partially stale arrays (entity slots) can be read directly.
+// It returns the values and the address each one was read from; an error is only returned
+// when the pointer array itself cannot be read.
+func ReadPtrArray[T any](proc Process, base ProcessMemoryAddress, count int) ([]T, []ProcessMemoryAddress, error) {
+	if count <= 0 {
+		return nil, nil, nil
+	}
+
+	data, err := proc.ReadMemory(base, ProcessMemorySize(count*8))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read pointer array at 0x%X: %w", base, err)
+	}
+
+	order := ByteOrderOf(proc)
+
+	var values []T
+	var addresses []ProcessMemoryAddress
+	for i := 0; i < count; i++ {
+		ptr := ProcessMemoryAddress(order.Uint64(data[i*8:]))
+		if !isPlausiblePointer(proc, ptr) {
+			continue
+		}
+
+		value, err := Read[T](proc, ptr)
+		if err != nil {
+			continue
+		}
+		values = append(values, value)
+		addresses = append(addresses, ptr)
+	}
+
+	return values, addresses, nil
+}
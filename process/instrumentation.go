@@ -0,0 +1,416 @@
+package process
+
+import (
+	"context"
+	"encoding/binary"
+	"iter"
+	"sync"
+	"time"
+
+	"gomem/process/memory_map"
+)
+
+// Operation is the kind of memory operation reported to an Instrumentation
+type Operation string
+
+const (
+	OperationRead  Operation = "read"
+	OperationWrite Operation = "write"
+	OperationScan  Operation = "scan"
+)
+
+// OperationEvent describes one completed memory operation
+type OperationEvent struct {
+	Operation Operation
+	Address   ProcessMemoryAddress      // Start address, 0 for scans
+	Size      ProcessMemorySize         // Bytes requested, pattern length for scans
+	Region    *memory_map.MemoryMapItem // Region containing Address, nil if unknown or for scans
+	Matches   int                       // Number of matches, scans only
+	Duration  time.Duration
+	Err       error
+}
+
+// Instrumentation receives an event for every read, write and scan of an InstrumentedProcess.
+// Observe is called synchronously from the goroutine performing the operation, possibly
+// from many goroutines at once, so implementations must be fast and safe for concurrent use.
+type Instrumentation interface {
+	Observe(event OperationEvent)
+}
+
+// InstrumentedProcess wraps a Process and reports its reads, writes and scans to an Instrumentation.
+// ReadMemory(Partial), WriteMemory, the typed reads, ReadBlob(s) and the scans are reported; the remaining
+// methods are passed through. Use Unwrap to reach platform specific methods of the wrapped process.
+type InstrumentedProcess struct {
+	Process
+	hooks Instrumentation
+
+	mu sync.RWMutex
+	mm []memory_map.MemoryMapItem // sorted snapshot used to resolve the region of each read
+}
+
+// Instrument wraps proc so that its operations are reported to hooks
+func Instrument(proc Process, hooks Instrumentation) *InstrumentedProcess {
+	p := &InstrumentedProcess{Process: proc, hooks: hooks}
+	p.refreshRegions()
+	return p
+}
+
+// Unwrap returns the wrapped process
+func (p *InstrumentedProcess) Unwrap() Process {
+	return p.Process
+}
+
+// ByteOrder returns the byte order of the wrapped process
+func (p *InstrumentedProcess) ByteOrder() binary.ByteOrder {
+	return ByteOrderOf(p.Process)
+}
+
+// ProtectMemory changes the protection of a range of the wrapped process, see process.ProtectMemory
+func (p *InstrumentedProcess) ProtectMemory(addr ProcessMemoryAddress, size ProcessMemorySize, perms string) (string, error) {
+	previous, err := ProtectMemory(p.Process, addr, size, perms)
+	if err == nil {
+		p.refreshRegions()
+	}
+	return previous, err
+}
+
+// GetModules returns the modules of the wrapped process, see process.GetModules
+func (p *InstrumentedProcess) GetModules() ([]Module, error) {
+	return GetModules(p.Process)
+}
+
+// AllocateMemory allocates memory inside the wrapped process, see process.AllocateMemory
+func (p *InstrumentedProcess) AllocateMemory(size ProcessMemorySize, perms string) (ProcessMemoryAddress, error) {
+	addr, err := AllocateMemory(p.Process, size, perms)
+	if err == nil {
+		p.refreshRegions()
+	}
+	return addr, err
+}
+
+// FreeMemory releases memory of the wrapped process, see process.FreeMemory
+func (p *InstrumentedProcess) FreeMemory(addr ProcessMemoryAddress, size ProcessMemorySize) error {
+	err := FreeMemory(p.Process, addr, size)
+	if err == nil {
+		p.refreshRegions()
+	}
+	return err
+}
+
+// Suspend pauses the wrapped process, see process.Suspend
+func (p *InstrumentedProcess) Suspend() error {
+	return Suspend(p.Process)
+}
+
+// Resume lets the wrapped process run again, see process.Resume
+func (p *InstrumentedProcess) Resume() error {
+	return Resume(p.Process)
+}
+
+// CallFunction calls a function inside the wrapped process, see process.CallFunction
+func (p *InstrumentedProcess) CallFunction(addr ProcessMemoryAddress, args ...uint64) (CallResult, error) {
+	return CallFunction(p.Process, addr, args...)
+}
+
+// refreshRegions takes a new memory map snapshot for region resolution
+func (p *InstrumentedProcess) refreshRegions() {
+	mm, _ := p.Process.GetMemoryMap()
+	p.mu.Lock()
+	p.mm = mm
+	p.mu.Unlock()
+}
+
+// region returns the memory map region containing addr
+func (p *InstrumentedProcess) region(addr ProcessMemoryAddress) *memory_map.MemoryMapItem {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return memory_map.IsValidAddress2(uint64(addr), p.mm)
+}
+
+// observe reports a completed operation
+func (p *InstrumentedProcess) observe(op Operation, addr ProcessMemoryAddress, size ProcessMemorySize, start time.Time, err error) {
+	event := OperationEvent{
+		Operation: op,
+		Address:   addr,
+		Size:      size,
+		Duration:  time.Since(start),
+		Err:       err,
+	}
+	if op != OperationScan {
+		event.Region = p.region(addr)
+	}
+	p.hooks.Observe(event)
+}
+
+// observeScan reports a completed scan
+func (p *InstrumentedProcess) observeScan(aob AOB, matches int, start time.Time, err error) {
+	p.observeScanSize(ProcessMemorySize(len(aob.Pattern)), matches, start, err)
+}
+
+// observeScanSize reports a scan for a value of size bytes
+func (p *InstrumentedProcess) observeScanSize(size ProcessMemorySize, matches int, start time.Time, err error) {
+	p.hooks.Observe(OperationEvent{
+		Operation: OperationScan,
+		Size:      size,
+		Matches:   matches,
+		Duration:  time.Since(start),
+		Err:       err,
+	})
+}
+
+// instrumentedRead times a typed read of size bytes
+func instrumentedRead[T any](p *InstrumentedProcess, addr ProcessMemoryAddress, size ProcessMemorySize, read func(ProcessMemoryAddress) (T, error)) (T, error) {
+	start := time.Now()
+	v, err := read(addr)
+	p.observe(OperationRead, addr, size, start, err)
+	return v, err
+}
+
+func (p *InstrumentedProcess) Open(pid ProcessID) error {
+	err := p.Process.Open(pid)
+	p.refreshRegions()
+	return err
+}
+
+func (p *InstrumentedProcess) UpdateMemoryMap() error {
+	err := p.Process.UpdateMemoryMap()
+	p.refreshRegions()
+	return err
+}
+
+func (p *InstrumentedProcess) ReadMemory(addr ProcessMemoryAddress, size ProcessMemorySize) ([]byte, error) {
+	start := time.Now()
+	data, err := p.Process.ReadMemory(addr, size)
+	p.observe(OperationRead, addr, size, start, err)
+	return data, err
+}
+
+func (p *InstrumentedProcess) ReadMemoryPartial(addr ProcessMemoryAddress, size ProcessMemorySize) PartialRead {
+	start := time.Now()
+	result := p.Process.ReadMemoryPartial(addr, size)
+	p.observe(OperationRead, addr, size, start, result.Err())
+	return result
+}
+
+func (p *InstrumentedProcess) WriteMemory(addr ProcessMemoryAddress, data []byte) error {
+	start := time.Now()
+	err := p.Process.WriteMemory(addr, data)
+	p.observe(OperationWrite, addr, ProcessMemorySize(len(data)), start, err)
+	return err
+}
+
+func (p *InstrumentedProcess) ReadUINT8(addr ProcessMemoryAddress) (uint8, error) {
+	return instrumentedRead(p, addr, 1, p.Process.ReadUINT8)
+}
+
+func (p *InstrumentedProcess) ReadUINT16(addr ProcessMemoryAddress) (uint16, error) {
+	return instrumentedRead(p, addr, 2, p.Process.ReadUINT16)
+}
+
+func (p *InstrumentedProcess) ReadUINT32(addr ProcessMemoryAddress) (uint32, error) {
+	return instrumentedRead(p, addr, 4, p.Process.ReadUINT32)
+}
+
+func (p *InstrumentedProcess) ReadUINT64(addr ProcessMemoryAddress) (uint64, error) {
+	return instrumentedRead(p, addr, 8, p.Process.ReadUINT64)
+}
+
+func (p *InstrumentedProcess) ReadINT8(addr ProcessMemoryAddress) (int8, error) {
+	return instrumentedRead(p, addr, 1, p.Process.ReadINT8)
+}
+
+func (p *InstrumentedProcess) ReadINT16(addr ProcessMemoryAddress) (int16, error) {
+	return instrumentedRead(p, addr, 2, p.Process.ReadINT16)
+}
+
+func (p *InstrumentedProcess) ReadINT32(addr ProcessMemoryAddress) (int32, error) {
+	return instrumentedRead(p, addr, 4, p.Process.ReadINT32)
+}
+
+func (p *InstrumentedProcess) ReadINT64(addr ProcessMemoryAddress) (int64, error) {
+	return instrumentedRead(p, addr, 8, p.Process.ReadINT64)
+}
+
+func (p *InstrumentedProcess) ReadFLOAT32(addr ProcessMemoryAddress) (float32, error) {
+	return instrumentedRead(p, addr, 4, p.Process.ReadFLOAT32)
+}
+
+func (p *InstrumentedProcess) ReadFLOAT64(addr ProcessMemoryAddress) (float64, error) {
+	return instrumentedRead(p, addr, 8, p.Process.ReadFLOAT64)
+}
+
+func (p *InstrumentedProcess) ReadPOINTER(addr ProcessMemoryAddress) (ProcessMemoryAddress, error) {
+	return instrumentedRead(p, addr, 8, p.Process.ReadPOINTER)
+}
+
+func (p *InstrumentedProcess) ReadNTS(addr ProcessMemoryAddress, maxLength ProcessMemorySize) (string, error) {
+	start := time.Now()
+	s, err := p.Process.ReadNTS(addr, maxLength)
+	p.observe(OperationRead, addr, maxLength, start, err)
+	return s, err
+}
+
+func (p *InstrumentedProcess) ReadBlob(addr ProcessMemoryAddress, size ProcessMemorySize) (ProcessReadOffset, error) {
+	start := time.Now()
+	blob, err := p.Process.ReadBlob(addr, size)
+	p.observe(OperationRead, addr, size, start, err)
+	return blob, err
+}
+
+// ReadBlobs reports one read per requested address, each with the duration of the whole batch
+func (p *InstrumentedProcess) ReadBlobs(list []ProcessMemoryAddress, size ProcessMemorySize) []ReadBlobsResult {
+	start := time.Now()
+	results := p.Process.ReadBlobs(list, size)
+	for _, result := range results {
+		p.observe(OperationRead, result.Address, size, start, result.Err)
+	}
+	return results
+}
+
+func (p *InstrumentedProcess) Scan(aob AOB) ([]ProcessMemoryAddress, error) {
+	start := time.Now()
+	results, err := p.Process.Scan(aob)
+	p.observeScan(aob, len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanParallel(aob AOB, maxdop uint) ([]ProcessMemoryAddress, error) {
+	start := time.Now()
+	results, err := p.Process.ScanParallel(aob, maxdop)
+	p.observeScan(aob, len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanFirst(aob AOB) (ProcessMemoryAddress, error) {
+	start := time.Now()
+	result, err := p.Process.ScanFirst(aob)
+	p.observeScan(aob, boolToInt(err == nil), start, err)
+	return result, err
+}
+
+func (p *InstrumentedProcess) ScanFirstParallel(aob AOB, maxdop uint) (ProcessMemoryAddress, error) {
+	start := time.Now()
+	result, err := p.Process.ScanFirstParallel(aob, maxdop)
+	p.observeScan(aob, boolToInt(err == nil), start, err)
+	return result, err
+}
+
+func (p *InstrumentedProcess) ScanRange(aob AOB, start, end ProcessMemoryAddress) ([]ProcessMemoryAddress, error) {
+	begin := time.Now()
+	results, err := p.Process.ScanRange(aob, start, end)
+	p.observeScan(aob, len(results), begin, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanRangeParallel(aob AOB, start, end ProcessMemoryAddress, maxdop uint) ([]ProcessMemoryAddress, error) {
+	begin := time.Now()
+	results, err := p.Process.ScanRangeParallel(aob, start, end, maxdop)
+	p.observeScan(aob, len(results), begin, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanInteger(value int64, size uint) ([]ProcessMemoryAddress, error) {
+	start := time.Now()
+	results, err := p.Process.ScanInteger(value, size)
+	p.observeScanSize(ProcessMemorySize(size), len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanFloat(value float64, isFloat32 bool) ([]ProcessMemoryAddress, error) {
+	start := time.Now()
+	results, err := p.Process.ScanFloat(value, isFloat32)
+	p.observeScanSize(floatSize(isFloat32), len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanString(value string, isUTF16 bool) ([]ProcessMemoryAddress, error) {
+	start := time.Now()
+	results, err := p.Process.ScanString(value, isUTF16)
+	p.observeScan(StringAOB(value, isUTF16), len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanIntegerParallel(value int64, size uint, maxdop uint) ([]ProcessMemoryAddress, error) {
+	start := time.Now()
+	results, err := p.Process.ScanIntegerParallel(value, size, maxdop)
+	p.observeScanSize(ProcessMemorySize(size), len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanFloatParallel(value float64, isFloat32 bool, maxdop uint) ([]ProcessMemoryAddress, error) {
+	start := time.Now()
+	results, err := p.Process.ScanFloatParallel(value, isFloat32, maxdop)
+	p.observeScanSize(floatSize(isFloat32), len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanStringParallel(value string, isUTF16 bool, maxdop uint) ([]ProcessMemoryAddress, error) {
+	start := time.Now()
+	results, err := p.Process.ScanStringParallel(value, isUTF16, maxdop)
+	p.observeScan(StringAOB(value, isUTF16), len(results), start, err)
+	return results, err
+}
+
+// floatSize returns the size of a float32 or float64
+func floatSize(isFloat32 bool) ProcessMemorySize {
+	if isFloat32 {
+		return 4
+	}
+	return 8
+}
+
+func (p *InstrumentedProcess) ScanWithOptions(aob AOB, options ScanOptions) ([]ScanMatch, error) {
+	start := time.Now()
+	results, err := p.Process.ScanWithOptions(aob, options)
+	p.observeScan(aob, len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanCtx(ctx context.Context, aob AOB) ([]ProcessMemoryAddress, error) {
+	start := time.Now()
+	results, err := p.Process.ScanCtx(ctx, aob)
+	p.observeScan(aob, len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanWithOptionsCtx(ctx context.Context, aob AOB, options ScanOptions) ([]ScanMatch, error) {
+	start := time.Now()
+	results, err := p.Process.ScanWithOptionsCtx(ctx, aob, options)
+	p.observeScan(aob, len(results), start, err)
+	return results, err
+}
+
+func (p *InstrumentedProcess) ScanIter(aob AOB) iter.Seq2[ProcessMemoryAddress, error] {
+	return ScanMatchAddressesIter(p.ScanWithOptionsIter(context.Background(), aob, ScanOptions{}))
+}
+
+// ScanWithOptionsIter reports the scan once the caller stops iterating, with the matches
+// yielded until then
+func (p *InstrumentedProcess) ScanWithOptionsIter(ctx context.Context, aob AOB, options ScanOptions) iter.Seq2[ScanMatch, error] {
+	return func(yield func(ScanMatch, error) bool) {
+		start := time.Now()
+		matches := 0
+		var scanErr error
+		defer func() {
+			p.observeScan(aob, matches, start, scanErr)
+		}()
+
+		for match, err := range p.Process.ScanWithOptionsIter(ctx, aob, options) {
+			if err != nil {
+				scanErr = err
+			} else {
+				matches++
+			}
+			if !yield(match, err) {
+				return
+			}
+		}
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -2,7 +2,9 @@ package memory_map
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
+	"strings"
 )
 
 // MemoryMapItem represents a memory region in a process's address space
@@ -10,10 +12,14 @@ type MemoryMapItem struct {
 	Address uint64 // The starting address of the memory region
 	Size    uint   // The size of the memory region in bytes
 	Perms   string // Permissions (e.g., "r-xp" for read, execute, private)
+	Path    string // Backing file or pseudo path (e.g., "/usr/lib/libc.so.6", "[heap]"), empty for anonymous mappings
 }
 
 // String returns a string representation of the memory map item
 func (mmItem MemoryMapItem) String() string {
+	if mmItem.Path != "" {
+		return fmt.Sprintf("Address: %x, Size: %d, Perms: %s, Path: %s", mmItem.Address, mmItem.Size, mmItem.Perms, mmItem.Path)
+	}
 	return fmt.Sprintf("Address: %x, Size: %d, Perms: %s", mmItem.Address, mmItem.Size, mmItem.Perms)
 }
 
@@ -25,6 +31,123 @@ func (mmItem MemoryMapItem) IsWritable() bool {
 	return mmItem.Perms[1] == 'w'
 }
 
+func (mmItem MemoryMapItem) IsExecutable() bool {
+	return len(mmItem.Perms) > 2 && mmItem.Perms[2] == 'x'
+}
+
+// RegionKind classifies a memory region by what it is backed by
+type RegionKind int
+
+const (
+	RegionAnonymous RegionKind = iota // Anonymous mapping (malloc arenas, mmap, thread stacks, ...)
+	RegionHeap                        // The [heap] (brk) region
+	RegionStack                       // The main [stack] or a [stack:tid] region
+	RegionImage                       // File-backed mapping (executable, shared library, mapped file)
+	RegionSpecial                     // Other kernel provided regions ([vdso], [vvar], [vsyscall], ...)
+	RegionTLS                         // Thread local storage block, only reported by thread region helpers
+)
+
+func (k RegionKind) String() string {
+	switch k {
+	case RegionAnonymous:
+		return "anonymous"
+	case RegionHeap:
+		return "heap"
+	case RegionStack:
+		return "stack"
+	case RegionImage:
+		return "image"
+	case RegionSpecial:
+		return "special"
+	case RegionTLS:
+		return "tls"
+	}
+	return fmt.Sprintf("RegionKind(%d)", int(k))
+}
+
+// Kind classifies the region from its path
+func (mmItem MemoryMapItem) Kind() RegionKind {
+	switch {
+	case mmItem.Path == "":
+		return RegionAnonymous
+	case mmItem.Path == "[heap]":
+		return RegionHeap
+	case mmItem.Path == "[stack]" || strings.HasPrefix(mmItem.Path, "[stack:"):
+		return RegionStack
+	case strings.HasPrefix(mmItem.Path, "["):
+		return RegionSpecial
+	}
+	return RegionImage
+}
+
+// IsHeapLike reports whether the region is readable and writable heap or anonymous memory,
+// where the vast majority of dynamically allocated values live
+func (mmItem MemoryMapItem) IsHeapLike() bool {
+	if len(mmItem.Perms) < 2 || !mmItem.IsReadable() || !mmItem.IsWritable() {
+		return false
+	}
+	kind := mmItem.Kind()
+	return kind == RegionHeap || kind == RegionAnonymous
+}
+
+// IsStack reports whether the region is a readable stack region
+func (mmItem MemoryMapItem) IsStack() bool {
+	return len(mmItem.Perms) > 0 && mmItem.IsReadable() && mmItem.Kind() == RegionStack
+}
+
+// RegionFilter selects memory regions, e.g. for exporting or scanning a subset of the memory map
+type RegionFilter func(item MemoryMapItem) bool
+
+// HeapFilter selects heap and anonymous read-write regions
+func HeapFilter(item MemoryMapItem) bool {
+	return item.IsHeapLike()
+}
+
+// StackFilter selects stack regions
+func StackFilter(item MemoryMapItem) bool {
+	return item.IsStack()
+}
+
+// ClassifiedRegion is a memory range with a known role, e.g. the stack or TLS block of a thread.
+// The range may be a part of a memory map region.
+type ClassifiedRegion struct {
+	Address uint64
+	Size    uint
+	Kind    RegionKind
+	TID     int           // Thread the region belongs to, 0 if not thread specific
+	Region  MemoryMapItem // Memory map region containing the range
+}
+
+// String returns a string representation of the classified region
+func (r ClassifiedRegion) String() string {
+	return fmt.Sprintf("Address: %x, Size: %d, Kind: %s, TID: %d", r.Address, r.Size, r.Kind, r.TID)
+}
+
+// Overlaps reports whether the classified range overlaps the memory map region
+func (r ClassifiedRegion) Overlaps(item MemoryMapItem) bool {
+	return r.Address < item.Address+uint64(item.Size) && item.Address < r.Address+uint64(r.Size)
+}
+
+// IncludeRegionsFilter selects the memory map regions overlapping any of the classified regions
+func IncludeRegionsFilter(regions []ClassifiedRegion) RegionFilter {
+	return func(item MemoryMapItem) bool {
+		for _, r := range regions {
+			if r.Overlaps(item) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ExcludeRegionsFilter selects the memory map regions overlapping none of the classified regions
+func ExcludeRegionsFilter(regions []ClassifiedRegion) RegionFilter {
+	include := IncludeRegionsFilter(regions)
+	return func(item MemoryMapItem) bool {
+		return !include(item)
+	}
+}
+
 // MemoryMap defines the interface for operations related to a process's memory map
 type MemoryMap interface {
 	// ReadMemoryMap reads and parses the memory map for a process
@@ -74,3 +197,43 @@ func GetMemoryRegionForAddress(addr uint64, memoryMap []MemoryMapItem) *MemoryMa
 	}
 	return nil
 }
+
+// ModuleBase returns the lowest address mapped from the given module.
+// module is matched against the full path of each region and its base name (e.g. "libc.so.6").
+func ModuleBase(module string, memoryMap []MemoryMapItem) (uint64, bool) {
+	found := false
+	var base uint64
+	for _, item := range memoryMap {
+		if item.Path == "" || (item.Path != module && filepath.Base(item.Path) != module) {
+			continue
+		}
+		if !found || item.Address < base {
+			base = item.Address
+			found = true
+		}
+	}
+	return base, found
+}
+
+// FormatAddress describes an address so it stays recognizable across runs despite ASLR:
+// "client.dll+0x12A4F0" relative to the base of the module or kernel region ([heap],
+// [stack], ...) containing it, the address with the permissions and kind of its region for
+// anonymous memory, e.g. "0x7F3A10000040 (rw- anonymous)", and the bare address otherwise.
+func FormatAddress(addr uint64, memoryMap []MemoryMapItem) string {
+	region := GetMemoryRegionForAddress(addr, memoryMap)
+	if region == nil {
+		return fmt.Sprintf("0x%X", addr)
+	}
+
+	if region.Path != "" {
+		if base, ok := ModuleBase(region.Path, memoryMap); ok {
+			return fmt.Sprintf("%s+0x%X", filepath.Base(region.Path), addr-base)
+		}
+	}
+
+	perms := region.Perms
+	if len(perms) > 3 {
+		perms = perms[:3]
+	}
+	return fmt.Sprintf("0x%X (%s %s)", addr, perms, region.Kind())
+}
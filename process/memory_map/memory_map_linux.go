@@ -54,10 +54,17 @@ func (l *LinuxMemoryMap) ReadMemoryMap(pid int) ([]MemoryMapItem, error) {
 		size := uint(endAddr - startAddr)
 		perms := fields[1]
 
+		// Pathname is the optional 6th column (offset, dev and inode are skipped)
+		path := ""
+		if len(fields) > 5 {
+			path = strings.Join(fields[5:], " ")
+		}
+
 		memoryMap = append(memoryMap, MemoryMapItem{
 			Address: startAddr,
 			Size:    size,
 			Perms:   perms,
+			Path:    path,
 		})
 	}
 
@@ -4,8 +4,76 @@ package memory_map
 
 import (
 	"fmt"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
 )
 
+var (
+	modkernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procOpenProcess           = modkernel32.NewProc("OpenProcess")
+	procCloseHandle           = modkernel32.NewProc("CloseHandle")
+	procVirtualQueryEx        = modkernel32.NewProc("VirtualQueryEx")
+	procK32GetMappedFileNameW = modkernel32.NewProc("K32GetMappedFileNameW")
+)
+
+const (
+	processQueryInformation = 0x0400
+	processVMRead           = 0x0010
+
+	maxPath = 1024
+)
+
+// Region states, types and page protections of MEMORY_BASIC_INFORMATION
+const (
+	MEM_COMMIT  = 0x1000
+	MEM_RESERVE = 0x2000
+	MEM_FREE    = 0x10000
+
+	MEM_PRIVATE = 0x20000
+	MEM_MAPPED  = 0x40000
+	MEM_IMAGE   = 0x1000000
+
+	PAGE_NOACCESS          = 0x01
+	PAGE_READONLY          = 0x02
+	PAGE_READWRITE         = 0x04
+	PAGE_WRITECOPY         = 0x08
+	PAGE_EXECUTE           = 0x10
+	PAGE_EXECUTE_READ      = 0x20
+	PAGE_EXECUTE_READWRITE = 0x40
+	PAGE_EXECUTE_WRITECOPY = 0x80
+	PAGE_GUARD             = 0x100
+)
+
+// MemoryBasicInformation mirrors MEMORY_BASIC_INFORMATION (64-bit layout)
+type MemoryBasicInformation struct {
+	BaseAddress       uintptr
+	AllocationBase    uintptr
+	AllocationProtect uint32
+	PartitionId       uint16
+	_                 uint16
+	RegionSize        uintptr
+	State             uint32
+	Protect           uint32
+	Type              uint32
+	_                 uint32
+}
+
+// VirtualQueryEx returns the information of the region of the process handle containing addr
+func VirtualQueryEx(handle syscall.Handle, addr uintptr) (MemoryBasicInformation, error) {
+	var mbi MemoryBasicInformation
+	ret, _, err := procVirtualQueryEx.Call(
+		uintptr(handle),
+		addr,
+		uintptr(unsafe.Pointer(&mbi)),
+		unsafe.Sizeof(mbi),
+	)
+	if ret == 0 {
+		return mbi, err
+	}
+	return mbi, nil
+}
+
 // WindowsMemoryMap implements MemoryMap for Windows
 type WindowsMemoryMap struct{}
 
@@ -14,23 +82,117 @@ func NewWindowsMemoryMap() *WindowsMemoryMap {
 	return &WindowsMemoryMap{}
 }
 
-// ReadMemoryMap reads and parses the memory map for a process
+// ReadMemoryMap opens the process and enumerates its committed regions, see ReadMemoryMapHandle
 func (w *WindowsMemoryMap) ReadMemoryMap(pid int) ([]MemoryMapItem, error) {
-	// Placeholder: Implement using VirtualQueryEx
-	return nil, fmt.Errorf("ReadMemoryMap not implemented for Windows")
+	handle, _, err := procOpenProcess.Call(processQueryInformation|processVMRead, 0, uintptr(pid))
+	if handle == 0 {
+		return nil, fmt.Errorf("OpenProcess failed: %v", err)
+	}
+	defer procCloseHandle.Call(handle)
+
+	return w.ReadMemoryMapHandle(syscall.Handle(handle))
+}
+
+// ReadMemoryMapHandle enumerates the committed regions of an opened process with VirtualQueryEx.
+// Protections are translated to Linux style permission strings ("r-xp", "rw-s", ...):
+// guard and no-access pages are "---", the fourth character is 's' for mapped views and
+// 'p' for private and image memory. Image and mapped regions get their backing file as Path.
+// The regions are sorted by address.
+func (w *WindowsMemoryMap) ReadMemoryMapHandle(handle syscall.Handle) ([]MemoryMapItem, error) {
+	var memoryMap []MemoryMapItem
+	paths := make(map[uintptr]string) // backing file per allocation base
+
+	addr := uintptr(0)
+	for {
+		mbi, err := VirtualQueryEx(handle, addr)
+		if err != nil || mbi.RegionSize == 0 {
+			// Past the end of the user address space
+			break
+		}
+
+		if mbi.State == MEM_COMMIT {
+			item := MemoryMapItem{
+				Address: uint64(mbi.BaseAddress),
+				Size:    uint(mbi.RegionSize),
+				Perms:   protectToPerms(mbi.Protect, mbi.Type),
+			}
+
+			if mbi.Type == MEM_IMAGE || mbi.Type == MEM_MAPPED {
+				path, ok := paths[mbi.AllocationBase]
+				if !ok {
+					path = mappedFileName(handle, mbi.AllocationBase)
+					paths[mbi.AllocationBase] = path
+				}
+				item.Path = path
+			}
+
+			memoryMap = append(memoryMap, item)
+		}
+
+		next := mbi.BaseAddress + mbi.RegionSize
+		if next <= addr {
+			break
+		}
+		addr = next
+	}
+
+	if len(memoryMap) == 0 {
+		return nil, fmt.Errorf("VirtualQueryEx returned no committed regions")
+	}
+
+	return memoryMap, nil
+}
+
+// protectToPerms translates a page protection and region type to a permission string
+func protectToPerms(protect, typ uint32) string {
+	perms := []byte("---p")
+	if typ == MEM_MAPPED {
+		perms[3] = 's'
+	}
+
+	if protect&(PAGE_GUARD|PAGE_NOACCESS) != 0 {
+		return string(perms)
+	}
+
+	switch protect & 0xFF {
+	case PAGE_READONLY:
+		perms[0] = 'r'
+	case PAGE_READWRITE, PAGE_WRITECOPY:
+		perms[0], perms[1] = 'r', 'w'
+	case PAGE_EXECUTE:
+		perms[2] = 'x'
+	case PAGE_EXECUTE_READ:
+		perms[0], perms[2] = 'r', 'x'
+	case PAGE_EXECUTE_READWRITE, PAGE_EXECUTE_WRITECOPY:
+		perms[0], perms[1], perms[2] = 'r', 'w', 'x'
+	}
+
+	return string(perms)
+}
+
+// mappedFileName returns the device path of the file mapped at addr, empty if none
+func mappedFileName(handle syscall.Handle, addr uintptr) string {
+	buf := make([]uint16, maxPath)
+	n, _, _ := procK32GetMappedFileNameW.Call(
+		uintptr(handle),
+		addr,
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+	)
+	if n == 0 {
+		return ""
+	}
+	return string(utf16.Decode(buf[:n]))
 }
 
 func (w *WindowsMemoryMap) IsReadablePerms(perms string) bool {
-	// Placeholder
-	return true
+	return len(perms) > 0 && perms[0] == 'r'
 }
 
 func (w *WindowsMemoryMap) IsWritablePerms(perms string) bool {
-	// Placeholder
-	return true
+	return len(perms) > 1 && perms[1] == 'w'
 }
 
 func (w *WindowsMemoryMap) IsExecutablePerms(perms string) bool {
-	// Placeholder
-	return true
+	return len(perms) > 2 && perms[2] == 'x'
 }
@@ -20,8 +20,9 @@ func (pms ProcessMemorySize) ToString() string {
 
 // AOB (Array of Bytes) represents a pattern to search for in memory
 type AOB struct {
-	Pattern []byte // The byte pattern to search for
-	Mask    []byte // Optional mask where 0xFF means exact match and 0x00 means wildcard
+	Pattern  []byte       // The byte pattern to search for
+	Mask     []byte       // Optional mask where 0xFF means exact match and 0x00 means wildcard
+	Captures []AOBCapture // Optional named fields decoded from the matched bytes, see DecodeCaptures
 }
 
 // IsValid checks if the AOB pattern is valid
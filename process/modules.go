@@ -0,0 +1,151 @@
+package process
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gomem/process/memory_map"
+)
+
+// Module is an executable, shared library or other file mapped into a process
+type Module struct {
+	Name string               // Base name, e.g. "libc.so.6" or "game.exe"
+	Path string               // Full path of the backing file
+	Base ProcessMemoryAddress // Lowest mapped address
+	Size uint64               // Span from Base to the end of the highest mapped region
+}
+
+// End returns the address just past the module
+func (m Module) End() ProcessMemoryAddress {
+	return m.Base + ProcessMemoryAddress(m.Size)
+}
+
+// Contains reports whether addr is inside the module
+func (m Module) Contains(addr ProcessMemoryAddress) bool {
+	return addr >= m.Base && addr < m.End()
+}
+
+// String returns a one line description of the module
+func (m Module) String() string {
+	return fmt.Sprintf("%016x-%016x %s", uint64(m.Base), uint64(m.End()), m.Path)
+}
+
+// Address returns the address at rva, an offset relative to the module base as shown by
+// disassemblers and other reversing tools
+func (m Module) Address(rva uint64) ProcessMemoryAddress {
+	return m.Base + ProcessMemoryAddress(rva)
+}
+
+// ModuleLister is implemented by processes with a native module list, e.g. the loader list
+// on Windows. Other processes derive their modules from the memory map, see GetModules.
+type ModuleLister interface {
+	GetModules() ([]Module, error)
+}
+
+// GetModules returns the modules of proc sorted by base address, from its native module list
+// when it has one and from the file-backed regions of its memory map otherwise
+func GetModules(proc Process) ([]Module, error) {
+	if lister, ok := proc.(ModuleLister); ok {
+		return lister.GetModules()
+	}
+
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		return nil, err
+	}
+	return ModulesFromMemoryMap(mm), nil
+}
+
+// FindModule returns the module of proc named name, matched against the base name and the
+// full path of each module. An exact match wins over a case-insensitive one, as Windows
+// module names are not case sensitive.
+func FindModule(proc Process, name string) (Module, error) {
+	modules, err := GetModules(proc)
+	if err != nil {
+		return Module{}, err
+	}
+
+	for _, m := range modules {
+		if m.Name == name || m.Path == name {
+			return m, nil
+		}
+	}
+	for _, m := range modules {
+		if strings.EqualFold(m.Name, name) || strings.EqualFold(m.Path, name) {
+			return m, nil
+		}
+	}
+	return Module{}, fmt.Errorf("module %s not found", name)
+}
+
+// GetModuleBase returns the base address of the module of proc named name, e.g.
+// GetModuleBase(proc, "libclient.so"), see FindModule
+func GetModuleBase(proc Process, name string) (ProcessMemoryAddress, error) {
+	m, err := FindModule(proc, name)
+	if err != nil {
+		return 0, err
+	}
+	return m.Base, nil
+}
+
+// ModuleAddress returns the address at rva in the module of proc named module, so a static
+// offset from a reversing tool can be used as is, e.g. ModuleAddress(proc, "game.exe", 0x1A2B3C0)
+// instead of BASEADDRESS+0x1A2B3C0 which only holds for images loaded at their preferred base.
+// rva is not checked against the module size, .bss may extend past the file-backed regions.
+func ModuleAddress(proc Process, module string, rva uint64) (ProcessMemoryAddress, error) {
+	m, err := FindModule(proc, module)
+	if err != nil {
+		return 0, err
+	}
+	return m.Address(rva), nil
+}
+
+// ModulesFromMemoryMap groups the file-backed regions of a memory map by path, one module per
+// file spanning from its lowest to its highest mapped address, sorted by base address.
+// Pseudo paths ([heap], [stack], [vdso], ...) are not modules.
+func ModulesFromMemoryMap(mm []memory_map.MemoryMapItem) []Module {
+	index := make(map[string]int)
+	var modules []Module
+
+	for _, item := range mm {
+		if item.Kind() != memory_map.RegionImage {
+			continue
+		}
+
+		start := ProcessMemoryAddress(item.Address)
+		end := start + ProcessMemoryAddress(item.Size)
+
+		i, ok := index[item.Path]
+		if !ok {
+			index[item.Path] = len(modules)
+			modules = append(modules, Module{
+				Name: PointerChain{Module: item.Path}.Normalized().Module,
+				Path: item.Path,
+				Base: start,
+				Size: uint64(item.Size),
+			})
+			continue
+		}
+
+		m := &modules[i]
+		moduleEnd := max(m.End(), end)
+		m.Base = min(m.Base, start)
+		m.Size = uint64(moduleEnd - m.Base)
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Base < modules[j].Base
+	})
+	return modules
+}
+
+// FormatAddress describes addr as module+offset (e.g. "client.dll+0x12A4F0"), falling back to
+// the permissions and kind of its region, see memory_map.FormatAddress
+func FormatAddress(proc Process, addr ProcessMemoryAddress) string {
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		return fmt.Sprintf("0x%X", uint64(addr))
+	}
+	return memory_map.FormatAddress(uint64(addr), mm)
+}
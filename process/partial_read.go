@@ -0,0 +1,114 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+)
+
+// partialReadPageSize is the granularity at which ReadPartial probes a range that could not
+// be read at once
+const partialReadPageSize = 0x1000
+
+// PartialReadRange is a piece of a PartialRead, readable when Err is nil
+type PartialReadRange struct {
+	Address ProcessMemoryAddress
+	Size    ProcessMemorySize
+	Err     error // Why the bytes could not be read, nil if they were
+}
+
+// End returns the address just past the range
+func (r PartialReadRange) End() ProcessMemoryAddress {
+	return r.Address + ProcessMemoryAddress(r.Size)
+}
+
+// PartialRead is the result of ReadMemoryPartial: every requested byte, with the ranges that
+// could and could not be read
+type PartialRead struct {
+	Address ProcessMemoryAddress
+	Data    []byte             // The requested bytes, 0 where they could not be read
+	Ranges  []PartialReadRange // Consecutive ranges covering Data, alternating readable and not
+}
+
+// Complete reports whether every byte was read
+func (r PartialRead) Complete() bool {
+	return r.Err() == nil
+}
+
+// Err returns the error of the first unreadable range, nil if every byte was read
+func (r PartialRead) Err() error {
+	for _, rng := range r.Ranges {
+		if rng.Err != nil {
+			return rng.Err
+		}
+	}
+	return nil
+}
+
+// Valid reports whether the byte at addr was read
+func (r PartialRead) Valid(addr ProcessMemoryAddress) bool {
+	for _, rng := range r.Ranges {
+		if addr >= rng.Address && addr < rng.End() {
+			return rng.Err == nil
+		}
+	}
+	return false
+}
+
+// ValidBytes returns the number of bytes that were read
+func (r PartialRead) ValidBytes() ProcessMemorySize {
+	var n ProcessMemorySize
+	for _, rng := range r.Ranges {
+		if rng.Err == nil {
+			n += rng.Size
+		}
+	}
+	return n
+}
+
+// ReadPartial reads size bytes at addr with read, keeping whatever can be read instead of
+// failing the whole range: the range is read at once when possible; otherwise the prefix
+// returned with ErrPartialRead is kept and the rest is probed page by page, retrying the
+// remainder at once after each readable page. Implementations of ReadMemoryPartial call it
+// with their ReadMemory.
+func ReadPartial(read func(ProcessMemoryAddress, ProcessMemorySize) ([]byte, error), addr ProcessMemoryAddress, size ProcessMemorySize) PartialRead {
+	result := PartialRead{Address: addr, Data: make([]byte, size)}
+	end := addr + ProcessMemoryAddress(size)
+
+	for cur := addr; cur < end; {
+		data, err := read(cur, ProcessMemorySize(end-cur))
+		if err == nil || (errors.Is(err, ErrPartialRead) && len(data) > 0) {
+			copy(result.Data[cur-addr:], data)
+			result.add(cur, ProcessMemorySize(len(data)), nil)
+			cur += ProcessMemoryAddress(len(data))
+			if err == nil || cur >= end {
+				break
+			}
+		}
+
+		// Probe the page at cur on its own
+		next := min((cur/partialReadPageSize+1)*partialReadPageSize, end)
+		data, err = read(cur, ProcessMemorySize(next-cur))
+		if err == nil && len(data) != int(next-cur) {
+			err = fmt.Errorf("%w: read %d of %d bytes at 0x%X", ErrPartialRead, len(data), next-cur, uint64(cur))
+		}
+		if err == nil {
+			copy(result.Data[cur-addr:], data)
+		}
+		result.add(cur, ProcessMemorySize(next-cur), err)
+		cur = next
+	}
+
+	return result
+}
+
+// add appends a range, merging it into the previous one when both are readable or both not
+func (r *PartialRead) add(addr ProcessMemoryAddress, size ProcessMemorySize, err error) {
+	if size == 0 {
+		return
+	}
+	if last := len(r.Ranges) - 1; last >= 0 && (r.Ranges[last].Err == nil) == (err == nil) {
+		r.Ranges[last].Size += size
+		return
+	}
+	r.Ranges = append(r.Ranges, PartialReadRange{Address: addr, Size: size, Err: err})
+}
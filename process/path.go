@@ -2,6 +2,7 @@ package process
 
 import (
 	"fmt"
+	"reflect"
 	"unsafe"
 )
 
@@ -10,6 +11,57 @@ import (
 // The last offset is added to the final pointer, and then T is read from that address.
 // If offsets is empty, it reads T from base.
 func ReadPath[T any](proc Process, base ProcessMemoryAddress, offsets ...ProcessMemorySize) (T, error) {
+	return ReadPathTransform[T](proc, nil, base, offsets...)
+}
+
+// ReadPathTransform is ReadPath for obfuscated pointers: every pointer read along the path
+// is decoded with transform before it is checked and followed.
+func ReadPathTransform[T any](proc Process, transform PointerTransform, base ProcessMemoryAddress, offsets ...ProcessMemorySize) (T, error) {
+	finalAddr, err := resolvePath(proc, transform, false, base, offsets)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	// Read the final value
+	val, err := Read[T](proc, finalAddr)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("failed to read final value at 0x%x: %w", finalAddr, err)
+	}
+
+	return val, nil
+}
+
+// WritePath writes value at the end of a pointer path, walked like ReadPath.
+// Every pointer along the path and the final address are checked against the memory map
+// before anything is written.
+func WritePath[T any](proc Process, base ProcessMemoryAddress, value T, offsets ...ProcessMemorySize) error {
+	return WritePathTransform(proc, nil, base, value, offsets...)
+}
+
+// WritePathTransform is WritePath for obfuscated pointers: every pointer read along the path
+// is decoded with transform before it is checked and followed.
+func WritePathTransform[T any](proc Process, transform PointerTransform, base ProcessMemoryAddress, value T, offsets ...ProcessMemorySize) error {
+	finalAddr, err := resolvePath(proc, transform, true, base, offsets)
+	if err != nil {
+		return err
+	}
+
+	if !proc.IsValidAddress(finalAddr) {
+		return fmt.Errorf("final address 0x%x is not mapped", finalAddr)
+	}
+
+	if err := Write(proc, finalAddr, value); err != nil {
+		return fmt.Errorf("failed to write final value at 0x%x: %w", finalAddr, err)
+	}
+
+	return nil
+}
+
+// resolvePath walks a pointer path and returns the address its last offset points to.
+// With validate, every pointer read along the path must be mapped.
+func resolvePath(proc Process, transform PointerTransform, validate bool, base ProcessMemoryAddress, offsets []ProcessMemorySize) (ProcessMemoryAddress, error) {
 	currentAddr := base
 
 	// Iterate over all offsets except the last one
@@ -22,14 +74,17 @@ func ReadPath[T any](proc Process, base ProcessMemoryAddress, offsets ...Process
 		// TODO: Support 32-bit pointers if needed, maybe via Process interface?
 		ptrVal, err := Read[uint64](proc, ptrAddr)
 		if err != nil {
-			var zero T
-			return zero, fmt.Errorf("failed to read pointer at offset %d (addr 0x%x): %w", i, ptrAddr, err)
+			return 0, fmt.Errorf("failed to read pointer at offset %d (addr 0x%x): %w", i, ptrAddr, err)
 		}
 
+		ptrVal = uint64(transform.Apply(ProcessMemoryAddress(ptrVal)))
+
 		// Check if pointer is valid
 		if ptrVal == 0 {
-			var zero T
-			return zero, fmt.Errorf("pointer at offset %d (addr 0x%x) is null", i, ptrAddr)
+			return 0, fmt.Errorf("pointer at offset %d (addr 0x%x) is null", i, ptrAddr)
+		}
+		if validate && !proc.IsValidAddress(ProcessMemoryAddress(ptrVal)) {
+			return 0, fmt.Errorf("pointer at offset %d (addr 0x%x) points to unmapped address 0x%x", i, ptrAddr, ptrVal)
 		}
 
 		currentAddr = ProcessMemoryAddress(ptrVal)
@@ -41,16 +96,7 @@ func ReadPath[T any](proc Process, base ProcessMemoryAddress, offsets ...Process
 		finalOffset = offsets[len(offsets)-1]
 	}
 
-	finalAddr := currentAddr + ProcessMemoryAddress(finalOffset)
-
-	// Read the final value
-	val, err := Read[T](proc, finalAddr)
-	if err != nil {
-		var zero T
-		return zero, fmt.Errorf("failed to read final value at 0x%x: %w", finalAddr, err)
-	}
-
-	return val, nil
+	return currentAddr + ProcessMemoryAddress(finalOffset), nil
 }
 
 // Read is a helper to read a single value of type T from memory
@@ -93,11 +139,32 @@ func readT[T any](proc Process, addr ProcessMemoryAddress) (T, error) {
 		return t, err
 	}
 
-	// Copy data to t
+	// Copy data to t, scalars of a target in a foreign byte order are swapped
+	if !IsNativeByteOrder(ByteOrderOf(proc)) {
+		data = append([]byte(nil), data...)
+		SwapScalarBytes(reflect.TypeOf(t).Kind(), data)
+	}
 	copyTo(&t, data)
 	return t, nil
 }
 
+// Write is a helper to write a single value of type T to memory, the counterpart of Read.
+// Scalars are written in the byte order of proc.
+func Write[T any](proc Process, addr ProcessMemoryAddress, value T) error {
+	size := int(unsafe.Sizeof(value))
+	if size == 0 {
+		return nil
+	}
+
+	data := make([]byte, size)
+	copy(data, unsafe.Slice((*byte)(unsafe.Pointer(&value)), size))
+	if !IsNativeByteOrder(ByteOrderOf(proc)) {
+		SwapScalarBytes(reflect.TypeOf(value).Kind(), data)
+	}
+
+	return proc.WriteMemory(addr, data)
+}
+
 // copyTo copies bytes to *T
 func copyTo[T any](dst *T, src []byte) {
 	size := int(unsafe.Sizeof(*dst))
@@ -0,0 +1,165 @@
+package process
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// PatternBuilder builds an AOB piece by piece, so generated scanners don't have to format
+// pattern strings for ParseAOB:
+//
+//	aob := process.NewPatternBuilder().Bytes(0x48, 0x8B).Any(2).UINT32(1234).Build()
+//
+// Values are encoded little-endian. The zero value is ready to use.
+type PatternBuilder struct {
+	pattern  []byte
+	mask     []byte
+	captures []AOBCapture
+}
+
+// NewPatternBuilder returns an empty PatternBuilder
+func NewPatternBuilder() *PatternBuilder {
+	return &PatternBuilder{}
+}
+
+// Bytes appends bytes that must match exactly
+func (b *PatternBuilder) Bytes(values ...byte) *PatternBuilder {
+	for _, v := range values {
+		b.pattern = append(b.pattern, v)
+		b.mask = append(b.mask, 0xFF)
+	}
+	return b
+}
+
+// Any appends n wildcard bytes
+func (b *PatternBuilder) Any(n int) *PatternBuilder {
+	for range max(n, 0) {
+		b.pattern = append(b.pattern, 0)
+		b.mask = append(b.mask, 0x00)
+	}
+	return b
+}
+
+// Masked appends bytes compared under mask (0xFF exact, 0x00 wildcard, other values match
+// the selected bits); values past the end of mask must match exactly
+func (b *PatternBuilder) Masked(values []byte, mask []byte) *PatternBuilder {
+	for i, v := range values {
+		m := byte(0xFF)
+		if i < len(mask) {
+			m = mask[i]
+		}
+		b.pattern = append(b.pattern, v&m)
+		b.mask = append(b.mask, m)
+	}
+	return b
+}
+
+// HighNibble appends a byte whose upper 4 bits must equal those of v, the "4?" of a pattern
+// string, e.g. the opcode of the 0x40-0x4F REX prefixes
+func (b *PatternBuilder) HighNibble(v byte) *PatternBuilder {
+	return b.Masked([]byte{v}, []byte{0xF0})
+}
+
+// LowNibble appends a byte whose lower 4 bits must equal those of v, the "?C" of a pattern
+// string
+func (b *PatternBuilder) LowNibble(v byte) *PatternBuilder {
+	return b.Masked([]byte{v}, []byte{0x0F})
+}
+
+// Capture appends the wildcard bytes of a named capture of type typ, see AOBCapture
+func (b *PatternBuilder) Capture(name string, typ CaptureType) *PatternBuilder {
+	b.captures = append(b.captures, AOBCapture{Name: name, Type: typ, Offset: len(b.pattern)})
+	return b.Any(typ.Size())
+}
+
+// AOB appends another pattern and its captures, a nil mask matches every byte exactly
+func (b *PatternBuilder) AOB(aob AOB) *PatternBuilder {
+	for _, c := range aob.Captures {
+		c.Offset += len(b.pattern)
+		b.captures = append(b.captures, c)
+	}
+	if aob.Mask == nil {
+		return b.Bytes(aob.Pattern...)
+	}
+	return b.Masked(aob.Pattern, aob.Mask)
+}
+
+// UINT8 appends an unsigned 8-bit integer
+func (b *PatternBuilder) UINT8(v uint8) *PatternBuilder {
+	return b.Bytes(v)
+}
+
+// UINT16 appends an unsigned 16-bit integer
+func (b *PatternBuilder) UINT16(v uint16) *PatternBuilder {
+	return b.Bytes(binary.LittleEndian.AppendUint16(nil, v)...)
+}
+
+// UINT32 appends an unsigned 32-bit integer
+func (b *PatternBuilder) UINT32(v uint32) *PatternBuilder {
+	return b.Bytes(binary.LittleEndian.AppendUint32(nil, v)...)
+}
+
+// UINT64 appends an unsigned 64-bit integer
+func (b *PatternBuilder) UINT64(v uint64) *PatternBuilder {
+	return b.Bytes(binary.LittleEndian.AppendUint64(nil, v)...)
+}
+
+// INT8 appends a signed 8-bit integer
+func (b *PatternBuilder) INT8(v int8) *PatternBuilder {
+	return b.UINT8(uint8(v))
+}
+
+// INT16 appends a signed 16-bit integer
+func (b *PatternBuilder) INT16(v int16) *PatternBuilder {
+	return b.UINT16(uint16(v))
+}
+
+// INT32 appends a signed 32-bit integer
+func (b *PatternBuilder) INT32(v int32) *PatternBuilder {
+	return b.UINT32(uint32(v))
+}
+
+// INT64 appends a signed 64-bit integer
+func (b *PatternBuilder) INT64(v int64) *PatternBuilder {
+	return b.UINT64(uint64(v))
+}
+
+// FLOAT32 appends a 32-bit float
+func (b *PatternBuilder) FLOAT32(v float32) *PatternBuilder {
+	return b.UINT32(math.Float32bits(v))
+}
+
+// FLOAT64 appends a 64-bit float
+func (b *PatternBuilder) FLOAT64(v float64) *PatternBuilder {
+	return b.UINT64(math.Float64bits(v))
+}
+
+// POINTER appends a 64-bit address
+func (b *PatternBuilder) POINTER(addr ProcessMemoryAddress) *PatternBuilder {
+	return b.UINT64(uint64(addr))
+}
+
+// String appends the UTF-8 bytes of s, without terminator
+func (b *PatternBuilder) String(s string) *PatternBuilder {
+	return b.Bytes([]byte(s)...)
+}
+
+// UTF16 appends the UTF-16 encoding of s, with the wildcards of UTF16AOB
+func (b *PatternBuilder) UTF16(s string) *PatternBuilder {
+	return b.AOB(UTF16AOB(s))
+}
+
+// Len returns the number of bytes in the pattern so far
+func (b *PatternBuilder) Len() int {
+	return len(b.pattern)
+}
+
+// Build returns the pattern. The builder can keep being used, later calls don't change
+// the returned AOB.
+func (b *PatternBuilder) Build() AOB {
+	return AOB{
+		Pattern:  append([]byte(nil), b.pattern...),
+		Mask:     append([]byte(nil), b.mask...),
+		Captures: append([]AOBCapture(nil), b.captures...),
+	}
+}
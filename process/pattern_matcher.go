@@ -0,0 +1,150 @@
+package process
+
+import "bytes"
+
+// minAnchorLength is the shortest run of fully masked bytes searched with bytes.Index
+const minAnchorLength = 2
+
+// ScanMatcher finds the matches of a scan in a buffer, see ScanRegionsMatcherCtx
+type ScanMatcher interface {
+	// Len returns the number of bytes of a match
+	Len() int
+
+	// Find returns the offsets start, start+step, ... of data where a match starts
+	Find(data []byte, start, step int) []int
+}
+
+// PatternMatcher searches data for a masked pattern. A mask byte of 0 is a wildcard, other
+// mask bytes select the compared bits.
+//
+// Patterns with a run of at least minAnchorLength fully masked bytes are searched by finding
+// the longest run with bytes.Index, which is vectorized on most platforms, and verifying the
+// rest of the pattern around each hit. Other patterns use a Boyer-Moore-Horspool skip table.
+//
+// The skip table is built for the byte under the last pattern position: the window moves to
+// the next position whose pattern byte can equal it. Wildcards match every byte, so patterns
+// with a wildcard near their end skip little; a wildcard at the second to last position
+// degrades to the naive search.
+type PatternMatcher struct {
+	pattern []byte // Pattern with the wildcard bits cleared
+	mask    []byte
+	skip    [256]int
+
+	anchor       []byte // Longest run of fully masked bytes, nil to use the skip table
+	anchorOffset int    // Offset of the anchor in the pattern
+}
+
+// NewPatternMatcher returns a matcher for pattern, mask must have the same length
+func NewPatternMatcher(pattern, mask []byte) *PatternMatcher {
+	m := &PatternMatcher{
+		pattern: make([]byte, len(pattern)),
+		mask:    mask,
+	}
+	for i := range pattern {
+		m.pattern[i] = pattern[i] & mask[i]
+	}
+
+	// Find the longest run of bytes compared in full
+	for i := 0; i < len(mask); {
+		if mask[i] != 0xFF {
+			i++
+			continue
+		}
+		j := i
+		for j < len(mask) && mask[j] == 0xFF {
+			j++
+		}
+		if j-i >= minAnchorLength && j-i > len(m.anchor) {
+			m.anchor, m.anchorOffset = m.pattern[i:j], i
+		}
+		i = j
+	}
+
+	n := len(pattern)
+	for b := range m.skip {
+		m.skip[b] = n
+	}
+	// Later positions overwrite earlier ones with a smaller shift
+	for j := 0; j < n-1; j++ {
+		shift := n - 1 - j
+		if mask[j] == 0 {
+			for b := range m.skip {
+				m.skip[b] = shift
+			}
+			continue
+		}
+		for b := range m.skip {
+			if byte(b)&mask[j] == m.pattern[j] {
+				m.skip[b] = shift
+			}
+		}
+	}
+	return m
+}
+
+// Len returns the length of the pattern
+func (m *PatternMatcher) Len() int {
+	return len(m.pattern)
+}
+
+// Find returns the offsets start, start+step, ... of data where the pattern matches
+func (m *PatternMatcher) Find(data []byte, start, step int) []int {
+	n := len(m.pattern)
+	if n == 0 || len(data) < n {
+		return nil
+	}
+	step = max(step, 1)
+	if m.anchor != nil {
+		return m.findAnchored(data, start, step)
+	}
+
+	var matches []int
+	last := n - 1
+	for i := start; i <= len(data)-n; {
+		if m.matchAt(data, i) {
+			matches = append(matches, i)
+		}
+
+		// Skip to the first aligned offset at or after the BMH shift
+		next := i + m.skip[data[i+last]]
+		if step > 1 {
+			next += (step - (next-start)%step) % step
+		}
+		i = next
+	}
+	return matches
+}
+
+// findAnchored is Find searching for the anchor with bytes.Index
+func (m *PatternMatcher) findAnchored(data []byte, start, step int) []int {
+	n := len(m.pattern)
+	exact := len(m.anchor) == n
+
+	var matches []int
+	// Anchor positions of the windows start ... len(data)-n
+	from, end := start+m.anchorOffset, len(data)-n+m.anchorOffset+len(m.anchor)
+	for from+len(m.anchor) <= end {
+		hit := bytes.Index(data[from:end], m.anchor)
+		if hit < 0 {
+			break
+		}
+		i := from + hit - m.anchorOffset
+		if (i-start)%step == 0 && (exact || m.matchAt(data, i)) {
+			matches = append(matches, i)
+		}
+		from += hit + 1
+	}
+	return matches
+}
+
+// matchAt reports whether the pattern matches data at offset i, comparing from the end
+// where the skip table byte was read
+func (m *PatternMatcher) matchAt(data []byte, i int) bool {
+	window := data[i : i+len(m.pattern)]
+	for j := len(window) - 1; j >= 0; j-- {
+		if window[j]&m.mask[j] != m.pattern[j] {
+			return false
+		}
+	}
+	return true
+}
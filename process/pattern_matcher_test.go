@@ -0,0 +1,220 @@
+package process
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"gomem/process/memory_map"
+)
+
+// naiveFind is the reference matcher: every offset start, start+step, ... compared in full
+func naiveFind(data []byte, aob AOB, start, step int) []int {
+	var matches []int
+	for i := start; i+len(aob.Pattern) <= len(data); i += max(step, 1) {
+		matched := true
+		for j, b := range aob.Pattern {
+			mask := byte(0xFF)
+			if len(aob.Mask) > 0 {
+				mask = aob.Mask[j]
+			}
+			if data[i+j]&mask != b&mask {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// testData returns n pseudo random bytes from a small alphabet, so short patterns match often
+func testData(n int) []byte {
+	alphabet := []byte{0x00, 0x01, 0x05, 0x48, 0x4F, 0x8B, 0xF8}
+	r := rand.New(rand.NewSource(1))
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return data
+}
+
+var matcherPatterns = []struct {
+	name string
+	aob  string
+}{
+	{"single byte", "48"},
+	{"exact", "48 8B"},
+	{"exact long", "00 01 05 48"},
+	{"wildcard", "48 ?? 05"},
+	{"leading wildcard", "?? 48 8B"},
+	{"trailing wildcard", "48 8B ??"},
+	{"wildcard second to last", "48 00 ?? 01"},
+	{"anchor not at start", "?? 4? 8B 00 ?? 05"},
+	{"longest anchor", "48 8B ?? 00 01 05"},
+	{"high nibble", "4? 8B"},
+	{"low nibble", "?8 ?B"},
+	{"nibbles only", "4? ?8 0?"},
+	{"wildcards only", "?? ??"},
+}
+
+func TestPatternMatcherFind(t *testing.T) {
+	data := testData(4096)
+
+	for _, tt := range matcherPatterns {
+		aob, err := NewAOBFromString(tt.aob)
+		if err != nil {
+			t.Fatalf("NewAOBFromString(%q): %v", tt.aob, err)
+		}
+		matcher, err := newAOBMatcher(aob)
+		if err != nil {
+			t.Fatalf("newAOBMatcher(%q): %v", tt.aob, err)
+		}
+
+		for _, step := range []int{1, 2, 4, 8} {
+			for _, start := range []int{0, 1, 3} {
+				got := matcher.Find(data, start, step)
+				want := naiveFind(data, aob, start, step)
+				if !slices.Equal(got, want) {
+					t.Errorf("%s: Find(start %d, step %d) = %d matches, want %d", tt.name, start, step, len(got), len(want))
+				}
+			}
+		}
+	}
+}
+
+func TestPatternMatcherShortData(t *testing.T) {
+	matcher := NewPatternMatcher([]byte{0x48, 0x8B, 0x05}, []byte{0xFF, 0xFF, 0xFF})
+	for _, data := range [][]byte{nil, {0x48}, {0x48, 0x8B}} {
+		if got := matcher.Find(data, 0, 1); got != nil {
+			t.Errorf("Find(% X) = %v, want none", data, got)
+		}
+	}
+	if got := matcher.Find([]byte{0x48, 0x8B, 0x05}, 0, 1); !slices.Equal(got, []int{0}) {
+		t.Errorf("Find(exact) = %v, want [0]", got)
+	}
+}
+
+func TestScanRegionsChunked(t *testing.T) {
+	data := testData(1000)
+	const base = 0x10003 // Not aligned, so address and region alignment differ
+
+	regions := []memory_map.MemoryMapItem{{Address: base, Size: uint(len(data)), Perms: "rw-p"}}
+	read := func(addr ProcessMemoryAddress, size ProcessMemorySize) ([]byte, error) {
+		offset := uint64(addr) - base
+		return data[offset : offset+uint64(size)], nil
+	}
+
+	options := []struct {
+		name      string
+		options   ScanOptions
+		start     int // First tested offset of the region
+		alignment int
+	}{
+		{"unaligned", ScanOptions{}, 0, 1},
+		{"parallel", ScanOptions{MaxDOP: 4}, 0, 1},
+		{"region aligned", ScanOptions{Alignment: 4, AlignmentBase: AlignToRegion}, 0, 4},
+		{"region aligned with offset", ScanOptions{Alignment: 8, AlignmentOffset: 3, AlignmentBase: AlignToRegion}, 3, 8},
+		{"address aligned", ScanOptions{Alignment: 4}, 1, 4}, // 0x10003 + 1 is a multiple of 4
+	}
+
+	for _, tt := range matcherPatterns {
+		aob, err := NewAOBFromString(tt.aob)
+		if err != nil {
+			t.Fatalf("NewAOBFromString(%q): %v", tt.aob, err)
+		}
+
+		for _, o := range options {
+			want := naiveFind(data, aob, o.start, o.alignment)
+
+			// Limits smaller than the region split it in chunks, matches cross their boundaries
+			for _, limit := range []ProcessMemorySize{0, 16, 61, 256} {
+				matches, err := ScanRegions(regions, read, aob, o.options, ReadLimits{MaxReadSize: limit}, nil)
+				if err != nil {
+					t.Fatalf("%s, %s, limit %d: %v", tt.name, o.name, limit, err)
+				}
+
+				got := make([]int, len(matches))
+				for i, match := range matches {
+					got[i] = int(uint64(match.Address) - base)
+				}
+				if !slices.Equal(got, want) {
+					t.Errorf("%s, %s, limit %d: %d matches, want %d", tt.name, o.name, limit, len(got), len(want))
+				}
+			}
+		}
+	}
+}
+
+func TestScanChunks(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         ProcessMemorySize
+		size          uint
+		patternLength int
+		alignment     uint
+		chunks        int
+	}{
+		{"no limit", 0, 1000, 4, 1, 1},
+		{"fits", 1000, 1000, 4, 1, 1},
+		{"split", 100, 1000, 4, 1, 11},
+		{"aligned", 100, 1000, 4, 8, 11},
+		{"single step", 19, 100, 4, 16, 7},
+		{"pattern longer than the limit, region fits", 16, 10, 20, 1, 1},
+	}
+
+	for _, tt := range tests {
+		const address = 0x1000
+		chunks, err := ReadLimits{MaxReadSize: tt.limit}.ScanChunks(address, tt.size, tt.patternLength, tt.alignment)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(chunks) != tt.chunks {
+			t.Errorf("%s: %d chunks, want %d", tt.name, len(chunks), tt.chunks)
+		}
+
+		// Chunks respect the limit, start aligned and cover the region with the pattern overlap
+		end := uint64(address)
+		for i, chunk := range chunks {
+			if tt.limit > 0 && chunk.Size > uint(tt.limit) {
+				t.Errorf("%s: chunk %d is %d bytes, limit is %d", tt.name, i, chunk.Size, tt.limit)
+			}
+			if (chunk.Address-address)%uint64(max(tt.alignment, 1)) != 0 {
+				t.Errorf("%s: chunk %d at 0x%x is not aligned", tt.name, i, chunk.Address)
+			}
+			if chunk.Address > end {
+				t.Errorf("%s: gap before chunk %d at 0x%x", tt.name, i, chunk.Address)
+			}
+			if i < len(chunks)-1 && chunk.Size != uint(chunk.Keep)+uint(tt.patternLength-1) {
+				t.Errorf("%s: chunk %d keeps %d of %d bytes", tt.name, i, chunk.Keep, chunk.Size)
+			}
+			end = chunk.Address + uint64(chunk.Keep)
+		}
+		if last := chunks[len(chunks)-1]; last.Address+uint64(last.Size) != address+uint64(tt.size) {
+			t.Errorf("%s: chunks end at 0x%x, want 0x%x", tt.name, last.Address+uint64(last.Size), address+uint64(tt.size))
+		}
+	}
+}
+
+func TestScanChunksErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         ProcessMemorySize
+		patternLength int
+		alignment     uint
+	}{
+		{"pattern longer than the limit", 16, 20, 1},
+		{"pattern one byte longer than the limit", 16, 17, 1},
+		{"alignment step too large", 16, 4, 16},
+	}
+
+	for _, tt := range tests {
+		_, err := ReadLimits{MaxReadSize: tt.limit}.ScanChunks(0x1000, 100, tt.patternLength, tt.alignment)
+		if !errors.Is(err, ErrReadTooLarge) {
+			t.Errorf("%s: err = %v, want ErrReadTooLarge", tt.name, err)
+		}
+	}
+}
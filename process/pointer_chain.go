@@ -0,0 +1,267 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gomem/process/memory_map"
+)
+
+// PointerChain is a candidate pointer path: a base (optionally module relative) followed by
+// ReadPath offsets. A pointer is read at the current address plus each offset but the last,
+// the last offset is added to the final pointer.
+type PointerChain struct {
+	Module  string              // Module the base is relative to (base name or full path), empty for an absolute base
+	Base    uint64              // Offset from the module base, or the absolute base address
+	Offsets []ProcessMemorySize // ReadPath offsets
+}
+
+// ChainHop is the result of one pointer read while walking a chain
+type ChainHop struct {
+	Address ProcessMemoryAddress // Address the pointer was read from
+	Pointer ProcessMemoryAddress // Pointer read, 0 if the read failed
+	Valid   bool                 // The pointer was read and points into mapped memory
+	Err     error
+}
+
+// ChainResult is the outcome of walking a pointer chain on a process or dump
+type ChainResult struct {
+	Chain PointerChain
+	Base  ProcessMemoryAddress // Resolved base address
+	Hops  []ChainHop           // One entry per pointer read, up to and including the first invalid one
+	Final ProcessMemoryAddress // Final address, 0 unless every hop is valid
+	Valid bool                 // Every hop is valid and the final address is mapped
+	Err   error                // Reason the chain is not valid
+}
+
+// ChainStability compares a chain walked on two snapshots (two dumps, or a live process at two points in time)
+type ChainStability struct {
+	First  ChainResult
+	Second ChainResult
+	Stable bool // The chain is valid in both snapshots and resolves to the same final address
+}
+
+// NormalizePointerChain builds a chain from an absolute base address, expressing the base
+// relative to the module containing it when there is one. An empty offset list is
+// normalized to a single zero offset.
+func NormalizePointerChain(base ProcessMemoryAddress, offsets []ProcessMemorySize, mm []memory_map.MemoryMapItem) PointerChain {
+	chain := PointerChain{Base: uint64(base), Offsets: offsets}
+
+	if region := memory_map.IsValidAddress2(uint64(base), mm); region != nil && region.Path != "" && !strings.HasPrefix(region.Path, "[") {
+		module := filepath.Base(region.Path)
+		if moduleBase, ok := memory_map.ModuleBase(module, mm); ok && moduleBase <= uint64(base) {
+			chain.Module = module
+			chain.Base = uint64(base) - moduleBase
+		}
+	}
+
+	return chain.Normalized()
+}
+
+// ParsePointerChain parses the textual form of a chain as produced by String, or any address
+// expression of the same shape (e.g. "game.exe+0x1A2B30+[0x18]+[0x0]+0x40").
+// At most one module name may appear; it is kept symbolic rather than resolved.
+func ParsePointerChain(s string) (PointerChain, error) {
+	var module string
+	resolver := func(name string) (ProcessMemoryAddress, error) {
+		if module != "" && module != name {
+			return 0, fmt.Errorf("pointer chain refers to two modules (%s, %s)", module, name)
+		}
+		module = name
+		return 0, nil
+	}
+
+	expr, err := ParseAddressExpr(s, resolver)
+	if err != nil {
+		return PointerChain{}, err
+	}
+
+	spec, ok := expr.Path()
+	if !ok {
+		return PointerChain{}, fmt.Errorf("pointer chain %q: not a base followed by pointer offsets", s)
+	}
+
+	return PointerChain{Module: module, Base: uint64(spec.Base), Offsets: spec.Offsets}.Normalized(), nil
+}
+
+// Normalized returns the chain with the module reduced to its base name and at least one offset
+func (c PointerChain) Normalized() PointerChain {
+	if c.Module != "" {
+		c.Module = filepath.Base(c.Module)
+	}
+	if len(c.Offsets) == 0 {
+		c.Offsets = []ProcessMemorySize{0}
+	} else {
+		c.Offsets = append([]ProcessMemorySize(nil), c.Offsets...)
+	}
+	return c
+}
+
+// String returns the normalized textual form of the chain, an address expression that
+// ParsePointerChain and ParseAddressExpr accept, suitable for storing in bookmarks
+func (c PointerChain) String() string {
+	c = c.Normalized()
+
+	var sb strings.Builder
+	if c.Module != "" {
+		if strings.ContainsAny(c.Module, "+-*()[] \"") || (c.Module[0] >= '0' && c.Module[0] <= '9') {
+			fmt.Fprintf(&sb, "%q", c.Module)
+		} else {
+			sb.WriteString(c.Module)
+		}
+		if c.Base != 0 {
+			fmt.Fprintf(&sb, "+0x%X", c.Base)
+		}
+	} else {
+		fmt.Fprintf(&sb, "0x%X", c.Base)
+	}
+
+	last := len(c.Offsets) - 1
+	for _, off := range c.Offsets[:last] {
+		fmt.Fprintf(&sb, "+[0x%X]", uint64(off))
+	}
+	if c.Offsets[last] != 0 {
+		fmt.Fprintf(&sb, "+0x%X", uint64(c.Offsets[last]))
+	}
+
+	return sb.String()
+}
+
+// BaseAddress resolves the base of the chain against a memory map
+func (c PointerChain) BaseAddress(mm []memory_map.MemoryMapItem) (ProcessMemoryAddress, error) {
+	if c.Module == "" {
+		return ProcessMemoryAddress(c.Base), nil
+	}
+	moduleBase, ok := memory_map.ModuleBase(c.Module, mm)
+	if !ok {
+		return 0, fmt.Errorf("module %s not found", c.Module)
+	}
+	return ProcessMemoryAddress(moduleBase + c.Base), nil
+}
+
+// ValidatePointerChain walks the chain on proc, a live process or a dump, recording every
+// pointer read. The walk stops at the first pointer that cannot be read, is null or
+// points outside mapped memory.
+func ValidatePointerChain(proc Process, chain PointerChain) ChainResult {
+	chain = chain.Normalized()
+	result := ChainResult{Chain: chain}
+
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Base, result.Err = chain.BaseAddress(mm)
+	if result.Err != nil {
+		return result
+	}
+
+	current := result.Base
+	last := len(chain.Offsets) - 1
+	for i, off := range chain.Offsets[:last] {
+		hop := ChainHop{Address: current + ProcessMemoryAddress(off)}
+
+		hop.Pointer, hop.Err = proc.ReadPOINTER(hop.Address)
+		switch {
+		case hop.Err != nil:
+			hop.Pointer = 0
+		case hop.Pointer == 0:
+			hop.Err = fmt.Errorf("pointer at 0x%X is null", hop.Address)
+		case !proc.IsValidAddress(hop.Pointer):
+			hop.Err = fmt.Errorf("pointer at 0x%X points to unmapped address 0x%X", hop.Address, hop.Pointer)
+		default:
+			hop.Valid = true
+		}
+
+		result.Hops = append(result.Hops, hop)
+		if !hop.Valid {
+			result.Err = fmt.Errorf("hop %d: %w", i, hop.Err)
+			return result
+		}
+		current = hop.Pointer
+	}
+
+	final := current + ProcessMemoryAddress(chain.Offsets[last])
+	if !proc.IsValidAddress(final) {
+		result.Err = fmt.Errorf("final address 0x%X is not mapped", final)
+		return result
+	}
+
+	result.Final = final
+	result.Valid = true
+	return result
+}
+
+// CheckPointerChainStability walks the chain on two snapshots and reports whether it
+// resolves to the same final address in both
+func CheckPointerChainStability(first, second Process, chain PointerChain) ChainStability {
+	stability := ChainStability{
+		First:  ValidatePointerChain(first, chain),
+		Second: ValidatePointerChain(second, chain),
+	}
+	stability.Stable = stability.First.Valid && stability.Second.Valid && stability.First.Final == stability.Second.Final
+	return stability
+}
+
+// ChainRebase is a chain found on one snapshot re-resolved on another, e.g. a dump of the
+// program taken after a restart
+type ChainRebase struct {
+	First    ChainResult
+	Second   ChainResult
+	Expected []byte // Value at the final address in the first snapshot
+	Actual   []byte // Value at the final address in the second snapshot, nil if not reached
+	Matches  bool   // The chain resolves in both snapshots and lands on the same value
+	Err      error  // Reason the chain does not match
+}
+
+// RebasePointerChain walks the chain on first and reads the size bytes it lands on, then
+// walks it on second and reports whether it lands on the same value there. The base is
+// resolved against the memory map of each snapshot, so module relative chains survive ASLR
+// and the final addresses may differ.
+func RebasePointerChain(first, second Process, chain PointerChain, size ProcessMemorySize) ChainRebase {
+	rebase := ChainRebase{
+		First:  ValidatePointerChain(first, chain),
+		Second: ValidatePointerChain(second, chain),
+	}
+
+	switch {
+	case !rebase.First.Valid:
+		rebase.Err = fmt.Errorf("first snapshot: %w", rebase.First.Err)
+		return rebase
+	case !rebase.Second.Valid:
+		rebase.Err = fmt.Errorf("second snapshot: %w", rebase.Second.Err)
+		return rebase
+	}
+
+	var err error
+	if rebase.Expected, err = first.ReadMemory(rebase.First.Final, size); err != nil {
+		rebase.Err = fmt.Errorf("first snapshot: %w", err)
+		return rebase
+	}
+	if rebase.Actual, err = second.ReadMemory(rebase.Second.Final, size); err != nil {
+		rebase.Err = fmt.Errorf("second snapshot: %w", err)
+		return rebase
+	}
+
+	rebase.Matches = bytes.Equal(rebase.Expected, rebase.Actual)
+	if !rebase.Matches {
+		rebase.Err = fmt.Errorf("value changed from %X to %X", rebase.Expected, rebase.Actual)
+	}
+	return rebase
+}
+
+// FilterRebasedChains returns the chains that land on the same size bytes value in second
+// as in first, see RebasePointerChain; chains found by a pointer scan on one dump are
+// checked against a dump of the restarted program to drop the unstable ones
+func FilterRebasedChains(first, second Process, chains []PointerChain, size ProcessMemorySize) []PointerChain {
+	var kept []PointerChain
+	for _, chain := range chains {
+		if RebasePointerChain(first, second, chain, size).Matches {
+			kept = append(kept, chain)
+		}
+	}
+	return kept
+}
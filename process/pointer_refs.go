@@ -0,0 +1,43 @@
+package process
+
+import "runtime"
+
+// PointerReference is a pointer to or into a target address, found by ScanPointersTo
+type PointerReference struct {
+	Address ProcessMemoryAddress // Address of the pointer
+	Offset  ProcessMemorySize    // Target minus the pointer value, the offset of the target in the pointed-to structure
+}
+
+// ScanPointersTo searches the writable memory of proc for 8-byte aligned pointers whose value
+// is in [target-tolerance, target], i.e. the structures and globals referencing target or a
+// structure up to tolerance bytes before it. Call it again with the address of a reference
+// minus its own structure offset to walk up to the owner of a dynamically allocated struct.
+// The scan uses one worker per CPU; the references are sorted by address.
+func ScanPointersTo(proc Process, target ProcessMemoryAddress, tolerance ProcessMemorySize) ([]PointerReference, error) {
+	lo := uint64(target) - min(uint64(tolerance), uint64(target))
+	hi := uint64(target)
+
+	order := ByteOrderOf(proc)
+	options := ScanOptions{MaxDOP: uint(runtime.NumCPU()), WritableOnly: true}
+	addrs, err := scanValues(proc, options, rangeMatcher{size: 8, match: func(data []byte) bool {
+		v := order.Uint64(data)
+		return v >= lo && v <= hi
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the values back to compute the offsets, pointers changed meanwhile are dropped
+	refs := make([]PointerReference, 0, len(addrs))
+	for _, result := range proc.ReadBlobs(addrs, 8) {
+		if result.Err != nil {
+			continue
+		}
+		v := order.Uint64(result.Blob.Data())
+		if v < lo || v > hi {
+			continue
+		}
+		refs = append(refs, PointerReference{Address: result.Address, Offset: ProcessMemorySize(hi - v)})
+	}
+	return refs, nil
+}
@@ -0,0 +1,171 @@
+package process
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PointerTransform decodes a pointer value as stored in memory into the address it refers
+// to, for targets that lightly obfuscate their pointers (XOR with a key, bit rotation, ...).
+// A nil transform leaves pointers unchanged.
+type PointerTransform func(ProcessMemoryAddress) ProcessMemoryAddress
+
+// Apply runs the transform on ptr, a nil transform returns ptr unchanged
+func (t PointerTransform) Apply(ptr ProcessMemoryAddress) ProcessMemoryAddress {
+	if t == nil {
+		return ptr
+	}
+	return t(ptr)
+}
+
+// XorPointer returns a transform XORing pointers with key
+func XorPointer(key uint64) PointerTransform {
+	return func(ptr ProcessMemoryAddress) ProcessMemoryAddress {
+		return ptr ^ ProcessMemoryAddress(key)
+	}
+}
+
+// RotateLeftPointer returns a transform rotating pointers left by n bits
+func RotateLeftPointer(n int) PointerTransform {
+	return func(ptr ProcessMemoryAddress) ProcessMemoryAddress {
+		return ProcessMemoryAddress(bits.RotateLeft64(uint64(ptr), n))
+	}
+}
+
+// RotateRightPointer returns a transform rotating pointers right by n bits
+func RotateRightPointer(n int) PointerTransform {
+	return RotateLeftPointer(-n)
+}
+
+// ComposePointerTransforms returns a transform applying each transform in order
+func ComposePointerTransforms(transforms ...PointerTransform) PointerTransform {
+	return func(ptr ProcessMemoryAddress) ProcessMemoryAddress {
+		for _, t := range transforms {
+			ptr = t.Apply(ptr)
+		}
+		return ptr
+	}
+}
+
+var pointerTransforms = struct {
+	sync.RWMutex
+	byName map[string]PointerTransform
+}{byName: make(map[string]PointerTransform)}
+
+// RegisterPointerTransform registers a named transform, so callback based decoding can be
+// referenced from specs and pod tags (ptr_transform=name)
+func RegisterPointerTransform(name string, transform PointerTransform) {
+	pointerTransforms.Lock()
+	defer pointerTransforms.Unlock()
+	pointerTransforms.byName[name] = transform
+}
+
+// LookupPointerTransform returns the transform registered under name
+func LookupPointerTransform(name string) (PointerTransform, bool) {
+	pointerTransforms.RLock()
+	defer pointerTransforms.RUnlock()
+	t, ok := pointerTransforms.byName[name]
+	return t, ok
+}
+
+// ParsePointerTransform parses a comma separated list of steps applied in order:
+// xor=KEY, rol=N, ror=N and transform=NAME (a registered transform). Numbers are hex with
+// a 0x prefix, decimal otherwise. An empty spec returns a nil transform.
+// Example: "xor=0xDEADBEEF,rol=13".
+func ParsePointerTransform(spec string) (PointerTransform, error) {
+	var steps []PointerTransform
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("pointer transform %q: missing value in %q", spec, part)
+		}
+
+		step, err := pointerTransformStep(strings.TrimSpace(key), strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("pointer transform %q: %w", spec, err)
+		}
+		steps = append(steps, step)
+	}
+
+	switch len(steps) {
+	case 0:
+		return nil, nil
+	case 1:
+		return steps[0], nil
+	}
+	return ComposePointerTransforms(steps...), nil
+}
+
+// pointerTransformStep builds a single step of a transform spec. Keys may carry a ptr_
+// prefix, as they do in pod tags.
+func pointerTransformStep(key, value string) (PointerTransform, error) {
+	key = strings.TrimPrefix(key, "ptr_")
+
+	if key == "transform" {
+		t, ok := LookupPointerTransform(value)
+		if !ok {
+			return nil, fmt.Errorf("unknown transform %q", value)
+		}
+		return t, nil
+	}
+
+	var n uint64
+	var err error
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		n, err = strconv.ParseUint(value[2:], 16, 64)
+	} else {
+		n, err = strconv.ParseUint(value, 10, 64)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %q", key, value)
+	}
+
+	switch key {
+	case "xor":
+		return XorPointer(n), nil
+	case "rol":
+		return RotateLeftPointer(int(n % 64)), nil
+	case "ror":
+		return RotateRightPointer(int(n % 64)), nil
+	}
+	return nil, fmt.Errorf("unknown step %q", key)
+}
+
+// ReadPointerChainTransform is ReadPointerChain for obfuscated pointers: every pointer read
+// along the chain is decoded with transform before it is validated and followed.
+func ReadPointerChainTransform(proc Process, transform PointerTransform, base ProcessMemoryAddress, size ProcessMemorySize, offsets ...ProcessMemorySize) (ProcessReadOffset, error) {
+	current := base
+	for i := 0; i < len(offsets)-1; i++ {
+		addr := current + ProcessMemoryAddress(offsets[i])
+
+		ptr, err := proc.ReadPOINTER(addr)
+		if err != nil {
+			return nil, fmt.Errorf("ReadPointerChain: failed to read pointer at step %d (addr=%#x): %w", i, uint64(addr), err)
+		}
+		ptr = transform.Apply(ptr)
+		if ptr == 0 {
+			return nil, fmt.Errorf("ReadPointerChain: NULL pointer at step %d (addr=%#x + off=%#x)", i, uint64(current), uint64(offsets[i]))
+		}
+		if !proc.IsValidAddress(ptr) {
+			return nil, fmt.Errorf("ReadPointerChain: invalid pointer %#x at step %d (addr=%#x + off=%#x)", uint64(ptr), i, uint64(current), uint64(offsets[i]))
+		}
+		current = ptr
+	}
+
+	if len(offsets) > 0 {
+		current += ProcessMemoryAddress(offsets[len(offsets)-1])
+	}
+
+	blob, err := proc.ReadBlob(current, size)
+	if err != nil {
+		return nil, fmt.Errorf("ReadPointerChain: read blob at %#x (size=%#x) failed: %w", uint64(current), uint64(size), err)
+	}
+	return blob, nil
+}
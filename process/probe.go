@@ -0,0 +1,134 @@
+package process
+
+import (
+	"fmt"
+	"strings"
+
+	"gomem/process/memory_map"
+)
+
+// probeReadSize is the size of the reads ProbeRegions makes at both ends of a region
+const probeReadSize = 16
+
+// RegionProbe is the outcome of probing one region, see ProbeRegions
+type RegionProbe struct {
+	Region   memory_map.MemoryMapItem
+	Readable bool   // Both ends of the region could be read
+	Reason   string // Why the region is not readable, empty when it is
+	Err      error  // Read error, nil when the region is readable or was not read
+}
+
+// String returns a one line description of the probe
+func (p RegionProbe) String() string {
+	s := fmt.Sprintf("%016x-%016x %s %s", p.Region.Address, p.Region.Address+uint64(p.Region.Size), p.Region.Perms, p.Region.Path)
+	if !p.Readable {
+		s += ": " + p.Reason
+	}
+	return s
+}
+
+// ProbeReport lists which regions of a memory map can actually be read
+type ProbeReport struct {
+	Regions []RegionProbe // One entry per region, in memory map order
+}
+
+// Readable returns the probes of the readable regions
+func (r *ProbeReport) Readable() []RegionProbe {
+	return r.filter(true)
+}
+
+// Unreadable returns the probes of the regions that can't be read
+func (r *ProbeReport) Unreadable() []RegionProbe {
+	return r.filter(false)
+}
+
+func (r *ProbeReport) filter(readable bool) []RegionProbe {
+	var out []RegionProbe
+	for _, p := range r.Regions {
+		if p.Readable == readable {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ReadableBytes returns the total size of the readable regions
+func (r *ProbeReport) ReadableBytes() uint64 {
+	var total uint64
+	for _, p := range r.Readable() {
+		total += uint64(p.Region.Size)
+	}
+	return total
+}
+
+// UnreadableBytes returns the total size of the regions that can't be read
+func (r *ProbeReport) UnreadableBytes() uint64 {
+	var total uint64
+	for _, p := range r.Unreadable() {
+		total += uint64(p.Region.Size)
+	}
+	return total
+}
+
+// String returns a summary followed by the unreadable regions and their reason
+func (r *ProbeReport) String() string {
+	var sb strings.Builder
+	readable, unreadable := r.Readable(), r.Unreadable()
+	fmt.Fprintf(&sb, "%d regions, %d readable (%d bytes), %d unreadable (%d bytes)\n",
+		len(r.Regions), len(readable), r.ReadableBytes(), len(unreadable), r.UnreadableBytes())
+	for _, p := range unreadable {
+		fmt.Fprintf(&sb, "  %s\n", p)
+	}
+	return sb.String()
+}
+
+// ProbeRegions tries a small read at the start and at the end of every region of the memory
+// map and reports which regions are actually readable and why the others are not (no read
+// permission, rejected by the process, or the read error). It shows up front what a scan or
+// dump will cover, instead of holes being discovered through scattered read errors.
+func ProbeRegions(proc Process) (*ProbeReport, error) {
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ProbeReport{Regions: make([]RegionProbe, 0, len(mm))}
+	for _, region := range mm {
+		report.Regions = append(report.Regions, probeRegion(proc, region))
+	}
+	return report, nil
+}
+
+// probeRegion reads both ends of a region
+func probeRegion(proc Process, region memory_map.MemoryMapItem) RegionProbe {
+	probe := RegionProbe{Region: region}
+
+	switch {
+	case region.Size == 0:
+		probe.Reason = "empty region"
+		return probe
+	case len(region.Perms) == 0 || !region.IsReadable():
+		probe.Reason = fmt.Sprintf("no read permission (%s)", region.Perms)
+		return probe
+	case !proc.IsValidAddress(ProcessMemoryAddress(region.Address)):
+		probe.Reason = "address rejected by IsValidAddress"
+		return probe
+	}
+
+	size := ProcessMemorySize(min(uint64(region.Size), probeReadSize))
+	ends := []uint64{region.Address}
+	if last := region.Address + uint64(region.Size) - uint64(size); last != region.Address {
+		ends = append(ends, last)
+	}
+
+	for _, addr := range ends {
+		if _, err := proc.ReadMemory(ProcessMemoryAddress(addr), size); err != nil {
+			probe.Reason = fmt.Sprintf("read at 0x%x failed", addr)
+			probe.Err = err
+			return probe
+		}
+	}
+
+	probe.Readable = true
+	return probe
+}
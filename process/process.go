@@ -23,6 +23,10 @@ var (
 	ErrProcessNotOpen = errors.New("process not open")
 
 	ErrInvalidPointer = errors.New("invalid pointer read")
+
+	// ErrPartialRead is returned together with the bytes that could be read when a read
+	// runs into an unreadable page (guard page, PAGE_NOACCESS hole) part way through.
+	ErrPartialRead = errors.New("partial read")
 )
 
 type ReadBlobsResult struct {
@@ -1,12 +1,29 @@
 package process
 
 import (
+	"context"
+	"iter"
+
 	"gomem/process/memory_map"
 )
 
+// BASEADDRESS is the default image base of 64-bit Windows executables.
+//
+// Deprecated: images are relocated by ASLR, use GetModuleBase or ModuleAddress to resolve
+// static offsets against the actual module base.
 var BASEADDRESS = ProcessMemoryAddress(0x140000000)
 
 // Process is the interface that defines operations for interacting with a system process
+//
+// Concurrency: the live process implementations (Linux, Windows and macOS) are safe for
+// concurrent use. Reads, scans and address validation may run in parallel from any number
+// of goroutines; each call works on a consistent snapshot of the memory map taken when it
+// starts, so a concurrent UpdateMemoryMap never affects a call in progress. Open and Close
+// may also be called concurrently with reads, in-flight reads then complete against the
+// previous process or fail with ErrProcessNotOpen. Values returned by GetMemoryMap are
+// copies and may be modified freely. Dumps (process_blob.ProcessDump) only support
+// concurrent reads and scans: WriteMemory, Load and Close must not run concurrently with
+// other calls.
 type Process interface {
 	// Open opens a process with the given PID for memory operations
 	Open(pid ProcessID) error
@@ -29,6 +46,11 @@ type Process interface {
 	// ReadMemory reads memory from the process at the specified address
 	ReadMemory(addr ProcessMemoryAddress, size ProcessMemorySize) ([]byte, error)
 
+	// ReadMemoryPartial reads size bytes at addr like ReadMemory, but returns the bytes that
+	// could be read with the ranges that could not instead of failing the whole read, e.g.
+	// for hexdumps around the edge of a region, see ReadPartial
+	ReadMemoryPartial(addr ProcessMemoryAddress, size ProcessMemorySize) PartialRead
+
 	// WriteMemory writes data to the process memory at the specified address
 	WriteMemory(addr ProcessMemoryAddress, data []byte) error
 
@@ -167,6 +189,13 @@ type MemoryScanner interface {
 	// ScanFirstParallel searches for the first occurrence of a pattern using parallel scanning
 	ScanFirstParallel(aob AOB, maxdop uint) (ProcessMemoryAddress, error)
 
+	// ScanRange searches for a pattern in the memory between start and end (exclusive),
+	// e.g. one module or heap arena
+	ScanRange(aob AOB, start, end ProcessMemoryAddress) ([]ProcessMemoryAddress, error)
+
+	// ScanRangeParallel searches for a pattern between start and end using parallel scanning
+	ScanRangeParallel(aob AOB, start, end ProcessMemoryAddress, maxdop uint) ([]ProcessMemoryAddress, error)
+
 	// ScanInteger searches for an integer value in memory
 	ScanInteger(value int64, size uint) ([]ProcessMemoryAddress, error)
 
@@ -175,4 +204,32 @@ type MemoryScanner interface {
 
 	// ScanString searches for a string in memory
 	ScanString(value string, isUTF16 bool) ([]ProcessMemoryAddress, error)
+
+	// ScanIntegerParallel searches for an integer value using parallel scanning
+	ScanIntegerParallel(value int64, size uint, maxdop uint) ([]ProcessMemoryAddress, error)
+
+	// ScanFloatParallel searches for a float value using parallel scanning
+	ScanFloatParallel(value float64, isFloat32 bool, maxdop uint) ([]ProcessMemoryAddress, error)
+
+	// ScanStringParallel searches for a string using parallel scanning
+	ScanStringParallel(value string, isUTF16 bool, maxdop uint) ([]ProcessMemoryAddress, error)
+
+	// ScanWithOptions searches for a pattern and returns the matches sorted by address,
+	// optionally with a copy of the surrounding bytes so hits don't need to be re-read
+	ScanWithOptions(aob AOB, options ScanOptions) ([]ScanMatch, error)
+
+	// ScanCtx searches for a pattern like Scan, stopping when ctx is done; the matches
+	// found until then are returned with ctx.Err()
+	ScanCtx(ctx context.Context, aob AOB) ([]ProcessMemoryAddress, error)
+
+	// ScanWithOptionsCtx is ScanWithOptions stopping when ctx is done, see ScanCtx
+	ScanWithOptionsCtx(ctx context.Context, aob AOB, options ScanOptions) ([]ScanMatch, error)
+
+	// ScanIter searches for a pattern and yields the addresses in order while the scan runs,
+	// so matches are processed as they are found and breaking out of the loop stops the scan
+	ScanIter(aob AOB) iter.Seq2[ProcessMemoryAddress, error]
+
+	// ScanWithOptionsIter is ScanWithOptionsCtx yielding the matches while the scan runs,
+	// see ScanIter; errors are yielded with a zero ScanMatch
+	ScanWithOptionsIter(ctx context.Context, aob AOB, options ScanOptions) iter.Seq2[ScanMatch, error]
 }
@@ -0,0 +1,68 @@
+package process
+
+import (
+	"fmt"
+)
+
+// MemoryProtector is implemented by processes that can change the page protection of target
+// memory, e.g. to make a read-only data region writable and restore it afterwards
+type MemoryProtector interface {
+	// ProtectMemory sets the permissions of the pages covering [addr, addr+size) to perms
+	// ("r--", "rw-", "r-x", "rwx", ...) and returns the previous permissions of the page at addr,
+	// which can be passed back to restore them
+	ProtectMemory(addr ProcessMemoryAddress, size ProcessMemorySize, perms string) (string, error)
+}
+
+// ProtectMemory changes the protection of a range of proc, see MemoryProtector.
+// It fails for processes that can't change page protections (dumps), ErrReadOnly for
+// read-only wrappers.
+func ProtectMemory(proc Process, addr ProcessMemoryAddress, size ProcessMemorySize, perms string) (string, error) {
+	protector, ok := proc.(MemoryProtector)
+	if !ok {
+		return "", fmt.Errorf("%T does not support changing memory protection", proc)
+	}
+	return protector.ProtectMemory(addr, size, perms)
+}
+
+// ParsePerms parses the first three characters of a permission string in memory map format
+// ("r-x", "rw-p", ...). Each position holds its letter or '-'.
+func ParsePerms(perms string) (read, write, exec bool, err error) {
+	if len(perms) < 3 {
+		return false, false, false, fmt.Errorf("invalid permissions %q", perms)
+	}
+
+	for i, want := range []byte("rwx") {
+		switch perms[i] {
+		case want:
+		case '-':
+			continue
+		default:
+			return false, false, false, fmt.Errorf("invalid permissions %q", perms)
+		}
+		switch i {
+		case 0:
+			read = true
+		case 1:
+			write = true
+		case 2:
+			exec = true
+		}
+	}
+
+	return read, write, exec, nil
+}
+
+// FormatPerms returns the three character permission string of a protection, e.g. "r-x"
+func FormatPerms(read, write, exec bool) string {
+	perms := []byte("---")
+	if read {
+		perms[0] = 'r'
+	}
+	if write {
+		perms[1] = 'w'
+	}
+	if exec {
+		perms[2] = 'x'
+	}
+	return string(perms)
+}
@@ -0,0 +1,120 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrReadTooLarge is returned when a single read exceeds ReadLimits.MaxReadSize
+	ErrReadTooLarge = errors.New("read exceeds the maximum read size")
+
+	// ErrReadTimeout is returned when a read does not complete within ReadLimits.Timeout
+	ErrReadTimeout = errors.New("read timed out")
+)
+
+// ReadLimits caps the individual reads of a process, so a buggy caller can't request
+// gigabytes in one ReadMemory or stall on a slow target. Scans and grouped blob reads
+// honor MaxReadSize by splitting their reads into chunks.
+type ReadLimits struct {
+	// MaxReadSize is the largest single read allowed, 0 for no limit
+	MaxReadSize ProcessMemorySize
+
+	// Timeout is the deadline of a single read, 0 for none.
+	// A read that times out keeps running in the background until the system call
+	// returns, its result is discarded.
+	Timeout time.Duration
+}
+
+// ReadLimiter is implemented by processes whose reads can be limited
+type ReadLimiter interface {
+	// SetReadLimits sets the limits applied to every read
+	SetReadLimits(limits ReadLimits)
+
+	// GetReadLimits returns the current limits
+	GetReadLimits() ReadLimits
+}
+
+// Check returns an error wrapping ErrReadTooLarge if size exceeds MaxReadSize
+func (l ReadLimits) Check(size ProcessMemorySize) error {
+	if l.MaxReadSize > 0 && size > l.MaxReadSize {
+		return fmt.Errorf("%w: %d bytes requested, limit is %d", ErrReadTooLarge, size, l.MaxReadSize)
+	}
+	return nil
+}
+
+// Read checks size against MaxReadSize and runs read under Timeout
+func (l ReadLimits) Read(size ProcessMemorySize, read func() ([]byte, error)) ([]byte, error) {
+	if err := l.Check(size); err != nil {
+		return nil, err
+	}
+
+	if l.Timeout <= 0 {
+		return read()
+	}
+
+	type result struct {
+		data []byte
+		err  error
+	}
+
+	// Buffered so the read goroutine never blocks once the caller has given up
+	done := make(chan result, 1)
+	go func() {
+		data, err := read()
+		done <- result{data, err}
+	}()
+
+	timer := time.NewTimer(l.Timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.data, r.err
+	case <-timer.C:
+		return nil, fmt.Errorf("%w after %v (%d bytes)", ErrReadTimeout, l.Timeout, size)
+	}
+}
+
+// ScanChunk is one read of a chunked region scan
+type ScanChunk struct {
+	Address uint64 // Start of the read
+	Size    uint   // Bytes to read, including the overlap with the next chunk
+	Keep    int    // Matches at offsets >= Keep belong to the next chunk
+}
+
+// ScanChunks splits a region into the reads a scan for a pattern of patternLength bytes issues.
+// Without MaxReadSize the whole region is one chunk. Otherwise consecutive chunks overlap by
+// patternLength-1 bytes so no match is lost, and chunk starts stay multiples of alignment
+// from the region start so aligned scans test the same offsets as an unchunked scan.
+// No chunk exceeds MaxReadSize: when the overlap plus one alignment step doesn't fit in
+// MaxReadSize, e.g. for a pattern longer than MaxReadSize, the error wraps ErrReadTooLarge.
+func (l ReadLimits) ScanChunks(address uint64, size uint, patternLength int, alignment uint) ([]ScanChunk, error) {
+	overlap := uint(max(patternLength-1, 0))
+	alignment = max(alignment, 1)
+
+	if l.MaxReadSize == 0 || size <= uint(l.MaxReadSize) {
+		return []ScanChunk{{Address: address, Size: size, Keep: int(size)}}, nil
+	}
+	if uint(l.MaxReadSize) < overlap+alignment {
+		return nil, fmt.Errorf("%w: can't split the scan of a %d byte pattern aligned to %d at 0x%x, limit is %d",
+			ErrReadTooLarge, patternLength, alignment, address, l.MaxReadSize)
+	}
+
+	step := (uint(l.MaxReadSize) - overlap) / alignment * alignment
+
+	var chunks []ScanChunk
+	for offset := uint(0); offset < size; offset += step {
+		chunk := ScanChunk{
+			Address: address + uint64(offset),
+			Size:    min(step+overlap, size-offset),
+			Keep:    int(step),
+		}
+		chunks = append(chunks, chunk)
+		if offset+chunk.Size >= size {
+			break
+		}
+	}
+	return chunks, nil
+}
@@ -0,0 +1,79 @@
+package process
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrReadOnly is returned by a ReadOnlyProcess for any operation that would modify the target
+var ErrReadOnly = errors.New("process is read-only")
+
+// ReadOnlyProcess wraps a Process and rejects every write with ErrReadOnly, so analysis
+// code can guarantee it never modifies the target even if downstream code tries to.
+// Changing page protections, allocating memory, calling functions and loading a dump over
+// the wrapped process fail with ErrReadOnly as well.
+// Only the methods of the Process interface are reachable through the wrapper and the
+// wrapped process is deliberately not exposed, so platform specific write or patch
+// methods of the underlying implementation cannot be reached by type assertion.
+type ReadOnlyProcess struct {
+	target
+}
+
+// target embeds the wrapped Process under an unexported field name
+type target = Process
+
+// ReadOnly wraps proc so that all writes fail with ErrReadOnly
+func ReadOnly(proc Process) *ReadOnlyProcess {
+	return &ReadOnlyProcess{target: proc}
+}
+
+// ByteOrder returns the byte order of the wrapped process
+func (p *ReadOnlyProcess) ByteOrder() binary.ByteOrder {
+	return ByteOrderOf(p.target)
+}
+
+// GetModules returns the modules of the wrapped process, see process.GetModules
+func (p *ReadOnlyProcess) GetModules() ([]Module, error) {
+	return GetModules(p.target)
+}
+
+// Suspend pauses the wrapped process, see process.Suspend. Pausing doesn't modify the
+// target's memory and lets analysis read a consistent snapshot.
+func (p *ReadOnlyProcess) Suspend() error {
+	return Suspend(p.target)
+}
+
+// Resume lets the wrapped process run again, see process.Resume
+func (p *ReadOnlyProcess) Resume() error {
+	return Resume(p.target)
+}
+
+// WriteMemory always fails with ErrReadOnly
+func (p *ReadOnlyProcess) WriteMemory(addr ProcessMemoryAddress, data []byte) error {
+	return ErrReadOnly
+}
+
+// ProtectMemory always fails with ErrReadOnly
+func (p *ReadOnlyProcess) ProtectMemory(addr ProcessMemoryAddress, size ProcessMemorySize, perms string) (string, error) {
+	return "", ErrReadOnly
+}
+
+// AllocateMemory always fails with ErrReadOnly
+func (p *ReadOnlyProcess) AllocateMemory(size ProcessMemorySize, perms string) (ProcessMemoryAddress, error) {
+	return 0, ErrReadOnly
+}
+
+// FreeMemory always fails with ErrReadOnly
+func (p *ReadOnlyProcess) FreeMemory(addr ProcessMemoryAddress, size ProcessMemorySize) error {
+	return ErrReadOnly
+}
+
+// CallFunction always fails with ErrReadOnly, the called code could modify the target
+func (p *ReadOnlyProcess) CallFunction(addr ProcessMemoryAddress, args ...uint64) (CallResult, error) {
+	return CallResult{}, ErrReadOnly
+}
+
+// Load always fails with ErrReadOnly, it would replace the memory of the wrapped process
+func (p *ReadOnlyProcess) Load(dirname string) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,213 @@
+package process
+
+import (
+	"fmt"
+	"iter"
+	"path/filepath"
+	"strings"
+
+	"gomem/process/memory_map"
+)
+
+// ScanAlignmentBase selects what ScanOptions.Alignment is relative to
+type ScanAlignmentBase int
+
+const (
+	// AlignToAddress aligns match offsets on absolute addresses, i.e. relative to page starts
+	AlignToAddress ScanAlignmentBase = iota
+
+	// AlignToRegion aligns match offsets relative to the start of each region
+	AlignToRegion
+)
+
+// ScanOptions configures ScanWithOptions
+type ScanOptions struct {
+	// MaxDOP is the maximum degree of parallelism, 0 or 1 scans serially
+	MaxDOP uint
+
+	// Filter restricts the scan to the regions it accepts, nil scans every readable region
+	Filter memory_map.RegionFilter
+
+	// Alignment only tests matches at multiples of Alignment (0 or 1 tests every byte),
+	// shifted by AlignmentOffset, e.g. Alignment 0x1000 with AlignmentOffset 0x10 only
+	// tests offset 0x10 of every page
+	Alignment       uint
+	AlignmentOffset uint
+	AlignmentBase   ScanAlignmentBase
+
+	// ContextBefore and ContextAfter are the number of bytes around each match
+	// copied into ScanMatch.Data (clamped to the region), the match itself is always included
+	ContextBefore int
+	ContextAfter  int
+
+	// Suspend pauses the target for the duration of the scan (see Suspend), so values being
+	// written meanwhile aren't torn or missed. Dumps don't change and ignore it.
+	Suspend bool
+
+	// WritableOnly and ExecutableOnly restrict the scan to writable (data) or executable
+	// (code) regions
+	WritableOnly   bool
+	ExecutableOnly bool
+
+	// ExcludeFileBacked skips regions mapped from files (images, mapped files), leaving
+	// heap, stack and anonymous memory
+	ExcludeFileBacked bool
+
+	// Module restricts the scan to the regions mapped from a module, matched against the
+	// base name and the full path of the region, case-insensitive
+	Module string
+
+	// MaxRegionSize skips regions larger than this many bytes, e.g. huge reserved arenas or
+	// graphics buffers, 0 for no limit
+	MaxRegionSize uint
+
+	// Start and End restrict the scan to matches inside [Start, End), e.g. one module or
+	// heap arena; regions are clipped to the range. An End of 0 scans to the end of the
+	// address space.
+	Start ProcessMemoryAddress
+	End   ProcessMemoryAddress
+
+	// ChunkSize is the largest read of the scan, regions larger than it are read in chunks
+	// overlapping by the pattern length, 0 for DefaultScanChunkSize. ReadLimits.MaxReadSize
+	// and cancelable contexts lower it.
+	ChunkSize uint
+
+	// MaxResults stops the scan once this many matches are found, the ones with the lowest
+	// addresses, 0 for no limit. Regions are then scanned in address order, with up to
+	// MaxDOP chunks read ahead, and no chunk is read after the last match is found.
+	MaxResults int
+}
+
+// ScanMatch is a scan hit together with a copy of the memory around it
+type ScanMatch struct {
+	Address     ProcessMemoryAddress // Address of the match
+	DataAddress ProcessMemoryAddress // Address of Data[0]
+	Data        []byte               // Copy of the matched bytes and the requested context, nil if no context was requested
+}
+
+// AlignedStart returns the first offset to test within a region starting at regionAddress
+// and the step between tested offsets
+func (o ScanOptions) AlignedStart(regionAddress uint64) (start int, step int) {
+	if o.Alignment <= 1 {
+		return 0, 1
+	}
+
+	alignment := uint64(o.Alignment)
+	offset := uint64(o.AlignmentOffset) % alignment
+
+	if o.AlignmentBase == AlignToRegion {
+		return int(offset), int(alignment)
+	}
+
+	// First address >= regionAddress with address % alignment == offset
+	return int((offset + alignment - regionAddress%alignment) % alignment), int(alignment)
+}
+
+// Accepts reports whether a region is scanned: readable, and accepted by the region options
+// and Filter
+func (o ScanOptions) Accepts(region memory_map.MemoryMapItem) bool {
+	if len(region.Perms) == 0 || !region.IsReadable() {
+		return false
+	}
+	if o.WritableOnly && (len(region.Perms) < 2 || !region.IsWritable()) {
+		return false
+	}
+	if o.ExecutableOnly && !region.IsExecutable() {
+		return false
+	}
+	if o.ExcludeFileBacked && region.Kind() == memory_map.RegionImage {
+		return false
+	}
+	if o.Module != "" && !strings.EqualFold(region.Path, o.Module) && !strings.EqualFold(filepath.Base(region.Path), o.Module) {
+		return false
+	}
+	if o.MaxRegionSize > 0 && region.Size > o.MaxRegionSize {
+		return false
+	}
+	return o.Filter == nil || o.Filter(region)
+}
+
+// RangeScanOptions returns the options of a scan between start and end with up to maxdop
+// workers, for the ScanRange methods
+func RangeScanOptions(start, end ProcessMemoryAddress, maxdop uint) (ScanOptions, error) {
+	if end <= start {
+		return ScanOptions{}, fmt.Errorf("invalid scan range 0x%X-0x%X", uint64(start), uint64(end))
+	}
+	return ScanOptions{MaxDOP: maxdop, Start: start, End: end}, nil
+}
+
+// ClipRegion returns the part of a region to read for the Start and End range, false when
+// the region is outside of it. With AlignToRegion the start is moved back onto the alignment
+// grid of the region, matches before Start must then be skipped, see InRange.
+func (o ScanOptions) ClipRegion(region memory_map.MemoryMapItem) (address uint64, size uint, ok bool) {
+	start, end := region.Address, region.Address+uint64(region.Size)
+	if o.End != 0 {
+		end = min(end, uint64(o.End))
+	}
+	if uint64(o.Start) > start {
+		start = uint64(o.Start)
+		if o.AlignmentBase == AlignToRegion && o.Alignment > 1 {
+			start -= (start - region.Address) % uint64(o.Alignment)
+		}
+	}
+	if start >= end {
+		return 0, 0, false
+	}
+	return start, uint(end - start), true
+}
+
+// InRange reports whether a match at addr is inside the Start and End range
+func (o ScanOptions) InRange(addr ProcessMemoryAddress) bool {
+	return addr >= o.Start && (o.End == 0 || addr < o.End)
+}
+
+// MatchOffset returns the offset of the match within Data
+func (m ScanMatch) MatchOffset() int {
+	return int(m.Address - m.DataAddress)
+}
+
+// NewScanMatch builds the match found at offset within a region's data, copying
+// patternLength bytes plus the context requested by options out of data.
+func NewScanMatch(regionAddress uint64, data []byte, offset int, patternLength int, options ScanOptions) ScanMatch {
+	match := ScanMatch{
+		Address:     ProcessMemoryAddress(regionAddress + uint64(offset)),
+		DataAddress: ProcessMemoryAddress(regionAddress + uint64(offset)),
+	}
+
+	if options.ContextBefore <= 0 && options.ContextAfter <= 0 {
+		return match
+	}
+
+	start := max(offset-max(options.ContextBefore, 0), 0)
+	end := min(offset+patternLength+max(options.ContextAfter, 0), len(data))
+
+	match.DataAddress = ProcessMemoryAddress(regionAddress + uint64(start))
+	match.Data = make([]byte, end-start)
+	copy(match.Data, data[start:end])
+
+	return match
+}
+
+// ScanMatchAddresses returns the addresses of the matches
+func ScanMatchAddresses(matches []ScanMatch) []ProcessMemoryAddress {
+	if matches == nil {
+		return nil
+	}
+
+	addresses := make([]ProcessMemoryAddress, len(matches))
+	for i, match := range matches {
+		addresses[i] = match.Address
+	}
+	return addresses
+}
+
+// ScanMatchAddressesIter yields the addresses of the matches of a streaming scan
+func ScanMatchAddressesIter(matches iter.Seq2[ScanMatch, error]) iter.Seq2[ProcessMemoryAddress, error] {
+	return func(yield func(ProcessMemoryAddress, error) bool) {
+		for match, err := range matches {
+			if !yield(match.Address, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package process
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"runtime"
+	"sort"
+	"sync"
+
+	"gomem/process/memory_map"
+)
+
+// RegionReadFunc reads size bytes at addr for the scan engine
+type RegionReadFunc func(addr ProcessMemoryAddress, size ProcessMemorySize) ([]byte, error)
+
+// DefaultScanChunkSize is the largest read of a scan when ScanOptions.ChunkSize is 0, so huge
+// mappings are scanned without allocating their whole size per worker
+const DefaultScanChunkSize = 64 << 20
+
+// ContextScanChunkSize is the largest read of a cancelable scan, so cancellation is noticed
+// within large regions too
+const ContextScanChunkSize = 16 << 20
+
+// ScanRegions is the scan engine shared by the process implementations.
+// It searches the readable regions accepted by options (see ScanOptions.Accepts), clipped to
+// the Start and End range of options, with up to options.MaxDOP workers (capped to the number
+// of CPUs), reading regions larger than options.ChunkSize or limits.MaxReadSize in overlapping
+// chunks, and returns the matches sorted by address.
+// Regions that fail to read are skipped and reported to onReadError, which may be nil.
+func ScanRegions(regions []memory_map.MemoryMapItem, read RegionReadFunc, aob AOB, options ScanOptions, limits ReadLimits, onReadError func(addr uint64, err error)) ([]ScanMatch, error) {
+	return ScanRegionsCtx(context.Background(), regions, read, aob, options, limits, onReadError)
+}
+
+// ScanRegionsCtx is ScanRegions stopping when ctx is done: no further chunk is read, at most
+// ContextScanChunkSize bytes, and the matches found so far are returned with ctx.Err().
+func ScanRegionsCtx(ctx context.Context, regions []memory_map.MemoryMapItem, read RegionReadFunc, aob AOB, options ScanOptions, limits ReadLimits, onReadError func(addr uint64, err error)) ([]ScanMatch, error) {
+	matcher, err := newAOBMatcher(aob)
+	if err != nil {
+		return nil, err
+	}
+	return ScanRegionsMatcherCtx(ctx, regions, read, matcher, options, limits, onReadError)
+}
+
+// ScanRegionsMatcherCtx is ScanRegionsCtx searching with any ScanMatcher, e.g. one that
+// evaluates the values at the tested offsets instead of comparing bytes. Find is called
+// concurrently by the workers.
+func ScanRegionsMatcherCtx(ctx context.Context, regions []memory_map.MemoryMapItem, read RegionReadFunc, matcher ScanMatcher, options ScanOptions, limits ReadLimits, onReadError func(addr uint64, err error)) ([]ScanMatch, error) {
+	if matcher.Len() == 0 {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	// Limited scans stream the matches in address order and stop at the limit
+	if options.MaxResults > 0 {
+		var results []ScanMatch
+		for match, err := range ScanRegionsMatcherIter(ctx, regions, read, matcher, options, limits, onReadError) {
+			if err != nil {
+				return results, err
+			}
+			results = append(results, match)
+		}
+		return results, nil
+	}
+
+	// Limit maxdop to number of CPUs if it's too large
+	maxdop := min(max(options.MaxDOP, 1), uint(runtime.NumCPU()))
+
+	// Create a semaphore to limit concurrency
+	sem := make(chan struct{}, maxdop)
+	var wg sync.WaitGroup
+
+	// Create a mutex for results
+	var resultsMutex sync.Mutex
+	var results []ScanMatch
+
+	chunks, err := scanChunks(ctx, regions, matcher, options, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scan each readable memory region
+	for _, chunk := range chunks {
+		// Acquire a semaphore slot, unless the scan is canceled meanwhile
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+
+		go func(chunk ScanChunk) {
+			defer func() {
+				// Release the semaphore slot
+				<-sem
+				wg.Done()
+			}()
+
+			// If there are matches, add them to the results
+			if matches := scanChunk(ctx, chunk, read, matcher, options, onReadError); len(matches) > 0 {
+				resultsMutex.Lock()
+				results = append(results, matches...)
+				resultsMutex.Unlock()
+			}
+		}(chunk)
+	}
+
+	// Wait for all goroutines to finish
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Address < results[j].Address
+	})
+
+	return results, ctx.Err()
+}
+
+// ScanRegionsIter is ScanRegionsCtx yielding the matches in address order while the scan
+// runs, so callers can process them as they are found and stop early without accumulating
+// every match. Up to options.MaxDOP chunks are read ahead of the one being yielded. Breaking
+// out of the loop stops the scan; an invalid pattern or ctx being done is yielded as an
+// error with a zero ScanMatch, after the matches found until then. options.MaxResults ends
+// the sequence after that many matches.
+func ScanRegionsIter(ctx context.Context, regions []memory_map.MemoryMapItem, read RegionReadFunc, aob AOB, options ScanOptions, limits ReadLimits, onReadError func(addr uint64, err error)) iter.Seq2[ScanMatch, error] {
+	matcher, err := newAOBMatcher(aob)
+	if err != nil {
+		return func(yield func(ScanMatch, error) bool) {
+			yield(ScanMatch{}, err)
+		}
+	}
+	return ScanRegionsMatcherIter(ctx, regions, read, matcher, options, limits, onReadError)
+}
+
+// ScanRegionsMatcherIter is ScanRegionsIter searching with any ScanMatcher, see
+// ScanRegionsMatcherCtx
+func ScanRegionsMatcherIter(ctx context.Context, regions []memory_map.MemoryMapItem, read RegionReadFunc, matcher ScanMatcher, options ScanOptions, limits ReadLimits, onReadError func(addr uint64, err error)) iter.Seq2[ScanMatch, error] {
+	return func(yield func(ScanMatch, error) bool) {
+		if matcher.Len() == 0 {
+			yield(ScanMatch{}, fmt.Errorf("empty pattern"))
+			return
+		}
+
+		chunks, err := scanChunks(ctx, regions, matcher, options, limits)
+		if err != nil {
+			yield(ScanMatch{}, err)
+			return
+		}
+		sort.SliceStable(chunks, func(i, j int) bool {
+			return chunks[i].Address < chunks[j].Address
+		})
+
+		// The workers stop reading when the caller breaks out of the loop
+		scanCtx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		// Chunk i is scanned by a worker sending its matches to pending[i]
+		maxdop := int(min(max(options.MaxDOP, 1), uint(runtime.NumCPU())))
+		pending := make([]chan []ScanMatch, len(chunks))
+		started, yielded := 0, 0
+		for i := range chunks {
+			for ; started < len(chunks) && started < i+maxdop; started++ {
+				ch := make(chan []ScanMatch, 1)
+				pending[started] = ch
+				wg.Add(1)
+				go func(chunk ScanChunk) {
+					defer wg.Done()
+					ch <- scanChunk(scanCtx, chunk, read, matcher, options, onReadError)
+				}(chunks[started])
+			}
+
+			var matches []ScanMatch
+			select {
+			case matches = <-pending[i]:
+			case <-ctx.Done():
+			}
+			if err := ctx.Err(); err != nil {
+				yield(ScanMatch{}, err)
+				return
+			}
+			pending[i] = nil
+
+			for _, match := range matches {
+				if !yield(match, nil) {
+					return
+				}
+				if yielded++; options.MaxResults > 0 && yielded >= options.MaxResults {
+					return
+				}
+			}
+		}
+	}
+}
+
+// newAOBMatcher validates aob and returns its matcher; without a mask the pattern is
+// matched exactly
+func newAOBMatcher(aob AOB) (*PatternMatcher, error) {
+	// Validate the AOB
+	if len(aob.Pattern) == 0 {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	// If no mask is provided, create a mask of all 0xFF (exact match)
+	if len(aob.Mask) == 0 {
+		aob.Mask = bytes.Repeat([]byte{0xFF}, len(aob.Pattern))
+	} else if len(aob.Mask) != len(aob.Pattern) {
+		return nil, fmt.Errorf("mask length (%d) doesn't match pattern length (%d)",
+			len(aob.Mask), len(aob.Pattern))
+	}
+
+	// The skip table is built once and shared by the workers
+	return NewPatternMatcher(aob.Pattern, aob.Mask), nil
+}
+
+// scanChunks returns the reads of a scan: the readable regions accepted by options, clipped
+// to the scan range, with large regions split in overlapping chunks
+func scanChunks(ctx context.Context, regions []memory_map.MemoryMapItem, matcher ScanMatcher, options ScanOptions, limits ReadLimits) ([]ScanChunk, error) {
+	// Large regions are read in chunks, in smaller ones for cancelable scans, which check ctx
+	// between chunks
+	chunkSize := ProcessMemorySize(options.ChunkSize)
+	if chunkSize == 0 {
+		chunkSize = DefaultScanChunkSize
+	}
+	if limits.MaxReadSize == 0 || limits.MaxReadSize > chunkSize {
+		limits.MaxReadSize = chunkSize
+	}
+	if ctx.Done() != nil && limits.MaxReadSize > ContextScanChunkSize {
+		limits.MaxReadSize = ContextScanChunkSize
+	}
+
+	var chunks []ScanChunk
+	for _, region := range regions {
+		// Skip non-readable regions and regions rejected by the options
+		if !options.Accepts(region) {
+			continue
+		}
+
+		// Only the part of the region inside the scan range is read
+		address, size, ok := options.ClipRegion(region)
+		if !ok {
+			continue
+		}
+
+		// Regions larger than the maximum read size are scanned in overlapping chunks
+		regionChunks, err := limits.ScanChunks(address, size, matcher.Len(), options.Alignment)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, regionChunks...)
+	}
+	return chunks, nil
+}
+
+// scanChunk reads one chunk and returns its matches, nil when ctx is done or the read fails
+func scanChunk(ctx context.Context, chunk ScanChunk, read RegionReadFunc, matcher ScanMatcher, options ScanOptions, onReadError func(addr uint64, err error)) []ScanMatch {
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	data, err := read(ProcessMemoryAddress(chunk.Address), ProcessMemorySize(chunk.Size))
+	if errors.Is(err, ErrPartialRead) && len(data) > 0 {
+		// Scan the readable prefix of a region with a hole
+		err = nil
+	}
+	if err != nil {
+		// Some regions might fail to read due to permissions or other reasons
+		if onReadError != nil && err != ErrAddressNotMapped {
+			onReadError(chunk.Address, err)
+		}
+		return nil
+	}
+
+	// Search for matches in this chunk. Chunks start at multiples of the alignment from the
+	// region start, so region relative alignment carries over.
+	start, step := options.AlignedStart(chunk.Address)
+
+	var matches []ScanMatch
+	for _, offset := range matcher.Find(data, start, step) {
+		// Matches in the overlap are reported by the next chunk
+		if offset >= chunk.Keep {
+			continue
+		}
+		if !options.InRange(ProcessMemoryAddress(chunk.Address + uint64(offset))) {
+			continue
+		}
+		matches = append(matches, NewScanMatch(chunk.Address, data, offset, matcher.Len(), options))
+	}
+	return matches
+}
+
+// FindPatternMatches returns the offsets start, start+step, ... of data where the masked pattern matches.
+// A mask byte of 0 is a wildcard, other mask bytes select the compared bits.
+// Searches of the same pattern in many buffers should reuse a PatternMatcher instead.
+func FindPatternMatches(data, pattern, mask []byte, start, step int) []int {
+	if len(data) < len(pattern) {
+		return nil
+	}
+	return NewPatternMatcher(pattern, mask).Find(data, start, step)
+}
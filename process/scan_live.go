@@ -0,0 +1,211 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"gomem/process/memory_map"
+)
+
+// ScanTarget is what LiveScanner needs from a live process: its memory map, reads and read
+// limits, and suspension for ScanOptions.Suspend
+type ScanTarget interface {
+	GetMemoryMap() ([]memory_map.MemoryMapItem, error)
+	ReadMemory(addr ProcessMemoryAddress, size ProcessMemorySize) ([]byte, error)
+	GetReadLimits() ReadLimits
+	Suspender
+}
+
+// ScanLogger receives the progress of the scans of a LiveScanner, e.g. a *logger.Logger
+type ScanLogger interface {
+	Infoln(v ...interface{})
+	Debugln(v ...interface{})
+}
+
+// LiveScanner implements the scan methods of the live process backends on top of
+// ScanRegionsCtx and ScanRegionsIter, the backends delegate to it:
+//
+//	func (p *LinuxProcess) Scan(aob process.AOB) ([]process.ProcessMemoryAddress, error) {
+//		return process.LiveScanner{Target: p, Log: p.getLog()}.Scan(aob)
+//	}
+type LiveScanner struct {
+	Target ScanTarget
+	Log    ScanLogger
+}
+
+// Scan searches for the given pattern in the process memory
+// and returns all matching addresses
+func (s LiveScanner) Scan(aob AOB) ([]ProcessMemoryAddress, error) {
+	return s.ScanParallel(aob, 1)
+}
+
+// ScanParallel searches for the given pattern in parallel
+// maxdop controls the maximum degree of parallelism
+func (s LiveScanner) ScanParallel(aob AOB, maxdop uint) ([]ProcessMemoryAddress, error) {
+	matches, err := s.ScanWithOptions(aob, ScanOptions{MaxDOP: maxdop})
+	if err != nil {
+		return nil, err
+	}
+	return ScanMatchAddresses(matches), nil
+}
+
+// ScanRange searches for the given pattern between start and end (exclusive)
+func (s LiveScanner) ScanRange(aob AOB, start, end ProcessMemoryAddress) ([]ProcessMemoryAddress, error) {
+	return s.ScanRangeParallel(aob, start, end, 1)
+}
+
+// ScanRangeParallel searches for the given pattern between start and end in parallel
+func (s LiveScanner) ScanRangeParallel(aob AOB, start, end ProcessMemoryAddress, maxdop uint) ([]ProcessMemoryAddress, error) {
+	options, err := RangeScanOptions(start, end, maxdop)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.ScanWithOptions(aob, options)
+	if err != nil {
+		return nil, err
+	}
+	return ScanMatchAddresses(matches), nil
+}
+
+// ScanCtx searches for the given pattern until ctx is done, see ScanWithOptionsCtx
+func (s LiveScanner) ScanCtx(ctx context.Context, aob AOB) ([]ProcessMemoryAddress, error) {
+	matches, err := s.ScanWithOptionsCtx(ctx, aob, ScanOptions{})
+	return ScanMatchAddresses(matches), err
+}
+
+// ScanWithOptions searches for the given pattern in the readable regions of the process
+// and returns the matches sorted by address. Each region is read once; when context is
+// requested the bytes around each match are copied from that buffer.
+func (s LiveScanner) ScanWithOptions(aob AOB, options ScanOptions) ([]ScanMatch, error) {
+	return s.ScanWithOptionsCtx(context.Background(), aob, options)
+}
+
+// ScanWithOptionsCtx is ScanWithOptions stopping when ctx is done, checked before each region
+// and every ContextScanChunkSize bytes. The matches found until then are returned with
+// ctx.Err().
+func (s LiveScanner) ScanWithOptionsCtx(ctx context.Context, aob AOB, options ScanOptions) ([]ScanMatch, error) {
+	if options.Suspend {
+		if err := s.Target.Suspend(); err != nil {
+			return nil, err
+		}
+		defer s.Target.Resume()
+	}
+
+	// Get the memory map to know which regions to scan
+	memMap, err := s.Target.GetMemoryMap()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory map: %w", err)
+	}
+
+	// Log that we're starting a scan
+	s.Log.Infoln("Starting memory scan for pattern of length", len(aob.Pattern), "with maxdop=", max(options.MaxDOP, 1))
+
+	results, err := ScanRegionsCtx(ctx, memMap, s.Target.ReadMemory, aob, options, s.Target.GetReadLimits(), s.onReadError)
+	if err != nil {
+		s.Log.Infoln("Scan stopped, found", len(results), "matches:", err)
+		return results, err
+	}
+
+	s.Log.Infoln("Scan complete, found", len(results), "matches")
+	return results, nil
+}
+
+// ScanIter searches for the given pattern and yields the addresses in order while the scan
+// runs, see ScanWithOptionsIter
+func (s LiveScanner) ScanIter(aob AOB) iter.Seq2[ProcessMemoryAddress, error] {
+	return ScanMatchAddressesIter(s.ScanWithOptionsIter(context.Background(), aob, ScanOptions{}))
+}
+
+// ScanWithOptionsIter is ScanWithOptionsCtx yielding the matches in address order while the
+// scan runs; breaking out of the loop stops reading. With options.Suspend the process stays
+// suspended until the loop ends.
+func (s LiveScanner) ScanWithOptionsIter(ctx context.Context, aob AOB, options ScanOptions) iter.Seq2[ScanMatch, error] {
+	return func(yield func(ScanMatch, error) bool) {
+		if options.Suspend {
+			if err := s.Target.Suspend(); err != nil {
+				yield(ScanMatch{}, err)
+				return
+			}
+			defer s.Target.Resume()
+		}
+
+		memMap, err := s.Target.GetMemoryMap()
+		if err != nil {
+			yield(ScanMatch{}, fmt.Errorf("failed to get memory map: %w", err))
+			return
+		}
+
+		s.Log.Infoln("Starting streaming memory scan for pattern of length", len(aob.Pattern), "with maxdop=", max(options.MaxDOP, 1))
+
+		matches := ScanRegionsIter(ctx, memMap, s.Target.ReadMemory, aob, options, s.Target.GetReadLimits(), s.onReadError)
+		for match, err := range matches {
+			if !yield(match, err) {
+				return
+			}
+		}
+	}
+}
+
+// onReadError logs a region that failed to read, the scan skips it
+func (s LiveScanner) onReadError(addr uint64, err error) {
+	s.Log.Debugln("Failed to read memory region at", fmt.Sprintf("%x", addr), err)
+}
+
+// ScanFirst searches for the first occurrence of the pattern
+func (s LiveScanner) ScanFirst(aob AOB) (ProcessMemoryAddress, error) {
+	return s.ScanFirstParallel(aob, 1)
+}
+
+// ScanFirstParallel searches for the first occurrence of the pattern in parallel, the lowest
+// address. The scan stops at the first match instead of reading the remaining regions.
+func (s LiveScanner) ScanFirstParallel(aob AOB, maxdop uint) (ProcessMemoryAddress, error) {
+	results, err := s.ScanWithOptions(aob, ScanOptions{MaxDOP: maxdop, MaxResults: 1})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(results) == 0 {
+		return 0, fmt.Errorf("pattern not found")
+	}
+
+	return results[0].Address, nil
+}
+
+// ScanInteger searches for an integer value in memory
+func (s LiveScanner) ScanInteger(value int64, size uint) ([]ProcessMemoryAddress, error) {
+	return s.ScanIntegerParallel(value, size, 1)
+}
+
+// ScanIntegerParallel searches for an integer value in memory in parallel, encoded in the byte
+// order of the process
+func (s LiveScanner) ScanIntegerParallel(value int64, size uint, maxdop uint) ([]ProcessMemoryAddress, error) {
+	aob, err := IntegerAOB(value, size, ByteOrderOf(s.Target))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ScanParallel(aob, maxdop)
+}
+
+// ScanFloat searches for a float value in memory
+func (s LiveScanner) ScanFloat(value float64, isFloat32 bool) ([]ProcessMemoryAddress, error) {
+	return s.ScanFloatParallel(value, isFloat32, 1)
+}
+
+// ScanFloatParallel searches for a float value in memory in parallel, encoded in the byte order
+// of the process
+func (s LiveScanner) ScanFloatParallel(value float64, isFloat32 bool, maxdop uint) ([]ProcessMemoryAddress, error) {
+	return s.ScanParallel(FloatAOB(value, isFloat32, ByteOrderOf(s.Target)), maxdop)
+}
+
+// ScanString searches for a string in memory.
+// In UTF-16 mode the string is encoded as UTF-16LE and '?' matches any single code unit, see UTF16AOB.
+func (s LiveScanner) ScanString(value string, isUTF16 bool) ([]ProcessMemoryAddress, error) {
+	return s.ScanStringParallel(value, isUTF16, 1)
+}
+
+// ScanStringParallel searches for a string in memory in parallel, see ScanString
+func (s LiveScanner) ScanStringParallel(value string, isUTF16 bool, maxdop uint) ([]ProcessMemoryAddress, error) {
+	return s.ScanParallel(StringAOB(value, isUTF16), maxdop)
+}
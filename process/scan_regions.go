@@ -0,0 +1,64 @@
+package process
+
+import (
+	"gomem/process/memory_map"
+)
+
+// ScanHeap searches for a pattern in heap and anonymous read-write memory only.
+// Code, read-only data and mapped files are skipped, which is where most scan time is wasted.
+func ScanHeap(scanner MemoryScanner, aob AOB) ([]ProcessMemoryAddress, error) {
+	return scanRegions(scanner, aob, memory_map.HeapFilter)
+}
+
+// ScanStack searches for a pattern in stack regions only
+func ScanStack(scanner MemoryScanner, aob AOB) ([]ProcessMemoryAddress, error) {
+	return scanRegions(scanner, aob, memory_map.StackFilter)
+}
+
+// ScanHeapInteger searches for an integer value in heap memory, encoded in the byte order of
+// scanner, see ScanHeap
+func ScanHeapInteger(scanner MemoryScanner, value int64, size uint) ([]ProcessMemoryAddress, error) {
+	aob, err := IntegerAOB(value, size, ByteOrderOf(scanner))
+	if err != nil {
+		return nil, err
+	}
+	return ScanHeap(scanner, aob)
+}
+
+// ScanStackInteger searches for an integer value in stack memory, see ScanHeapInteger
+func ScanStackInteger(scanner MemoryScanner, value int64, size uint) ([]ProcessMemoryAddress, error) {
+	aob, err := IntegerAOB(value, size, ByteOrderOf(scanner))
+	if err != nil {
+		return nil, err
+	}
+	return ScanStack(scanner, aob)
+}
+
+// ScanHeapFloat searches for a float value in heap memory, see ScanHeapInteger
+func ScanHeapFloat(scanner MemoryScanner, value float64, isFloat32 bool) ([]ProcessMemoryAddress, error) {
+	return ScanHeap(scanner, FloatAOB(value, isFloat32, ByteOrderOf(scanner)))
+}
+
+// ScanStackFloat searches for a float value in stack memory, see ScanHeapInteger
+func ScanStackFloat(scanner MemoryScanner, value float64, isFloat32 bool) ([]ProcessMemoryAddress, error) {
+	return ScanStack(scanner, FloatAOB(value, isFloat32, ByteOrderOf(scanner)))
+}
+
+// ScanHeapString searches for a string in heap memory, see ScanHeap
+func ScanHeapString(scanner MemoryScanner, value string, isUTF16 bool) ([]ProcessMemoryAddress, error) {
+	return ScanHeap(scanner, StringAOB(value, isUTF16))
+}
+
+// ScanStackString searches for a string in stack memory, see ScanStack
+func ScanStackString(scanner MemoryScanner, value string, isUTF16 bool) ([]ProcessMemoryAddress, error) {
+	return ScanStack(scanner, StringAOB(value, isUTF16))
+}
+
+// scanRegions scans the regions accepted by filter
+func scanRegions(scanner MemoryScanner, aob AOB, filter memory_map.RegionFilter) ([]ProcessMemoryAddress, error) {
+	matches, err := scanner.ScanWithOptions(aob, ScanOptions{Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	return ScanMatchAddresses(matches), nil
+}
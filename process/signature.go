@@ -0,0 +1,155 @@
+package process
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gomem/process/memory_map"
+)
+
+// minSignatureLength is the shortest signature GenerateSignature returns, shorter patterns
+// that happen to be unique are unlikely to survive an update
+const minSignatureLength = 5
+
+// GenerateSignature derives a pattern matching the code at addr once in its module, for
+// exporting a reusable signature after finding an address by hand. Up to maxLen bytes are
+// read at addr and operands that likely change between builds or loads are wildcarded:
+//
+//   - rel32 operands of call, jmp and jcc, and RIP-relative displacements, whose target
+//     lies in the module
+//   - 64-bit immediates of movabs pointing into mapped memory
+//   - 32-bit values that are addresses inside the module, as in 32-bit code
+//
+// The shortest prefix of the masked bytes that is unique in the module is returned; the
+// pattern matches at addr. Instructions are not decoded, so the wildcards are heuristics
+// for x86 and x86-64 code.
+func GenerateSignature(proc Process, addr ProcessMemoryAddress, maxLen int) (AOB, error) {
+	if maxLen < minSignatureLength {
+		return AOB{}, fmt.Errorf("invalid length %d, expected at least %d", maxLen, minSignatureLength)
+	}
+
+	module, err := moduleOf(proc, addr)
+	if err != nil {
+		return AOB{}, err
+	}
+
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		return AOB{}, fmt.Errorf("failed to get memory map: %w", err)
+	}
+	region := memory_map.IsValidAddress2(uint64(addr), mm)
+	if region == nil {
+		return AOB{}, fmt.Errorf("address 0x%X is not mapped", uint64(addr))
+	}
+
+	// Stay inside the region, the next one may not be readable
+	size := min(uint64(maxLen), region.Address+uint64(region.Size)-uint64(addr))
+	data, err := proc.ReadMemory(addr, ProcessMemorySize(size))
+	if err != nil {
+		return AOB{}, fmt.Errorf("failed to read 0x%X: %w", uint64(addr), err)
+	}
+
+	aob := signatureAOB(addr, data, module, mm)
+
+	// Longer prefixes match a subset of the addresses shorter ones match, so uniqueness is
+	// monotonic in the length and the shortest unique prefix can be bisected
+	unique := func(n int) (bool, error) {
+		prefix := AOB{Pattern: aob.Pattern[:n], Mask: aob.Mask[:n]}
+		matches, err := proc.ScanWithOptions(prefix, ScanOptions{Start: module.Base, End: module.End(), MaxResults: 2})
+		if err != nil {
+			return false, err
+		}
+		return len(matches) == 1, nil
+	}
+
+	lo, hi := min(minSignatureLength, len(aob.Pattern)), len(aob.Pattern)
+	if ok, err := unique(hi); err != nil {
+		return AOB{}, err
+	} else if !ok {
+		return AOB{}, fmt.Errorf("no unique signature within %d bytes of 0x%X", hi, uint64(addr))
+	}
+	for lo < hi {
+		mid := (lo + hi) / 2
+		ok, err := unique(mid)
+		if err != nil {
+			return AOB{}, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	// Trailing wildcards don't narrow the matches, end the signature with the next fixed byte
+	// instead, or drop them when there is none
+	n := hi
+	for n < len(aob.Mask) && aob.Mask[n-1] == 0 {
+		n++
+	}
+	for n > 1 && aob.Mask[n-1] == 0 {
+		n--
+	}
+	return AOB{Pattern: aob.Pattern[:n], Mask: aob.Mask[:n]}, nil
+}
+
+// moduleOf returns the module of proc containing addr
+func moduleOf(proc Process, addr ProcessMemoryAddress) (Module, error) {
+	modules, err := GetModules(proc)
+	if err != nil {
+		return Module{}, err
+	}
+	for _, m := range modules {
+		if m.Contains(addr) {
+			return m, nil
+		}
+	}
+	return Module{}, fmt.Errorf("address 0x%X is not in a module", uint64(addr))
+}
+
+// signatureAOB returns data with the likely relocated operands wildcarded, see
+// GenerateSignature
+func signatureAOB(addr ProcessMemoryAddress, data []byte, module Module, mm []memory_map.MemoryMapItem) AOB {
+	aob := AOB{Pattern: append([]byte(nil), data...), Mask: make([]byte, len(data))}
+	for i := range aob.Mask {
+		aob.Mask[i] = 0xFF
+	}
+	wildcard := func(i, n int) {
+		for j := i; j < i+n; j++ {
+			aob.Pattern[j], aob.Mask[j] = 0, 0
+		}
+	}
+
+	for i := 0; i < len(data); i++ {
+		// Target of a rel32 at i, relative to the end of the operand
+		rel32 := func() bool {
+			if i+4 > len(data) {
+				return false
+			}
+			disp := int64(int32(binary.LittleEndian.Uint32(data[i:])))
+			return module.Contains(ProcessMemoryAddress(int64(addr) + int64(i) + 4 + disp))
+		}
+
+		switch {
+		case i > 0 && (data[i-1] == 0xE8 || data[i-1] == 0xE9) && rel32():
+			// call rel32, jmp rel32
+		case i > 1 && data[i-2] == 0x0F && data[i-1]&0xF0 == 0x80 && rel32():
+			// jcc rel32
+		case i > 1 && data[i-1]&0xC7 == 0x05 && rel32():
+			// ModRM of a RIP-relative operand (mod 00, r/m 101) followed by disp32
+		case i > 1 && data[i-2]&0xF8 == 0x48 && data[i-1]&0xF8 == 0xB8 && i+8 <= len(data) &&
+			memory_map.IsValidAddress2(binary.LittleEndian.Uint64(data[i:]), mm) != nil:
+			// movabs r64, imm64 (REX.W B8+r)
+			wildcard(i, 8)
+			i += 7
+			continue
+		case i+4 <= len(data) && module.Contains(ProcessMemoryAddress(binary.LittleEndian.Uint32(data[i:]))):
+			// Absolute address of a 32-bit module
+		default:
+			continue
+		}
+		wildcard(i, 4)
+		i += 3
+	}
+	return aob
+}
@@ -0,0 +1,49 @@
+package process
+
+import (
+	"unicode/utf16"
+)
+
+// StringAOB builds the pattern ScanString searches for: the UTF-8 bytes of value, or its UTF-16 encoding (see UTF16AOB)
+func StringAOB(value string, isUTF16 bool) AOB {
+	if !isUTF16 {
+		return AOB{Pattern: []byte(value)}
+	}
+	return UTF16AOB(value)
+}
+
+// UTF16AOB builds a little-endian UTF-16 pattern for value.
+// Characters outside the BMP are encoded as surrogate pairs. A '?' matches any single
+// code unit; use `\?` for a literal question mark and `\\` for a literal backslash.
+func UTF16AOB(value string) AOB {
+	var aob AOB
+
+	escaped := false
+	for _, r := range value {
+		if !escaped {
+			switch r {
+			case '\\':
+				escaped = true
+				continue
+			case '?':
+				aob.Pattern = append(aob.Pattern, 0, 0)
+				aob.Mask = append(aob.Mask, 0, 0)
+				continue
+			}
+		}
+		escaped = false
+
+		for _, unit := range utf16.Encode([]rune{r}) {
+			aob.Pattern = append(aob.Pattern, byte(unit), byte(unit>>8))
+			aob.Mask = append(aob.Mask, 0xFF, 0xFF)
+		}
+	}
+
+	// A trailing backslash is taken literally
+	if escaped {
+		aob.Pattern = append(aob.Pattern, '\\', 0)
+		aob.Mask = append(aob.Mask, 0xFF, 0xFF)
+	}
+
+	return aob
+}
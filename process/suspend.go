@@ -0,0 +1,62 @@
+package process
+
+import (
+	"fmt"
+)
+
+// Suspender is implemented by processes that can pause every thread of the target, so memory
+// that changes quickly can be read as a consistent snapshot without torn values
+type Suspender interface {
+	// Suspend stops every thread of the target until Resume is called
+	Suspend() error
+
+	// Resume lets the threads stopped by Suspend run again
+	Resume() error
+}
+
+// Suspend pauses proc, see Suspender.
+// It fails for processes that can't be suspended (dumps, wrappers of those).
+func Suspend(proc Process) error {
+	suspender, ok := proc.(Suspender)
+	if !ok {
+		return fmt.Errorf("%T does not support suspending the process", proc)
+	}
+	return suspender.Suspend()
+}
+
+// Resume lets proc run again after Suspend
+func Resume(proc Process) error {
+	suspender, ok := proc.(Suspender)
+	if !ok {
+		return fmt.Errorf("%T does not support suspending the process", proc)
+	}
+	return suspender.Resume()
+}
+
+// WhileSuspended runs fn with proc suspended and resumes it afterwards, also when fn fails
+func WhileSuspended(proc Process, fn func() error) error {
+	if err := Suspend(proc); err != nil {
+		return err
+	}
+	err := fn()
+	if resumeErr := Resume(proc); resumeErr != nil && err == nil {
+		err = resumeErr
+	}
+	return err
+}
+
+// SaveOptions controls SaveWithOptions
+type SaveOptions struct {
+	// Suspend pauses the target while it is saved, so the dump is a consistent snapshot
+	Suspend bool
+}
+
+// SaveWithOptions saves proc to a directory like Process.Save
+func SaveWithOptions(proc Process, dirname string, options SaveOptions) error {
+	if options.Suspend {
+		return WhileSuspended(proc, func() error {
+			return proc.Save(dirname)
+		})
+	}
+	return proc.Save(dirname)
+}
@@ -0,0 +1,25 @@
+package process
+
+import "fmt"
+
+// Thread is a thread of a process
+type Thread struct {
+	ID        int                  // Thread ID, the TID on Linux
+	Name      string               // Thread name, empty where the OS records none
+	StackBase ProcessMemoryAddress // Lowest address of the stack, 0 if it couldn't be found
+	StackSize uint64               // Size of the stack, 0 if it couldn't be found
+}
+
+// ThreadLister is implemented by processes that can list their threads
+type ThreadLister interface {
+	GetThreads() ([]Thread, error)
+}
+
+// GetThreads returns the threads of proc, see ThreadLister
+func GetThreads(proc Process) ([]Thread, error) {
+	lister, ok := proc.(ThreadLister)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support listing threads", proc)
+	}
+	return lister.GetThreads()
+}
@@ -0,0 +1,39 @@
+package process
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// IntegerAOB builds the pattern of an integer of size 1, 2, 4 or 8 bytes in byte order order,
+// usually ByteOrderOf the scanned process
+func IntegerAOB(value int64, size uint, order binary.ByteOrder) (AOB, error) {
+	pattern := make([]byte, size)
+	switch size {
+	case 1:
+		pattern[0] = byte(value)
+	case 2:
+		order.PutUint16(pattern, uint16(value))
+	case 4:
+		order.PutUint32(pattern, uint32(value))
+	case 8:
+		order.PutUint64(pattern, uint64(value))
+	default:
+		return AOB{}, fmt.Errorf("invalid integer size: %d", size)
+	}
+	return AOB{Pattern: pattern}, nil
+}
+
+// FloatAOB builds the pattern of a float32 or float64 value in byte order order, see IntegerAOB
+func FloatAOB(value float64, isFloat32 bool, order binary.ByteOrder) AOB {
+	if isFloat32 {
+		pattern := make([]byte, 4)
+		order.PutUint32(pattern, math.Float32bits(float32(value)))
+		return AOB{Pattern: pattern}
+	}
+
+	pattern := make([]byte, 8)
+	order.PutUint64(pattern, math.Float64bits(value))
+	return AOB{Pattern: pattern}
+}
@@ -0,0 +1,107 @@
+package process
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// ScanIntegerRange searches the readable memory of proc for signed integers of size 1, 2, 4
+// or 8 bytes between min and max (inclusive), in the byte order of proc. Unlike ScanInteger
+// every candidate is decoded, so only offsets aligned to size are tested.
+func ScanIntegerRange(proc Process, min, max int64, size uint) ([]ProcessMemoryAddress, error) {
+	switch size {
+	case 1, 2, 4, 8:
+	default:
+		return nil, fmt.Errorf("invalid integer size: %d", size)
+	}
+	if min > max {
+		return nil, fmt.Errorf("invalid range: %d > %d", min, max)
+	}
+
+	order := ByteOrderOf(proc)
+	shift := 64 - 8*size
+	return scanValues(proc, ScanOptions{}, rangeMatcher{size: int(size), match: func(data []byte) bool {
+		v := int64(decodeUint(data, order)<<shift) >> shift
+		return v >= min && v <= max
+	}})
+}
+
+// ScanFloatRange searches the readable memory of proc for float32 or float64 values between
+// min and max (inclusive), in the byte order of proc. NaNs never match. Only offsets aligned
+// to the size of the float are tested.
+func ScanFloatRange(proc Process, min, max float64, isFloat32 bool) ([]ProcessMemoryAddress, error) {
+	if !(min <= max) {
+		return nil, fmt.Errorf("invalid range: %g > %g", min, max)
+	}
+
+	order := ByteOrderOf(proc)
+	size := 8
+	if isFloat32 {
+		size = 4
+	}
+	return scanValues(proc, ScanOptions{}, rangeMatcher{size: size, match: func(data []byte) bool {
+		var v float64
+		if isFloat32 {
+			v = float64(math.Float32frombits(order.Uint32(data)))
+		} else {
+			v = math.Float64frombits(order.Uint64(data))
+		}
+		return v >= min && v <= max
+	}})
+}
+
+// scanValues runs the scan engine with matcher over the regions of proc accepted by options,
+// testing offsets aligned to the value size
+func scanValues(proc Process, options ScanOptions, matcher rangeMatcher) ([]ProcessMemoryAddress, error) {
+	regions, err := proc.GetMemoryMap()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory map: %w", err)
+	}
+
+	var limits ReadLimits
+	if limiter, ok := proc.(ReadLimiter); ok {
+		limits = limiter.GetReadLimits()
+	}
+
+	options.Alignment = uint(matcher.size)
+	matches, err := ScanRegionsMatcherCtx(context.Background(), regions, proc.ReadMemory, matcher, options, limits, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ScanMatchAddresses(matches), nil
+}
+
+// rangeMatcher is a ScanMatcher testing the value at every offset with match
+type rangeMatcher struct {
+	size  int
+	match func(data []byte) bool
+}
+
+func (m rangeMatcher) Len() int {
+	return m.size
+}
+
+func (m rangeMatcher) Find(data []byte, start, step int) []int {
+	var matches []int
+	for i := start; i+m.size <= len(data); i += max(step, 1) {
+		if m.match(data[i : i+m.size]) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// decodeUint reads an unsigned integer of len(data) bytes, 1, 2, 4 or 8, in the byte order order
+func decodeUint(data []byte, order binary.ByteOrder) uint64 {
+	switch len(data) {
+	case 1:
+		return uint64(data[0])
+	case 2:
+		return uint64(order.Uint16(data))
+	case 4:
+		return uint64(order.Uint32(data))
+	}
+	return order.Uint64(data)
+}
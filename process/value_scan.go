@@ -0,0 +1,36 @@
+package process
+
+import "fmt"
+
+// ScanIntegerAligned searches for an integer value at addresses that are multiples of
+// alignment (1, 2, 4 or 8). Aligning to the size of the value skips the misaligned hits
+// straddling two neighboring values and tests a fraction of the offsets; 1 tests every byte
+// like ScanInteger. The value is encoded in the byte order of scanner, see ByteOrderOf.
+func ScanIntegerAligned(scanner MemoryScanner, value int64, size, alignment uint) ([]ProcessMemoryAddress, error) {
+	aob, err := IntegerAOB(value, size, ByteOrderOf(scanner))
+	if err != nil {
+		return nil, err
+	}
+	return scanAligned(scanner, aob, alignment)
+}
+
+// ScanFloatAligned searches for a float value at addresses that are multiples of alignment,
+// see ScanIntegerAligned
+func ScanFloatAligned(scanner MemoryScanner, value float64, isFloat32 bool, alignment uint) ([]ProcessMemoryAddress, error) {
+	return scanAligned(scanner, FloatAOB(value, isFloat32, ByteOrderOf(scanner)), alignment)
+}
+
+// scanAligned scans for the pattern at addresses that are multiples of alignment
+func scanAligned(scanner MemoryScanner, aob AOB, alignment uint) ([]ProcessMemoryAddress, error) {
+	switch alignment {
+	case 1, 2, 4, 8:
+	default:
+		return nil, fmt.Errorf("invalid alignment: %d, expected 1, 2, 4 or 8", alignment)
+	}
+
+	matches, err := scanner.ScanWithOptions(aob, ScanOptions{Alignment: alignment})
+	if err != nil {
+		return nil, err
+	}
+	return ScanMatchAddresses(matches), nil
+}
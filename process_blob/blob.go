@@ -10,6 +10,7 @@ import (
 type ProcessBlob struct {
 	baseaddress process.ProcessMemoryAddress
 	data        []byte
+	order       binary.ByteOrder // nil for little-endian
 }
 
 var _ process.ProcessRead = (*ProcessBlob)(nil)
@@ -23,10 +24,32 @@ func NewProcessBlob(baseAddress process.ProcessMemoryAddress, data []byte) *Proc
 	}
 }
 
+// NewProcessBlobWithByteOrder creates a blob over data read from memory in the given byte order
+func NewProcessBlobWithByteOrder(baseAddress process.ProcessMemoryAddress, data []byte, order binary.ByteOrder) *ProcessBlob {
+	return &ProcessBlob{
+		baseaddress: baseAddress,
+		data:        data,
+		order:       order,
+	}
+}
+
+// ByteOrder returns the byte order typed reads of the blob use, little-endian by default
+func (p *ProcessBlob) ByteOrder() binary.ByteOrder {
+	if p.order == nil {
+		return binary.LittleEndian
+	}
+	return p.order
+}
+
 func (p *ProcessBlob) Data() []byte {
 	return p.data
 }
 
+// BaseAddress returns the address the blob data was read from
+func (p *ProcessBlob) BaseAddress() process.ProcessMemoryAddress {
+	return p.baseaddress
+}
+
 func (p *ProcessBlob) ReadMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ([]byte, error) {
 	if addr < p.baseaddress || process.ProcessMemorySize(addr)+size > process.ProcessMemorySize(p.baseaddress)+process.ProcessMemorySize(len(p.data)) {
 		return nil, errors.New("address out of bounds")
@@ -50,7 +73,7 @@ func (p *ProcessBlob) ReadUINT16(addr process.ProcessMemoryAddress) (uint16, err
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint16(data), nil
+	return p.ByteOrder().Uint16(data), nil
 }
 
 // ReadUINT32 reads an unsigned 32-bit integer from the specified address
@@ -59,7 +82,7 @@ func (p *ProcessBlob) ReadUINT32(addr process.ProcessMemoryAddress) (uint32, err
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint32(data), nil
+	return p.ByteOrder().Uint32(data), nil
 }
 
 // ReadUINT64 reads an unsigned 64-bit integer from the specified address
@@ -68,7 +91,7 @@ func (p *ProcessBlob) ReadUINT64(addr process.ProcessMemoryAddress) (uint64, err
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint64(data), nil
+	return p.ByteOrder().Uint64(data), nil
 }
 
 // ReadINT8 reads a signed 8-bit integer from the specified address
@@ -86,7 +109,7 @@ func (p *ProcessBlob) ReadINT16(addr process.ProcessMemoryAddress) (int16, error
 	if err != nil {
 		return 0, err
 	}
-	return int16(binary.LittleEndian.Uint16(data)), nil
+	return int16(p.ByteOrder().Uint16(data)), nil
 }
 
 // ReadINT32 reads a signed 32-bit integer from the specified address
@@ -95,7 +118,7 @@ func (p *ProcessBlob) ReadINT32(addr process.ProcessMemoryAddress) (int32, error
 	if err != nil {
 		return 0, err
 	}
-	return int32(binary.LittleEndian.Uint32(data)), nil
+	return int32(p.ByteOrder().Uint32(data)), nil
 }
 
 // ReadINT64 reads a signed 64-bit integer from the specified address
@@ -104,7 +127,7 @@ func (p *ProcessBlob) ReadINT64(addr process.ProcessMemoryAddress) (int64, error
 	if err != nil {
 		return 0, err
 	}
-	return int64(binary.LittleEndian.Uint64(data)), nil
+	return int64(p.ByteOrder().Uint64(data)), nil
 }
 
 // ReadFLOAT32 reads a 32-bit floating point number from the specified address
@@ -113,7 +136,7 @@ func (p *ProcessBlob) ReadFLOAT32(addr process.ProcessMemoryAddress) (float32, e
 	if err != nil {
 		return 0, err
 	}
-	bits := binary.LittleEndian.Uint32(data)
+	bits := p.ByteOrder().Uint32(data)
 	return *(*float32)(unsafe.Pointer(&bits)), nil
 }
 
@@ -123,7 +146,7 @@ func (p *ProcessBlob) ReadFLOAT64(addr process.ProcessMemoryAddress) (float64, e
 	if err != nil {
 		return 0, err
 	}
-	bits := binary.LittleEndian.Uint64(data)
+	bits := p.ByteOrder().Uint64(data)
 	return *(*float64)(unsafe.Pointer(&bits)), nil
 }
 
@@ -164,7 +187,7 @@ func (p *ProcessBlob) ReadPOINTER(addr process.ProcessMemoryAddress) (process.Pr
 	}
 
 	// Read as uint64 for 64-bit pointers
-	ptr := binary.LittleEndian.Uint64(data)
+	ptr := p.ByteOrder().Uint64(data)
 	return process.ProcessMemoryAddress(ptr), nil
 }
 
@@ -199,7 +222,7 @@ func (p *ProcessBlob) ReadBlob(addr process.ProcessMemoryAddress, size process.P
 		return nil, errors.New("read less data than requested")
 	}
 
-	return NewProcessBlob(addr, data[:size]), nil
+	return NewProcessBlobWithByteOrder(addr, data[:size], p.order), nil
 }
 
 func (p *ProcessBlob) ReadPointers(base process.ProcessMemoryAddress, count int) (results []process.ProcessMemoryAddress, err error) {
@@ -0,0 +1,45 @@
+package process_blob
+
+import (
+	"fmt"
+	"sort"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// NewProcessFromBytes returns a process over in-memory buffers, one region per entry keyed by
+// its base address. The regions are anonymous and readable/writable in the memory map, and
+// everything a loaded dump supports (typed reads, pod, scanning, pointer chains) works on
+// them, which is useful for tests, fuzzing struct decoders or memory captured by other tools.
+//
+// The buffers are used as is, not copied. Overlapping or empty regions are rejected.
+func NewProcessFromBytes(regions map[process.ProcessMemoryAddress][]byte) (*ProcessDump, error) {
+	p := NewProcessDump()
+	p.Name = "memory"
+
+	for addr, data := range regions {
+		if len(data) == 0 {
+			return nil, fmt.Errorf("region 0x%x is empty", uint64(addr))
+		}
+		p.MemoryMap = append(p.MemoryMap, memory_map.MemoryMapItem{
+			Address: uint64(addr),
+			Size:    uint(len(data)),
+			Perms:   "rw-p",
+		})
+		p.Blobs[uint64(addr)] = data
+	}
+
+	sort.Slice(p.MemoryMap, func(i, j int) bool {
+		return p.MemoryMap[i].Address < p.MemoryMap[j].Address
+	})
+
+	for i := 1; i < len(p.MemoryMap); i++ {
+		prev := p.MemoryMap[i-1]
+		if prev.Address+uint64(prev.Size) > p.MemoryMap[i].Address {
+			return nil, fmt.Errorf("region 0x%x overlaps region 0x%x", p.MemoryMap[i].Address, prev.Address)
+		}
+	}
+
+	return p, nil
+}
@@ -1,11 +1,14 @@
 package process_blob
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"unsafe"
 
@@ -13,12 +16,18 @@ import (
 	"gomem/process/memory_map"
 )
 
-// ProcessDump implements process.Process for a loaded process dump
+// ProcessDump implements process.Process for a loaded process dump. Reads and scans may run
+// concurrently, WriteMemory, Load and Close must not run concurrently with other calls.
 type ProcessDump struct {
 	PID       process.ProcessID
 	Name      string
 	MemoryMap []memory_map.MemoryMapItem
 	Blobs     map[uint64][]byte // Address -> Data
+	Modules   []process.Module  // Modules at capture time, empty for dumps saved without them
+	Threads   []process.Thread  // Threads at capture time, empty for dumps saved without them
+
+	order    binary.ByteOrder  // byte order of the dumped memory, nil for little-endian
+	original map[uint64][]byte // Captured data of the regions patched by WriteMemory
 }
 
 // NewProcessDump creates a new ProcessDump instance
@@ -28,6 +37,20 @@ func NewProcessDump() *ProcessDump {
 	}
 }
 
+// SetByteOrder sets the byte order of the dumped memory, overriding the one recorded by Save.
+// Call it after Load for dumps written without one, e.g. big-endian console memory.
+func (p *ProcessDump) SetByteOrder(order binary.ByteOrder) {
+	p.order = order
+}
+
+// ByteOrder returns the byte order of the dumped memory, little-endian by default
+func (p *ProcessDump) ByteOrder() binary.ByteOrder {
+	if p.order == nil {
+		return binary.LittleEndian
+	}
+	return p.order
+}
+
 func (p *ProcessDump) Open(pid process.ProcessID) error {
 	return fmt.Errorf("Open not supported for ProcessDump, use Load")
 }
@@ -35,6 +58,7 @@ func (p *ProcessDump) Open(pid process.ProcessID) error {
 func (p *ProcessDump) Close() error {
 	p.Blobs = nil
 	p.MemoryMap = nil
+	p.original = nil
 	return nil
 }
 
@@ -46,6 +70,23 @@ func (p *ProcessDump) UpdateMemoryMap() error {
 	return nil // Memory map is static in a dump
 }
 
+// GetModules returns the modules recorded when the dump was saved, or for dumps saved
+// without them the modules derived from the memory map, see process.GetModules
+func (p *ProcessDump) GetModules() ([]process.Module, error) {
+	if len(p.Modules) == 0 {
+		return process.ModulesFromMemoryMap(p.MemoryMap), nil
+	}
+	return slices.Clone(p.Modules), nil
+}
+
+// GetThreads returns the threads recorded when the dump was saved
+func (p *ProcessDump) GetThreads() ([]process.Thread, error) {
+	if len(p.Threads) == 0 {
+		return nil, fmt.Errorf("dump has no thread list")
+	}
+	return slices.Clone(p.Threads), nil
+}
+
 func (p *ProcessDump) IsValidAddress(addr process.ProcessMemoryAddress) bool {
 	return memory_map.IsValidAddress(uint64(addr), p.MemoryMap)
 }
@@ -56,42 +97,127 @@ func (p *ProcessDump) GetMemoryMap() ([]memory_map.MemoryMapItem, error) {
 	return result, nil
 }
 
+// ReadMemory returns size bytes at addr. Reads running past the end of a region continue in
+// the next one when it starts right there and was captured, so reads around region edges
+// work as on the live process.
 func (p *ProcessDump) ReadMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ([]byte, error) {
-	// Find the region containing the address
-	region := memory_map.GetMemoryRegionForAddress(uint64(addr), p.MemoryMap)
-	if region == nil {
-		return nil, process.ErrAddressNotMapped
-	}
+	result := []byte{}
+	for cur := uint64(addr); ; {
+		// Find the region containing the address
+		region := memory_map.GetMemoryRegionForAddress(cur, p.MemoryMap)
+		if region == nil {
+			if cur == uint64(addr) {
+				return nil, process.ErrAddressNotMapped
+			}
+			return nil, fmt.Errorf("read size %d exceeds region data bounds: 0x%x is not mapped", size, cur)
+		}
 
-	// Check if we have data for this region
-	data, ok := p.Blobs[region.Address]
-	if !ok {
-		return nil, fmt.Errorf("no data for region 0x%x", region.Address)
-	}
+		// Check if we have data for this region
+		data, ok := p.Blobs[region.Address]
+		if !ok {
+			return nil, fmt.Errorf("no data for region 0x%x", region.Address)
+		}
 
-	offset := uint64(addr) - region.Address
-	if offset >= uint64(len(data)) {
-		return nil, fmt.Errorf("address 0x%x out of bounds of region data", addr)
-	}
+		offset := cur - region.Address
+		if offset >= uint64(len(data)) {
+			return nil, fmt.Errorf("address 0x%x out of bounds of region data", cur)
+		}
+
+		n := min(uint64(size)-uint64(len(result)), uint64(len(data))-offset)
+		result = append(result, data[offset:offset+n]...)
+		cur += n
+		if uint64(len(result)) == uint64(size) {
+			return result, nil
+		}
 
-	if offset+uint64(size) > uint64(len(data)) {
-		return nil, fmt.Errorf("read size %d exceeds region data bounds", size)
+		// Only continue past the end of the region, not past the end of a partial capture
+		if offset+n < uint64(region.Size) {
+			return nil, fmt.Errorf("read size %d exceeds region data bounds", size)
+		}
 	}
+}
 
-	result := make([]byte, size)
-	copy(result, data[offset:offset+uint64(size)])
-	return result, nil
+// ReadMemoryPartial reads size bytes at addr, keeping the captured bytes around holes and
+// regions that were not captured, see process.ReadPartial
+func (p *ProcessDump) ReadMemoryPartial(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) process.PartialRead {
+	return process.ReadPartial(p.ReadMemory, addr, size)
 }
 
-func (p *ProcessDump) WriteMemory(addr process.ProcessMemoryAddress, data []byte) error {
-	return fmt.Errorf("WriteMemory not supported for ProcessDump")
+// DumpMetadata is the metadata.json file of a saved dump, written by the Save method of the
+// live processes and of ProcessDump
+type DumpMetadata struct {
+	PID       process.ProcessID `json:"pid"`
+	Name      string            `json:"name"`
+	ByteOrder string            `json:"byte_order"`
+	TotalSize uint64            `json:"total_size"`
+	Blobs     []BlobChecksum    `json:"blobs"`
+	Modules   []process.Module  `json:"modules"`
+	Threads   []process.Thread  `json:"threads"`
 }
 
+// Save writes the dump to dirname in the format of process dumps, including the changes made
+// with WriteMemory, so a patched dump can be loaded again with Load
 func (p *ProcessDump) Save(dirname string) error {
-	return fmt.Errorf("Save not supported for ProcessDump (already saved)")
+	if err := os.MkdirAll(dirname, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	metadata := DumpMetadata{
+		PID:       p.PID,
+		Name:      p.Name,
+		ByteOrder: process.ByteOrderName(p.ByteOrder()),
+		Modules:   p.Modules,
+		Threads:   p.Threads,
+	}
+
+	for _, region := range p.MemoryMap {
+		data, ok := p.Blobs[region.Address]
+		if !ok {
+			continue // Region was not captured
+		}
+
+		filename := filepath.Join(dirname, fmt.Sprintf("blob_0x%x_%d.bin", region.Address, region.Size))
+		if err := os.WriteFile(filename, data, 0644); err != nil {
+			return fmt.Errorf("failed to write blob %s: %w", filename, err)
+		}
+		metadata.Blobs = append(metadata.Blobs, NewBlobChecksum(region.Address, data))
+		metadata.TotalSize += uint64(len(data))
+	}
+
+	memoryMapJSON, err := json.MarshalIndent(p.MemoryMap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal memory map: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dirname, "process_memory_map.json"), memoryMapJSON, 0644); err != nil {
+		return fmt.Errorf("failed to write memory map file: %w", err)
+	}
+
+	metadataJSON, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dirname, "metadata.json"), metadataJSON, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+
+	return nil
 }
 
+// Load loads the dump saved in dirname, verifying the blobs against the checksums of the
+// metadata, see LoadWithOptions
 func (p *ProcessDump) Load(dirname string) error {
+	return p.LoadWithOptions(dirname, LoadOptions{})
+}
+
+// LoadWithOptions loads the dump saved in dirname. Unless options.SkipVerify is set, a blob
+// file that is missing, truncated or does not match its SHA-256 in metadata.json fails the
+// load; dumps saved without checksums are loaded unverified.
+func (p *ProcessDump) LoadWithOptions(dirname string, options LoadOptions) error {
+	// Loading replaces the regions and modifications of a dump loaded before
+	p.Blobs = make(map[uint64][]byte)
+	p.MemoryMap = nil
+	p.original = nil
+
 	// Read metadata
 	metadataPath := filepath.Join(dirname, "metadata.json")
 	metadataBytes, err := os.ReadFile(metadataPath)
@@ -99,15 +225,23 @@ func (p *ProcessDump) Load(dirname string) error {
 		return fmt.Errorf("failed to read metadata: %w", err)
 	}
 
-	var metadata struct {
-		PID  process.ProcessID `json:"pid"`
-		Name string            `json:"name"`
-	}
+	var metadata DumpMetadata
 	if err := json.Unmarshal(metadataBytes, &metadata); err != nil {
 		return fmt.Errorf("failed to unmarshal metadata: %w", err)
 	}
 	p.PID = metadata.PID
 	p.Name = metadata.Name
+	p.Modules = metadata.Modules
+	p.Threads = metadata.Threads
+
+	// Dumps written before the byte order was recorded are little-endian
+	if metadata.ByteOrder != "" {
+		order, err := process.ParseByteOrder(metadata.ByteOrder)
+		if err != nil {
+			return fmt.Errorf("invalid metadata: %w", err)
+		}
+		p.order = order
+	}
 
 	// Read memory map
 	mmPath := filepath.Join(dirname, "process_memory_map.json")
@@ -125,12 +259,25 @@ func (p *ProcessDump) Load(dirname string) error {
 		return p.MemoryMap[i].Address < p.MemoryMap[j].Address
 	})
 
+	// Checksums of the saved blobs, keyed by region address
+	checksums := make(map[uint64]BlobChecksum, len(metadata.Blobs))
+	if !options.SkipVerify {
+		for _, checksum := range metadata.Blobs {
+			checksums[checksum.Address] = checksum
+		}
+	}
+
 	// Load blobs
+	var totalSize uint64
 	for _, region := range p.MemoryMap {
 		// Skip if not readable (logic from Save)
 		// But we should check if file exists
 		filename := filepath.Join(dirname, fmt.Sprintf("blob_0x%x_%d.bin", region.Address, region.Size))
+		checksum, saved := checksums[region.Address]
 		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			if saved {
+				return fmt.Errorf("invalid dump: blob %s is missing", filename)
+			}
 			continue // Blob not saved (e.g. too large or not readable)
 		}
 
@@ -138,8 +285,23 @@ func (p *ProcessDump) Load(dirname string) error {
 		if err != nil {
 			return fmt.Errorf("failed to read blob %s: %w", filename, err)
 		}
+		if saved {
+			if err := checksum.Verify(data); err != nil {
+				return fmt.Errorf("invalid dump: %w", err)
+			}
+		}
 
 		p.Blobs[region.Address] = data
+		totalSize += uint64(len(data))
+	}
+
+	if !options.SkipVerify && len(metadata.Blobs) > 0 {
+		if len(checksums) != len(p.Blobs) {
+			return fmt.Errorf("invalid dump: %d blobs loaded, %d were saved", len(p.Blobs), len(checksums))
+		}
+		if totalSize != metadata.TotalSize {
+			return fmt.Errorf("invalid dump: %d bytes loaded, %d were saved", totalSize, metadata.TotalSize)
+		}
 	}
 
 	return nil
@@ -167,7 +329,7 @@ func (p *ProcessDump) ReadUINT16(addr process.ProcessMemoryAddress) (uint16, err
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint16(data), nil
+	return p.ByteOrder().Uint16(data), nil
 }
 
 func (p *ProcessDump) ReadUINT32(addr process.ProcessMemoryAddress) (uint32, error) {
@@ -175,7 +337,7 @@ func (p *ProcessDump) ReadUINT32(addr process.ProcessMemoryAddress) (uint32, err
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint32(data), nil
+	return p.ByteOrder().Uint32(data), nil
 }
 
 func (p *ProcessDump) ReadUINT64(addr process.ProcessMemoryAddress) (uint64, error) {
@@ -183,7 +345,7 @@ func (p *ProcessDump) ReadUINT64(addr process.ProcessMemoryAddress) (uint64, err
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint64(data), nil
+	return p.ByteOrder().Uint64(data), nil
 }
 
 func (p *ProcessDump) ReadINT8(addr process.ProcessMemoryAddress) (int8, error) {
@@ -199,7 +361,7 @@ func (p *ProcessDump) ReadINT16(addr process.ProcessMemoryAddress) (int16, error
 	if err != nil {
 		return 0, err
 	}
-	return int16(binary.LittleEndian.Uint16(data)), nil
+	return int16(p.ByteOrder().Uint16(data)), nil
 }
 
 func (p *ProcessDump) ReadINT32(addr process.ProcessMemoryAddress) (int32, error) {
@@ -207,7 +369,7 @@ func (p *ProcessDump) ReadINT32(addr process.ProcessMemoryAddress) (int32, error
 	if err != nil {
 		return 0, err
 	}
-	return int32(binary.LittleEndian.Uint32(data)), nil
+	return int32(p.ByteOrder().Uint32(data)), nil
 }
 
 func (p *ProcessDump) ReadINT64(addr process.ProcessMemoryAddress) (int64, error) {
@@ -215,7 +377,7 @@ func (p *ProcessDump) ReadINT64(addr process.ProcessMemoryAddress) (int64, error
 	if err != nil {
 		return 0, err
 	}
-	return int64(binary.LittleEndian.Uint64(data)), nil
+	return int64(p.ByteOrder().Uint64(data)), nil
 }
 
 func (p *ProcessDump) ReadFLOAT32(addr process.ProcessMemoryAddress) (float32, error) {
@@ -223,7 +385,7 @@ func (p *ProcessDump) ReadFLOAT32(addr process.ProcessMemoryAddress) (float32, e
 	if err != nil {
 		return 0, err
 	}
-	bits := binary.LittleEndian.Uint32(data)
+	bits := p.ByteOrder().Uint32(data)
 	return *(*float32)(unsafe.Pointer(&bits)), nil
 }
 
@@ -232,7 +394,7 @@ func (p *ProcessDump) ReadFLOAT64(addr process.ProcessMemoryAddress) (float64, e
 	if err != nil {
 		return 0, err
 	}
-	bits := binary.LittleEndian.Uint64(data)
+	bits := p.ByteOrder().Uint64(data)
 	return *(*float64)(unsafe.Pointer(&bits)), nil
 }
 
@@ -257,7 +419,7 @@ func (p *ProcessDump) ReadPOINTER(addr process.ProcessMemoryAddress) (process.Pr
 	if err != nil {
 		return 0, err
 	}
-	return process.ProcessMemoryAddress(binary.LittleEndian.Uint64(data)), nil
+	return process.ProcessMemoryAddress(p.ByteOrder().Uint64(data)), nil
 }
 
 func (p *ProcessDump) ReadPOINTER2(addr process.ProcessMemoryAddress) process.ProcessMemoryAddress {
@@ -285,7 +447,7 @@ func (p *ProcessDump) ReadBlob(addr process.ProcessMemoryAddress, size process.P
 	if err != nil {
 		return nil, err
 	}
-	return NewProcessBlob(addr, data), nil
+	return NewProcessBlobWithByteOrder(addr, data, p.order), nil
 }
 
 func (p *ProcessDump) ReadBlobs(list []process.ProcessMemoryAddress, size process.ProcessMemorySize) []process.ReadBlobsResult {
@@ -298,97 +460,165 @@ func (p *ProcessDump) ReadBlobs(list []process.ProcessMemoryAddress, size proces
 	return results
 }
 
+// ReadPointerChain walks pointer fields at all offsets except the last,
+// which is treated as a raw byte offset into the final struct, and then
+// reads `size` bytes starting there.
 func (p *ProcessDump) ReadPointerChain(base process.ProcessMemoryAddress, size process.ProcessMemorySize, offsets ...process.ProcessMemorySize) (process.ProcessReadOffset, error) {
-	return nil, fmt.Errorf("not implemented")
+	return process.ReadPointerChainTransform(p, nil, base, size, offsets...)
 }
 
+// ReadPointerChainDebug does the same as ReadPointerChain
 func (p *ProcessDump) ReadPointerChainDebug(base process.ProcessMemoryAddress, size process.ProcessMemorySize, offsets ...process.ProcessMemorySize) (process.ProcessReadOffset, error) {
-	return nil, fmt.Errorf("not implemented")
+	return p.ReadPointerChain(base, size, offsets...)
 }
 
 // MemoryScanner methods
-// Scan searches for the given pattern in the process memory
-func (p *ProcessDump) Scan(aob process.AOB) ([]process.ProcessMemoryAddress, error) {
-	var results []process.ProcessMemoryAddress
+// Scans run the shared engine, process.ScanRegions, over the captured regions; the engine
+// reads slices of the blobs, nothing is copied but the context of the matches.
 
-	// Validate the AOB
-	if len(aob.Pattern) == 0 {
-		return nil, fmt.Errorf("empty pattern")
+// Scan searches for the given pattern in the captured regions
+func (p *ProcessDump) Scan(aob process.AOB) ([]process.ProcessMemoryAddress, error) {
+	matches, err := p.ScanWithOptions(aob, process.ScanOptions{})
+	if err != nil {
+		return nil, err
 	}
+	return process.ScanMatchAddresses(matches), nil
+}
 
-	// If no mask is provided, create a mask of all 0xFF (exact match)
-	if len(aob.Mask) == 0 {
-		aob.Mask = make([]byte, len(aob.Pattern))
-		for i := range aob.Mask {
-			aob.Mask[i] = 0xFF
-		}
-	} else if len(aob.Mask) != len(aob.Pattern) {
-		return nil, fmt.Errorf("mask length (%d) doesn't match pattern length (%d)",
-			len(aob.Mask), len(aob.Pattern))
+// ScanParallel searches for the given pattern with up to maxdop workers
+func (p *ProcessDump) ScanParallel(aob process.AOB, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	matches, err := p.ScanWithOptions(aob, process.ScanOptions{MaxDOP: maxdop})
+	if err != nil {
+		return nil, err
 	}
+	return process.ScanMatchAddresses(matches), nil
+}
 
-	// Scan each blob
-	for addr, data := range p.Blobs {
-		matches := findPatternMatches(data, aob.Pattern, aob.Mask)
-		for _, offset := range matches {
-			results = append(results, process.ProcessMemoryAddress(addr+uint64(offset)))
-		}
-	}
+// ScanWithOptions searches the captured regions for the pattern, retaining the requested context around each match
+func (p *ProcessDump) ScanWithOptions(aob process.AOB, options process.ScanOptions) ([]process.ScanMatch, error) {
+	return p.ScanWithOptionsCtx(context.Background(), aob, options)
+}
 
-	// Sort results
-	sort.Slice(results, func(i, j int) bool {
-		return results[i] < results[j]
-	})
+// ScanCtx searches the captured regions for the pattern until ctx is done, see ScanWithOptionsCtx
+func (p *ProcessDump) ScanCtx(ctx context.Context, aob process.AOB) ([]process.ProcessMemoryAddress, error) {
+	matches, err := p.ScanWithOptionsCtx(ctx, aob, process.ScanOptions{})
+	return process.ScanMatchAddresses(matches), err
+}
 
-	return results, nil
+// ScanWithOptionsCtx is ScanWithOptions stopping when ctx is done, checked before each region
+// and every process.ContextScanChunkSize bytes. The matches found until then are returned
+// with ctx.Err().
+func (p *ProcessDump) ScanWithOptionsCtx(ctx context.Context, aob process.AOB, options process.ScanOptions) ([]process.ScanMatch, error) {
+	return process.ScanRegionsCtx(ctx, p.MemoryMap, p.blobSlice, aob, options, process.ReadLimits{}, nil)
+}
+
+// ScanIter searches the captured regions for the pattern and yields the addresses in order,
+// see ScanWithOptionsIter
+func (p *ProcessDump) ScanIter(aob process.AOB) iter.Seq2[process.ProcessMemoryAddress, error] {
+	return process.ScanMatchAddressesIter(p.ScanWithOptionsIter(context.Background(), aob, process.ScanOptions{}))
+}
+
+// ScanWithOptionsIter is ScanWithOptionsCtx yielding the matches in address order while the
+// scan runs; breaking out of the loop stops the scan
+func (p *ProcessDump) ScanWithOptionsIter(ctx context.Context, aob process.AOB, options process.ScanOptions) iter.Seq2[process.ScanMatch, error] {
+	return process.ScanRegionsIter(ctx, p.MemoryMap, p.blobSlice, aob, options, process.ReadLimits{}, nil)
 }
 
-// findPatternMatches finds all occurrences of the pattern in the data
-func findPatternMatches(data, pattern, mask []byte) []uint {
-	if len(data) < len(pattern) {
-		return nil
+// blobSlice returns the captured bytes at addr without copying them, for the scan engine.
+// Regions captured partially return their captured prefix with process.ErrPartialRead.
+func (p *ProcessDump) blobSlice(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ([]byte, error) {
+	region := memory_map.GetMemoryRegionForAddress(uint64(addr), p.MemoryMap)
+	if region == nil {
+		return nil, process.ErrAddressNotMapped
 	}
 
-	var matches []uint
+	data, ok := p.Blobs[region.Address]
+	if !ok {
+		return nil, fmt.Errorf("no data for region 0x%x", region.Address)
+	}
 
-	for i := 0; i <= len(data)-len(pattern); i++ {
-		matched := true
-		for j := 0; j < len(pattern); j++ {
-			if mask[j] == 0 {
-				continue
-			}
-			if (data[i+j] & mask[j]) != (pattern[j] & mask[j]) {
-				matched = false
-				break
-			}
-		}
-		if matched {
-			matches = append(matches, uint(i))
-		}
+	offset := uint64(addr) - region.Address
+	if offset >= uint64(len(data)) {
+		return nil, fmt.Errorf("%w: region 0x%x captured up to 0x%x", process.ErrPartialRead, region.Address, region.Address+uint64(len(data)))
 	}
-	return matches
+	end := offset + uint64(size)
+	if end > uint64(len(data)) {
+		return data[offset:], fmt.Errorf("%w: region 0x%x captured up to 0x%x", process.ErrPartialRead, region.Address, region.Address+uint64(len(data)))
+	}
+	return data[offset:end], nil
 }
 
-func (p *ProcessDump) ScanParallel(aob process.AOB, maxdop uint) ([]process.ProcessMemoryAddress, error) {
-	return nil, fmt.Errorf("ScanParallel not implemented")
+// ScanRange searches the captured regions between start and end (exclusive)
+func (p *ProcessDump) ScanRange(aob process.AOB, start, end process.ProcessMemoryAddress) ([]process.ProcessMemoryAddress, error) {
+	return p.ScanRangeParallel(aob, start, end, 1)
+}
+
+// ScanRangeParallel searches the captured regions between start and end with up to maxdop workers
+func (p *ProcessDump) ScanRangeParallel(aob process.AOB, start, end process.ProcessMemoryAddress, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	options, err := process.RangeScanOptions(start, end, maxdop)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := p.ScanWithOptions(aob, options)
+	if err != nil {
+		return nil, err
+	}
+	return process.ScanMatchAddresses(matches), nil
 }
 
+// ScanFirst searches for the first occurrence of the pattern
 func (p *ProcessDump) ScanFirst(aob process.AOB) (process.ProcessMemoryAddress, error) {
-	return 0, fmt.Errorf("ScanFirst not implemented")
+	return p.ScanFirstParallel(aob, 1)
 }
 
+// ScanFirstParallel searches for the first occurrence of the pattern with up to maxdop workers, the lowest
+// address. The scan stops at the first match instead of reading the remaining regions.
 func (p *ProcessDump) ScanFirstParallel(aob process.AOB, maxdop uint) (process.ProcessMemoryAddress, error) {
-	return 0, fmt.Errorf("ScanFirstParallel not implemented")
+	results, err := p.ScanWithOptions(aob, process.ScanOptions{MaxDOP: maxdop, MaxResults: 1})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(results) == 0 {
+		return 0, fmt.Errorf("pattern not found")
+	}
+
+	return results[0].Address, nil
 }
 
+// ScanInteger searches for an integer value in the captured regions
 func (p *ProcessDump) ScanInteger(value int64, size uint) ([]process.ProcessMemoryAddress, error) {
-	return nil, fmt.Errorf("ScanInteger not implemented")
+	return p.ScanIntegerParallel(value, size, 1)
+}
+
+// ScanIntegerParallel searches for an integer value with up to maxdop workers, encoded in
+// the byte order of the dump
+func (p *ProcessDump) ScanIntegerParallel(value int64, size uint, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	aob, err := process.IntegerAOB(value, size, p.ByteOrder())
+	if err != nil {
+		return nil, err
+	}
+
+	return p.ScanParallel(aob, maxdop)
 }
 
+// ScanFloat searches for a float value in the captured regions
 func (p *ProcessDump) ScanFloat(value float64, isFloat32 bool) ([]process.ProcessMemoryAddress, error) {
-	return nil, fmt.Errorf("ScanFloat not implemented")
+	return p.ScanFloatParallel(value, isFloat32, 1)
 }
 
+// ScanFloatParallel searches for a float value with up to maxdop workers, encoded in the
+// byte order of the dump
+func (p *ProcessDump) ScanFloatParallel(value float64, isFloat32 bool, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	return p.ScanParallel(process.FloatAOB(value, isFloat32, p.ByteOrder()), maxdop)
+}
+
+// ScanString searches for a string in the captured regions, see process.StringAOB
 func (p *ProcessDump) ScanString(value string, isUTF16 bool) ([]process.ProcessMemoryAddress, error) {
-	return nil, fmt.Errorf("ScanString not implemented")
+	return p.ScanStringParallel(value, isUTF16, 1)
+}
+
+// ScanStringParallel searches for a string with up to maxdop workers
+func (p *ProcessDump) ScanStringParallel(value string, isUTF16 bool, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	return p.ScanParallel(process.StringAOB(value, isUTF16), maxdop)
 }
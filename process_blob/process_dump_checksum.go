@@ -0,0 +1,43 @@
+package process_blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// BlobChecksum records a blob file of a dump, written to metadata.json on Save and verified
+// on Load
+type BlobChecksum struct {
+	Address uint64 `json:"address"`
+	Size    int    `json:"size"`   // Number of bytes in the blob file
+	SHA256  string `json:"sha256"` // Hex encoded SHA-256 of the blob file
+}
+
+// NewBlobChecksum returns the checksum of the blob data saved for the region at address
+func NewBlobChecksum(address uint64, data []byte) BlobChecksum {
+	sum := sha256.Sum256(data)
+	return BlobChecksum{
+		Address: address,
+		Size:    len(data),
+		SHA256:  hex.EncodeToString(sum[:]),
+	}
+}
+
+// Verify returns an error if data is not the blob the checksum was computed for
+func (c BlobChecksum) Verify(data []byte) error {
+	if len(data) != c.Size {
+		return fmt.Errorf("blob 0x%x is %d bytes, %d were saved", c.Address, len(data), c.Size)
+	}
+	if got := NewBlobChecksum(c.Address, data); got.SHA256 != c.SHA256 {
+		return fmt.Errorf("blob 0x%x is corrupted: sha256 %s, saved %s", c.Address, got.SHA256, c.SHA256)
+	}
+	return nil
+}
+
+// LoadOptions configures ProcessDump.LoadWithOptions
+type LoadOptions struct {
+	// SkipVerify loads the blobs without checking them against the checksums of the metadata,
+	// e.g. to salvage the intact regions of a damaged dump
+	SkipVerify bool
+}
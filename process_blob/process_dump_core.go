@@ -0,0 +1,394 @@
+package process_blob
+
+import (
+	"bufio"
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// ntFile is the type of the note listing the file-backed mappings of a core, "FILE" in ASCII
+const ntFile elf.NType = 0x46494c45
+
+// Layout of the core written by ExportCore
+const (
+	corePageSize     = 0x1000
+	corePrpsinfoSize = 136 // struct elf_prpsinfo on 64-bit targets
+)
+
+// coreFile is a mapping listed by the NT_FILE note of a core
+type coreFile struct {
+	start, end uint64
+	path       string
+}
+
+// LoadCore loads an ELF core file, e.g. written by the kernel when a process crashes or by
+// gcore, as a ProcessDump. Every PT_LOAD segment becomes a region, named after the mapped
+// file from the NT_FILE note, with the bytes stored in the core; segments the kernel did not
+// dump (see /proc/pid/coredump_filter) are mapped without data, and file-backed code usually
+// only has its first page. The PID and name come from the NT_PRPSINFO note.
+func LoadCore(path string) (*ProcessDump, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open core %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if f.Type != elf.ET_CORE {
+		return nil, fmt.Errorf("%s is not a core file: %s", path, f.Type)
+	}
+
+	// The segment sizes come from the file, check them before allocating
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open core %s: %w", path, err)
+	}
+	fileSize := uint64(info.Size())
+
+	p := NewProcessDump()
+	p.order = f.ByteOrder
+
+	var files []coreFile
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_NOTE {
+			continue
+		}
+		notes, err := io.ReadAll(prog.Open())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read notes: %w", err)
+		}
+		mapped, err := p.parseCoreNotes(notes, f.Class, f.ByteOrder)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, mapped...)
+	}
+
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_LOAD || prog.Memsz == 0 {
+			continue
+		}
+
+		region := memory_map.MemoryMapItem{
+			Address: prog.Vaddr,
+			Size:    uint(prog.Memsz),
+			Perms:   corePerms(prog.Flags),
+		}
+		for _, file := range files {
+			if prog.Vaddr >= file.start && prog.Vaddr < file.end {
+				region.Path = file.path
+				break
+			}
+		}
+		p.MemoryMap = append(p.MemoryMap, region)
+
+		if prog.Filesz == 0 {
+			continue // Not dumped
+		}
+		if prog.Off > fileSize || prog.Filesz > fileSize-prog.Off {
+			return nil, fmt.Errorf("segment 0x%x extends past the end of the core", prog.Vaddr)
+		}
+		data := make([]byte, min(prog.Filesz, prog.Memsz))
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			return nil, fmt.Errorf("failed to read segment 0x%x: %w", prog.Vaddr, err)
+		}
+		p.Blobs[region.Address] = data
+	}
+
+	sort.Slice(p.MemoryMap, func(i, j int) bool {
+		return p.MemoryMap[i].Address < p.MemoryMap[j].Address
+	})
+
+	// Fall back to the first mapped file, usually the executable
+	if p.Name == "" && len(files) > 0 {
+		p.Name = filepath.Base(files[0].path)
+	}
+
+	return p, nil
+}
+
+// corePerms returns the permissions of a PT_LOAD segment in the /proc/pid/maps notation.
+// Cores do not record whether a mapping was shared, so every region is private.
+func corePerms(flags elf.ProgFlag) string {
+	perms := []byte("---p")
+	if flags&elf.PF_R != 0 {
+		perms[0] = 'r'
+	}
+	if flags&elf.PF_W != 0 {
+		perms[1] = 'w'
+	}
+	if flags&elf.PF_X != 0 {
+		perms[2] = 'x'
+	}
+	return string(perms)
+}
+
+// parseCoreNotes reads the PID and name of the process from the NT_PRPSINFO note of a PT_NOTE
+// segment and returns the mappings listed by its NT_FILE note
+func (p *ProcessDump) parseCoreNotes(notes []byte, class elf.Class, order binary.ByteOrder) ([]coreFile, error) {
+	var files []coreFile
+	for len(notes) >= 12 {
+		namesz := uint64(order.Uint32(notes[0:]))
+		descsz := uint64(order.Uint32(notes[4:]))
+		typ := elf.NType(order.Uint32(notes[8:]))
+
+		nameEnd := 12 + align4(namesz)
+		descEnd := nameEnd + align4(descsz)
+		if descEnd > uint64(len(notes)) || nameEnd+descsz > uint64(len(notes)) {
+			return nil, fmt.Errorf("truncated note of type 0x%x", uint32(typ))
+		}
+		name := string(bytes.TrimRight(notes[12:12+namesz], "\x00"))
+		desc := notes[nameEnd : nameEnd+descsz]
+		notes = notes[descEnd:]
+
+		if name != "CORE" {
+			continue
+		}
+		switch typ {
+		case elf.NT_PRPSINFO:
+			p.parsePrpsinfo(desc, class, order)
+		case ntFile:
+			mapped, err := parseFileNote(desc, class, order)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, mapped...)
+		}
+	}
+	return files, nil
+}
+
+// parsePrpsinfo reads pr_pid and pr_fname of a struct elf_prpsinfo. On 32-bit targets the
+// uid and gid fields are 16 bits wide.
+func (p *ProcessDump) parsePrpsinfo(desc []byte, class elf.Class, order binary.ByteOrder) {
+	pidOffset, nameOffset := 24, 40
+	if class == elf.ELFCLASS32 {
+		pidOffset, nameOffset = 12, 28
+	}
+	if len(desc) < nameOffset+16 {
+		return
+	}
+	p.PID = process.ProcessID(order.Uint32(desc[pidOffset:]))
+	fname := desc[nameOffset : nameOffset+16]
+	if i := bytes.IndexByte(fname, 0); i >= 0 {
+		fname = fname[:i]
+	}
+	p.Name = string(fname)
+}
+
+// parseFileNote decodes an NT_FILE note: the number of mappings and the page size, a start,
+// end and file offset for each mapping, then the NUL-terminated file names in the same order
+func parseFileNote(desc []byte, class elf.Class, order binary.ByteOrder) ([]coreFile, error) {
+	word := 8
+	readWord := func(b []byte) uint64 { return order.Uint64(b) }
+	if class == elf.ELFCLASS32 {
+		word = 4
+		readWord = func(b []byte) uint64 { return uint64(order.Uint32(b)) }
+	}
+
+	if len(desc) < 2*word {
+		return nil, fmt.Errorf("truncated NT_FILE note")
+	}
+	count := readWord(desc)
+	if count > uint64(len(desc)-2*word)/uint64(3*word) {
+		return nil, fmt.Errorf("invalid NT_FILE note: %d mappings in %d bytes", count, len(desc))
+	}
+
+	entries := desc[2*word:]
+	names := entries[int(count)*3*word:]
+	files := make([]coreFile, count)
+	for i := range files {
+		entry := entries[i*3*word:]
+		files[i].start = readWord(entry)
+		files[i].end = readWord(entry[word:])
+
+		end := bytes.IndexByte(names, 0)
+		if end < 0 {
+			return nil, fmt.Errorf("truncated NT_FILE note: %d of %d names", i, count)
+		}
+		files[i].path = string(names[:end])
+		names = names[end+1:]
+	}
+	return files, nil
+}
+
+// align4 rounds n up to the 4 byte alignment of note names and descriptors
+func align4(n uint64) uint64 {
+	return (n + 3) &^ 3
+}
+
+// ExportCore writes the dump to path as a 64-bit ELF core file, which gdb and other standard
+// tools can open and LoadCore reads back. Every region becomes a PT_LOAD segment holding its
+// captured bytes, regions without data are written empty; an NT_PRPSINFO note records the PID
+// and name, an NT_FILE note the file-backed regions. Cores carry no registers, so debuggers
+// can inspect memory but show no threads. The machine is the one gomem runs on.
+func (p *ProcessDump) ExportCore(path string) error {
+	order := p.ByteOrder()
+	appender, ok := order.(binary.AppendByteOrder)
+	if !ok {
+		return fmt.Errorf("unsupported byte order: %s", order)
+	}
+	data := elf.ELFDATA2LSB
+	if order == binary.BigEndian {
+		data = elf.ELFDATA2MSB
+	}
+
+	var notes []byte
+	notes = appendCoreNote(notes, appender, elf.NT_PRPSINFO, p.prpsinfo(order))
+	notes = appendCoreNote(notes, appender, ntFile, p.fileNote(appender))
+
+	// Headers and notes, then the segments at offsets congruent to their address modulo the
+	// page size
+	phoff := uint64(binary.Size(elf.Header64{}))
+	phnum := uint64(1 + len(p.MemoryMap))
+	noteOffset := phoff + phnum*uint64(binary.Size(elf.Prog64{}))
+	offset := noteOffset + uint64(len(notes))
+
+	progs := []elf.Prog64{{
+		Type:   uint32(elf.PT_NOTE),
+		Off:    noteOffset,
+		Filesz: uint64(len(notes)),
+		Align:  4,
+	}}
+	for _, region := range p.MemoryMap {
+		size := uint64(len(p.Blobs[region.Address]))
+		offset += (region.Address - offset) % corePageSize
+		progs = append(progs, elf.Prog64{
+			Type:   uint32(elf.PT_LOAD),
+			Flags:  uint32(coreFlags(region.Perms)),
+			Off:    offset,
+			Vaddr:  region.Address,
+			Filesz: size,
+			Memsz:  uint64(region.Size),
+			Align:  corePageSize,
+		})
+		offset += size
+	}
+
+	header := elf.Header64{
+		Type:      uint16(elf.ET_CORE),
+		Machine:   uint16(coreMachine()),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     phoff,
+		Ehsize:    uint16(phoff),
+		Phentsize: uint16(binary.Size(elf.Prog64{})),
+		Phnum:     uint16(phnum),
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(data)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	header.Ident[elf.EI_OSABI] = byte(elf.ELFOSABI_NONE)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create core: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := binary.Write(w, order, header); err != nil {
+		return fmt.Errorf("failed to write core header: %w", err)
+	}
+	if err := binary.Write(w, order, progs); err != nil {
+		return fmt.Errorf("failed to write program headers: %w", err)
+	}
+	w.Write(notes)
+
+	written := noteOffset + uint64(len(notes))
+	for i, region := range p.MemoryMap {
+		prog := progs[i+1]
+		w.Write(make([]byte, prog.Off-written))
+		w.Write(p.Blobs[region.Address])
+		written = prog.Off + prog.Filesz
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write core: %w", err)
+	}
+	return f.Close()
+}
+
+// prpsinfo returns the NT_PRPSINFO descriptor of the dump with its PID and name, see
+// parsePrpsinfo
+func (p *ProcessDump) prpsinfo(order binary.ByteOrder) []byte {
+	desc := make([]byte, corePrpsinfoSize)
+	order.PutUint32(desc[24:], uint32(p.PID))
+	copy(desc[40:56], p.Name)  // pr_fname
+	copy(desc[56:136], p.Name) // pr_psargs
+	return desc
+}
+
+// fileNote returns the NT_FILE descriptor listing the file-backed regions, see parseFileNote.
+// The file offsets are not recorded by dumps and are written as 0.
+func (p *ProcessDump) fileNote(order binary.AppendByteOrder) []byte {
+	var entries, names []byte
+	count := 0
+	for _, region := range p.MemoryMap {
+		if region.Kind() != memory_map.RegionImage {
+			continue
+		}
+		entries = order.AppendUint64(entries, region.Address)
+		entries = order.AppendUint64(entries, region.Address+uint64(region.Size))
+		entries = order.AppendUint64(entries, 0)
+		names = append(append(names, region.Path...), 0)
+		count++
+	}
+
+	desc := order.AppendUint64(nil, uint64(count))
+	desc = order.AppendUint64(desc, corePageSize)
+	return append(append(desc, entries...), names...)
+}
+
+// appendCoreNote appends a note named "CORE" to notes
+func appendCoreNote(notes []byte, order binary.AppendByteOrder, typ elf.NType, desc []byte) []byte {
+	name := []byte("CORE\x00")
+	notes = order.AppendUint32(notes, uint32(len(name)))
+	notes = order.AppendUint32(notes, uint32(len(desc)))
+	notes = order.AppendUint32(notes, uint32(typ))
+	notes = append(notes, name...)
+	notes = append(notes, make([]byte, align4(uint64(len(name)))-uint64(len(name)))...)
+	notes = append(notes, desc...)
+	return append(notes, make([]byte, align4(uint64(len(desc)))-uint64(len(desc)))...)
+}
+
+// coreFlags returns the PT_LOAD flags of permissions in the /proc/pid/maps notation, see
+// corePerms
+func coreFlags(perms string) elf.ProgFlag {
+	var flags elf.ProgFlag
+	if strings.HasPrefix(perms, "r") {
+		flags |= elf.PF_R
+	}
+	if len(perms) > 1 && perms[1] == 'w' {
+		flags |= elf.PF_W
+	}
+	if len(perms) > 2 && perms[2] == 'x' {
+		flags |= elf.PF_X
+	}
+	return flags
+}
+
+// coreMachine returns the ELF machine of the architecture gomem is built for
+func coreMachine() elf.Machine {
+	switch runtime.GOARCH {
+	case "arm64":
+		return elf.EM_AARCH64
+	case "riscv64":
+		return elf.EM_RISCV
+	case "ppc64", "ppc64le":
+		return elf.EM_PPC64
+	case "s390x":
+		return elf.EM_S390
+	}
+	return elf.EM_X86_64
+}
@@ -0,0 +1,136 @@
+package process_blob
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// diffBlockSize is the size of the blocks Diff compares at once before looking for the
+// differing bytes
+const diffBlockSize = 4096
+
+// ByteRange is a range of bytes, e.g. changed between two dumps
+type ByteRange struct {
+	Address process.ProcessMemoryAddress
+	Size    uint64
+}
+
+// End returns the address just past the range
+func (r ByteRange) End() process.ProcessMemoryAddress {
+	return r.Address + process.ProcessMemoryAddress(r.Size)
+}
+
+// RegionDiff lists the changed bytes of a region present in both dumps
+type RegionDiff struct {
+	Region  memory_map.MemoryMapItem // Region in the second dump
+	Resized bool                     // The region size differs, the common prefix was compared
+	Changes []ByteRange              // Changed ranges, by address
+}
+
+// DumpDiff is the result of Diff
+type DumpDiff struct {
+	Regions  []RegionDiff               // Regions with changed bytes, by address
+	Added    []memory_map.MemoryMapItem // Regions only in the second dump
+	Removed  []memory_map.MemoryMapItem // Regions only in the first dump
+	Compared uint64                     // Number of bytes compared
+}
+
+// ChangedBytes returns the total size of the changed ranges
+func (d *DumpDiff) ChangedBytes() uint64 {
+	var total uint64
+	for _, region := range d.Regions {
+		for _, r := range region.Changes {
+			total += r.Size
+		}
+	}
+	return total
+}
+
+// Diff compares two dumps of the same process, e.g. before and after an action in a game,
+// and returns the changed byte ranges of every region mapped at the same address in both,
+// plus the regions added and removed between them. Only captured bytes are compared; regions
+// that were resized compare their common prefix. Use process.CompareProcesses for dumps of
+// two instances of a program, whose addresses differ.
+func Diff(a, b *ProcessDump) *DumpDiff {
+	regionsA := make(map[uint64]memory_map.MemoryMapItem, len(a.MemoryMap))
+	for _, region := range a.MemoryMap {
+		regionsA[region.Address] = region
+	}
+	inB := make(map[uint64]bool, len(b.MemoryMap))
+
+	result := &DumpDiff{}
+	for _, regionB := range b.MemoryMap {
+		inB[regionB.Address] = true
+		regionA, ok := regionsA[regionB.Address]
+		if !ok {
+			result.Added = append(result.Added, regionB)
+			continue
+		}
+
+		dataA, dataB := a.Blobs[regionA.Address], b.Blobs[regionB.Address]
+		n := min(len(dataA), len(dataB))
+		result.Compared += uint64(n)
+
+		diff := RegionDiff{
+			Region:  regionB,
+			Resized: regionA.Size != regionB.Size,
+			Changes: diffBytes(regionB.Address, dataA[:n], dataB[:n]),
+		}
+		if len(diff.Changes) > 0 {
+			result.Regions = append(result.Regions, diff)
+		}
+	}
+
+	for _, region := range a.MemoryMap {
+		if !inB[region.Address] {
+			result.Removed = append(result.Removed, region)
+		}
+	}
+
+	sort.Slice(result.Regions, func(i, j int) bool {
+		return result.Regions[i].Region.Address < result.Regions[j].Region.Address
+	})
+	sort.Slice(result.Added, func(i, j int) bool {
+		return result.Added[i].Address < result.Added[j].Address
+	})
+	sort.Slice(result.Removed, func(i, j int) bool {
+		return result.Removed[i].Address < result.Removed[j].Address
+	})
+	return result
+}
+
+// diffBytes returns the ranges where a and b, of equal length and captured at base, differ
+func diffBytes(base uint64, a, b []byte) []ByteRange {
+	var ranges []ByteRange
+	for off := 0; off < len(a); off += diffBlockSize {
+		end := min(off+diffBlockSize, len(a))
+		if bytes.Equal(a[off:end], b[off:end]) {
+			continue
+		}
+
+		for i := off; i < end; i++ {
+			if a[i] == b[i] {
+				continue
+			}
+			addr := process.ProcessMemoryAddress(base + uint64(i))
+
+			// Extend the previous range when it ends right here, ranges may span blocks
+			if last := len(ranges) - 1; last >= 0 && ranges[last].End() == addr {
+				ranges[last].Size++
+				continue
+			}
+			ranges = append(ranges, ByteRange{Address: addr, Size: 1})
+		}
+	}
+	return ranges
+}
+
+// String returns a one line summary of the diff
+func (d *DumpDiff) String() string {
+	return fmt.Sprintf("%d bytes compared, %d bytes changed in %d regions, %d regions added, %d removed",
+		d.Compared, d.ChangedBytes(), len(d.Regions), len(d.Added), len(d.Removed))
+}
@@ -0,0 +1,73 @@
+package process_blob
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// ExportRegion writes the raw contents of the region containing addr to path
+func (p *ProcessDump) ExportRegion(addr process.ProcessMemoryAddress, path string) error {
+	region := memory_map.GetMemoryRegionForAddress(uint64(addr), p.MemoryMap)
+	if region == nil {
+		return process.ErrAddressNotMapped
+	}
+
+	data, ok := p.Blobs[region.Address]
+	if !ok {
+		return fmt.Errorf("no data for region 0x%x", region.Address)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write region 0x%x: %w", region.Address, err)
+	}
+
+	return nil
+}
+
+// ExportRange writes size bytes starting at addr to path.
+// The range must lie within a single captured region.
+func (p *ProcessDump) ExportRange(addr process.ProcessMemoryAddress, size process.ProcessMemorySize, path string) error {
+	data, err := p.ReadMemory(addr, size)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write range 0x%x+0x%x: %w", addr, size, err)
+	}
+
+	return nil
+}
+
+// ExportMatching writes every captured region accepted by filter to its own file in dir,
+// named region_0x<address>_<size>_<perms>.bin, and returns the paths written.
+// A nil filter exports every captured region.
+func (p *ProcessDump) ExportMatching(filter memory_map.RegionFilter, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	var paths []string
+	for _, region := range p.MemoryMap {
+		if filter != nil && !filter(region) {
+			continue
+		}
+
+		data, ok := p.Blobs[region.Address]
+		if !ok {
+			continue // Region was not captured
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("region_0x%x_%d_%s.bin", region.Address, len(data), region.Perms))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return paths, fmt.Errorf("failed to write region 0x%x: %w", region.Address, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
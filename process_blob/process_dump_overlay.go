@@ -0,0 +1,94 @@
+package process_blob
+
+import (
+	"sort"
+
+	"gomem/process/memory_map"
+)
+
+// Overlay returns a new dump combining p with a later snapshot.
+// Every captured region of other replaces the bytes of p it overlaps; regions of p
+// that are only partially covered keep their uncovered parts. Regions of other that
+// were not captured are only added where p has nothing mapped. The PID, name and byte
+// order are taken from other. Region data is shared with the source dumps, not copied.
+//
+// Overlays can be chained to apply several deltas on top of a base dump:
+//
+//	view := base.Overlay(delta1).Overlay(delta2)
+func (p *ProcessDump) Overlay(other *ProcessDump) *ProcessDump {
+	result := NewProcessDump()
+	result.PID = other.PID
+	result.Name = other.Name
+	result.order = other.order
+
+	// Captured regions of the newer snapshot win
+	var overrides []memory_map.MemoryMapItem
+	for _, region := range other.MemoryMap {
+		if data, ok := other.Blobs[region.Address]; ok {
+			overrides = append(overrides, region)
+			result.MemoryMap = append(result.MemoryMap, region)
+			result.Blobs[region.Address] = data
+		}
+	}
+
+	// Keep the parts of the older regions that were not overridden
+	for _, region := range p.MemoryMap {
+		data, captured := p.Blobs[region.Address]
+		for _, piece := range subtractRegions(region, overrides) {
+			result.MemoryMap = append(result.MemoryMap, piece)
+			if captured {
+				start := piece.Address - region.Address
+				if start < uint64(len(data)) {
+					end := min(start+uint64(piece.Size), uint64(len(data)))
+					result.Blobs[piece.Address] = data[start:end]
+				}
+			}
+		}
+	}
+
+	// Mapped but uncaptured regions of the newer snapshot fill the remaining holes
+	for _, region := range other.MemoryMap {
+		if _, ok := other.Blobs[region.Address]; ok {
+			continue
+		}
+		result.MemoryMap = append(result.MemoryMap, subtractRegions(region, result.MemoryMap)...)
+	}
+
+	sort.Slice(result.MemoryMap, func(i, j int) bool {
+		return result.MemoryMap[i].Address < result.MemoryMap[j].Address
+	})
+
+	return result
+}
+
+// subtractRegions returns the parts of region not covered by any of the given regions
+func subtractRegions(region memory_map.MemoryMapItem, cover []memory_map.MemoryMapItem) []memory_map.MemoryMapItem {
+	pieces := []memory_map.MemoryMapItem{region}
+
+	for _, c := range cover {
+		cStart, cEnd := c.Address, c.Address+uint64(c.Size)
+
+		var next []memory_map.MemoryMapItem
+		for _, piece := range pieces {
+			start, end := piece.Address, piece.Address+uint64(piece.Size)
+			if cEnd <= start || cStart >= end {
+				next = append(next, piece)
+				continue
+			}
+			if cStart > start {
+				head := piece
+				head.Size = uint(cStart - start)
+				next = append(next, head)
+			}
+			if cEnd < end {
+				tail := piece
+				tail.Address = cEnd
+				tail.Size = uint(end - cEnd)
+				next = append(next, tail)
+			}
+		}
+		pieces = next
+	}
+
+	return pieces
+}
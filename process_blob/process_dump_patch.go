@@ -0,0 +1,86 @@
+package process_blob
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// WriteMemory patches the captured bytes at addr, e.g. to try a struct interpretation or
+// neutralize a pointer before rerunning an analysis. The write must lie within the captured
+// data of one region. Regions are copied on their first write: the blobs loaded from disk, and
+// those shared with other dumps by Overlay, are never modified. Writes are not safe to run
+// concurrently with reads or scans of the dump.
+func (p *ProcessDump) WriteMemory(addr process.ProcessMemoryAddress, data []byte) error {
+	region := memory_map.GetMemoryRegionForAddress(uint64(addr), p.MemoryMap)
+	if region == nil {
+		return process.ErrAddressNotMapped
+	}
+
+	blob, ok := p.Blobs[region.Address]
+	if !ok {
+		return fmt.Errorf("no data for region 0x%x", region.Address)
+	}
+
+	offset := uint64(addr) - region.Address
+	if offset+uint64(len(data)) > uint64(len(blob)) {
+		return fmt.Errorf("write of %d bytes at 0x%x exceeds region data bounds", len(data), addr)
+	}
+
+	if _, copied := p.original[region.Address]; !copied {
+		if p.original == nil {
+			p.original = make(map[uint64][]byte)
+		}
+		p.original[region.Address] = blob
+		blob = slices.Clone(blob)
+		p.Blobs[region.Address] = blob
+	}
+
+	copy(blob[offset:], data)
+	return nil
+}
+
+// Modifications returns the byte ranges changed by WriteMemory, by address. Bytes written
+// with their original value are not listed.
+func (p *ProcessDump) Modifications() []ByteRange {
+	var ranges []ByteRange
+	for address, original := range p.original {
+		ranges = append(ranges, diffBytes(address, original, p.Blobs[address])...)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Address < ranges[j].Address
+	})
+	return ranges
+}
+
+// ResetModifications reverts every write, restoring the captured bytes
+func (p *ProcessDump) ResetModifications() {
+	for address, original := range p.original {
+		p.Blobs[address] = original
+	}
+	p.original = nil
+}
+
+// ResetRange reverts the writes to the size bytes at addr, within one region
+func (p *ProcessDump) ResetRange(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) error {
+	region := memory_map.GetMemoryRegionForAddress(uint64(addr), p.MemoryMap)
+	if region == nil {
+		return process.ErrAddressNotMapped
+	}
+
+	original, ok := p.original[region.Address]
+	if !ok {
+		return nil // Region was never written
+	}
+
+	offset := uint64(addr) - region.Address
+	end := min(offset+uint64(size), uint64(len(original)))
+	if offset < end {
+		copy(p.Blobs[region.Address][offset:end], original[offset:end])
+	}
+	return nil
+}
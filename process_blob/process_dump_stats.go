@@ -0,0 +1,124 @@
+package process_blob
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gomem/process/memory_map"
+)
+
+// RegionsByPath returns the regions whose backing path matches pattern.
+// The pattern is a path.Match glob tested against both the full path and its base name
+// (e.g. "libc*", "/usr/lib/*", "[heap]"); a pattern without wildcards matches any path containing it.
+func (p *ProcessDump) RegionsByPath(pattern string) ([]memory_map.MemoryMapItem, error) {
+	isGlob := strings.ContainsAny(pattern, "*?[")
+	if isGlob {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	var result []memory_map.MemoryMapItem
+	for _, region := range p.MemoryMap {
+		if region.Path == "" {
+			continue
+		}
+
+		matched := strings.Contains(region.Path, pattern)
+		if isGlob {
+			fullMatch, _ := path.Match(pattern, region.Path)
+			baseMatch, _ := path.Match(pattern, path.Base(region.Path))
+			// "[heap]" is both a literal path and a character class, so keep the substring test too
+			matched = matched || fullMatch || baseMatch
+		}
+
+		if matched {
+			result = append(result, region)
+		}
+	}
+
+	return result, nil
+}
+
+// PermStats summarizes the regions sharing a permission string
+type PermStats struct {
+	Regions       int
+	MappedBytes   uint64
+	CapturedBytes uint64
+}
+
+// DumpStats is a summary of a dump's memory map and captured data
+type DumpStats struct {
+	Regions         int                  // Number of regions in the memory map
+	CapturedRegions int                  // Number of regions with saved data
+	MappedBytes     uint64               // Total size of the memory map
+	CapturedBytes   uint64               // Total size of the saved data
+	ByPerms         map[string]PermStats // Breakdown per permission string
+	Largest         []memory_map.MemoryMapItem
+}
+
+// statsLargestCount is the number of regions reported in DumpStats.Largest
+const statsLargestCount = 10
+
+// Stats returns a summary of the dump
+func (p *ProcessDump) Stats() DumpStats {
+	stats := DumpStats{
+		Regions: len(p.MemoryMap),
+		ByPerms: make(map[string]PermStats),
+	}
+
+	for _, region := range p.MemoryMap {
+		perm := stats.ByPerms[region.Perms]
+		perm.Regions++
+		perm.MappedBytes += uint64(region.Size)
+
+		if data, ok := p.Blobs[region.Address]; ok {
+			stats.CapturedRegions++
+			stats.CapturedBytes += uint64(len(data))
+			perm.CapturedBytes += uint64(len(data))
+		}
+
+		stats.MappedBytes += uint64(region.Size)
+		stats.ByPerms[region.Perms] = perm
+	}
+
+	stats.Largest = make([]memory_map.MemoryMapItem, len(p.MemoryMap))
+	copy(stats.Largest, p.MemoryMap)
+	sort.SliceStable(stats.Largest, func(i, j int) bool {
+		return stats.Largest[i].Size > stats.Largest[j].Size
+	})
+	if len(stats.Largest) > statsLargestCount {
+		stats.Largest = stats.Largest[:statsLargestCount]
+	}
+
+	return stats
+}
+
+// String returns a multi-line, human readable summary
+func (s DumpStats) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Regions: %d (%d captured)\n", s.Regions, s.CapturedRegions)
+	fmt.Fprintf(&b, "Mapped: %d bytes, Captured: %d bytes\n", s.MappedBytes, s.CapturedBytes)
+
+	perms := make([]string, 0, len(s.ByPerms))
+	for perm := range s.ByPerms {
+		perms = append(perms, perm)
+	}
+	sort.Strings(perms)
+
+	fmt.Fprintln(&b, "By permissions:")
+	for _, perm := range perms {
+		ps := s.ByPerms[perm]
+		fmt.Fprintf(&b, "  %-5s %5d regions %14d bytes mapped %14d bytes captured\n", perm, ps.Regions, ps.MappedBytes, ps.CapturedBytes)
+	}
+
+	fmt.Fprintln(&b, "Largest regions:")
+	for _, region := range s.Largest {
+		fmt.Fprintf(&b, "  %016x %14d bytes (%s) %s\n", region.Address, region.Size, region.Perms, region.Path)
+	}
+
+	return b.String()
+}
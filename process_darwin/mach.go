@@ -0,0 +1,184 @@
+//go:build darwin && cgo
+
+package process_darwin
+
+/*
+#include <stdlib.h>
+#include <mach/mach.h>
+#include <mach/mach_error.h>
+#include <mach/mach_vm.h>
+#include <libproc.h>
+
+static kern_return_t gomem_task_for_pid(int pid, mach_port_t *task) {
+	return task_for_pid(mach_task_self(), pid, task);
+}
+
+static void gomem_deallocate(mach_port_t port) {
+	mach_port_deallocate(mach_task_self(), port);
+}
+
+static kern_return_t gomem_region(mach_port_t task, mach_vm_address_t *addr, mach_vm_size_t *size, int *protection, int *shared) {
+	vm_region_basic_info_data_64_t info;
+	mach_msg_type_number_t count = VM_REGION_BASIC_INFO_COUNT_64;
+	mach_port_t object = MACH_PORT_NULL;
+
+	kern_return_t kr = mach_vm_region(task, addr, size, VM_REGION_BASIC_INFO_64, (vm_region_info_t)&info, &count, &object);
+	if (kr == KERN_SUCCESS) {
+		*protection = info.protection;
+		*shared = info.shared;
+		if (object != MACH_PORT_NULL) {
+			mach_port_deallocate(mach_task_self(), object);
+		}
+	}
+	return kr;
+}
+
+static kern_return_t gomem_read(mach_port_t task, mach_vm_address_t addr, mach_vm_size_t size, void *buf, mach_vm_size_t *out) {
+	return mach_vm_read_overwrite(task, addr, size, (mach_vm_address_t)buf, out);
+}
+
+static kern_return_t gomem_protect(mach_port_t task, mach_vm_address_t addr, mach_vm_size_t size, int protection) {
+	return mach_vm_protect(task, addr, size, FALSE, protection);
+}
+
+static kern_return_t gomem_write(mach_port_t task, mach_vm_address_t addr, void *data, mach_msg_type_number_t size) {
+	return mach_vm_write(task, addr, (vm_offset_t)data, size);
+}
+
+static kern_return_t gomem_allocate(mach_port_t task, mach_vm_address_t *addr, mach_vm_size_t size) {
+	return mach_vm_allocate(task, addr, size, VM_FLAGS_ANYWHERE);
+}
+
+static kern_return_t gomem_deallocate_memory(mach_port_t task, mach_vm_address_t addr, mach_vm_size_t size) {
+	return mach_vm_deallocate(task, addr, size);
+}
+
+static kern_return_t gomem_suspend(mach_port_t task) {
+	return task_suspend(task);
+}
+
+static kern_return_t gomem_resume(mach_port_t task) {
+	return task_resume(task);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// kernInvalidAddress is KERN_INVALID_ADDRESS, returned by mach_vm_region past the last region
+const kernInvalidAddress = 1
+
+// machError formats a kern_return_t
+func machError(call string, kr C.kern_return_t) error {
+	return fmt.Errorf("%s failed: %s (%d)", call, C.GoString(C.mach_error_string(kr)), int(kr))
+}
+
+// taskForPID returns the task port of a process. It requires root or the
+// com.apple.security.cs.debugger entitlement, and targets without get-task-allow
+// are refused by SIP.
+func taskForPID(pid int) (uint32, error) {
+	var task C.mach_port_t
+	if kr := C.gomem_task_for_pid(C.int(pid), &task); kr != C.KERN_SUCCESS {
+		return 0, machError("task_for_pid", kr)
+	}
+	return uint32(task), nil
+}
+
+// deallocatePort releases a task port obtained with taskForPID
+func deallocatePort(task uint32) {
+	C.gomem_deallocate(C.mach_port_t(task))
+}
+
+// machRegion returns the first region at or above addr, errEndOfRegions past the last one
+func machRegion(task uint32, addr uint64) (machRegionInfo, error) {
+	address := C.mach_vm_address_t(addr)
+	var size C.mach_vm_size_t
+	var protection, shared C.int
+
+	kr := C.gomem_region(C.mach_port_t(task), &address, &size, &protection, &shared)
+	if kr == kernInvalidAddress {
+		return machRegionInfo{}, errEndOfRegions
+	}
+	if kr != C.KERN_SUCCESS {
+		return machRegionInfo{}, machError("mach_vm_region", kr)
+	}
+
+	return machRegionInfo{
+		Address:    uint64(address),
+		Size:       uint64(size),
+		Protection: int(protection),
+		Shared:     shared != 0,
+	}, nil
+}
+
+// machRead reads len(buf) bytes at addr and returns the number of bytes copied
+func machRead(task uint32, addr uint64, buf []byte) (int, error) {
+	var out C.mach_vm_size_t
+	kr := C.gomem_read(C.mach_port_t(task), C.mach_vm_address_t(addr), C.mach_vm_size_t(len(buf)), unsafe.Pointer(&buf[0]), &out)
+	if kr != C.KERN_SUCCESS {
+		return int(out), machError("mach_vm_read_overwrite", kr)
+	}
+	return int(out), nil
+}
+
+// machWrite writes data at addr
+func machWrite(task uint32, addr uint64, data []byte) error {
+	if kr := C.gomem_write(C.mach_port_t(task), C.mach_vm_address_t(addr), unsafe.Pointer(&data[0]), C.mach_msg_type_number_t(len(data))); kr != C.KERN_SUCCESS {
+		return machError("mach_vm_write", kr)
+	}
+	return nil
+}
+
+// machProtect sets the protection of the pages covering [addr, addr+size)
+func machProtect(task uint32, addr, size uint64, protection int) error {
+	if kr := C.gomem_protect(C.mach_port_t(task), C.mach_vm_address_t(addr), C.mach_vm_size_t(size), C.int(protection)); kr != C.KERN_SUCCESS {
+		return machError("mach_vm_protect", kr)
+	}
+	return nil
+}
+
+// machAllocate allocates zeroed pages anywhere in the task and returns their address
+func machAllocate(task uint32, size uint64) (uint64, error) {
+	var addr C.mach_vm_address_t
+	if kr := C.gomem_allocate(C.mach_port_t(task), &addr, C.mach_vm_size_t(size)); kr != C.KERN_SUCCESS {
+		return 0, machError("mach_vm_allocate", kr)
+	}
+	return uint64(addr), nil
+}
+
+// machDeallocate releases the pages covering [addr, addr+size)
+func machDeallocate(task uint32, addr, size uint64) error {
+	if kr := C.gomem_deallocate_memory(C.mach_port_t(task), C.mach_vm_address_t(addr), C.mach_vm_size_t(size)); kr != C.KERN_SUCCESS {
+		return machError("mach_vm_deallocate", kr)
+	}
+	return nil
+}
+
+// machSuspend increments the suspend count of the task, its threads don't run while it is above 0
+func machSuspend(task uint32) error {
+	if kr := C.gomem_suspend(C.mach_port_t(task)); kr != C.KERN_SUCCESS {
+		return machError("task_suspend", kr)
+	}
+	return nil
+}
+
+// machResume decrements the suspend count of the task
+func machResume(task uint32) error {
+	if kr := C.gomem_resume(C.mach_port_t(task)); kr != C.KERN_SUCCESS {
+		return machError("task_resume", kr)
+	}
+	return nil
+}
+
+// regionFilename returns the path of the file mapped at addr, empty for anonymous memory
+func regionFilename(pid int, addr uint64) string {
+	buf := make([]byte, C.PROC_PIDPATHINFO_MAXSIZE)
+	n := C.proc_regionfilename(C.int(pid), C.uint64_t(addr), unsafe.Pointer(&buf[0]), C.uint32_t(len(buf)))
+	if n <= 0 {
+		return ""
+	}
+	return string(buf[:n])
+}
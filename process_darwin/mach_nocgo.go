@@ -0,0 +1,50 @@
+//go:build darwin && !cgo
+
+package process_darwin
+
+import "errors"
+
+// errCgoRequired is returned by every mach call when built without cgo
+var errCgoRequired = errors.New("process_darwin requires cgo for the mach APIs")
+
+func taskForPID(pid int) (uint32, error) {
+	return 0, errCgoRequired
+}
+
+func deallocatePort(task uint32) {}
+
+func machRegion(task uint32, addr uint64) (machRegionInfo, error) {
+	return machRegionInfo{}, errCgoRequired
+}
+
+func machRead(task uint32, addr uint64, buf []byte) (int, error) {
+	return 0, errCgoRequired
+}
+
+func machWrite(task uint32, addr uint64, data []byte) error {
+	return errCgoRequired
+}
+
+func machProtect(task uint32, addr, size uint64, protection int) error {
+	return errCgoRequired
+}
+
+func machAllocate(task uint32, size uint64) (uint64, error) {
+	return 0, errCgoRequired
+}
+
+func machDeallocate(task uint32, addr, size uint64) error {
+	return errCgoRequired
+}
+
+func machSuspend(task uint32) error {
+	return errCgoRequired
+}
+
+func machResume(task uint32) error {
+	return errCgoRequired
+}
+
+func regionFilename(pid int, addr uint64) string {
+	return ""
+}
@@ -0,0 +1,474 @@
+//go:build darwin
+
+package process_darwin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+
+	"gomem/coloransi"
+
+	"github.com/Moonlight-Companies/gologger/logger"
+)
+
+const (
+	VM_PROT_READ    = 0x1
+	VM_PROT_WRITE   = 0x2
+	VM_PROT_EXECUTE = 0x4
+	VM_PROT_COPY    = 0x10
+)
+
+// darwinPageSize is the granularity at which reads are retried after a failed copy
+// (16 KiB on Apple silicon, reads stay correct on 4 KiB pages)
+const darwinPageSize = 0x4000
+
+// errEndOfRegions is returned by machRegion past the last region of the task
+var errEndOfRegions = errors.New("no more regions")
+
+// machRegionInfo is the part of vm_region_basic_info_64 used to build the memory map
+type machRegionInfo struct {
+	Address    uint64
+	Size       uint64
+	Protection int
+	Shared     bool
+}
+
+// DarwinProcess implements the process.Process interface for macOS using the mach VM APIs
+type DarwinProcess struct {
+	pid  process.ProcessID
+	task uint32
+	log  *logger.Logger
+	mm   []memory_map.MemoryMapItem
+	mu   sync.RWMutex
+
+	limits process.ReadLimits
+	order  binary.ByteOrder // byte order of the target memory, nil for little-endian
+}
+
+// New creates a new DarwinProcess instance
+func New() process.Process {
+	return &DarwinProcess{
+		log: logger.NewLogger(coloransi.Color(coloransi.Red, coloransi.ColorOrange, "process-not-open")),
+	}
+}
+
+// NewWithPID creates a new DarwinProcess instance and opens it with the given PID
+func NewWithPID(pid process.ProcessID) (process.Process, error) {
+	p := &DarwinProcess{}
+	err := p.Open(pid)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Open acquires the task port of the process and reads its memory map
+func (p *DarwinProcess) Open(pid process.ProcessID) error {
+	task, err := taskForPID(int(pid))
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.task != 0 {
+		deallocatePort(p.task)
+	}
+
+	p.pid = pid
+	p.task = task
+	p.log = logger.NewLogger(coloransi.Color(coloransi.ColorPurple, coloransi.ColorOrange, fmt.Sprintf("process-%d", pid)))
+
+	if err := p.updateMemoryMapInternal(); err != nil {
+		p.log.Warn("Failed to initialize memory map: ", err)
+	}
+
+	p.log.Infoln("Process opened")
+	return nil
+}
+
+// Close releases the task port
+func (p *DarwinProcess) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.task != 0 {
+		deallocatePort(p.task)
+		p.task = 0
+	}
+
+	p.pid = 0
+	p.mm = nil
+	p.log = logger.NewLogger(coloransi.Color(coloransi.Red, coloransi.ColorOrange, "process-not-open"))
+	p.log.Infoln("Process closed")
+
+	return nil
+}
+
+func (p *DarwinProcess) GetPID() process.ProcessID {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pid
+}
+
+func (p *DarwinProcess) UpdateMemoryMap() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.updateMemoryMapInternal()
+}
+
+// updateMemoryMapInternal walks the regions of the task with mach_vm_region.
+// The caller must hold p.mu.
+func (p *DarwinProcess) updateMemoryMapInternal() error {
+	if p.task == 0 {
+		return process.ErrProcessNotOpen
+	}
+
+	var mm []memory_map.MemoryMapItem
+	for addr := uint64(0); ; {
+		region, err := machRegion(p.task, addr)
+		if errors.Is(err, errEndOfRegions) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read memory map: %w", err)
+		}
+
+		mm = append(mm, memory_map.MemoryMapItem{
+			Address: region.Address,
+			Size:    uint(region.Size),
+			Perms:   protectionToPerms(region.Protection, region.Shared),
+			Path:    regionFilename(int(p.pid), region.Address),
+		})
+
+		if region.Address+region.Size <= addr {
+			break
+		}
+		addr = region.Address + region.Size
+	}
+
+	p.mm = mm
+	return nil
+}
+
+// protectionToPerms converts a vm_prot_t into Linux style "rwxp" permissions
+func protectionToPerms(protection int, shared bool) string {
+	perms := []byte("---p")
+	if protection&VM_PROT_READ != 0 {
+		perms[0] = 'r'
+	}
+	if protection&VM_PROT_WRITE != 0 {
+		perms[1] = 'w'
+	}
+	if protection&VM_PROT_EXECUTE != 0 {
+		perms[2] = 'x'
+	}
+	if shared {
+		perms[3] = 's'
+	}
+	return string(perms)
+}
+
+// IsValidAddress checks if the address is inside a readable region of the memory map
+func (p *DarwinProcess) IsValidAddress(addr process.ProcessMemoryAddress) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.task == 0 {
+		return false
+	}
+
+	item := memory_map.IsValidAddress2(uint64(addr), p.mm)
+	return item != nil && item.IsReadable()
+}
+
+func (p *DarwinProcess) GetMemoryMap() ([]memory_map.MemoryMapItem, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.task == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+	result := make([]memory_map.MemoryMapItem, len(p.mm))
+	copy(result, p.mm)
+	return result, nil
+}
+
+// getLog returns the current logger, which is replaced on Open and Close
+func (p *DarwinProcess) getLog() *logger.Logger {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.log
+}
+
+// SetReadLimits sets the size cap and deadline applied to every read, see process.ReadLimits
+func (p *DarwinProcess) SetReadLimits(limits process.ReadLimits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limits = limits
+}
+
+// GetReadLimits returns the limits applied to every read
+func (p *DarwinProcess) GetReadLimits() process.ReadLimits {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.limits
+}
+
+// SetByteOrder sets the byte order typed reads and pod decoding use for the target memory,
+// e.g. binary.BigEndian for the guest memory of an emulator. The default is little-endian.
+func (p *DarwinProcess) SetByteOrder(order binary.ByteOrder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.order = order
+}
+
+// ByteOrder returns the byte order of the target memory
+func (p *DarwinProcess) ByteOrder() binary.ByteOrder {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.order == nil {
+		return binary.LittleEndian
+	}
+	return p.order
+}
+
+// ReadMemory reads size bytes at addr with mach_vm_read_overwrite.
+// If the range runs into an unreadable page, the readable prefix is returned together
+// with an error wrapping process.ErrPartialRead.
+func (p *DarwinProcess) ReadMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ([]byte, error) {
+	if size == 0 {
+		return []byte{}, nil
+	}
+
+	p.mu.RLock()
+	task, limits := p.task, p.limits
+	p.mu.RUnlock()
+
+	if task == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+
+	return limits.Read(size, func() ([]byte, error) {
+		return readMemory(task, addr, size)
+	})
+}
+
+// readMemory reads size bytes at addr, salvaging the readable prefix of a failed read
+func readMemory(task uint32, addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ([]byte, error) {
+	buf := make([]byte, size)
+	n, err := machRead(task, uint64(addr), buf)
+	if err == nil && n == len(buf) {
+		return buf, nil
+	}
+
+	// mach_vm_read_overwrite fails the whole range on a single bad page, salvage what precedes it
+	done := 0
+	for done < len(buf) {
+		cur := uint64(addr) + uint64(done)
+		chunk := min(int(darwinPageSize-cur%darwinPageSize), len(buf)-done)
+
+		n, chunkErr := machRead(task, cur, buf[done:done+chunk])
+		done += n
+		if chunkErr != nil || n != chunk {
+			break
+		}
+	}
+
+	if done == 0 {
+		if err == nil {
+			err = fmt.Errorf("read incomplete: expected %d, got 0", size)
+		}
+		return nil, err
+	}
+
+	return buf[:done], fmt.Errorf("%w: read %d of %d bytes at 0x%X", process.ErrPartialRead, done, size, uint64(addr))
+}
+
+// ReadMemoryPartial reads size bytes at addr, keeping the bytes that can be read around
+// unreadable pages, see process.ReadPartial
+func (p *DarwinProcess) ReadMemoryPartial(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) process.PartialRead {
+	return process.ReadPartial(p.ReadMemory, addr, size)
+}
+
+// WriteMemory writes data at addr with mach_vm_write. The whole range must be inside
+// writable regions of the memory map.
+func (p *DarwinProcess) WriteMemory(addr process.ProcessMemoryAddress, data []byte) error {
+	p.mu.RLock()
+	task, mm := p.task, p.mm
+	p.mu.RUnlock()
+
+	if task == 0 {
+		return process.ErrProcessNotOpen
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	end := uint64(addr) + uint64(len(data))
+	for cur := uint64(addr); cur < end; {
+		region := memory_map.IsValidAddress2(cur, mm)
+		if region == nil {
+			return fmt.Errorf("invalid memory address %x", cur)
+		}
+		if !region.IsWritable() {
+			return fmt.Errorf("memory region at %x is not writable", region.Address)
+		}
+		cur = region.Address + uint64(region.Size)
+	}
+
+	// Create a copy of the data to avoid potential modification during the write
+	dataCopy := make([]byte, len(data))
+	copy(dataCopy, data)
+
+	if err := machWrite(task, uint64(addr), dataCopy); err != nil {
+		return fmt.Errorf("failed to write process memory: %w", err)
+	}
+	return nil
+}
+
+// ProtectMemory changes the protection of the pages covering [addr, addr+size) with
+// mach_vm_protect. perms is a permission string such as "rw-" or "r-x", the previous
+// permissions of the page at addr are returned so they can be restored. Write access is
+// requested with VM_PROT_COPY, so shared read-only mappings (e.g. __TEXT) get a private copy.
+func (p *DarwinProcess) ProtectMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize, perms string) (string, error) {
+	p.mu.RLock()
+	task, mm := p.task, p.mm
+	p.mu.RUnlock()
+
+	if task == 0 {
+		return "", process.ErrProcessNotOpen
+	}
+
+	read, write, exec, err := process.ParsePerms(perms)
+	if err != nil {
+		return "", err
+	}
+	protection := 0
+	if read {
+		protection |= VM_PROT_READ
+	}
+	if write {
+		protection |= VM_PROT_WRITE | VM_PROT_COPY
+	}
+	if exec {
+		protection |= VM_PROT_EXECUTE
+	}
+
+	region := memory_map.IsValidAddress2(uint64(addr), mm)
+	if region == nil {
+		return "", fmt.Errorf("invalid memory address %x", addr)
+	}
+	previous := region.Perms[:min(len(region.Perms), 3)]
+
+	if err := machProtect(task, uint64(addr), uint64(size), protection); err != nil {
+		return "", err
+	}
+
+	if err := p.UpdateMemoryMap(); err != nil {
+		return previous, err
+	}
+	return previous, nil
+}
+
+// AllocateMemory allocates size bytes of zeroed memory in the task with mach_vm_allocate and
+// sets perms ("rw-", "r-x", ...) with mach_vm_protect, see process.MemoryAllocator. The size
+// is rounded up to whole pages.
+func (p *DarwinProcess) AllocateMemory(size process.ProcessMemorySize, perms string) (process.ProcessMemoryAddress, error) {
+	p.mu.RLock()
+	task := p.task
+	p.mu.RUnlock()
+
+	if task == 0 {
+		return 0, process.ErrProcessNotOpen
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("can't allocate 0 bytes")
+	}
+
+	read, write, exec, err := process.ParsePerms(perms)
+	if err != nil {
+		return 0, err
+	}
+	protection := 0
+	if read {
+		protection |= VM_PROT_READ
+	}
+	if write {
+		protection |= VM_PROT_WRITE
+	}
+	if exec {
+		protection |= VM_PROT_EXECUTE
+	}
+
+	addr, err := machAllocate(task, uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	// New pages are read/write
+	if protection != VM_PROT_READ|VM_PROT_WRITE {
+		if err := machProtect(task, addr, uint64(size), protection); err != nil {
+			machDeallocate(task, addr, uint64(size))
+			return 0, err
+		}
+	}
+
+	if err := p.UpdateMemoryMap(); err != nil {
+		return process.ProcessMemoryAddress(addr), err
+	}
+	return process.ProcessMemoryAddress(addr), nil
+}
+
+// FreeMemory releases memory returned by AllocateMemory with mach_vm_deallocate
+func (p *DarwinProcess) FreeMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) error {
+	p.mu.RLock()
+	task := p.task
+	p.mu.RUnlock()
+
+	if task == 0 {
+		return process.ErrProcessNotOpen
+	}
+
+	if err := machDeallocate(task, uint64(addr), uint64(size)); err != nil {
+		return err
+	}
+	return p.UpdateMemoryMap()
+}
+
+// Suspend suspends the task with task_suspend, see process.Suspender. Suspensions nest:
+// each Suspend needs a matching Resume.
+func (p *DarwinProcess) Suspend() error {
+	p.mu.RLock()
+	task := p.task
+	p.mu.RUnlock()
+
+	if task == 0 {
+		return process.ErrProcessNotOpen
+	}
+	return machSuspend(task)
+}
+
+// Resume resumes the task suspended by Suspend with task_resume
+func (p *DarwinProcess) Resume() error {
+	p.mu.RLock()
+	task := p.task
+	p.mu.RUnlock()
+
+	if task == 0 {
+		return process.ErrProcessNotOpen
+	}
+	return machResume(task)
+}
+
+func (p *DarwinProcess) Save(dirname string) error {
+	return fmt.Errorf("Save not implemented")
+}
+
+func (p *DarwinProcess) Load(dirname string) error {
+	return fmt.Errorf("Load not implemented")
+}
@@ -0,0 +1,211 @@
+//go:build darwin
+
+package process_darwin
+
+import (
+	"errors"
+	"unsafe"
+
+	"gomem/process"
+	"gomem/process_blob"
+)
+
+// ReadUINT8 reads an unsigned 8-bit integer from the specified address
+func (p *DarwinProcess) ReadUINT8(addr process.ProcessMemoryAddress) (uint8, error) {
+	data, err := p.ReadMemory(addr, 1)
+	if err != nil {
+		return 0, err
+	}
+	return data[0], nil
+}
+
+// ReadUINT16 reads an unsigned 16-bit integer from the specified address
+func (p *DarwinProcess) ReadUINT16(addr process.ProcessMemoryAddress) (uint16, error) {
+	data, err := p.ReadMemory(addr, 2)
+	if err != nil {
+		return 0, err
+	}
+	return p.ByteOrder().Uint16(data), nil
+}
+
+// ReadUINT32 reads an unsigned 32-bit integer from the specified address
+func (p *DarwinProcess) ReadUINT32(addr process.ProcessMemoryAddress) (uint32, error) {
+	data, err := p.ReadMemory(addr, 4)
+	if err != nil {
+		return 0, err
+	}
+	return p.ByteOrder().Uint32(data), nil
+}
+
+// ReadUINT64 reads an unsigned 64-bit integer from the specified address
+func (p *DarwinProcess) ReadUINT64(addr process.ProcessMemoryAddress) (uint64, error) {
+	data, err := p.ReadMemory(addr, 8)
+	if err != nil {
+		return 0, err
+	}
+	return p.ByteOrder().Uint64(data), nil
+}
+
+// ReadINT8 reads a signed 8-bit integer from the specified address
+func (p *DarwinProcess) ReadINT8(addr process.ProcessMemoryAddress) (int8, error) {
+	data, err := p.ReadMemory(addr, 1)
+	if err != nil {
+		return 0, err
+	}
+	return int8(data[0]), nil
+}
+
+// ReadINT16 reads a signed 16-bit integer from the specified address
+func (p *DarwinProcess) ReadINT16(addr process.ProcessMemoryAddress) (int16, error) {
+	data, err := p.ReadMemory(addr, 2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(p.ByteOrder().Uint16(data)), nil
+}
+
+// ReadINT32 reads a signed 32-bit integer from the specified address
+func (p *DarwinProcess) ReadINT32(addr process.ProcessMemoryAddress) (int32, error) {
+	data, err := p.ReadMemory(addr, 4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(p.ByteOrder().Uint32(data)), nil
+}
+
+// ReadINT64 reads a signed 64-bit integer from the specified address
+func (p *DarwinProcess) ReadINT64(addr process.ProcessMemoryAddress) (int64, error) {
+	data, err := p.ReadMemory(addr, 8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(p.ByteOrder().Uint64(data)), nil
+}
+
+// ReadFLOAT32 reads a 32-bit floating point number from the specified address
+func (p *DarwinProcess) ReadFLOAT32(addr process.ProcessMemoryAddress) (float32, error) {
+	data, err := p.ReadMemory(addr, 4)
+	if err != nil {
+		return 0, err
+	}
+	bits := p.ByteOrder().Uint32(data)
+	return *(*float32)(unsafe.Pointer(&bits)), nil
+}
+
+// ReadFLOAT64 reads a 64-bit floating point number from the specified address
+func (p *DarwinProcess) ReadFLOAT64(addr process.ProcessMemoryAddress) (float64, error) {
+	data, err := p.ReadMemory(addr, 8)
+	if err != nil {
+		return 0, err
+	}
+	bits := p.ByteOrder().Uint64(data)
+	return *(*float64)(unsafe.Pointer(&bits)), nil
+}
+
+// ReadNTS reads a null-terminated string from the specified address with a maximum length
+func (p *DarwinProcess) ReadNTS(addr process.ProcessMemoryAddress, maxLength process.ProcessMemorySize) (string, error) {
+	if maxLength == 0 {
+		return "", nil
+	}
+
+	// Read the maximum length
+	data, err := p.ReadMemory(addr, maxLength)
+	if err != nil {
+		return "", err
+	}
+
+	// Find the null terminator
+	for i, b := range data {
+		if b == 0 {
+			return string(data[:i]), nil
+		}
+	}
+
+	// If no null terminator found, return the whole buffer as string
+	return string(data), nil
+}
+
+// ReadPOINTER reads a pointer value from the specified address
+func (p *DarwinProcess) ReadPOINTER(addr process.ProcessMemoryAddress) (process.ProcessMemoryAddress, error) {
+	// On 64-bit systems, pointers are 8 bytes
+	// On 32-bit systems, pointers are 4 bytes
+	const ptrSize = 8 // Assuming 64-bit architecture
+
+	data, err := p.ReadMemory(addr, ptrSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Read as uint64 for 64-bit pointers
+	ptr := p.ByteOrder().Uint64(data)
+	return process.ProcessMemoryAddress(ptr), nil
+}
+
+func (p *DarwinProcess) ReadPOINTER2(addr process.ProcessMemoryAddress) process.ProcessMemoryAddress {
+	ptr, err := p.ReadPOINTER(addr)
+	if err != nil {
+		return 0 // Return zero on error
+	}
+	return ptr
+}
+
+func (p *DarwinProcess) ReadBlob(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) (process.ProcessReadOffset, error) {
+	if size == 0 {
+		return nil, nil // Return nil for zero size
+	}
+
+	data, err := p.ReadMemory(addr, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < int(size) {
+		return nil, errors.New("read less data than requested")
+	}
+
+	return process_blob.NewProcessBlobWithByteOrder(addr, data[:size], p.ByteOrder()), nil
+}
+
+func (p *DarwinProcess) ReadPointers(base process.ProcessMemoryAddress, count int) (results []process.ProcessMemoryAddress, err error) {
+	if count <= 0 {
+		return nil, errors.New("invalid count for pointers")
+	}
+
+	data, err := p.ReadMemory(base, process.ProcessMemorySize(count*8))
+	if err != nil {
+		return nil, err
+	}
+	for i := range count {
+		ptr := process.ProcessMemoryAddress(p.ByteOrder().Uint64(data[i*8:]))
+		if p.IsValidAddress(ptr) {
+			results = append(results, ptr)
+		}
+	}
+	return results, nil
+}
+
+// ReadBlobs reads a blob of blobReadSize bytes at every address of list.
+// It returns one ReadBlobsResult per input address, preserving the order.
+func (p *DarwinProcess) ReadBlobs(list []process.ProcessMemoryAddress, blobReadSize process.ProcessMemorySize) []process.ReadBlobsResult {
+	results := make([]process.ReadBlobsResult, len(list))
+	for i, addr := range list {
+		blob, err := p.ReadBlob(addr, blobReadSize)
+		if err == nil && blob == nil {
+			err = errors.New("blob read size is zero")
+		}
+		results[i] = process.ReadBlobsResult{Address: addr, Blob: blob, Err: err}
+	}
+	return results
+}
+
+// ReadPointerChain walks pointer fields at all offsets except the last,
+// which is treated as a raw byte offset into the final struct, and then
+// reads `size` bytes starting there.
+func (p *DarwinProcess) ReadPointerChain(base process.ProcessMemoryAddress, size process.ProcessMemorySize, offsets ...process.ProcessMemorySize) (process.ProcessReadOffset, error) {
+	return process.ReadPointerChainTransform(p, nil, base, size, offsets...)
+}
+
+// ReadPointerChainDebug does the same as ReadPointerChain
+func (p *DarwinProcess) ReadPointerChainDebug(base process.ProcessMemoryAddress, size process.ProcessMemorySize, offsets ...process.ProcessMemorySize) (process.ProcessReadOffset, error) {
+	return p.ReadPointerChain(base, size, offsets...)
+}
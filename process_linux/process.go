@@ -3,6 +3,7 @@
 package process_linux
 
 import (
+	"encoding/binary"
 	"fmt"
 	"os"
 	"sort"
@@ -21,12 +22,21 @@ func LastOpenProcess() process.Process {
 	return lastOpenProcess
 }
 
-// LinuxProcess implements the process.Process interface for Linux systems
+// LinuxProcess implements the process.Process interface for Linux systems.
+//
+// It is safe for concurrent use. The PID, logger and memory map are guarded by an RWMutex;
+// the memory map is never modified in place, UpdateMemoryMap swaps in a new slice, so read
+// paths take a read lock only long enough to grab the current snapshot and then work on
+// it without holding the lock (reads, validation and scans run in parallel).
 type LinuxProcess struct {
-	pid process.ProcessID
-	log *logger.Logger
-	mm  []memory_map.MemoryMapItem
-	mu  sync.Mutex
+	pid       process.ProcessID
+	startTime uint64 // start time of pid, used to detect PID reuse
+	log       *logger.Logger
+	mm        []memory_map.MemoryMapItem // immutable snapshot, replaced as a whole by UpdateMemoryMap
+	bookmarks *process.Bookmarks
+	limits    process.ReadLimits
+	order     binary.ByteOrder // byte order of the target memory, nil for little-endian
+	mu        sync.RWMutex
 }
 
 // New creates a new LinuxProcess instance
@@ -57,8 +67,15 @@ func (p *LinuxProcess) Open(pid process.ProcessID) error {
 		return fmt.Errorf("process with PID %d does not exist", pid)
 	}
 
+	startTime, err := processStartTime(pid)
+	if err != nil {
+		return fmt.Errorf("failed to read start time of PID %d: %w", pid, err)
+	}
+
 	p.mu.Lock()
 	p.pid = pid
+	p.startTime = startTime
+	p.mm = nil
 	p.log = logger.NewLogger(coloransi.Color(coloransi.ColorPurple, coloransi.ColorOrange, fmt.Sprintf("process-%d", pid)))
 	p.mu.Unlock()
 
@@ -67,7 +84,7 @@ func (p *LinuxProcess) Open(pid process.ProcessID) error {
 		return fmt.Errorf("failed to initialize memory map: %w", err)
 	}
 
-	p.log.Infoln("Process opened")
+	p.getLog().Infoln("Process opened")
 
 	return nil
 }
@@ -80,6 +97,7 @@ func (p *LinuxProcess) Close() error {
 
 	// Reset process state
 	p.pid = 0
+	p.startTime = 0
 	p.mm = nil
 
 	p.log = logger.NewLogger(coloransi.Color(coloransi.Red, coloransi.ColorOrange, "process-not-open"))
@@ -91,19 +109,63 @@ func (p *LinuxProcess) Close() error {
 
 // GetPID returns the process ID
 func (p *LinuxProcess) GetPID() process.ProcessID {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pid
+}
+
+// snapshot returns the PID and the current memory map snapshot.
+// The memory map must not be modified, it is shared with other readers.
+func (p *LinuxProcess) snapshot() (process.ProcessID, []memory_map.MemoryMapItem) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pid, p.mm
+}
+
+// SetReadLimits sets the size cap and deadline applied to every read, see process.ReadLimits
+func (p *LinuxProcess) SetReadLimits(limits process.ReadLimits) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	return p.pid
+	p.limits = limits
 }
 
-func (p *LinuxProcess) UpdateMemoryMap() error {
-	// First get the pid value without holding the lock for long
+// GetReadLimits returns the limits applied to every read
+func (p *LinuxProcess) GetReadLimits() process.ReadLimits {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.limits
+}
+
+// SetByteOrder sets the byte order typed reads and pod decoding use for the target memory,
+// e.g. binary.BigEndian for the guest memory of an emulator. The default is little-endian.
+func (p *LinuxProcess) SetByteOrder(order binary.ByteOrder) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	if p.pid == 0 {
-		return fmt.Errorf("process not opened")
+	p.order = order
+}
+
+// ByteOrder returns the byte order of the target memory
+func (p *LinuxProcess) ByteOrder() binary.ByteOrder {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.order == nil {
+		return binary.LittleEndian
+	}
+	return p.order
+}
+
+// getLog returns the current logger, which is replaced on Open and Close
+func (p *LinuxProcess) getLog() *logger.Logger {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.log
+}
+
+func (p *LinuxProcess) UpdateMemoryMap() error {
+	pid, _ := p.snapshot()
+	if pid == 0 {
+		return process.ErrProcessNotOpen
 	}
-	pid := p.pid
 
 	// Read memory map without holding the lock
 	linuxMemMap := memory_map.NewLinuxMemoryMap()
@@ -117,34 +179,36 @@ func (p *LinuxProcess) UpdateMemoryMap() error {
 		return mm[i].Address < mm[j].Address
 	})
 
-	// Now update the memory map with the lock
+	// Swap in the new snapshot, unless the process was closed or reopened meanwhile
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pid != pid {
+		return fmt.Errorf("process changed while updating the memory map")
+	}
 	p.mm = mm
 	return nil
 }
 
 func (p *LinuxProcess) IsValidAddress(addr process.ProcessMemoryAddress) bool {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	return p.isValidAddressInternal(addr)
+	_, mm := p.snapshot()
+	return isValidAddressIn(mm, addr)
 }
 
-// Internal helper function that assumes the mutex is already locked
-func (p *LinuxProcess) isValidAddressInternal(addr process.ProcessMemoryAddress) bool {
+// isValidAddressIn checks an address against a memory map snapshot
+func isValidAddressIn(mm []memory_map.MemoryMapItem, addr process.ProcessMemoryAddress) bool {
 	// Check if address is within any mapped memory region
 
 	if addr <= 0x10000 {
 		return false
 	}
 
-	if addr > 0x700000000000 {
+	// Top of the x86-64 user address space, as for dumps; mmap places libraries, stacks and
+	// allocations just below it
+	if addr > 0x7FFFFFFFFFFF {
 		return false
 	}
-	// if addr > 0x7FFFFFFFFFFF {
-	// 	return false
-	// }
 
-	if item := memory_map.IsValidAddress2(uint64(addr), p.mm); item != nil {
+	if item := memory_map.IsValidAddress2(uint64(addr), mm); item != nil {
 		// Check if memory region is readable
 		if isReadablePerms(item.Perms) {
 			return true
@@ -154,10 +218,9 @@ func (p *LinuxProcess) isValidAddressInternal(addr process.ProcessMemoryAddress)
 	return false
 }
 
-// Internal helper function that assumes the mutex is already locked
-// Returns the memory region containing the address and whether it's writable
-func (p *LinuxProcess) getMemoryRegionForAddress(addr process.ProcessMemoryAddress) (*memory_map.MemoryMapItem, bool) {
-	for _, item := range p.mm {
+// getMemoryRegionForAddress returns the memory region of a snapshot containing the address and whether it's writable
+func getMemoryRegionForAddress(mm []memory_map.MemoryMapItem, addr process.ProcessMemoryAddress) (*memory_map.MemoryMapItem, bool) {
+	for _, item := range mm {
 		end := item.Address + uint64(item.Size)
 		if uint64(addr) >= item.Address && uint64(addr) < end {
 			return &item, isWritablePerms(item.Perms)
@@ -167,18 +230,14 @@ func (p *LinuxProcess) getMemoryRegionForAddress(addr process.ProcessMemoryAddre
 }
 
 func (p *LinuxProcess) GetMemoryMap() ([]memory_map.MemoryMapItem, error) {
-	p.mu.Lock()
-
-	if p.pid == 0 {
-		p.mu.Unlock()
-		return nil, fmt.Errorf("process not opened")
+	pid, mm := p.snapshot()
+	if pid == 0 {
+		return nil, process.ErrProcessNotOpen
 	}
 
 	// Make a copy of the memory map to prevent external modification
-	result := make([]memory_map.MemoryMapItem, len(p.mm))
-	copy(result, p.mm)
-
-	p.mu.Unlock()
+	result := make([]memory_map.MemoryMapItem, len(mm))
+	copy(result, mm)
 
 	return result, nil
 }
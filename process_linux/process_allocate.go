@@ -0,0 +1,95 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"fmt"
+	"os"
+
+	"gomem/process"
+
+	"golang.org/x/sys/unix"
+)
+
+// AllocateMemory maps size bytes of zeroed anonymous memory with the given permissions
+// ("rw-", "rwx", ...) inside the target with an mmap call made by the target itself, see
+// ProtectMemory for how and the access needed. The size is rounded up to whole pages. The
+// memory map is refreshed afterwards, so the new region can be read and written at once.
+func (p *LinuxProcess) AllocateMemory(size process.ProcessMemorySize, perms string) (process.ProcessMemoryAddress, error) {
+	pid, mm := p.snapshot()
+	if pid == 0 {
+		return 0, process.ErrProcessNotOpen
+	}
+	if int(pid) == os.Getpid() {
+		return 0, fmt.Errorf("AllocateMemory can't target the calling process")
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("can't allocate 0 bytes")
+	}
+
+	prot, err := permsToProt(perms)
+	if err != nil {
+		return 0, err
+	}
+
+	pageSize := uint64(os.Getpagesize())
+	length := (uint64(size) + pageSize - 1) &^ (pageSize - 1)
+
+	insn, err := findSyscallInstruction(pid, mm)
+	if err != nil {
+		return 0, err
+	}
+
+	ret, err := remoteSyscall(int(pid), insn, unix.SYS_MMAP,
+		0, length, uint64(prot), unix.MAP_PRIVATE|unix.MAP_ANONYMOUS, ^uint64(0), 0)
+	if err != nil {
+		return 0, err
+	}
+	if errno := int64(ret); errno < 0 && errno >= -4095 {
+		return 0, fmt.Errorf("mmap of %d bytes %s failed: %w", length, perms, unix.Errno(-errno))
+	}
+
+	if err := p.UpdateMemoryMap(); err != nil {
+		return process.ProcessMemoryAddress(ret), err
+	}
+
+	p.getLog().Infof("Allocated 0x%x-0x%x %s", ret, ret+length, perms)
+	return process.ProcessMemoryAddress(ret), nil
+}
+
+// FreeMemory unmaps memory returned by AllocateMemory, size is the size it was allocated with
+func (p *LinuxProcess) FreeMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) error {
+	pid, mm := p.snapshot()
+	if pid == 0 {
+		return process.ErrProcessNotOpen
+	}
+	if int(pid) == os.Getpid() {
+		return fmt.Errorf("FreeMemory can't target the calling process")
+	}
+
+	pageSize := uint64(os.Getpagesize())
+	if uint64(addr)%pageSize != 0 {
+		return fmt.Errorf("address 0x%x is not page aligned", addr)
+	}
+	length := (uint64(size) + pageSize - 1) &^ (pageSize - 1)
+
+	insn, err := findSyscallInstruction(pid, mm)
+	if err != nil {
+		return err
+	}
+
+	ret, err := remoteSyscall(int(pid), insn, unix.SYS_MUNMAP, uint64(addr), length)
+	if err != nil {
+		return err
+	}
+	if errno := int64(ret); errno < 0 && errno >= -4095 {
+		return fmt.Errorf("munmap 0x%x-0x%x failed: %w", addr, uint64(addr)+length, unix.Errno(-errno))
+	}
+
+	if err := p.UpdateMemoryMap(); err != nil {
+		return err
+	}
+
+	p.getLog().Infof("Freed 0x%x-0x%x", addr, uint64(addr)+length)
+	return nil
+}
@@ -0,0 +1,148 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"gomem/process"
+
+	"golang.org/x/sys/unix"
+)
+
+// callTimeout is how long CallFunction waits for the called function to return
+const callTimeout = 10 * time.Second
+
+// CallFunction calls the function at addr in the main thread of the target, see
+// process.FunctionCaller. The thread is stopped with ptrace, its registers are pointed at the
+// function with the arguments in the System V registers and a return address of 0 below its
+// stack, and it is resumed until the return faults at 0. Its registers, including the
+// floating point state, are restored afterwards, so it continues as if nothing happened.
+//
+// The function runs on the main thread while the other threads keep running, so it must be
+// safe to call from wherever the main thread was stopped; a function taking a lock the
+// thread holds deadlocks until the call times out. Signals arriving meanwhile are delivered;
+// a crash in the function is reported as an error and doesn't kill the target. See
+// ProtectMemory for the access needed.
+func (p *LinuxProcess) CallFunction(addr process.ProcessMemoryAddress, args ...uint64) (process.CallResult, error) {
+	pid, _ := p.snapshot()
+	if pid == 0 {
+		return process.CallResult{}, process.ErrProcessNotOpen
+	}
+	if int(pid) == os.Getpid() {
+		return process.CallResult{}, fmt.Errorf("CallFunction can't target the calling process")
+	}
+	if !callSupported {
+		return process.CallResult{}, fmt.Errorf("calling functions is not implemented on %s", runtime.GOARCH)
+	}
+	if len(args) > process.MaxCallArgs {
+		return process.CallResult{}, fmt.Errorf("at most %d arguments can be passed, got %d", process.MaxCallArgs, len(args))
+	}
+
+	var result process.CallResult
+	err := ptraceStopped(int(pid), func() error {
+		var err error
+		result, err = callStopped(int(pid), uint64(addr), args)
+		return err
+	})
+	if err != nil {
+		return result, fmt.Errorf("call of 0x%x failed: %w", uint64(addr), err)
+	}
+
+	p.getLog().Debugf("Called 0x%x, returned 0x%x", uint64(addr), result.Int)
+	return result, nil
+}
+
+// callStopped runs the call in the stopped thread tid and restores its state
+func callStopped(tid int, addr uint64, args []uint64) (process.CallResult, error) {
+	var saved unix.PtraceRegs
+	if err := unix.PtraceGetRegs(tid, &saved); err != nil {
+		return process.CallResult{}, fmt.Errorf("ptrace getregs failed: %w", err)
+	}
+	savedFP, err := getFPRegs(tid)
+	if err != nil {
+		return process.CallResult{}, err
+	}
+	defer func() {
+		unix.PtraceSetRegs(tid, &saved)
+		setFPRegs(tid, savedFP)
+	}()
+
+	// Leave the red zone and a margin of the interrupted frame alone, and push the return
+	// address 0 so the stack is aligned as after a call instruction
+	sp := (callStackPointer(&saved) - callStackSkip) &^ 15
+	sp -= 8
+	var zero [8]byte
+	if _, err := unix.PtracePokeData(tid, uintptr(sp), zero[:]); err != nil {
+		return process.CallResult{}, fmt.Errorf("failed to push the return address: %w", err)
+	}
+
+	regs := saved
+	setCallRegs(&regs, addr, sp, args)
+	if err := unix.PtraceSetRegs(tid, &regs); err != nil {
+		return process.CallResult{}, fmt.Errorf("ptrace setregs failed: %w", err)
+	}
+
+	signal := 0
+	deadline := time.Now().Add(callTimeout)
+	for {
+		if err := unix.PtraceCont(tid, signal); err != nil {
+			return process.CallResult{}, fmt.Errorf("ptrace cont failed: %w", err)
+		}
+
+		status, err := waitCall(tid, deadline)
+		if err != nil {
+			return process.CallResult{}, err
+		}
+		if status.Exited() || status.Signaled() {
+			return process.CallResult{}, fmt.Errorf("process exited during the call")
+		}
+
+		if err := unix.PtraceGetRegs(tid, &regs); err != nil {
+			return process.CallResult{}, fmt.Errorf("ptrace getregs failed: %w", err)
+		}
+
+		switch sig := status.StopSignal(); sig {
+		case unix.SIGSEGV, unix.SIGBUS, unix.SIGILL, unix.SIGFPE:
+			if sig == unix.SIGSEGV && regs.PC() == 0 {
+				fp, err := getFPRegs(tid)
+				if err != nil {
+					return process.CallResult{}, err
+				}
+				return callResult(&regs, fp), nil
+			}
+			// Not delivered, so the target survives a crash of the function
+			return process.CallResult{}, fmt.Errorf("function crashed with %v at 0x%x", sig, regs.PC())
+		default:
+			signal = int(sig)
+		}
+	}
+}
+
+// waitCall waits for the next stop of tid until deadline, then interrupts it
+func waitCall(tid int, deadline time.Time) (unix.WaitStatus, error) {
+	var status unix.WaitStatus
+	for {
+		wpid, err := unix.Wait4(tid, &status, unix.WALL|unix.WNOHANG, nil)
+		if err != nil {
+			return status, fmt.Errorf("wait for %d failed: %w", tid, err)
+		}
+		if wpid != 0 {
+			return status, nil
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Stop the thread so its registers can be restored, the function is abandoned midway
+	unix.Tgkill(tid, tid, unix.SIGSTOP)
+	if err := waitStopped(tid); err != nil {
+		return status, err
+	}
+	return status, fmt.Errorf("function did not return within %v", callTimeout)
+}
@@ -0,0 +1,84 @@
+//go:build linux && amd64
+
+package process_linux
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+
+	"gomem/process"
+
+	"golang.org/x/sys/unix"
+)
+
+// callSupported reports whether CallFunction is implemented
+const callSupported = true
+
+// callStackSkip is the space left below the stack pointer of the interrupted code, its
+// 128 byte red zone and a margin
+const callStackSkip = 256
+
+// fpRegsSize is the size of struct user_fpregs_struct, the FXSAVE area
+const fpRegsSize = 512
+
+// xmm0Offset is the offset of XMM0 in the FXSAVE area
+const xmm0Offset = 160
+
+// callStackPointer returns the stack pointer
+func callStackPointer(regs *unix.PtraceRegs) uint64 {
+	return regs.Rsp
+}
+
+// setCallRegs prepares regs to call the function at pc with the System V AMD64 calling
+// convention, sp points to the return address
+func setCallRegs(regs *unix.PtraceRegs, pc, sp uint64, args []uint64) {
+	var a [process.MaxCallArgs]uint64
+	copy(a[:], args)
+
+	regs.Rip = pc
+	regs.Rsp = sp
+	regs.Rdi = a[0]
+	regs.Rsi = a[1]
+	regs.Rdx = a[2]
+	regs.Rcx = a[3]
+	regs.R8 = a[4]
+	regs.R9 = a[5]
+	// No vector registers used by variadic calls
+	regs.Rax = 0
+	// Not in a syscall anymore, so the kernel does not restart an interrupted one on resume
+	regs.Orig_rax = ^uint64(0)
+}
+
+// callResult returns RAX and the low half of XMM0
+func callResult(regs *unix.PtraceRegs, fp []byte) process.CallResult {
+	return process.CallResult{
+		Int:   regs.Rax,
+		Float: binary.LittleEndian.Uint64(fp[xmm0Offset:]),
+	}
+}
+
+// getFPRegs saves the floating point and SSE registers of a stopped thread
+func getFPRegs(tid int) ([]byte, error) {
+	buf := make([]byte, fpRegsSize)
+	if err := ptraceFPRegs(unix.PTRACE_GETFPREGS, tid, buf); err != nil {
+		return nil, fmt.Errorf("ptrace getfpregs failed: %w", err)
+	}
+	return buf, nil
+}
+
+// setFPRegs restores registers saved by getFPRegs
+func setFPRegs(tid int, buf []byte) error {
+	if err := ptraceFPRegs(unix.PTRACE_SETFPREGS, tid, buf); err != nil {
+		return fmt.Errorf("ptrace setfpregs failed: %w", err)
+	}
+	return nil
+}
+
+func ptraceFPRegs(request int, tid int, buf []byte) error {
+	_, _, errno := unix.Syscall6(unix.SYS_PTRACE, uintptr(request), uintptr(tid), 0, uintptr(unsafe.Pointer(&buf[0])), 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
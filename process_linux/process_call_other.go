@@ -0,0 +1,24 @@
+//go:build linux && !amd64
+
+package process_linux
+
+import (
+	"gomem/process"
+
+	"golang.org/x/sys/unix"
+)
+
+// callSupported is false, calling functions is not implemented on this architecture
+const callSupported = false
+
+const callStackSkip = 0
+
+func callStackPointer(regs *unix.PtraceRegs) uint64 { return 0 }
+
+func setCallRegs(regs *unix.PtraceRegs, pc, sp uint64, args []uint64) {}
+
+func callResult(regs *unix.PtraceRegs, fp []byte) process.CallResult { return process.CallResult{} }
+
+func getFPRegs(tid int) ([]byte, error) { return nil, nil }
+
+func setFPRegs(tid int, buf []byte) error { return nil }
@@ -0,0 +1,179 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"gomem/process"
+)
+
+// enumerateProcesses walks /proc once and reads the information of every process.
+// Each process costs two file reads (status and cmdline) and one readlink (exe),
+// using a single read buffer for the whole walk.
+func enumerateProcesses() ([]process.ProcessInfo, error) {
+	dir, err := os.Open("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proc: %w", err)
+	}
+	defer dir.Close()
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proc: %w", err)
+	}
+
+	results := make([]process.ProcessInfo, 0, len(names))
+	buf := make([]byte, 0, 4096)
+
+	for _, name := range names {
+		pid, err := strconv.Atoi(name)
+		if err != nil {
+			// Not a PID directory
+			continue
+		}
+
+		info, err := readProcessInfo(process.ProcessID(pid), name, &buf)
+		if err != nil {
+			// Process may have terminated while we were reading
+			continue
+		}
+
+		results = append(results, *info)
+	}
+
+	return results, nil
+}
+
+// readProcessInfo reads the information of a single process, dirname being the PID as a string.
+// buf is reused between calls to avoid allocating a buffer per file.
+func readProcessInfo(pid process.ProcessID, dirname string, buf *[]byte) (*process.ProcessInfo, error) {
+	procPath := "/proc/" + dirname
+
+	info := &process.ProcessInfo{PID: pid}
+
+	// Name, parent, state, owner, threads and memory all come from /proc/<pid>/status. When
+	// it can't be read the name is taken from /proc/<pid>/comm and the rest is left empty.
+	var err error
+	*buf, err = readProcFile(procPath+"/status", *buf)
+	if err == nil {
+		parseProcessStatus(*buf, info)
+	} else {
+		*buf, err = readProcFile(procPath+"/comm", *buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read process name: %w", err)
+		}
+		info.Name = string(bytes.TrimSpace(*buf))
+	}
+
+	// Read executable path from /proc/<pid>/exe symlink
+	// Some processes don't have an exe (e.g., kernel threads)
+	info.Exe, _ = os.Readlink(procPath + "/exe")
+
+	// Read the command line from /proc/<pid>/cmdline
+	*buf, err = readProcFile(procPath+"/cmdline", *buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process cmdline: %w", err)
+	}
+
+	cmdlineBytes := *buf
+	if len(cmdlineBytes) > 0 {
+		// Remove the trailing NULL byte
+		if cmdlineBytes[len(cmdlineBytes)-1] == 0 {
+			cmdlineBytes = cmdlineBytes[:len(cmdlineBytes)-1]
+		}
+
+		// Split by NULL bytes
+		for _, arg := range bytes.Split(cmdlineBytes, []byte{0}) {
+			info.Cmdline = append(info.Cmdline, string(arg))
+		}
+	}
+
+	return info, nil
+}
+
+// readProcFile reads a /proc file into buf, growing it as needed.
+// /proc files report a size of 0, so os.ReadFile would start with a small buffer every time.
+func readProcFile(path string, buf []byte) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return buf[:0], err
+	}
+	defer f.Close()
+
+	buf = buf[:0]
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := f.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, err
+		}
+	}
+}
+
+// parseProcessStatus fills info from the contents of /proc/<pid>/status
+func parseProcessStatus(status []byte, info *process.ProcessInfo) {
+	for len(status) > 0 {
+		line := status
+		if i := bytes.IndexByte(status, '\n'); i >= 0 {
+			line, status = status[:i], status[i+1:]
+		} else {
+			status = nil
+		}
+
+		colon := bytes.IndexByte(line, ':')
+		if colon < 0 {
+			continue
+		}
+
+		key := string(line[:colon])
+		value := bytes.TrimSpace(line[colon+1:])
+
+		switch key {
+		case "Name":
+			info.Name = string(value)
+		case "PPid":
+			if ppidVal, err := strconv.Atoi(string(value)); err == nil {
+				info.PPID = process.ProcessID(ppidVal)
+			}
+		case "State":
+			if len(value) > 0 {
+				info.State = process.ProcessState(value[0:1]) // First character is the state code
+			}
+		case "Uid":
+			// Extract the effective UID
+			uidParts := bytes.Fields(value)
+			if len(uidParts) >= 2 {
+				// This is simplified - in a real implementation, you would look up
+				// the username from /etc/passwd or use a syscall
+				info.User = "uid_" + string(uidParts[1]) // Placeholder
+			}
+		case "Threads":
+			if threadsVal, err := strconv.Atoi(string(value)); err == nil {
+				info.Threads = threadsVal
+			}
+		case "VmRSS":
+			// Extract memory usage (format: "1234 kB")
+			memParts := bytes.Fields(value)
+			if len(memParts) >= 1 {
+				if memVal, err := strconv.ParseUint(string(memParts[0]), 10, 64); err == nil {
+					if len(memParts) > 1 && string(memParts[1]) == "kB" {
+						info.Memory = memVal * 1024 // Convert kB to bytes
+					} else {
+						info.Memory = memVal
+					}
+				}
+			}
+		}
+	}
+}
@@ -3,25 +3,44 @@
 package process_linux
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
 	"gomem/process"
 )
 
-// LinuxProcessFinder implements the process.ProcessFinder interface
-type LinuxProcessFinder struct{}
+// DefaultProcessListMaxAge is a reasonable MaxAge for NewProcessFinderWithMaxAge when the
+// process list is queried repeatedly, e.g. to walk a process tree
+const DefaultProcessListMaxAge = time.Second
+
+// LinuxProcessFinder implements the process.ProcessFinder interface.
+// Listing and hierarchy queries share a single /proc enumeration which can be reused
+// for MaxAge, so walking a process tree or filtering repeatedly does not re-read
+// every process each time.
+type LinuxProcessFinder struct {
+	// MaxAge is how long an enumeration is reused, 0 disables caching
+	MaxAge time.Duration
+
+	mu         sync.Mutex
+	snapshot   []process.ProcessInfo
+	snapshotAt time.Time
+}
 
-// NewProcessFinder creates a new LinuxProcessFinder
+// NewProcessFinder creates a new LinuxProcessFinder reading /proc on every query, see
+// NewProcessFinderWithMaxAge to reuse enumerations
 func NewProcessFinder() process.ProcessFinder {
 	return &LinuxProcessFinder{}
 }
 
+// NewProcessFinderWithMaxAge creates a new LinuxProcessFinder reusing enumerations for maxAge (0 disables caching)
+func NewProcessFinderWithMaxAge(maxAge time.Duration) process.ProcessFinder {
+	return &LinuxProcessFinder{MaxAge: maxAge}
+}
+
 // FindProcess finds a process by name and returns its PID
 // This is kept for backward compatibility
 func FindProcess(name string) (process.ProcessID, error) {
@@ -38,6 +57,35 @@ func FindProcess(name string) (process.ProcessID, error) {
 	return processes[0].PID, nil
 }
 
+// Invalidate drops the cached enumeration so the next query re-reads /proc
+func (f *LinuxProcessFinder) Invalidate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.snapshot = nil
+	f.snapshotAt = time.Time{}
+}
+
+// processes returns the cached enumeration, refreshing it if it is older than MaxAge.
+// The returned slice is shared and must not be modified.
+func (f *LinuxProcessFinder) processes() ([]process.ProcessInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.snapshot != nil && f.MaxAge > 0 && time.Since(f.snapshotAt) < f.MaxAge {
+		return f.snapshot, nil
+	}
+
+	snapshot, err := enumerateProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	f.snapshot = snapshot
+	f.snapshotAt = time.Now()
+	return snapshot, nil
+}
+
 // FindProcessByPID finds a process by its PID
 func (f *LinuxProcessFinder) FindProcessByPID(pid process.ProcessID) (*process.ProcessInfo, error) {
 	procPath := fmt.Sprintf("/proc/%d", pid)
@@ -52,59 +100,45 @@ func (f *LinuxProcessFinder) FindProcessByPID(pid process.ProcessID) (*process.P
 
 // FindProcessByName finds processes by their name (exact match)
 func (f *LinuxProcessFinder) FindProcessByName(name string) ([]process.ProcessInfo, error) {
-	return findProcessesByNamePattern("^" + regexp.QuoteMeta(name) + "$")
+	return f.findProcessesByNamePattern("^" + regexp.QuoteMeta(name) + "$")
 }
 
 // FindProcessByNamePattern finds processes by their name (pattern match)
 func (f *LinuxProcessFinder) FindProcessByNamePattern(pattern string) ([]process.ProcessInfo, error) {
-	return findProcessesByNamePattern(pattern)
+	return f.findProcessesByNamePattern(pattern)
 }
 
 // FindAllProcesses returns information about all running processes
 func (f *LinuxProcessFinder) FindAllProcesses() ([]process.ProcessInfo, error) {
-	return findProcessesByNamePattern(".*")
+	allProcesses, err := f.processes()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]process.ProcessInfo, len(allProcesses))
+	copy(results, allProcesses)
+	return results, nil
 }
 
 // Helper function to find processes by name pattern
-func findProcessesByNamePattern(pattern string) ([]process.ProcessInfo, error) {
+func (f *LinuxProcessFinder) findProcessesByNamePattern(pattern string) ([]process.ProcessInfo, error) {
 	// Compile the regex pattern
 	re, err := regexp.Compile(pattern)
 	if err != nil {
 		return nil, fmt.Errorf("invalid pattern: %w", err)
 	}
 
-	// List all directories in /proc that are numbers (PIDs)
-	entries, err := os.ReadDir("/proc")
+	allProcesses, err := f.processes()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read /proc: %w", err)
+		return nil, err
 	}
 
 	var results []process.ProcessInfo
 
-	for _, entry := range entries {
-		// Check if the entry is a directory and its name is a number (PID)
-		if !entry.IsDir() {
-			continue
-		}
-
-		pid, err := strconv.Atoi(entry.Name())
-		if err != nil {
-			// Not a PID directory
-			continue
-		}
-
-		procID := process.ProcessID(pid)
-
-		// Get process info
-		info, err := getProcessInfo(procID)
-		if err != nil {
-			// Process may have terminated while we were reading
-			continue
-		}
-
+	for _, info := range allProcesses {
 		// Check if the process name matches the pattern
 		if re.MatchString(info.Name) {
-			results = append(results, *info)
+			results = append(results, info)
 		}
 	}
 
@@ -113,141 +147,30 @@ func findProcessesByNamePattern(pattern string) ([]process.ProcessInfo, error) {
 
 // Helper function to get process information
 func getProcessInfo(pid process.ProcessID) (*process.ProcessInfo, error) {
-	procPath := fmt.Sprintf("/proc/%d", pid)
-
-	// Read process name from /proc/<pid>/comm
-	nameBytes, err := os.ReadFile(filepath.Join(procPath, "comm"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read process name: %w", err)
-	}
-	name := strings.TrimSpace(string(nameBytes))
-
-	// Read executable path from /proc/<pid>/exe symlink
-	exe, err := os.Readlink(filepath.Join(procPath, "exe"))
-	if err != nil {
-		// Some processes don't have an exe (e.g., kernel threads)
-		exe = ""
-	}
-
-	// Read the command line from /proc/<pid>/cmdline
-	cmdlineBytes, err := os.ReadFile(filepath.Join(procPath, "cmdline"))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read process cmdline: %w", err)
-	}
-
-	// Split the command line on NULL bytes
-	var cmdline []string
-	if len(cmdlineBytes) > 0 {
-		// Remove the trailing NULL byte
-		if cmdlineBytes[len(cmdlineBytes)-1] == 0 {
-			cmdlineBytes = cmdlineBytes[:len(cmdlineBytes)-1]
-		}
-
-		// Split by NULL bytes
-		for _, arg := range bytes.Split(cmdlineBytes, []byte{0}) {
-			cmdline = append(cmdline, string(arg))
-		}
-	}
-
-	// Get process status from /proc/<pid>/status
-	var (
-		ppid    process.ProcessID    = 0
-		state   process.ProcessState = ""
-		user    string               = ""
-		threads int                  = 0
-		memory  uint64               = 0
-	)
-
-	// Read the status file
-	statusBytes, err := os.ReadFile(filepath.Join(procPath, "status"))
-	if err == nil {
-		// Parse status information
-		statusLines := strings.Split(string(statusBytes), "\n")
-		for _, line := range statusLines {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) != 2 {
-				continue
-			}
-
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-
-			switch key {
-			case "PPid":
-				if ppidVal, err := strconv.Atoi(value); err == nil {
-					ppid = process.ProcessID(ppidVal)
-				}
-			case "State":
-				if len(value) > 0 {
-					state = process.ProcessState(value[0:1]) // First character is the state code
-				}
-			case "Uid":
-				// Extract the effective UID
-				uidParts := strings.Fields(value)
-				if len(uidParts) >= 2 {
-					user = uidParts[1] // Effective UID
-				}
-			case "Threads":
-				if threadsVal, err := strconv.Atoi(value); err == nil {
-					threads = threadsVal
-				}
-			case "VmRSS":
-				// Extract memory usage (format: "1234 kB")
-				memParts := strings.Fields(value)
-				if len(memParts) >= 1 {
-					if memVal, err := strconv.ParseUint(memParts[0], 10, 64); err == nil {
-						if len(memParts) > 1 && memParts[1] == "kB" {
-							memory = memVal * 1024 // Convert kB to bytes
-						} else {
-							memory = memVal
-						}
-					}
-				}
-			}
-		}
-	}
-
-	// Get username from UID
-	if user != "" {
-		// This is simplified - in a real implementation, you would look up
-		// the username from /etc/passwd or use a syscall
-		user = "uid_" + user // Placeholder
-	}
-
-	return &process.ProcessInfo{
-		PID:     pid,
-		PPID:    ppid,
-		Name:    name,
-		Exe:     exe,
-		Cmdline: cmdline,
-		State:   state,
-		User:    user,
-		Threads: threads,
-		Memory:  memory,
-	}, nil
+	var buf []byte
+	return readProcessInfo(pid, strconv.Itoa(int(pid)), &buf)
 }
 
 // FindProcessByCommandLine finds processes that have a specific argument in their command line
 func (f *LinuxProcessFinder) FindProcessByCommandLine(arg string) ([]process.ProcessInfo, error) {
-	return findProcessesByCommandLinePattern(regexp.QuoteMeta(arg))
+	return f.findProcessesByCommandLinePattern(regexp.QuoteMeta(arg))
 }
 
 // FindProcessByCommandLinePattern finds processes with command line arguments matching a pattern
 func (f *LinuxProcessFinder) FindProcessByCommandLinePattern(pattern string) ([]process.ProcessInfo, error) {
-	return findProcessesByCommandLinePattern(pattern)
+	return f.findProcessesByCommandLinePattern(pattern)
 }
 
 // Helper function to find processes by command line pattern
-func findProcessesByCommandLinePattern(pattern string) ([]process.ProcessInfo, error) {
+func (f *LinuxProcessFinder) findProcessesByCommandLinePattern(pattern string) ([]process.ProcessInfo, error) {
 	// Compile the regex pattern
 	re, err := regexp.Compile(pattern)
 	if err != nil {
 		return nil, fmt.Errorf("invalid pattern: %w", err)
 	}
 
-	finder := NewProcessFinder()
 	// Get all processes
-	allProcesses, err := finder.FindAllProcesses()
+	allProcesses, err := f.processes()
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +193,7 @@ func findProcessesByCommandLinePattern(pattern string) ([]process.ProcessInfo, e
 // FindChildProcesses finds all child processes of a given PID
 func (f *LinuxProcessFinder) FindChildProcesses(parentPID process.ProcessID) ([]process.ProcessInfo, error) {
 	// Get all processes
-	allProcesses, err := f.FindAllProcesses()
+	allProcesses, err := f.processes()
 	if err != nil {
 		return nil, err
 	}
@@ -290,7 +213,7 @@ func (f *LinuxProcessFinder) FindChildProcesses(parentPID process.ProcessID) ([]
 // FindDescendantProcesses finds all descendant processes (children, grandchildren, etc.) of a given PID
 func (f *LinuxProcessFinder) FindDescendantProcesses(rootPID process.ProcessID) ([]process.ProcessInfo, error) {
 	// Get all processes
-	allProcesses, err := f.FindAllProcesses()
+	allProcesses, err := f.processes()
 	if err != nil {
 		return nil, err
 	}
@@ -350,14 +273,8 @@ func (f *LinuxProcessFinder) FindDescendantProcesses(rootPID process.ProcessID)
 
 // GetProcessTree returns a tree-like representation of processes starting from a root PID
 func (f *LinuxProcessFinder) GetProcessTree(rootPID process.ProcessID) (*process.ProcessTreeNode, error) {
-	// Check if the root process exists
-	rootProcess, err := f.FindProcessByPID(rootPID)
-	if err != nil {
-		return nil, err
-	}
-
 	// Get all processes
-	allProcesses, err := f.FindAllProcesses()
+	allProcesses, err := f.processes()
 	if err != nil {
 		return nil, err
 	}
@@ -377,8 +294,18 @@ func (f *LinuxProcessFinder) GetProcessTree(rootPID process.ProcessID) (*process
 		childrenMap[proc.PPID] = append(childrenMap[proc.PPID], proc.PID)
 	}
 
+	// Check if the root process exists, it may have started after the enumeration
+	rootProcess, exists := processMap[rootPID]
+	if !exists {
+		info, err := f.FindProcessByPID(rootPID)
+		if err != nil {
+			return nil, err
+		}
+		rootProcess = *info
+	}
+
 	// Build the tree recursively
-	tree := buildProcessTree(*rootProcess, childrenMap, processMap)
+	tree := buildProcessTree(rootProcess, childrenMap, processMap)
 
 	return tree, nil
 }
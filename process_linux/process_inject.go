@@ -0,0 +1,131 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gomem/process"
+	"gomem/symbols"
+)
+
+// dlopen flags
+const (
+	RTLD_LAZY   = 0x0001
+	RTLD_NOW    = 0x0002
+	RTLD_GLOBAL = 0x0100
+)
+
+// dlModules are the modules exporting dlopen, libc since glibc 2.34, libdl before, musl
+var dlModules = []string{"libc.so.6", "libdl.so.2", "libc.musl-x86_64.so.1", "ld-musl-x86_64.so.1"}
+
+// InjectedLibrary is a shared object loaded into the target by InjectLibrary
+type InjectedLibrary struct {
+	Handle process.ProcessMemoryAddress // Handle returned by dlopen, for UnloadLibrary
+	Module process.Module               // Module of the library in the refreshed module list
+}
+
+// InjectLibrary loads the shared object at path into the target by calling its dlopen with
+// CallFunction, so the constructors of the library run in the target. path is resolved to
+// an absolute path, which must also be valid in the mount namespace of the target. Loading
+// a library that is already loaded returns its handle and increases its reference count.
+//
+// dlopen runs on the main thread of the target: a target stopped in the loader or the
+// allocator deadlocks until the call times out. Statically linked targets can't load
+// libraries.
+func (p *LinuxProcess) InjectLibrary(path string) (InjectedLibrary, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return InjectedLibrary{}, err
+	}
+	// The memory map shows the file the links point to
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return InjectedLibrary{}, err
+	}
+
+	dlopen, module, err := p.dlSymbol("dlopen")
+	if err != nil {
+		return InjectedLibrary{}, err
+	}
+
+	arg, err := p.AllocateMemory(process.ProcessMemorySize(len(abs)+1), "rw-")
+	if err != nil {
+		return InjectedLibrary{}, err
+	}
+	defer p.FreeMemory(arg, process.ProcessMemorySize(len(abs)+1))
+
+	if err := p.WriteMemory(arg, append([]byte(abs), 0)); err != nil {
+		return InjectedLibrary{}, fmt.Errorf("failed to write library path: %w", err)
+	}
+
+	result, err := p.CallFunction(dlopen, uint64(arg), RTLD_NOW|RTLD_GLOBAL)
+	if err != nil {
+		return InjectedLibrary{}, fmt.Errorf("dlopen of %s failed: %w", abs, err)
+	}
+	if result.Int == 0 {
+		return InjectedLibrary{}, fmt.Errorf("dlopen of %s failed: %s", abs, p.dlError(module))
+	}
+
+	lib := InjectedLibrary{Handle: process.ProcessMemoryAddress(result.Int)}
+	if err := p.UpdateMemoryMap(); err != nil {
+		return lib, err
+	}
+	lib.Module, err = process.FindModule(p, abs)
+	if err != nil {
+		return lib, fmt.Errorf("%s loaded but not mapped: %w", abs, err)
+	}
+
+	p.getLog().Infof("Injected %s at 0x%x, handle 0x%x", abs, uint64(lib.Module.Base), uint64(lib.Handle))
+	return lib, nil
+}
+
+// UnloadLibrary calls dlclose with a handle returned by InjectLibrary. The library is
+// unmapped once its reference count drops to 0 and its destructors have run.
+func (p *LinuxProcess) UnloadLibrary(handle process.ProcessMemoryAddress) error {
+	dlclose, module, err := p.dlSymbol("dlclose")
+	if err != nil {
+		return err
+	}
+
+	result, err := p.CallFunction(dlclose, uint64(handle))
+	if err != nil {
+		return fmt.Errorf("dlclose of 0x%x failed: %w", uint64(handle), err)
+	}
+	if int32(result.Int) != 0 {
+		return fmt.Errorf("dlclose of 0x%x failed: %s", uint64(handle), p.dlError(module))
+	}
+	return p.UpdateMemoryMap()
+}
+
+// dlSymbol resolves a function of the dynamic loader interface and returns the module
+// exporting it
+func (p *LinuxProcess) dlSymbol(name string) (process.ProcessMemoryAddress, string, error) {
+	for _, module := range dlModules {
+		if addr, err := symbols.ResolveSymbol(p, module, name); err == nil {
+			return addr, module, nil
+		}
+	}
+	return 0, "", fmt.Errorf("%s not found, the target may be statically linked", name)
+}
+
+// dlError returns the message of the last failed dlopen or dlclose of the main thread
+func (p *LinuxProcess) dlError(module string) string {
+	dlerror, err := symbols.ResolveSymbol(p, module, "dlerror")
+	if err != nil {
+		return "unknown error"
+	}
+	result, err := p.CallFunction(dlerror)
+	if err != nil || result.Int == 0 {
+		return "unknown error"
+	}
+	msg, err := p.ReadNTS(process.ProcessMemoryAddress(result.Int), 1024)
+	if err != nil {
+		return "unknown error"
+	}
+	return msg
+}
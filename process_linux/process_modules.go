@@ -0,0 +1,15 @@
+//go:build linux
+
+package process_linux
+
+import "gomem/process"
+
+// GetModules returns the files mapped into the process (executable, shared libraries and
+// other mapped files), built from the pathnames of /proc/pid/maps
+func (p *LinuxProcess) GetModules() ([]process.Module, error) {
+	pid, mm := p.snapshot()
+	if pid == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+	return process.ModulesFromMemoryMap(mm), nil
+}
@@ -0,0 +1,197 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+
+	"golang.org/x/sys/unix"
+)
+
+// ProtectMemory changes the protection of the pages covering [addr, addr+size) with an
+// mprotect call made by the target itself: the main thread is stopped with ptrace, pointed
+// at a syscall instruction of its own code, single-stepped over it and restored.
+// It needs the same ptrace access as a debugger (same user and ptrace_scope permitting, or
+// CAP_SYS_PTRACE) and a process other than the caller.
+//
+// perms is a permission string such as "rw-" or "r-x", the previous permissions of the page
+// at addr are returned so they can be restored. The memory map is refreshed afterwards, so
+// WriteMemory accepts pages made writable.
+func (p *LinuxProcess) ProtectMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize, perms string) (string, error) {
+	pid, mm := p.snapshot()
+	if pid == 0 {
+		return "", process.ErrProcessNotOpen
+	}
+	if int(pid) == os.Getpid() {
+		return "", fmt.Errorf("ProtectMemory can't target the calling process")
+	}
+
+	prot, err := permsToProt(perms)
+	if err != nil {
+		return "", err
+	}
+
+	region, _ := getMemoryRegionForAddress(mm, addr)
+	if region == nil {
+		return "", fmt.Errorf("memory region not found for address %x", addr)
+	}
+	previous := region.Perms[:min(len(region.Perms), 3)]
+
+	// mprotect works on whole pages
+	pageSize := uint64(os.Getpagesize())
+	start := uint64(addr) &^ (pageSize - 1)
+	end := (uint64(addr) + uint64(size) + pageSize - 1) &^ (pageSize - 1)
+
+	insn, err := findSyscallInstruction(pid, mm)
+	if err != nil {
+		return "", err
+	}
+
+	ret, err := remoteSyscall(int(pid), insn, unix.SYS_MPROTECT, start, end-start, uint64(prot))
+	if err != nil {
+		return "", err
+	}
+	if errno := int64(ret); errno < 0 && errno >= -4095 {
+		return "", fmt.Errorf("mprotect 0x%x-0x%x %s failed: %w", start, end, perms, unix.Errno(-errno))
+	}
+
+	if err := p.UpdateMemoryMap(); err != nil {
+		return previous, err
+	}
+
+	p.getLog().Infof("Changed protection of 0x%x-0x%x from %s to %s", start, end, previous, perms)
+	return previous, nil
+}
+
+// permsToProt converts a permission string such as "rw-" to PROT_* flags
+func permsToProt(perms string) (int, error) {
+	read, write, exec, err := process.ParsePerms(perms)
+	if err != nil {
+		return 0, err
+	}
+	prot := unix.PROT_NONE
+	if read {
+		prot |= unix.PROT_READ
+	}
+	if write {
+		prot |= unix.PROT_WRITE
+	}
+	if exec {
+		prot |= unix.PROT_EXEC
+	}
+	return prot, nil
+}
+
+// findSyscallInstruction returns the address of a syscall instruction in the executable
+// regions of the target, the vdso and libc first
+func findSyscallInstruction(pid process.ProcessID, mm []memory_map.MemoryMapItem) (uint64, error) {
+	if len(syscallInstruction) == 0 {
+		return 0, fmt.Errorf("remote syscalls are not supported on %s", runtime.GOARCH)
+	}
+
+	var candidates []memory_map.MemoryMapItem
+	for _, item := range mm {
+		if isExecutablePerms(item.Perms) && isReadablePerms(item.Perms) {
+			candidates = append(candidates, item)
+		}
+	}
+	// Prefer the regions known to hold syscall instructions
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return isSyscallRegion(candidates[i]) && !isSyscallRegion(candidates[j])
+	})
+
+	const chunkSize = 1 << 20
+	for _, item := range candidates {
+		for off := uint64(0); off < uint64(item.Size); off += chunkSize {
+			n := min(uint64(item.Size)-off, chunkSize)
+			// Read directly, libraries and the vdso are mapped above the range IsValidAddress accepts
+			data, err := process_vm_readv(pid, nil, process.ProcessMemorySize(n), process.ProcessMemoryAddress(item.Address+off), process.ProcessMemorySize(n))
+			if err != nil {
+				break
+			}
+			for i := 0; ; {
+				j := bytes.Index(data[i:], syscallInstruction)
+				if j < 0 {
+					break
+				}
+				at := item.Address + off + uint64(i+j)
+				if at%syscallAlignment == 0 {
+					return at, nil
+				}
+				i += j + 1
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("no syscall instruction found in the executable regions")
+}
+
+// isSyscallRegion reports whether a region is the vdso or libc
+func isSyscallRegion(item memory_map.MemoryMapItem) bool {
+	return item.Path == "[vdso]" || strings.HasPrefix(filepath.Base(item.Path), "libc")
+}
+
+// remoteSyscall makes the main thread of pid execute the syscall instruction at insn with the
+// given number and up to six arguments, and returns the raw result. The thread registers are
+// restored and the process is detached before returning.
+func remoteSyscall(pid int, insn uint64, nr uint64, args ...uint64) (uint64, error) {
+	if len(args) > 6 {
+		return 0, fmt.Errorf("syscalls take at most 6 arguments, got %d", len(args))
+	}
+	var a [6]uint64
+	copy(a[:], args)
+
+	var result uint64
+	err := ptraceStopped(pid, func() error {
+		var saved unix.PtraceRegs
+		if err := unix.PtraceGetRegs(pid, &saved); err != nil {
+			return fmt.Errorf("ptrace getregs failed: %w", err)
+		}
+		defer unix.PtraceSetRegs(pid, &saved)
+
+		regs := saved
+		setSyscallRegs(&regs, insn, nr, a)
+		if err := unix.PtraceSetRegs(pid, &regs); err != nil {
+			return fmt.Errorf("ptrace setregs failed: %w", err)
+		}
+
+		if err := unix.PtraceSingleStep(pid); err != nil {
+			return fmt.Errorf("ptrace singlestep failed: %w", err)
+		}
+		if err := waitStopped(pid); err != nil {
+			return err
+		}
+
+		if err := unix.PtraceGetRegs(pid, &regs); err != nil {
+			return fmt.Errorf("ptrace getregs failed: %w", err)
+		}
+		if syscallPC(&regs) != insn+uint64(len(syscallInstruction)) {
+			return fmt.Errorf("remote syscall did not complete (pc 0x%x)", syscallPC(&regs))
+		}
+
+		result = syscallResult(&regs)
+		return nil
+	})
+	return result, err
+}
+
+// waitStopped waits for a ptrace stop of pid
+func waitStopped(pid int) error {
+	var status unix.WaitStatus
+	if _, err := unix.Wait4(pid, &status, unix.WALL, nil); err != nil {
+		return fmt.Errorf("wait for %d failed: %w", pid, err)
+	}
+	if !status.Stopped() {
+		return fmt.Errorf("process %d did not stop (status 0x%x)", pid, uint32(status))
+	}
+	return nil
+}
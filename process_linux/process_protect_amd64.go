@@ -0,0 +1,35 @@
+//go:build linux && amd64
+
+package process_linux
+
+import "golang.org/x/sys/unix"
+
+// syscallInstruction is the x86-64 syscall instruction
+var syscallInstruction = []byte{0x0F, 0x05}
+
+// syscallAlignment is the alignment of instructions, none on x86
+const syscallAlignment = 1
+
+// setSyscallRegs prepares regs to run the syscall instruction at pc
+func setSyscallRegs(regs *unix.PtraceRegs, pc, nr uint64, args [6]uint64) {
+	regs.Rip = pc
+	regs.Rax = nr
+	regs.Rdi = args[0]
+	regs.Rsi = args[1]
+	regs.Rdx = args[2]
+	regs.R10 = args[3]
+	regs.R8 = args[4]
+	regs.R9 = args[5]
+	// Not in a syscall anymore, so the kernel does not restart an interrupted one on resume
+	regs.Orig_rax = ^uint64(0)
+}
+
+// syscallPC returns the instruction pointer
+func syscallPC(regs *unix.PtraceRegs) uint64 {
+	return regs.Rip
+}
+
+// syscallResult returns the return value of a syscall
+func syscallResult(regs *unix.PtraceRegs) uint64 {
+	return regs.Rax
+}
@@ -0,0 +1,28 @@
+//go:build linux && arm64
+
+package process_linux
+
+import "golang.org/x/sys/unix"
+
+// syscallInstruction is the AArch64 svc #0 instruction
+var syscallInstruction = []byte{0x01, 0x00, 0x00, 0xD4}
+
+// syscallAlignment is the alignment of instructions
+const syscallAlignment = 4
+
+// setSyscallRegs prepares regs to run the syscall instruction at pc
+func setSyscallRegs(regs *unix.PtraceRegs, pc, nr uint64, args [6]uint64) {
+	regs.Pc = pc
+	regs.Regs[8] = nr
+	copy(regs.Regs[:6], args[:])
+}
+
+// syscallPC returns the program counter
+func syscallPC(regs *unix.PtraceRegs) uint64 {
+	return regs.Pc
+}
+
+// syscallResult returns the return value of a syscall
+func syscallResult(regs *unix.PtraceRegs) uint64 {
+	return regs.Regs[0]
+}
@@ -0,0 +1,16 @@
+//go:build linux && !amd64 && !arm64
+
+package process_linux
+
+import "golang.org/x/sys/unix"
+
+// syscallInstruction is empty, remote syscalls are not implemented on this architecture
+var syscallInstruction []byte
+
+const syscallAlignment = 1
+
+func setSyscallRegs(regs *unix.PtraceRegs, pc, nr uint64, args [6]uint64) {}
+
+func syscallPC(regs *unix.PtraceRegs) uint64 { return 0 }
+
+func syscallResult(regs *unix.PtraceRegs) uint64 { return 0 }
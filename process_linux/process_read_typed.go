@@ -3,7 +3,6 @@
 package process_linux
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"sync"
@@ -29,7 +28,7 @@ func (p *LinuxProcess) ReadUINT16(addr process.ProcessMemoryAddress) (uint16, er
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint16(data), nil
+	return p.ByteOrder().Uint16(data), nil
 }
 
 // ReadUINT32 reads an unsigned 32-bit integer from the specified address
@@ -38,7 +37,7 @@ func (p *LinuxProcess) ReadUINT32(addr process.ProcessMemoryAddress) (uint32, er
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint32(data), nil
+	return p.ByteOrder().Uint32(data), nil
 }
 
 // ReadUINT64 reads an unsigned 64-bit integer from the specified address
@@ -47,7 +46,7 @@ func (p *LinuxProcess) ReadUINT64(addr process.ProcessMemoryAddress) (uint64, er
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint64(data), nil
+	return p.ByteOrder().Uint64(data), nil
 }
 
 // ReadINT8 reads a signed 8-bit integer from the specified address
@@ -65,7 +64,7 @@ func (p *LinuxProcess) ReadINT16(addr process.ProcessMemoryAddress) (int16, erro
 	if err != nil {
 		return 0, err
 	}
-	return int16(binary.LittleEndian.Uint16(data)), nil
+	return int16(p.ByteOrder().Uint16(data)), nil
 }
 
 // ReadINT32 reads a signed 32-bit integer from the specified address
@@ -74,7 +73,7 @@ func (p *LinuxProcess) ReadINT32(addr process.ProcessMemoryAddress) (int32, erro
 	if err != nil {
 		return 0, err
 	}
-	return int32(binary.LittleEndian.Uint32(data)), nil
+	return int32(p.ByteOrder().Uint32(data)), nil
 }
 
 // ReadINT64 reads a signed 64-bit integer from the specified address
@@ -83,7 +82,7 @@ func (p *LinuxProcess) ReadINT64(addr process.ProcessMemoryAddress) (int64, erro
 	if err != nil {
 		return 0, err
 	}
-	return int64(binary.LittleEndian.Uint64(data)), nil
+	return int64(p.ByteOrder().Uint64(data)), nil
 }
 
 // ReadFLOAT32 reads a 32-bit floating point number from the specified address
@@ -92,7 +91,7 @@ func (p *LinuxProcess) ReadFLOAT32(addr process.ProcessMemoryAddress) (float32,
 	if err != nil {
 		return 0, err
 	}
-	bits := binary.LittleEndian.Uint32(data)
+	bits := p.ByteOrder().Uint32(data)
 	return *(*float32)(unsafe.Pointer(&bits)), nil
 }
 
@@ -102,7 +101,7 @@ func (p *LinuxProcess) ReadFLOAT64(addr process.ProcessMemoryAddress) (float64,
 	if err != nil {
 		return 0, err
 	}
-	bits := binary.LittleEndian.Uint64(data)
+	bits := p.ByteOrder().Uint64(data)
 	return *(*float64)(unsafe.Pointer(&bits)), nil
 }
 
@@ -141,7 +140,7 @@ func (p *LinuxProcess) ReadPOINTER(addr process.ProcessMemoryAddress) (process.P
 	}
 
 	// Read as uint64 for 64-bit pointers
-	ptr := binary.LittleEndian.Uint64(data)
+	ptr := p.ByteOrder().Uint64(data)
 	return process.ProcessMemoryAddress(ptr), nil
 }
 
@@ -167,7 +166,7 @@ func (p *LinuxProcess) ReadBlob(addr process.ProcessMemoryAddress, size process.
 		return nil, errors.New("read less data than requested")
 	}
 
-	return process_blob.NewProcessBlob(addr, data[:size]), nil
+	return process_blob.NewProcessBlobWithByteOrder(addr, data[:size], p.ByteOrder()), nil
 }
 
 func (p *LinuxProcess) ReadPointers(base process.ProcessMemoryAddress, count int) (results []process.ProcessMemoryAddress, err error) {
@@ -181,14 +180,15 @@ func (p *LinuxProcess) ReadPointers(base process.ProcessMemoryAddress, count int
 	if err != nil {
 		return nil, err
 	}
+	_, mm := p.snapshot()
 	for i := range count {
 		offset := i * 8
 		if offset+8 > len(data) {
 			return nil, errors.New("not enough data read for pointers")
 		}
-		ptr := binary.LittleEndian.Uint64(data[offset : offset+8])
+		ptr := p.ByteOrder().Uint64(data[offset : offset+8])
 
-		if memory_map.IsValidAddress2(ptr, p.mm) != nil {
+		if memory_map.IsValidAddress2(ptr, mm) != nil {
 			results = append(results, process.ProcessMemoryAddress(ptr))
 		}
 	}
@@ -285,12 +285,13 @@ func (p *LinuxProcess) ReadBlobs(list []process.ProcessMemoryAddress, blobReadSi
 	// Key: Start address of the memory_map.MemoryMapItem (Region)
 	// Value: Pointer to the GroupedReadOp for that region
 	groups := make(map[uint64]*GroupedReadOp)
+	_, mm := p.snapshot()
 
 	for i, currentReqAddr := range list {
 		// 1. Find the memory region for the start of the current request
 		// IsValidAddress2 should ideally return the region containing 'currentReqAddr'.
-		// We assume p.mm is the sorted list of MemoryMapItems for the process.
-		regionItem := memory_map.IsValidAddress2(uint64(currentReqAddr), p.mm)
+		// mm is the sorted snapshot of MemoryMapItems for the process.
+		regionItem := memory_map.IsValidAddress2(uint64(currentReqAddr), mm)
 
 		if regionItem == nil {
 			results[i] = process.ReadBlobsResult{Address: currentReqAddr, Err: ErrAddressNotInAnyValidRegion}
@@ -391,6 +392,15 @@ func (p *LinuxProcess) ReadBlobs(list []process.ProcessMemoryAddress, blobReadSi
 			// An issue might be if sizeForCombinedRead becomes 0 due to an empty request list or logic error,
 			// but groups map should only contain groups with at least one request.
 
+			// A combined read over the maximum read size is split into the original requests
+			if p.GetReadLimits().Check(sizeForCombinedRead) != nil {
+				for _, req := range g.Requests {
+					blob, err := p.ReadBlob(req.Address, req.Size)
+					results[req.Index] = process.ReadBlobsResult{Address: req.Address, Blob: blob, Err: err}
+				}
+				return
+			}
+
 			// Assuming p.ReadBlob returns (data []byte, err error)
 			combinedData, err := p.ReadBlob(g.CombinedReadStart, sizeForCombinedRead)
 
@@ -441,7 +451,7 @@ func (p *LinuxProcess) ReadBlobs(list []process.ProcessMemoryAddress, blobReadSi
 
 				results[req.Index] = process.ReadBlobsResult{
 					Address: req.Address,
-					Blob:    process_blob.NewProcessBlob(req.Address, blobForRequest),
+					Blob:    process_blob.NewProcessBlobWithByteOrder(req.Address, blobForRequest, p.ByteOrder()),
 					Err:     nil,
 				}
 			}
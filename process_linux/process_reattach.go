@@ -0,0 +1,112 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"gomem/process"
+)
+
+// Bookmarks returns the ASLR-stable bookmarks of the process, which are re-resolved on Reattach
+func (p *LinuxProcess) Bookmarks() *process.Bookmarks {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.bookmarks == nil {
+		p.bookmarks = process.NewBookmarks()
+	}
+	return p.bookmarks
+}
+
+// AddBookmark registers a bookmark at offset from the base of module and returns its current address
+func (p *LinuxProcess) AddBookmark(name, module string, offset uint64) (process.ProcessMemoryAddress, error) {
+	mm, err := p.GetMemoryMap()
+	if err != nil {
+		return 0, err
+	}
+	return p.Bookmarks().Set(name, module, offset, mm)
+}
+
+// AddBookmarkRef registers a bookmark for any address reference, pointer chains included,
+// and returns its current address
+func (p *LinuxProcess) AddBookmarkRef(name string, ref process.AddressRef) (process.ProcessMemoryAddress, error) {
+	return p.Bookmarks().SetRef(name, ref, p)
+}
+
+// IsAlive reports whether the opened process is still running.
+// A PID reused by a different process is detected by its start time.
+func (p *LinuxProcess) IsAlive() bool {
+	p.mu.RLock()
+	pid, startTime := p.pid, p.startTime
+	p.mu.RUnlock()
+
+	if pid == 0 {
+		return false
+	}
+
+	current, err := processStartTime(pid)
+	return err == nil && current == startTime
+}
+
+// ReattachByName reopens the process by exact name if the original one is gone, see ReattachByPattern
+func (p *LinuxProcess) ReattachByName(name string) (bool, error) {
+	return p.ReattachByPattern("^" + regexp.QuoteMeta(name) + "$")
+}
+
+// ReattachByPattern checks whether the opened process is still alive and, if it exited
+// (or its PID was reused), opens the first process whose name matches pattern instead,
+// refreshes the memory map and re-resolves the bookmarks.
+// It returns true if the process was reattached, false if the original process is still running.
+func (p *LinuxProcess) ReattachByPattern(pattern string) (bool, error) {
+	if p.IsAlive() {
+		return false, nil
+	}
+
+	processes, err := NewProcessFinderWithMaxAge(0).FindProcessByNamePattern(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	if len(processes) == 0 {
+		return false, fmt.Errorf("no process found matching pattern '%s'", pattern)
+	}
+
+	if err := p.Open(processes[0].PID); err != nil {
+		return false, fmt.Errorf("failed to reattach to PID %d: %w", processes[0].PID, err)
+	}
+
+	if unresolved := p.Bookmarks().Refresh(p); len(unresolved) > 0 {
+		p.getLog().Warn("Bookmarks not resolved after reattach: ", unresolved)
+	}
+
+	p.getLog().Infoln("Reattached to PID", processes[0].PID)
+
+	return true, nil
+}
+
+// processStartTime returns the start time of a process (in clock ticks since boot) from /proc/<pid>/stat
+func processStartTime(pid process.ProcessID) (uint64, error) {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(int(pid)) + "/stat")
+	if err != nil {
+		return 0, err
+	}
+
+	// The command name may contain spaces and parentheses, fields resume after the last ')'
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("malformed stat for PID %d", pid)
+	}
+
+	// Fields after the name start at field 3 (state), starttime is field 22
+	fields := bytes.Fields(stat[end+1:])
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("malformed stat for PID %d", pid)
+	}
+
+	return strconv.ParseUint(string(fields[19]), 10, 64)
+}
@@ -0,0 +1,110 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+
+	"golang.org/x/sys/unix"
+)
+
+// Registers is the general purpose register set of a thread as read by PTRACE_GETREGS, in
+// the layout of the architecture (Rip, Rsp, Rcx, ... on amd64, Regs, Sp, Pc on arm64)
+type Registers = unix.PtraceRegs
+
+// NamedRegister is one register of a Registers set with its conventional name
+type NamedRegister struct {
+	Name  string
+	Value uint64
+}
+
+// GetRegisters returns the registers of thread tid of the process. The thread is stopped
+// with ptrace only while they are read, which needs the same access as a debugger (same
+// user and ptrace_scope permitting, or CAP_SYS_PTRACE). A thread already traced by a
+// debugger can't be read.
+func (p *LinuxProcess) GetRegisters(tid int) (*Registers, error) {
+	regs := new(Registers)
+	err := p.withStoppedThread(tid, func() error {
+		if err := unix.PtraceGetRegs(tid, regs); err != nil {
+			return fmt.Errorf("ptrace getregs of thread %d failed: %w", tid, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return regs, nil
+}
+
+// SetRegisters replaces the registers of thread tid of the process, typically with a set
+// returned by GetRegisters and modified. See GetRegisters for the access needed.
+func (p *LinuxProcess) SetRegisters(tid int, regs *Registers) error {
+	return p.withStoppedThread(tid, func() error {
+		if err := unix.PtraceSetRegs(tid, regs); err != nil {
+			return fmt.Errorf("ptrace setregs of thread %d failed: %w", tid, err)
+		}
+		return nil
+	})
+}
+
+// NamedRegisters returns the registers of a set in the conventional order of the architecture
+func NamedRegisters(regs *Registers) []NamedRegister {
+	return namedRegisters(regs)
+}
+
+// FormatRegisters returns one line per register with its value and, when it points into the
+// process, what it points to (e.g. "rcx 0x00007f3a1c2d5e10 libc.so.6+0x1D5E10"), see
+// memory_map.FormatAddress
+func (p *LinuxProcess) FormatRegisters(regs *Registers) string {
+	_, mm := p.snapshot()
+
+	var sb strings.Builder
+	for _, r := range NamedRegisters(regs) {
+		fmt.Fprintf(&sb, "%-7s 0x%016x", r.Name, r.Value)
+		if memory_map.IsValidAddress(r.Value, mm) {
+			fmt.Fprintf(&sb, " %s", memory_map.FormatAddress(r.Value, mm))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// withStoppedThread attaches to thread tid of the process with ptrace, waits for it to stop,
+// runs fn and detaches. Every ptrace request must come from the thread that attached, so fn
+// runs on the locked OS thread of the caller.
+func (p *LinuxProcess) withStoppedThread(tid int, fn func() error) error {
+	pid, _ := p.snapshot()
+	if pid == 0 {
+		return process.ErrProcessNotOpen
+	}
+	if int(pid) == os.Getpid() {
+		return fmt.Errorf("ptrace can't target the calling process")
+	}
+	if _, err := os.Stat(fmt.Sprintf("/proc/%d/task/%d", pid, tid)); err != nil {
+		return fmt.Errorf("thread %d is not a thread of process %d", tid, pid)
+	}
+
+	return ptraceStopped(tid, fn)
+}
+
+// ptraceStopped attaches to tid, waits for the ptrace stop, runs fn and detaches
+func ptraceStopped(tid int, fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := unix.PtraceAttach(tid); err != nil {
+		return fmt.Errorf("ptrace attach to %d failed: %w", tid, err)
+	}
+	defer unix.PtraceDetach(tid)
+
+	if err := waitStopped(tid); err != nil {
+		return err
+	}
+	return fn()
+}
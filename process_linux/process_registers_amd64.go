@@ -0,0 +1,34 @@
+//go:build linux && amd64
+
+package process_linux
+
+// namedRegisters lists the x86-64 general purpose registers
+func namedRegisters(regs *Registers) []NamedRegister {
+	return []NamedRegister{
+		{"rip", regs.Rip},
+		{"rsp", regs.Rsp},
+		{"rbp", regs.Rbp},
+		{"rax", regs.Rax},
+		{"rbx", regs.Rbx},
+		{"rcx", regs.Rcx},
+		{"rdx", regs.Rdx},
+		{"rsi", regs.Rsi},
+		{"rdi", regs.Rdi},
+		{"r8", regs.R8},
+		{"r9", regs.R9},
+		{"r10", regs.R10},
+		{"r11", regs.R11},
+		{"r12", regs.R12},
+		{"r13", regs.R13},
+		{"r14", regs.R14},
+		{"r15", regs.R15},
+		{"eflags", regs.Eflags},
+		{"fs_base", regs.Fs_base},
+		{"gs_base", regs.Gs_base},
+	}
+}
+
+// threadPointer returns the thread pointer of a register set, fs_base on x86-64
+func threadPointer(regs *Registers) (uint64, error) {
+	return regs.Fs_base, nil
+}
@@ -0,0 +1,23 @@
+//go:build linux && arm64
+
+package process_linux
+
+import "fmt"
+
+// namedRegisters lists the AArch64 general purpose registers
+func namedRegisters(regs *Registers) []NamedRegister {
+	named := []NamedRegister{
+		{"pc", regs.Pc},
+		{"sp", regs.Sp},
+	}
+	for i, value := range regs.Regs {
+		named = append(named, NamedRegister{fmt.Sprintf("x%d", i), value})
+	}
+	return append(named, NamedRegister{"pstate", regs.Pstate})
+}
+
+// threadPointer returns the thread pointer of a register set. TPIDR_EL0 is not part of the
+// general purpose registers read on AArch64.
+func threadPointer(regs *Registers) (uint64, error) {
+	return 0, fmt.Errorf("thread pointer not supported on arm64")
+}
@@ -0,0 +1,20 @@
+//go:build linux && !amd64 && !arm64
+
+package process_linux
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// namedRegisters lists only the program counter, the register layout of this architecture
+// is not described
+func namedRegisters(regs *Registers) []NamedRegister {
+	return []NamedRegister{{"pc", uint64(regs.PC())}}
+}
+
+// threadPointer returns the thread pointer of a register set, not supported on this
+// architecture
+func threadPointer(regs *Registers) (uint64, error) {
+	return 0, fmt.Errorf("thread pointer not supported on %s", runtime.GOARCH)
+}
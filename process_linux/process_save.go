@@ -6,13 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"gomem/process"
+	"gomem/process_blob"
 
 	"os"
 	"path/filepath"
 	"strconv"
 	"time"
-
-	"gomem/process/memory_map"
 )
 
 // Save saves the process memory and metadata to a directory
@@ -24,25 +23,13 @@ func (p *LinuxProcess) Save(dirname string) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// First get the necessary information under lock
-	p.mu.Lock()
-	fmt.Printf("Save: Acquired mutex for initial data\n")
-
 	// Check if process is opened
-	if p.pid == 0 {
-		p.mu.Unlock() // Don't forget to unlock before returning
-		return fmt.Errorf("process not opened")
+	pid, _ := p.snapshot()
+	if pid == 0 {
+		return process.ErrProcessNotOpen
 	}
 
-	// Get a copy of the PID
-	pid := p.pid
-
-	// Log under lock
-	p.log.Infoln("Saving process to directory:", dirname)
-
-	// Release the lock while doing external operations
-	p.mu.Unlock()
-	fmt.Printf("Save: Released mutex for external operations\n")
+	p.getLog().Infoln("Saving process to directory:", dirname)
 
 	// Get process name using ps command without holding the lock
 	procInfo, err := findProcessByPID(pid)
@@ -51,22 +38,12 @@ func (p *LinuxProcess) Save(dirname string) error {
 		name = procInfo.Name
 	}
 
-	// Save metadata (process name and PID)
-	metadata := struct {
-		PID  process.ProcessID `json:"pid"`
-		Name string            `json:"name"`
-	}{
-		PID:  pid,
-		Name: name,
-	}
-
-	metadataJSON, err := json.MarshalIndent(metadata, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
-	}
-
-	if err := os.WriteFile(filepath.Join(dirname, "metadata.json"), metadataJSON, 0644); err != nil {
-		return fmt.Errorf("failed to write metadata file: %w", err)
+	// Metadata (process name, PID, byte order and the checksums of the blobs), written once
+	// the blobs are saved
+	metadata := process_blob.DumpMetadata{
+		PID:       pid,
+		Name:      name,
+		ByteOrder: process.ByteOrderName(p.ByteOrder()),
 	}
 
 	// Update memory map without a long-held lock
@@ -74,11 +51,14 @@ func (p *LinuxProcess) Save(dirname string) error {
 		return fmt.Errorf("failed to update memory map: %w", err)
 	}
 
-	// Get a copy of the memory map under lock
-	p.mu.Lock()
-	mmCopy := make([]memory_map.MemoryMapItem, len(p.mm))
-	copy(mmCopy, p.mm)
-	p.mu.Unlock()
+	// Get the memory map snapshot, it is never modified in place
+	_, mmCopy := p.snapshot()
+
+	// Record the modules and threads for module-relative addressing in the dump
+	metadata.Modules = process.ModulesFromMemoryMap(mmCopy)
+	if metadata.Threads, err = p.GetThreads(); err != nil {
+		p.getLog().Infoln("Failed to list threads:", err)
+	}
 
 	// Serialize the memory map without holding the lock
 	memoryMapJSON, err := json.MarshalIndent(mmCopy, "", "  ")
@@ -137,7 +117,7 @@ func (p *LinuxProcess) Save(dirname string) error {
 		// Skip regions that are too large
 		if region.Size > 100*1024*1024 { // 100 MB
 			fmt.Printf("  - Skipping large region: %d MB\n", region.Size/1024/1024)
-			p.log.Infoln("Skipping large region at", fmt.Sprintf("%x", region.Address),
+			p.getLog().Infoln("Skipping large region at", fmt.Sprintf("%x", region.Address),
 				"(size:", region.Size/1024/1024, "MB)")
 			regionTypeStats["skipped_too_large"]++
 			continue
@@ -153,14 +133,14 @@ func (p *LinuxProcess) Save(dirname string) error {
 		readStart := time.Now()
 
 		// Read memory - this is where it's likely hanging
-		data, err := p.ReadMemory(process.ProcessMemoryAddress(region.Address), process.ProcessMemorySize(region.Size))
+		data, err := p.readChunked(process.ProcessMemoryAddress(region.Address), process.ProcessMemorySize(region.Size))
 
 		readDuration := time.Since(readStart)
 		fmt.Printf("  - Read operation took %v\n", readDuration)
 
 		if err != nil {
 			fmt.Printf("  - ERROR reading memory: %v\n", err)
-			p.log.Infoln("Failed to read memory region at", fmt.Sprintf("%x", region.Address), ":", err)
+			p.getLog().Infoln("Failed to read memory region at", fmt.Sprintf("%x", region.Address), ":", err)
 			errorCount++
 			regionTypeStats["read_error"]++
 			continue
@@ -176,7 +156,7 @@ func (p *LinuxProcess) Save(dirname string) error {
 
 		if err := os.WriteFile(filename, data, 0644); err != nil {
 			fmt.Printf("  - ERROR writing file: %v\n", err)
-			p.log.Infoln("Failed to write memory file for region at", fmt.Sprintf("%x", region.Address), ":", err)
+			p.getLog().Infoln("Failed to write memory file for region at", fmt.Sprintf("%x", region.Address), ":", err)
 			errorCount++
 			regionTypeStats["write_error"]++
 			continue
@@ -186,10 +166,21 @@ func (p *LinuxProcess) Save(dirname string) error {
 		fmt.Printf("  - Write operation took %v\n", writeDuration)
 
 		fmt.Printf("  - Successfully saved region to file\n")
+		metadata.Blobs = append(metadata.Blobs, process_blob.NewBlobChecksum(region.Address, data))
+		metadata.TotalSize += uint64(len(data))
 		savedCount++
 		regionTypeStats["saved"]++
 	}
 
+	metadataJSON, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dirname, "metadata.json"), metadataJSON, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+
 	fmt.Printf("Region statistics:\n")
 	fmt.Printf("  - Skipped non-readable: %d\n", regionTypeStats["skipped_non_readable"])
 	fmt.Printf("  - Skipped too large: %d\n", regionTypeStats["skipped_too_large"])
@@ -197,10 +188,7 @@ func (p *LinuxProcess) Save(dirname string) error {
 	fmt.Printf("  - Write errors: %d\n", regionTypeStats["write_error"])
 	fmt.Printf("  - Successfully saved: %d\n", regionTypeStats["saved"])
 
-	// Acquire lock just for logging
-	p.mu.Lock()
-	p.log.Infoln("Process dump saved successfully:", savedCount, "regions saved,", errorCount, "errors")
-	p.mu.Unlock()
+	p.getLog().Infoln("Process dump saved successfully:", savedCount, "regions saved,", errorCount, "errors")
 
 	return nil
 }
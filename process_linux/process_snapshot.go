@@ -0,0 +1,233 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+	"gomem/process_blob"
+)
+
+// Flags of the /proc/pid/pagemap entries, see Documentation/admin-guide/mm/pagemap.rst
+const (
+	pagemapSoftDirty = 1 << 55
+	pagemapPresent   = 1 << 63
+)
+
+// clearSoftDirty is the clear_refs command resetting the soft-dirty bits of every page
+const clearSoftDirty = "4"
+
+// SnapshotStats describes the work done by the last Snapshotter.Snapshot
+type SnapshotStats struct {
+	Regions     int    // Regions captured
+	Pages       int    // Pages of the captured regions
+	DirtyPages  int    // Pages read from the process, the others were copied from the previous snapshot
+	BytesRead   uint64 // Bytes read from the process
+	Incremental bool   // Only the soft-dirty pages were read
+}
+
+// Snapshotter captures the memory of a process repeatedly, reading only the pages written
+// since the previous snapshot. The kernel marks written pages soft-dirty; each snapshot reads
+// those pages, copies the others from the previous snapshot and clears the bits through
+// /proc/pid/clear_refs, so dump and compare loops over a large process read little memory.
+//
+// The first snapshot reads every readable region, as do later ones for regions mapped or
+// resized since. Kernels built without CONFIG_MEM_SOFT_DIRTY report no soft-dirty page, which
+// is detected on the first snapshot: every snapshot then reads everything. Clearing the bits
+// affects other soft-dirty users of the process, such as CRIU.
+//
+// Pages written between reading the pagemap and clearing the bits are missed until they are
+// written again; set Suspend for exact snapshots.
+type Snapshotter struct {
+	// Suspend pauses the process for the duration of each snapshot
+	Suspend bool
+
+	// MaxRegionSize skips regions larger than this many bytes, 0 for no limit
+	MaxRegionSize uint
+
+	proc      *LinuxProcess
+	previous  *process_blob.ProcessDump
+	softDirty bool
+	stats     SnapshotStats
+}
+
+// NewSnapshotter returns a Snapshotter of the process, see Snapshotter
+func (p *LinuxProcess) NewSnapshotter() *Snapshotter {
+	return &Snapshotter{proc: p}
+}
+
+// Stats returns what the last snapshot read
+func (s *Snapshotter) Stats() SnapshotStats {
+	return s.stats
+}
+
+// SoftDirty reports whether the kernel tracks soft-dirty pages for the process, known after
+// the first snapshot
+func (s *Snapshotter) SoftDirty() bool {
+	return s.softDirty
+}
+
+// Snapshot captures the readable memory of the process into a dump. The dumps returned
+// earlier are not modified, so consecutive snapshots can be compared with process_blob.Diff.
+func (s *Snapshotter) Snapshot() (*process_blob.ProcessDump, error) {
+	if s.Suspend {
+		if err := s.proc.Suspend(); err != nil {
+			return nil, err
+		}
+		defer s.proc.Resume()
+	}
+
+	pid, _ := s.proc.snapshot()
+	if pid == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+	if err := s.proc.UpdateMemoryMap(); err != nil {
+		return nil, fmt.Errorf("failed to update memory map: %w", err)
+	}
+	_, mm := s.proc.snapshot()
+
+	pagemap, err := os.Open(filepath.Join("/proc", strconv.Itoa(int(pid)), "pagemap"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pagemap: %w", err)
+	}
+	defer pagemap.Close()
+
+	regions := s.regions(mm)
+
+	// Read the soft-dirty bits of every region before clearing them
+	pageSize := uint64(os.Getpagesize())
+	entries := make([][]uint64, len(regions))
+	for i, region := range regions {
+		if entries[i], err = readPagemap(pagemap, region, pageSize); err != nil {
+			return nil, err
+		}
+	}
+
+	first := s.previous == nil
+	if first {
+		s.softDirty = anySoftDirty(entries)
+	}
+	if s.softDirty {
+		if err := os.WriteFile(filepath.Join("/proc", strconv.Itoa(int(pid)), "clear_refs"), []byte(clearSoftDirty), 0); err != nil {
+			return nil, fmt.Errorf("failed to clear the soft-dirty bits: %w", err)
+		}
+	}
+
+	dump := process_blob.NewProcessDump()
+	dump.PID = pid
+	dump.Name = "unknown"
+	if info, err := findProcessByPID(pid); err == nil && info != nil {
+		dump.Name = info.Name
+	}
+	dump.MemoryMap = mm
+	dump.SetByteOrder(s.proc.ByteOrder())
+
+	s.stats = SnapshotStats{Incremental: s.softDirty && !first}
+	for i, region := range regions {
+		data, read, err := s.capture(region, entries[i], pageSize)
+		if err != nil {
+			s.proc.getLog().Debugln("Failed to read memory region at", fmt.Sprintf("%x", region.Address), err)
+			continue
+		}
+		dump.Blobs[region.Address] = data
+
+		s.stats.Regions++
+		s.stats.Pages += len(entries[i])
+		s.stats.DirtyPages += read
+		s.stats.BytesRead += uint64(read) * pageSize
+	}
+
+	s.previous = dump
+	return dump, nil
+}
+
+// regions returns the regions of mm a snapshot captures
+func (s *Snapshotter) regions(mm []memory_map.MemoryMapItem) []memory_map.MemoryMapItem {
+	var regions []memory_map.MemoryMapItem
+	for _, region := range mm {
+		if !isReadablePerms(region.Perms) || region.Size == 0 {
+			continue
+		}
+		if s.MaxRegionSize > 0 && region.Size > s.MaxRegionSize {
+			continue
+		}
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// capture returns the bytes of a region and the number of pages read from the process: the
+// soft-dirty pages when the previous snapshot holds the region, every page otherwise
+func (s *Snapshotter) capture(region memory_map.MemoryMapItem, entries []uint64, pageSize uint64) ([]byte, int, error) {
+	var previous []byte
+	if s.previous != nil && s.stats.Incremental {
+		previous = s.previous.Blobs[region.Address]
+	}
+	if uint64(len(previous)) != uint64(region.Size) {
+		data, err := s.proc.readChunked(process.ProcessMemoryAddress(region.Address), process.ProcessMemorySize(region.Size))
+		if err != nil {
+			return nil, 0, err
+		}
+		return data, len(entries), nil
+	}
+
+	data := make([]byte, len(previous))
+	copy(data, previous)
+
+	// Read the runs of consecutive dirty pages
+	read := 0
+	for i := 0; i < len(entries); {
+		if entries[i]&pagemapSoftDirty == 0 {
+			i++
+			continue
+		}
+		j := i
+		for j < len(entries) && entries[j]&pagemapSoftDirty != 0 {
+			j++
+		}
+
+		start, end := uint64(i)*pageSize, min(uint64(j)*pageSize, uint64(len(data)))
+		run, err := s.proc.readChunked(process.ProcessMemoryAddress(region.Address+start), process.ProcessMemorySize(end-start))
+		if err != nil {
+			return nil, 0, err
+		}
+		copy(data[start:end], run)
+		read += j - i
+		i = j
+	}
+	return data, read, nil
+}
+
+// readPagemap returns the pagemap entries of the pages of a region
+func readPagemap(pagemap *os.File, region memory_map.MemoryMapItem, pageSize uint64) ([]uint64, error) {
+	pages := (uint64(region.Size) + pageSize - 1) / pageSize
+	buf := make([]byte, pages*8)
+	if _, err := pagemap.ReadAt(buf, int64(region.Address/pageSize*8)); err != nil {
+		return nil, fmt.Errorf("failed to read the pagemap of 0x%x: %w", region.Address, err)
+	}
+
+	entries := make([]uint64, pages)
+	for i := range entries {
+		entries[i] = binary.NativeEndian.Uint64(buf[i*8:])
+	}
+	return entries, nil
+}
+
+// anySoftDirty reports whether a present page is soft-dirty. Pages start soft-dirty until
+// the bits are first cleared, so none being set means the kernel does not track them.
+func anySoftDirty(entries [][]uint64) bool {
+	for _, region := range entries {
+		for _, e := range region {
+			if e&pagemapPresent != 0 && e&pagemapSoftDirty != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gomem/process"
+
+	"golang.org/x/sys/unix"
+)
+
+// suspendTimeout is how long Suspend waits for every thread to stop
+const suspendTimeout = time.Second
+
+// Suspend stops the process with SIGSTOP and waits until every thread is stopped, see
+// process.Suspender. Unlike on Windows and macOS suspensions don't nest, Resume continues the
+// process however often it was suspended, including a process stopped before Suspend.
+func (p *LinuxProcess) Suspend() error {
+	pid, _ := p.snapshot()
+	if pid == 0 {
+		return process.ErrProcessNotOpen
+	}
+	if int(pid) == os.Getpid() {
+		return fmt.Errorf("can't suspend the calling process")
+	}
+
+	if err := unix.Kill(int(pid), unix.SIGSTOP); err != nil {
+		return fmt.Errorf("failed to stop process %d: %w", pid, err)
+	}
+
+	// The signal is delivered asynchronously, running threads stop at their next kernel entry
+	deadline := time.Now().Add(suspendTimeout)
+	for !threadsStopped(pid) {
+		if time.Now().After(deadline) {
+			// Don't leave the process half stopped when the caller won't call Resume
+			unix.Kill(int(pid), unix.SIGCONT)
+			return fmt.Errorf("process %d did not stop within %v", pid, suspendTimeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	p.getLog().Debugln("Process suspended")
+	return nil
+}
+
+// Resume continues the process with SIGCONT
+func (p *LinuxProcess) Resume() error {
+	pid, _ := p.snapshot()
+	if pid == 0 {
+		return process.ErrProcessNotOpen
+	}
+
+	if err := unix.Kill(int(pid), unix.SIGCONT); err != nil {
+		return fmt.Errorf("failed to continue process %d: %w", pid, err)
+	}
+
+	p.getLog().Debugln("Process resumed")
+	return nil
+}
+
+// threadsStopped reports whether every thread of pid is in the stopped (T) or traced (t) state
+func threadsStopped(pid process.ProcessID) bool {
+	tids, err := threadIDs(pid)
+	if err != nil {
+		return false
+	}
+
+	for _, tid := range tids {
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/task/%d/stat", pid, tid))
+		if err != nil {
+			continue // Exited meanwhile
+		}
+
+		// "<tid> (<comm>) <state> ...", comm may contain spaces and parentheses
+		stat := string(data)
+		end := strings.LastIndexByte(stat, ')')
+		if end < 0 || end+2 >= len(stat) {
+			return false
+		}
+		if state := stat[end+2]; state != 'T' && state != 't' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,272 @@
+//go:build linux
+
+package process_linux
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// ThreadIDs returns the IDs of the threads of the process, the main thread (TID == PID) first
+func (p *LinuxProcess) ThreadIDs() ([]int, error) {
+	pid, _ := p.snapshot()
+	if pid == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+	return threadIDs(pid)
+}
+
+// GetThreads returns the threads of the process, the main thread first, with the name from
+// /proc/<pid>/task/<tid>/comm and the stack region found as in ThreadStacks
+func (p *LinuxProcess) GetThreads() ([]process.Thread, error) {
+	pid, mm := p.snapshot()
+	if pid == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+
+	tids, err := threadIDs(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	threads := make([]process.Thread, 0, len(tids))
+	for _, tid := range tids {
+		thread := process.Thread{ID: tid}
+		if comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/task/%d/comm", pid, tid)); err == nil {
+			thread.Name = strings.TrimSuffix(string(comm), "\n")
+		}
+		if region := threadStackRegion(pid, tid, mm); region != nil {
+			thread.StackBase = process.ProcessMemoryAddress(region.Address)
+			thread.StackSize = uint64(region.Size)
+		}
+		threads = append(threads, thread)
+	}
+	return threads, nil
+}
+
+// threadIDs lists /proc/<pid>/task
+func threadIDs(pid process.ProcessID) ([]int, error) {
+	dir, err := os.Open(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read threads: %w", err)
+	}
+	defer dir.Close()
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read threads: %w", err)
+	}
+
+	tids := make([]int, 0, len(names))
+	for _, name := range names {
+		if tid, err := strconv.Atoi(name); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+
+	sort.Slice(tids, func(i, j int) bool {
+		if tids[i] == int(pid) || tids[j] == int(pid) {
+			return tids[i] == int(pid)
+		}
+		return tids[i] < tids[j]
+	})
+
+	return tids, nil
+}
+
+// threadStackPointer returns the stack pointer of a thread from /proc/<pid>/task/<tid>/syscall.
+// It is only available while the thread is blocked (not "running"), and needs the same
+// ptrace access as reading the process memory.
+func threadStackPointer(pid process.ProcessID, tid int) (uint64, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/task/%d/syscall", pid, tid))
+	if err != nil {
+		return 0, err
+	}
+
+	// "<nr> <args...> <sp> <pc>", "-1 <sp> <pc>" or "running"
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return 0, fmt.Errorf("thread %d is running", tid)
+	}
+
+	sp, err := strconv.ParseUint(strings.TrimPrefix(fields[len(fields)-2], "0x"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse stack pointer of thread %d: %w", tid, err)
+	}
+	return sp, nil
+}
+
+// ThreadStacks identifies the stack region of every thread.
+// The main thread's stack is the [stack] region; other thread stacks are anonymous
+// mappings, found from the stack pointer of each thread (see threadStackPointer).
+// Threads whose stack pointer cannot be read (e.g. currently running) are skipped.
+func (p *LinuxProcess) ThreadStacks() ([]memory_map.ClassifiedRegion, error) {
+	pid, mm := p.snapshot()
+	if pid == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+
+	tids, err := threadIDs(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	var stacks []memory_map.ClassifiedRegion
+	for _, tid := range tids {
+		region := threadStackRegion(pid, tid, mm)
+		if region == nil {
+			p.getLog().Debugln("Failed to identify the stack of thread", tid)
+			continue
+		}
+
+		stacks = append(stacks, memory_map.ClassifiedRegion{
+			Address: region.Address,
+			Size:    region.Size,
+			Kind:    memory_map.RegionStack,
+			TID:     tid,
+			Region:  *region,
+		})
+	}
+
+	return stacks, nil
+}
+
+// threadStackRegion returns the memory map region holding the stack of a thread
+func threadStackRegion(pid process.ProcessID, tid int, mm []memory_map.MemoryMapItem) *memory_map.MemoryMapItem {
+	// Older kernels label thread stacks as [stack:tid]
+	label := fmt.Sprintf("[stack:%d]", tid)
+	for i := range mm {
+		if mm[i].Path == label || (tid == int(pid) && mm[i].Path == "[stack]") {
+			return &mm[i]
+		}
+	}
+
+	sp, err := threadStackPointer(pid, tid)
+	if err != nil || sp == 0 {
+		return nil
+	}
+	return memory_map.IsValidAddress2(sp, mm)
+}
+
+// ThreadTLSBlocks identifies the static TLS block of every thread, the main thread included.
+// On x86-64 the block ends at the thread pointer (fs_base, read with ptrace as in
+// GetRegisters) and its size is derived from the PT_TLS segments of the loaded modules, laid
+// out like glibc does; the thread descriptor above the thread pointer is not included.
+// Modules loaded with dlopen are counted as well, although their TLS is usually allocated
+// elsewhere, so the block may start somewhat below the actual one. Threads whose registers
+// can't be read are skipped. Other architectures are not supported.
+func (p *LinuxProcess) ThreadTLSBlocks() ([]memory_map.ClassifiedRegion, error) {
+	pid, mm := p.snapshot()
+	if pid == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+
+	tids, err := threadIDs(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	size := staticTLSSize(pid, process.ModulesFromMemoryMap(mm))
+	if size == 0 {
+		return nil, nil // No module has thread local variables
+	}
+
+	var blocks []memory_map.ClassifiedRegion
+	for _, tid := range tids {
+		regs, err := p.GetRegisters(tid)
+		if err != nil {
+			p.getLog().Debugln("Failed to read the thread pointer of thread", tid, err)
+			continue
+		}
+		tp, err := threadPointer(regs)
+		if err != nil {
+			return nil, err
+		}
+
+		// The block lies below the thread pointer, within the mapping holding the descriptor
+		region := memory_map.IsValidAddress2(tp-1, mm)
+		if tp == 0 || region == nil {
+			p.getLog().Debugln("Thread pointer of thread", tid, "is not mapped")
+			continue
+		}
+		start := max(tp-min(size, tp), region.Address)
+
+		blocks = append(blocks, memory_map.ClassifiedRegion{
+			Address: start,
+			Size:    uint(tp - start),
+			Kind:    memory_map.RegionTLS,
+			TID:     tid,
+			Region:  *region,
+		})
+	}
+	return blocks, nil
+}
+
+// staticTLSSize returns the size of the static TLS block of the modules, from the PT_TLS
+// segments of their files, placed below the thread pointer in order with their alignment as
+// glibc's _dl_determine_tlsoffset does. Files that can't be read are skipped.
+func staticTLSSize(pid process.ProcessID, modules []process.Module) uint64 {
+	var offset uint64
+	for _, m := range modules {
+		memsz, vaddr, align, ok := moduleTLSSegment(pid, m.Path)
+		if !ok {
+			continue
+		}
+		align = max(align, 1)
+		firstByte := -vaddr & (align - 1)
+		offset = (offset+memsz-firstByte+align-1)/align*align + firstByte
+	}
+	return offset
+}
+
+// moduleTLSSegment returns the PT_TLS segment of the ELF file at path, read through
+// /proc/<pid>/root when the process runs in another mount namespace
+func moduleTLSSegment(pid process.ProcessID, path string) (memsz, vaddr, align uint64, ok bool) {
+	f, err := elf.Open(path)
+	if err != nil {
+		if f, err = elf.Open(filepath.Join("/proc", fmt.Sprint(pid), "root", path)); err != nil {
+			return 0, 0, 0, false
+		}
+	}
+	defer f.Close()
+
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_TLS && prog.Memsz > 0 {
+			return prog.Memsz, prog.Vaddr, prog.Align, true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// ThreadRegions returns the stacks and TLS blocks of all threads, sorted by address,
+// so they can be included in or excluded from scans with memory_map.IncludeRegionsFilter
+// and memory_map.ExcludeRegionsFilter. glibc places the TLS block of a thread it created at
+// the top of its stack mapping, so those blocks overlap the stacks reported for their
+// threads; the main thread's block lies in memory allocated by the dynamic loader.
+func (p *LinuxProcess) ThreadRegions() ([]memory_map.ClassifiedRegion, error) {
+	stacks, err := p.ThreadStacks()
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := p.ThreadTLSBlocks()
+	if err != nil {
+		// The stacks are still useful on architectures without TLS support
+		p.getLog().Debugln("Failed to identify the TLS blocks:", err)
+	}
+
+	regions := append(stacks, blocks...)
+
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].Address < regions[j].Address
+	})
+
+	return regions, nil
+}
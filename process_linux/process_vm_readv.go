@@ -3,10 +3,12 @@
 package process_linux
 
 import (
+	"errors"
 	"fmt"
 	"unsafe"
 
 	"gomem/process"
+	"gomem/process/memory_map"
 
 	"golang.org/x/sys/unix"
 )
@@ -60,36 +62,142 @@ func process_vm_readv(
 	return localBuf, nil
 }
 
-// ReadMemory reads memory from the process at the specified address
+// maxRemoteIovecs is the most remote iovecs passed to one process_vm_readv call (IOV_MAX)
+const maxRemoteIovecs = 1024
+
+// ReadMemory reads memory from the process at the specified address.
+// The range is first read with one remote iovec, so mappings that grew since the last
+// UpdateMemoryMap are read whole. The kernel never splits an iovec on a fault, so when that
+// read fails it is retried with one iovec per mapping, as long as the mappings are contiguous
+// and readable in the memory map: when the range runs into a gap, an unreadable mapping or
+// memory unmapped since the last UpdateMemoryMap, the mappings read before it are returned
+// together with an error wrapping process.ErrPartialRead.
 func (p *LinuxProcess) ReadMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ([]byte, error) {
-	// First, acquire the lock to check pid and validate the address
-	pid := p.pid
+	// Take a consistent snapshot of the PID and memory map, the lock is not held for the system call
+	pid, mm := p.snapshot()
 	if pid == 0 {
 		return nil, process.ErrProcessNotOpen
 	}
 
-	// Make a copy of the PID and check address validity
-	p.mu.Lock()
-	valid := p.isValidAddressInternal(addr)
-	// Release the lock before the system call
-	p.mu.Unlock()
-
-	if !valid {
+	if !isValidAddressIn(mm, addr) {
 		return nil, process.ErrAddressNotMapped
 	}
 
 	// Use process_vm_readv to read memory without holding the lock
-	data, err := process_vm_readv(
-		pid,
-		nil, // Local buffer will be allocated in the function
-		size,
-		addr,
-		size,
-	)
+	data, err := p.GetReadLimits().Read(size, func() ([]byte, error) {
+		if size > 0 {
+			if data, err := process_vm_readv(pid, nil, size, addr, size); err == nil {
+				return data, nil
+			}
+		}
+		return readSpans(pid, readableSpans(mm, addr, size), size)
+	})
 
 	if err != nil {
+		if errors.Is(err, process.ErrPartialRead) && len(data) > 0 {
+			return data, err
+		}
 		return nil, fmt.Errorf("process_vm_readv: failed to read process memory: %w", err)
 	}
 
 	return data, nil
 }
+
+// readableSpans splits the size bytes at addr at the boundaries of the regions of mm, up to
+// the first byte outside the contiguous readable regions from addr
+func readableSpans(mm []memory_map.MemoryMapItem, addr process.ProcessMemoryAddress, size process.ProcessMemorySize) []unix.RemoteIovec {
+	var spans []unix.RemoteIovec
+	end := uint64(addr) + uint64(size)
+	for cur := uint64(addr); cur < end; {
+		item := memory_map.IsValidAddress2(cur, mm)
+		if item == nil || !isReadablePerms(item.Perms) {
+			break
+		}
+		next := min(item.Address+uint64(item.Size), end)
+		spans = append(spans, unix.RemoteIovec{Base: uintptr(cur), Len: int(next - cur)})
+		cur = next
+	}
+	return spans
+}
+
+// readSpans reads the remote spans, size bytes in total when they cover the whole read, into
+// one buffer. It stops at the first span that can't be read and returns the bytes before it
+// with an error wrapping process.ErrPartialRead.
+func readSpans(pid process.ProcessID, spans []unix.RemoteIovec, size process.ProcessMemorySize) ([]byte, error) {
+	if size == 0 {
+		return []byte{}, nil
+	}
+
+	var total int
+	for _, span := range spans {
+		total += span.Len
+	}
+	if total == 0 {
+		return nil, process.ErrAddressNotMapped
+	}
+
+	start := uint64(spans[0].Base)
+	buf := make([]byte, total)
+	done := 0
+	for len(spans) > 0 {
+		batch := spans[:min(len(spans), maxRemoteIovecs)]
+		spans = spans[len(batch):]
+
+		want := 0
+		for _, span := range batch {
+			want += span.Len
+		}
+
+		localIov := unix.Iovec{Base: &buf[done]}
+		localIov.SetLen(want)
+
+		n, _, errno := unix.Syscall6(
+			unix.SYS_PROCESS_VM_READV,
+			uintptr(pid),
+			uintptr(unsafe.Pointer(&localIov)),
+			uintptr(1),
+			uintptr(unsafe.Pointer(&batch[0])),
+			uintptr(len(batch)),
+			uintptr(0),
+		)
+		if errno != 0 {
+			if done == 0 {
+				return nil, fmt.Errorf("process_vm_readv failed: %s (errno: %d)", errno.Error(), errno)
+			}
+			break
+		}
+		done += int(n)
+		if int(n) != want {
+			break
+		}
+	}
+
+	if done < int(size) {
+		return buf[:done], fmt.Errorf("%w: read %d of %d bytes at 0x%X", process.ErrPartialRead, done, size, start)
+	}
+	return buf, nil
+}
+
+// ReadMemoryPartial reads size bytes at addr, keeping the bytes that can be read, e.g. when
+// the range runs past the end of a mapping, see process.ReadPartial
+func (p *LinuxProcess) ReadMemoryPartial(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) process.PartialRead {
+	return process.ReadPartial(p.ReadMemory, addr, size)
+}
+
+// readChunked reads a large range in reads of at most the configured MaxReadSize
+func (p *LinuxProcess) readChunked(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ([]byte, error) {
+	limits := p.GetReadLimits()
+	if limits.MaxReadSize == 0 || size <= limits.MaxReadSize {
+		return p.ReadMemory(addr, size)
+	}
+
+	data := make([]byte, 0, size)
+	for offset := process.ProcessMemorySize(0); offset < size; offset += limits.MaxReadSize {
+		chunk, err := p.ReadMemory(addr+process.ProcessMemoryAddress(offset), min(limits.MaxReadSize, size-offset))
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+	return data, nil
+}
@@ -34,12 +34,12 @@ func process_vm_writev(
 	// Call process_vm_writev
 	n, _, errno := unix.Syscall6(
 		unix.SYS_PROCESS_VM_WRITEV,
-		uintptr(pid),                      // Remote process PID
-		uintptr(unsafe.Pointer(&localIov)), // Local iovec
-		uintptr(1),                        // Number of local iovecs
+		uintptr(pid),                        // Remote process PID
+		uintptr(unsafe.Pointer(&localIov)),  // Local iovec
+		uintptr(1),                          // Number of local iovecs
 		uintptr(unsafe.Pointer(&remoteIov)), // Remote iovec
-		uintptr(1),                        // Number of remote iovecs
-		uintptr(0),                        // Flags (reserved for future use)
+		uintptr(1),                          // Number of remote iovecs
+		uintptr(0),                          // Flags (reserved for future use)
 	)
 
 	// Check for errors
@@ -52,29 +52,21 @@ func process_vm_writev(
 
 // WriteMemory writes data to the process memory at the specified address
 func (p *LinuxProcess) WriteMemory(addr process.ProcessMemoryAddress, data []byte) error {
-	// Acquire the lock for checking state and permissions
-	p.mu.Lock()
-	
-	if p.pid == 0 {
-		p.mu.Unlock()
-		return fmt.Errorf("process not opened")
-	}
+	// Take a consistent snapshot of the PID and memory map for checking state and permissions
+	pid, mm := p.snapshot()
 
-	// Make a copy of the PID to avoid race conditions
-	pid := p.pid
+	if pid == 0 {
+		return process.ErrProcessNotOpen
+	}
 
 	// Validate the address
-	if !p.isValidAddressInternal(addr) {
-		p.mu.Unlock()
+	if !isValidAddressIn(mm, addr) {
 		return fmt.Errorf("invalid memory address %x", addr)
 	}
 
 	// Check permissions for writing (must be writeable)
-	region, isWritable := p.getMemoryRegionForAddress(addr)
-	
-	// Release the lock before the system call
-	p.mu.Unlock()
-	
+	region, isWritable := getMemoryRegionForAddress(mm, addr)
+
 	if region == nil {
 		return fmt.Errorf("memory region not found for address %x", addr)
 	}
@@ -107,4 +99,4 @@ func (p *LinuxProcess) WriteMemory(addr process.ProcessMemoryAddress, data []byt
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,13 @@
+//go:build darwin
+
+package process_lua
+
+import (
+	"gomem/process"
+	"gomem/process_darwin"
+)
+
+// openProcess opens a live process for gomem.attach
+func openProcess(pid process.ProcessID) (process.Process, error) {
+	return process_darwin.NewWithPID(pid)
+}
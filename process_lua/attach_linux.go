@@ -0,0 +1,13 @@
+//go:build linux
+
+package process_lua
+
+import (
+	"gomem/process"
+	"gomem/process_linux"
+)
+
+// openProcess opens a live process for gomem.attach
+func openProcess(pid process.ProcessID) (process.Process, error) {
+	return process_linux.NewWithPID(pid)
+}
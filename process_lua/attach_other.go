@@ -0,0 +1,14 @@
+//go:build !linux && !windows && !darwin
+
+package process_lua
+
+import (
+	"fmt"
+
+	"gomem/process"
+)
+
+// openProcess is not supported on this platform, only dumps can be loaded
+func openProcess(pid process.ProcessID) (process.Process, error) {
+	return nil, fmt.Errorf("attaching to processes is not supported on this platform")
+}
@@ -0,0 +1,378 @@
+package process_lua
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"gomem/hexdump"
+	"gomem/process"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// scalarTypes are the fixed size value types of read_*/write_* and struct schemas
+var scalarTypes = map[string]int{
+	"u8": 1, "u16": 2, "u32": 4, "u64": 8,
+	"i8": 1, "i16": 2, "i32": 4, "i64": 8,
+	"f32": 4, "f64": 8,
+	"ptr": 8, "bool": 1,
+}
+
+// functions returns the functions of the gomem table
+func (e *Engine) functions() map[string]lua.LGFunction {
+	funcs := map[string]lua.LGFunction{
+		"attach":      e.luaAttach,
+		"load":        e.luaLoad,
+		"pid":         e.luaPID,
+		"regions":     e.luaRegions,
+		"modules":     e.luaModules,
+		"module_base": e.luaModuleBase,
+		"read_string": e.luaReadString,
+		"read_bytes":  e.luaReadBytes,
+		"write_bytes": e.luaWriteBytes,
+		"scan":        e.luaScan,
+		"scan_int":    e.luaScanInt,
+		"scan_float":  e.luaScanFloat,
+		"scan_string": e.luaScanString,
+		"read_struct": e.luaReadStruct,
+		"sizeof":      e.luaSizeof,
+		"hexdump":     e.luaHexdump,
+		"hex":         luaHex,
+		"printf":      e.luaPrintf,
+	}
+
+	for name := range scalarTypes {
+		if name == "bool" {
+			continue
+		}
+		funcs["read_"+name] = e.luaRead(name)
+		funcs["write_"+name] = e.luaWrite(name)
+	}
+
+	return funcs
+}
+
+// checkAddress reads an address argument given as a number, or a string parsed like the
+// addresses of the command line tools: hex or an address expression such as
+// "game.exe+0x1A2B30+[0x18]", see process.ParseAddress
+func (e *Engine) checkAddress(L *lua.LState, n int) process.ProcessMemoryAddress {
+	switch v := L.CheckAny(n).(type) {
+	case lua.LNumber:
+		return process.ProcessMemoryAddress(uint64(v))
+	case lua.LString:
+		addr, err := process.ParseAddress(e.proc, string(v))
+		if err != nil {
+			L.ArgError(n, err.Error())
+		}
+		return addr
+	}
+	L.ArgError(n, "address expected")
+	return 0
+}
+
+// pushError pushes nil and the error message, the Lua convention for recoverable errors
+func pushError(L *lua.LState, err error) int {
+	L.Push(lua.LNil)
+	L.Push(lua.LString(err.Error()))
+	return 2
+}
+
+// addressList converts addresses to a Lua array
+func addressList(L *lua.LState, addresses []process.ProcessMemoryAddress) *lua.LTable {
+	table := L.CreateTable(len(addresses), 0)
+	for i, addr := range addresses {
+		table.RawSetInt(i+1, lua.LNumber(addr))
+	}
+	return table
+}
+
+func (e *Engine) luaAttach(L *lua.LState) int {
+	if err := e.Attach(process.ProcessID(L.CheckInt(1))); err != nil {
+		return pushError(L, err)
+	}
+	L.Push(lua.LTrue)
+	return 1
+}
+
+func (e *Engine) luaLoad(L *lua.LState) int {
+	if err := e.LoadDump(L.CheckString(1)); err != nil {
+		return pushError(L, err)
+	}
+	L.Push(lua.LTrue)
+	return 1
+}
+
+func (e *Engine) luaPID(L *lua.LState) int {
+	L.Push(lua.LNumber(e.requireProcess(L).GetPID()))
+	return 1
+}
+
+// luaRegions returns the memory map as an array of {address, size, perms, path, kind}
+func (e *Engine) luaRegions(L *lua.LState) int {
+	mm, err := e.requireProcess(L).GetMemoryMap()
+	if err != nil {
+		return pushError(L, err)
+	}
+
+	table := L.CreateTable(len(mm), 0)
+	for i, item := range mm {
+		region := L.NewTable()
+		region.RawSetString("address", lua.LNumber(item.Address))
+		region.RawSetString("size", lua.LNumber(item.Size))
+		region.RawSetString("perms", lua.LString(item.Perms))
+		region.RawSetString("path", lua.LString(item.Path))
+		region.RawSetString("kind", lua.LString(item.Kind().String()))
+		table.RawSetInt(i+1, region)
+	}
+	L.Push(table)
+	return 1
+}
+
+func (e *Engine) luaModules(L *lua.LState) int {
+	modules, err := process.GetModules(e.requireProcess(L))
+	if err != nil {
+		return pushError(L, err)
+	}
+
+	table := L.CreateTable(len(modules), 0)
+	for i, m := range modules {
+		module := L.NewTable()
+		module.RawSetString("name", lua.LString(m.Name))
+		module.RawSetString("path", lua.LString(m.Path))
+		module.RawSetString("base", lua.LNumber(m.Base))
+		module.RawSetString("size", lua.LNumber(m.Size))
+		table.RawSetInt(i+1, module)
+	}
+	L.Push(table)
+	return 1
+}
+
+func (e *Engine) luaModuleBase(L *lua.LState) int {
+	module := L.CheckString(1)
+	base, err := process.GetModuleBase(e.requireProcess(L), module)
+	if err != nil {
+		return pushError(L, err)
+	}
+	L.Push(lua.LNumber(base))
+	return 1
+}
+
+// luaRead returns the read_<typ> function
+func (e *Engine) luaRead(typ string) lua.LGFunction {
+	size := scalarTypes[typ]
+	return func(L *lua.LState) int {
+		addr := e.checkAddress(L, 1)
+		proc := e.requireProcess(L)
+		data, err := proc.ReadMemory(addr, process.ProcessMemorySize(size))
+		if err != nil {
+			return pushError(L, err)
+		}
+		L.Push(decodeScalar(typ, data, process.ByteOrderOf(proc)))
+		return 1
+	}
+}
+
+// luaWrite returns the write_<typ> function
+func (e *Engine) luaWrite(typ string) lua.LGFunction {
+	size := scalarTypes[typ]
+	return func(L *lua.LState) int {
+		addr := e.checkAddress(L, 1)
+		value := float64(L.CheckNumber(2))
+
+		var bits uint64
+		switch typ {
+		case "f32":
+			bits = uint64(math.Float32bits(float32(value)))
+		case "f64":
+			bits = math.Float64bits(value)
+		case "i8", "i16", "i32", "i64":
+			bits = uint64(int64(value))
+		default:
+			bits = uint64(value)
+		}
+
+		proc := e.requireProcess(L)
+		data := encodeScalar(bits, size, process.ByteOrderOf(proc))
+		if err := proc.WriteMemory(addr, data); err != nil {
+			return pushError(L, err)
+		}
+		L.Push(lua.LTrue)
+		return 1
+	}
+}
+
+// encodeScalar encodes the low size bytes of bits in the given byte order
+func encodeScalar(bits uint64, size int, order binary.ByteOrder) []byte {
+	data := make([]byte, size)
+	switch size {
+	case 1:
+		data[0] = byte(bits)
+	case 2:
+		order.PutUint16(data, uint16(bits))
+	case 4:
+		order.PutUint32(data, uint32(bits))
+	case 8:
+		order.PutUint64(data, bits)
+	}
+	return data
+}
+
+// decodeScalar decodes a value of a scalar type stored in the given byte order
+func decodeScalar(typ string, data []byte, order binary.ByteOrder) lua.LValue {
+	switch typ {
+	case "u8":
+		return lua.LNumber(data[0])
+	case "u16":
+		return lua.LNumber(order.Uint16(data))
+	case "u32":
+		return lua.LNumber(order.Uint32(data))
+	case "u64", "ptr":
+		return lua.LNumber(order.Uint64(data))
+	case "i8":
+		return lua.LNumber(int8(data[0]))
+	case "i16":
+		return lua.LNumber(int16(order.Uint16(data)))
+	case "i32":
+		return lua.LNumber(int32(order.Uint32(data)))
+	case "i64":
+		return lua.LNumber(int64(order.Uint64(data)))
+	case "f32":
+		return lua.LNumber(math.Float32frombits(order.Uint32(data)))
+	case "f64":
+		return lua.LNumber(math.Float64frombits(order.Uint64(data)))
+	case "bool":
+		return lua.LBool(data[0] != 0)
+	}
+	return lua.LNil
+}
+
+func (e *Engine) luaReadString(L *lua.LState) int {
+	addr := e.checkAddress(L, 1)
+	maxLength := L.OptInt(2, 256)
+	s, err := e.requireProcess(L).ReadNTS(addr, process.ProcessMemorySize(maxLength))
+	if err != nil {
+		return pushError(L, err)
+	}
+	L.Push(lua.LString(s))
+	return 1
+}
+
+func (e *Engine) luaReadBytes(L *lua.LState) int {
+	addr := e.checkAddress(L, 1)
+	size := L.CheckInt(2)
+	data, err := e.requireProcess(L).ReadMemory(addr, process.ProcessMemorySize(size))
+	if err != nil {
+		return pushError(L, err)
+	}
+	L.Push(lua.LString(data))
+	return 1
+}
+
+func (e *Engine) luaWriteBytes(L *lua.LState) int {
+	addr := e.checkAddress(L, 1)
+	data := L.CheckString(2)
+	if err := e.requireProcess(L).WriteMemory(addr, []byte(data)); err != nil {
+		return pushError(L, err)
+	}
+	L.Push(lua.LTrue)
+	return 1
+}
+
+// luaScan scans for an AOB pattern, optionally restricted to "heap" or "stack" regions
+func (e *Engine) luaScan(L *lua.LState) int {
+	aob, err := process.NewAOBFromString(L.CheckString(1))
+	if err != nil {
+		L.ArgError(1, err.Error())
+	}
+
+	proc := e.requireProcess(L)
+
+	var addresses []process.ProcessMemoryAddress
+	switch L.OptString(2, "") {
+	case "":
+		addresses, err = proc.Scan(aob)
+	case "heap":
+		addresses, err = process.ScanHeap(proc, aob)
+	case "stack":
+		addresses, err = process.ScanStack(proc, aob)
+	default:
+		L.ArgError(2, `"heap" or "stack" expected`)
+	}
+	if err != nil {
+		return pushError(L, err)
+	}
+
+	L.Push(addressList(L, addresses))
+	return 1
+}
+
+func (e *Engine) luaScanInt(L *lua.LState) int {
+	addresses, err := process.ScanIntegerAligned(e.requireProcess(L), L.CheckInt64(1), uint(L.OptInt(2, 4)), uint(L.OptInt(3, 1)))
+	if err != nil {
+		return pushError(L, err)
+	}
+	L.Push(addressList(L, addresses))
+	return 1
+}
+
+func (e *Engine) luaScanFloat(L *lua.LState) int {
+	addresses, err := process.ScanFloatAligned(e.requireProcess(L), float64(L.CheckNumber(1)), L.OptBool(2, true), uint(L.OptInt(3, 1)))
+	if err != nil {
+		return pushError(L, err)
+	}
+	L.Push(addressList(L, addresses))
+	return 1
+}
+
+func (e *Engine) luaScanString(L *lua.LState) int {
+	addresses, err := e.requireProcess(L).ScanString(L.CheckString(1), L.OptBool(2, false))
+	if err != nil {
+		return pushError(L, err)
+	}
+	L.Push(addressList(L, addresses))
+	return 1
+}
+
+func (e *Engine) luaHexdump(L *lua.LState) int {
+	addr := e.checkAddress(L, 1)
+	size := L.OptInt(2, 256)
+
+	proc := e.requireProcess(L)
+	data, err := proc.ReadMemory(addr, process.ProcessMemorySize(size))
+	if err != nil {
+		return pushError(L, err)
+	}
+
+	options := hexdump.DefaultOptions()
+	options.StartOffset = uint64(addr)
+	options.OffsetWidth = 16
+	hexdump.DumpToWriter(e.Output, data, options)
+
+	L.Push(lua.LTrue)
+	return 1
+}
+
+// luaHex formats a number as 0x...
+func luaHex(L *lua.LState) int {
+	L.Push(lua.LString(fmt.Sprintf("0x%X", uint64(L.CheckNumber(1)))))
+	return 1
+}
+
+// luaPrintf formats with string.format and writes to the engine output
+func (e *Engine) luaPrintf(L *lua.LState) int {
+	format := L.GetField(L.GetGlobal("string"), "format")
+	args := make([]lua.LValue, L.GetTop())
+	for i := range args {
+		args[i] = L.Get(i + 1)
+	}
+
+	if err := L.CallByParam(lua.P{Fn: format, NRet: 1, Protect: true}, args...); err != nil {
+		L.RaiseError("%v", err)
+	}
+	s := L.Get(-1)
+	L.Pop(1)
+
+	fmt.Fprint(e.Output, lua.LVAsString(s))
+	return 0
+}
@@ -0,0 +1,129 @@
+// Package process_lua embeds a Lua interpreter exposing process reads, writes, scans
+// and schema based struct reads, so workflows can be automated without writing Go.
+//
+// Scripts use the global "gomem" table:
+//
+//	gomem.attach(pid), gomem.load(dir), gomem.pid(), gomem.regions(), gomem.modules(), gomem.module_base(name)
+//	gomem.read_u8/u16/u32/u64/i8/i16/i32/i64/f32/f64/ptr(addr)
+//	gomem.read_string(addr [, max]), gomem.read_bytes(addr, size)
+//	gomem.write_u8/u16/u32/u64/i8/i16/i32/i64/f32/f64/ptr(addr, value), gomem.write_bytes(addr, data)
+//	gomem.scan(pattern [, "heap"|"stack"]), gomem.scan_int(value, size [, align]),
+//	gomem.scan_float(value [, is32 [, align]]), gomem.scan_string(s [, utf16])
+//	gomem.read_struct(addr, schema), gomem.sizeof(schema)
+//	gomem.hexdump(addr, size), gomem.hex(n), gomem.printf(format, ...)
+//
+// Addresses are numbers, hex strings ("0x7ff6...") or address expressions ("game.exe+0x10+[0x18]").
+// Lua numbers are float64, so 64-bit values above 2^53 lose precision; read them with
+// read_bytes if that matters.
+// Reads and writes return nil and an error message on failure instead of raising.
+package process_lua
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gomem/process"
+	"gomem/process_blob"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Engine runs Lua scripts against a process or a process dump.
+// An Engine is not safe for concurrent use, like the Lua state it wraps.
+type Engine struct {
+	L     *lua.LState
+	proc  process.Process
+	owned bool // proc was opened by the engine and is closed with it
+
+	// Output receives the output of gomem.hexdump and gomem.printf
+	Output io.Writer
+}
+
+// NewEngine creates a Lua engine bound to proc, which may be nil until the script attaches.
+// proc stays owned by the caller, Close doesn't close it.
+func NewEngine(proc process.Process) *Engine {
+	e := &Engine{
+		L:      lua.NewState(),
+		proc:   proc,
+		Output: os.Stdout,
+	}
+
+	e.L.SetGlobal("gomem", e.L.SetFuncs(e.L.NewTable(), e.functions()))
+
+	return e
+}
+
+// Close closes the Lua state and the process the engine attached or loaded itself, a
+// process passed to NewEngine or SetProcess is left open
+func (e *Engine) Close() {
+	e.L.Close()
+	e.setProcess(nil, false)
+}
+
+// Process returns the process the engine is bound to, nil if none
+func (e *Engine) Process() process.Process {
+	return e.proc
+}
+
+// SetProcess binds the engine to proc, which stays owned by the caller. A process the
+// engine attached or loaded itself before is closed.
+func (e *Engine) SetProcess(proc process.Process) {
+	e.setProcess(proc, false)
+}
+
+// setProcess binds the engine to proc, closing the previous process if the engine owns it
+func (e *Engine) setProcess(proc process.Process, owned bool) {
+	if e.proc != nil && e.owned && e.proc != proc {
+		e.proc.Close()
+	}
+	e.proc, e.owned = proc, owned
+}
+
+// Attach opens the process with the given PID and binds the engine to it
+func (e *Engine) Attach(pid process.ProcessID) error {
+	proc, err := openProcess(pid)
+	if err != nil {
+		return err
+	}
+	e.setProcess(proc, true)
+	return nil
+}
+
+// LoadDump loads a dump saved with Process.Save and binds the engine to it
+func (e *Engine) LoadDump(dirname string) error {
+	dump := process_blob.NewProcessDump()
+	if err := dump.Load(dirname); err != nil {
+		return fmt.Errorf("failed to load dump from %s: %w", dirname, err)
+	}
+	e.setProcess(dump, true)
+	return nil
+}
+
+// SetArgs exposes the script arguments as the global "arg" table (arg[0] is the script)
+func (e *Engine) SetArgs(script string, args []string) {
+	table := e.L.NewTable()
+	table.RawSetInt(0, lua.LString(script))
+	for i, arg := range args {
+		table.RawSetInt(i+1, lua.LString(arg))
+	}
+	e.L.SetGlobal("arg", table)
+}
+
+// RunFile runs a Lua script file
+func (e *Engine) RunFile(path string) error {
+	return e.L.DoFile(path)
+}
+
+// RunString runs a Lua chunk
+func (e *Engine) RunString(source string) error {
+	return e.L.DoString(source)
+}
+
+// requireProcess returns the bound process or raises a Lua error
+func (e *Engine) requireProcess(L *lua.LState) process.Process {
+	if e.proc == nil {
+		L.RaiseError("no process attached, call gomem.attach(pid) or gomem.load(dir) first")
+	}
+	return e.proc
+}
@@ -0,0 +1,130 @@
+package process_lua
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gomem/process"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// schemaField is one field of a struct schema
+type schemaField struct {
+	Name   string
+	Type   string // scalar type, "char" or "bytes"
+	Offset int
+	Size   int
+}
+
+// arrayType matches the "char[N]" and "bytes[N]" schema types
+var arrayType = regexp.MustCompile(`^(char|bytes)\[(\d+)\]$`)
+
+// parseSchema parses a struct schema, an array of fields given either as
+// {"name", "type"} or {name = "name", type = "type", offset = n}.
+// Fields without an offset follow the previous field at their natural alignment,
+// like the fields of a Go struct read with pod.ReadT.
+// It returns the fields and the size of the struct.
+func parseSchema(table *lua.LTable) ([]schemaField, int, error) {
+	var fields []schemaField
+	next, align := 0, 1
+
+	for i := 1; i <= table.Len(); i++ {
+		entry, ok := table.RawGetInt(i).(*lua.LTable)
+		if !ok {
+			return nil, 0, fmt.Errorf("field %d: table expected", i)
+		}
+
+		field := schemaField{
+			Name: lua.LVAsString(entry.RawGetString("name")),
+			Type: lua.LVAsString(entry.RawGetString("type")),
+		}
+		if field.Name == "" {
+			field.Name = lua.LVAsString(entry.RawGetInt(1))
+		}
+		if field.Type == "" {
+			field.Type = lua.LVAsString(entry.RawGetInt(2))
+		}
+		if field.Name == "" || field.Type == "" {
+			return nil, 0, fmt.Errorf("field %d: name and type are required", i)
+		}
+
+		fieldAlign := 1
+		if size, ok := scalarTypes[field.Type]; ok {
+			field.Size = size
+			fieldAlign = size
+		} else if m := arrayType.FindStringSubmatch(field.Type); m != nil {
+			field.Type = m[1]
+			field.Size, _ = strconv.Atoi(m[2])
+		} else {
+			return nil, 0, fmt.Errorf("field %s: unknown type %q", field.Name, field.Type)
+		}
+
+		if offset, ok := entry.RawGetString("offset").(lua.LNumber); ok {
+			if offset < 0 {
+				return nil, 0, fmt.Errorf("field %s: negative offset %v", field.Name, offset)
+			}
+			field.Offset = int(offset)
+		} else {
+			field.Offset = alignUp(next, fieldAlign)
+		}
+
+		next = max(next, field.Offset+field.Size)
+		align = max(align, fieldAlign)
+		fields = append(fields, field)
+	}
+
+	return fields, alignUp(next, align), nil
+}
+
+// alignUp rounds n up to a multiple of align
+func alignUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
+
+// luaReadStruct reads a struct described by a schema and returns it as a table keyed by field name
+func (e *Engine) luaReadStruct(L *lua.LState) int {
+	addr := e.checkAddress(L, 1)
+	fields, size, err := parseSchema(L.CheckTable(2))
+	if err != nil {
+		L.ArgError(2, err.Error())
+	}
+
+	proc := e.requireProcess(L)
+	data, err := proc.ReadMemory(addr, process.ProcessMemorySize(size))
+	if err != nil {
+		return pushError(L, err)
+	}
+
+	order := process.ByteOrderOf(proc)
+	result := L.NewTable()
+	for _, field := range fields {
+		raw := data[field.Offset : field.Offset+field.Size]
+		switch field.Type {
+		case "char":
+			if i := bytes.IndexByte(raw, 0); i >= 0 {
+				raw = raw[:i]
+			}
+			result.RawSetString(field.Name, lua.LString(raw))
+		case "bytes":
+			result.RawSetString(field.Name, lua.LString(raw))
+		default:
+			result.RawSetString(field.Name, decodeScalar(field.Type, raw, order))
+		}
+	}
+
+	L.Push(result)
+	return 1
+}
+
+// luaSizeof returns the size of a struct schema
+func (e *Engine) luaSizeof(L *lua.LState) int {
+	_, size, err := parseSchema(L.CheckTable(1))
+	if err != nil {
+		L.ArgError(1, err.Error())
+	}
+	L.Push(lua.LNumber(size))
+	return 1
+}
@@ -0,0 +1,234 @@
+//go:build linux
+
+package process_manage_linux
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gomem/process/memory_map"
+)
+
+// MemorySample is the memory usage of a process at one point in time, sizes in KB
+type MemorySample struct {
+	Time      time.Time `json:"time"`
+	VmSize    int64     `json:"vm_size"`   // Virtual memory size
+	VmRSS     int64     `json:"vm_rss"`    // Resident set size
+	Regions   int       `json:"regions"`   // Number of regions in the memory map
+	Heap      int64     `json:"heap"`      // Size of the [heap] region
+	Anonymous int64     `json:"anonymous"` // Total size of the anonymous regions
+	Stack     int64     `json:"stack"`     // Total size of the stack regions
+	Image     int64     `json:"image"`     // Total size of the file-backed regions
+	Special   int64     `json:"special"`   // Total size of [vdso], [vvar], ...
+}
+
+// memorySampleHeader is the CSV header matching memorySample.record
+var memorySampleHeader = []string{"time", "vm_size", "vm_rss", "regions", "heap", "anonymous", "stack", "image", "special"}
+
+// record returns the CSV fields of the sample
+func (s MemorySample) record() []string {
+	return []string{
+		s.Time.Format(time.RFC3339Nano),
+		strconv.FormatInt(s.VmSize, 10),
+		strconv.FormatInt(s.VmRSS, 10),
+		strconv.Itoa(s.Regions),
+		strconv.FormatInt(s.Heap, 10),
+		strconv.FormatInt(s.Anonymous, 10),
+		strconv.FormatInt(s.Stack, 10),
+		strconv.FormatInt(s.Image, 10),
+		strconv.FormatInt(s.Special, 10),
+	}
+}
+
+// MemorySampler records the memory usage of a process over time into a ring buffer, the
+// oldest samples are dropped once it is full. Leaks or large allocations in the target can
+// then be lined up with the time of scan findings.
+type MemorySampler struct {
+	pm       *ProcessManager
+	pid      int
+	mapper   *memory_map.LinuxMemoryMap
+	mu       sync.Mutex
+	samples  []MemorySample
+	next     int  // Index the next sample is stored at
+	wrapped  bool // The buffer is full and next holds the oldest sample
+	capacity int
+}
+
+// NewMemorySampler creates a sampler for pid keeping the last capacity samples
+func (pm *ProcessManager) NewMemorySampler(pid int, capacity int) *MemorySampler {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &MemorySampler{
+		pm:       pm,
+		pid:      pid,
+		mapper:   memory_map.NewLinuxMemoryMap(),
+		samples:  make([]MemorySample, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+// PID returns the process ID being sampled
+func (s *MemorySampler) PID() int {
+	return s.pid
+}
+
+// Sample reads VmSize/VmRSS from /proc/[pid]/status and the region totals from
+// /proc/[pid]/maps, stores the sample and returns it
+func (s *MemorySampler) Sample() (MemorySample, error) {
+	proc, err := s.pm.getProcessInfo(s.pid)
+	if err != nil {
+		return MemorySample{}, err
+	}
+
+	mm, err := s.mapper.ReadMemoryMap(s.pid)
+	if err != nil {
+		return MemorySample{}, fmt.Errorf("failed to read memory map of %d: %w", s.pid, err)
+	}
+
+	sample := MemorySample{
+		Time:    time.Now(),
+		VmSize:  proc.VmSize,
+		VmRSS:   proc.VmRSS,
+		Regions: len(mm),
+	}
+	for _, item := range mm {
+		size := int64(item.Size) / 1024
+		switch item.Kind() {
+		case memory_map.RegionHeap:
+			sample.Heap += size
+		case memory_map.RegionAnonymous:
+			sample.Anonymous += size
+		case memory_map.RegionStack:
+			sample.Stack += size
+		case memory_map.RegionImage:
+			sample.Image += size
+		default:
+			sample.Special += size
+		}
+	}
+
+	s.add(sample)
+	return sample, nil
+}
+
+// add stores a sample, overwriting the oldest one when the buffer is full
+func (s *MemorySampler) add(sample MemorySample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) < s.capacity {
+		s.samples = append(s.samples, sample)
+		return
+	}
+	s.samples[s.next] = sample
+	s.next = (s.next + 1) % s.capacity
+	s.wrapped = true
+}
+
+// Run samples immediately and then every interval until ctx is done or the process is gone.
+// It returns ctx.Err() when cancelled and the sampling error otherwise.
+func (s *MemorySampler) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid sampling interval %v", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sample(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Samples returns a copy of the stored samples, oldest first
+func (s *MemorySampler) Samples() []MemorySample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]MemorySample, 0, len(s.samples))
+	if s.wrapped {
+		out = append(out, s.samples[s.next:]...)
+		out = append(out, s.samples[:s.next]...)
+	} else {
+		out = append(out, s.samples...)
+	}
+	return out
+}
+
+// Len returns the number of stored samples
+func (s *MemorySampler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.samples)
+}
+
+// Reset drops all stored samples
+func (s *MemorySampler) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples = s.samples[:0]
+	s.next = 0
+	s.wrapped = false
+}
+
+// At returns the last sample taken at or before t, to look up the memory usage at the time
+// of a scan finding
+func (s *MemorySampler) At(t time.Time) (MemorySample, bool) {
+	samples := s.Samples()
+	i := sort.Search(len(samples), func(i int) bool {
+		return samples[i].Time.After(t)
+	})
+	if i == 0 {
+		return MemorySample{}, false
+	}
+	return samples[i-1], true
+}
+
+// Between returns the samples taken in [from, to], oldest first
+func (s *MemorySampler) Between(from, to time.Time) []MemorySample {
+	var out []MemorySample
+	for _, sample := range s.Samples() {
+		if !sample.Time.Before(from) && !sample.Time.After(to) {
+			out = append(out, sample)
+		}
+	}
+	return out
+}
+
+// WriteCSV writes the stored samples as CSV with a header row, sizes in KB
+func (s *MemorySampler) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(memorySampleHeader); err != nil {
+		return err
+	}
+	for _, sample := range s.Samples() {
+		if err := cw.Write(sample.record()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the stored samples as an indented JSON array
+func (s *MemorySampler) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s.Samples())
+}
@@ -0,0 +1,213 @@
+// Package process_metrics exports the reads, writes and scans of a process as Prometheus metrics.
+//
+//	collector := process_metrics.NewCollector()
+//	proc = collector.Instrument(proc)
+//	http.Handle("/metrics", collector)
+//
+// The text exposition format is written directly, so no Prometheus client library is needed.
+package process_metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// DefaultBuckets are the latency histogram buckets in seconds, from 10µs to 1s
+var DefaultBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// Collector implements process.Instrumentation and serves the collected metrics.
+// It is safe for concurrent use.
+type Collector struct {
+	// Namespace prefixes every metric name
+	Namespace string
+
+	buckets []float64
+
+	mu         sync.Mutex
+	operations map[process.Operation]*operationStats
+	latency    map[latencyKey]*histogram
+}
+
+// operationStats are the counters of one operation kind
+type operationStats struct {
+	total   uint64
+	errors  uint64
+	bytes   uint64
+	matches uint64
+}
+
+// latencyKey identifies a latency histogram
+type latencyKey struct {
+	operation process.Operation
+	region    string
+}
+
+// histogram is a cumulative Prometheus histogram
+type histogram struct {
+	counts []uint64 // per bucket, non cumulative, the last one is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewCollector creates a collector with the "gomem" namespace and DefaultBuckets
+func NewCollector() *Collector {
+	return NewCollectorWithBuckets(DefaultBuckets)
+}
+
+// NewCollectorWithBuckets creates a collector with custom latency buckets (in seconds, ascending)
+func NewCollectorWithBuckets(buckets []float64) *Collector {
+	return &Collector{
+		Namespace:  "gomem",
+		buckets:    append([]float64{}, buckets...),
+		operations: make(map[process.Operation]*operationStats),
+		latency:    make(map[latencyKey]*histogram),
+	}
+}
+
+// Instrument wraps proc so that its operations are recorded by the collector
+func (c *Collector) Instrument(proc process.Process) *process.InstrumentedProcess {
+	return process.Instrument(proc, c)
+}
+
+// Observe records an operation, see process.Instrumentation
+func (c *Collector) Observe(event process.OperationEvent) {
+	key := latencyKey{operation: event.Operation}
+	if event.Operation == process.OperationRead {
+		key.region = regionLabel(event.Region)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.operations[event.Operation]
+	if stats == nil {
+		stats = &operationStats{}
+		c.operations[event.Operation] = stats
+	}
+	stats.total++
+	if event.Err != nil {
+		stats.errors++
+	}
+	if event.Operation != process.OperationScan {
+		stats.bytes += uint64(event.Size)
+	}
+	stats.matches += uint64(event.Matches)
+
+	h := c.latency[key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(c.buckets)+1)}
+		c.latency[key] = h
+	}
+	seconds := event.Duration.Seconds()
+	h.counts[sort.SearchFloat64s(c.buckets, seconds)]++
+	h.sum += seconds
+	h.count++
+}
+
+// regionLabel names a region for the latency histograms, keeping the label cardinality low:
+// the file name of mapped files, the pseudo path of kernel regions, otherwise the region kind
+func regionLabel(region *memory_map.MemoryMapItem) string {
+	switch {
+	case region == nil:
+		return "unmapped"
+	case region.Path == "":
+		return region.Kind().String()
+	case strings.HasPrefix(region.Path, "["):
+		return region.Path
+	}
+	return filepath.Base(region.Path)
+}
+
+// Reset clears all collected metrics
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.operations = make(map[process.Operation]*operationStats)
+	c.latency = make(map[latencyKey]*histogram)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+
+	c.mu.Lock()
+	ops := make([]process.Operation, 0, len(c.operations))
+	for op := range c.operations {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i] < ops[j] })
+
+	counter := func(name, help string, value func(*operationStats) uint64) {
+		fmt.Fprintf(&b, "# HELP %s_%s %s\n# TYPE %s_%s counter\n", c.Namespace, name, help, c.Namespace, name)
+		for _, op := range ops {
+			fmt.Fprintf(&b, "%s_%s{operation=%q} %d\n", c.Namespace, name, op, value(c.operations[op]))
+		}
+	}
+	counter("operations_total", "Number of memory operations.", func(s *operationStats) uint64 { return s.total })
+	counter("operation_errors_total", "Number of failed memory operations.", func(s *operationStats) uint64 { return s.errors })
+	counter("operation_bytes_total", "Number of bytes requested by reads and writes.", func(s *operationStats) uint64 { return s.bytes })
+	counter("scan_matches_total", "Number of scan matches.", func(s *operationStats) uint64 { return s.matches })
+
+	keys := make([]latencyKey, 0, len(c.latency))
+	for key := range c.latency {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].region < keys[j].region
+	})
+
+	name := c.Namespace + "_operation_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Latency of memory operations, reads are split by region.\n# TYPE %s histogram\n", name, name)
+	for _, key := range keys {
+		labels := fmt.Sprintf("operation=%q", key.operation)
+		if key.region != "" {
+			labels += fmt.Sprintf(",region=%s", quoteLabel(key.region))
+		}
+
+		h := c.latency[key]
+		cumulative := uint64(0)
+		for i, bound := range c.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "%s_bucket{%s,le=\"%g\"} %d\n", name, labels, bound, cumulative)
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(&b, "%s_sum{%s} %g\n", name, labels, h.sum)
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+	c.mu.Unlock()
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// quoteLabel quotes a label value, escaping backslashes, quotes and newlines
+func quoteLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// ServeHTTP serves the metrics, so the collector can be mounted as the /metrics handler
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// ListenAndServe serves the metrics on addr at /metrics until the server fails
+func (c *Collector) ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c)
+	return http.ListenAndServe(addr, mux)
+}
@@ -0,0 +1,349 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"encoding/binary"
+	"fmt"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+
+	"gomem/process"
+)
+
+var (
+	modntdll                      = syscall.NewLazyDLL("ntdll.dll")
+	procNtQueryInformationProcess = modntdll.NewProc("NtQueryInformationProcess")
+	procNtQueryInformationThread  = modntdll.NewProc("NtQueryInformationThread")
+
+	procCreateToolhelp32Snapshot = modkernel32.NewProc("CreateToolhelp32Snapshot")
+	procThread32First            = modkernel32.NewProc("Thread32First")
+	procThread32Next             = modkernel32.NewProc("Thread32Next")
+	procOpenThread               = modkernel32.NewProc("OpenThread")
+)
+
+const (
+	processBasicInformationClass = 0
+	threadBasicInformationClass  = 0
+
+	TH32CS_SNAPTHREAD                = 0x4
+	THREAD_QUERY_INFORMATION         = 0x40
+	THREAD_QUERY_LIMITED_INFORMATION = 0x800
+
+	// maxLoaderModules bounds the walk of the loader list in case it is corrupt or changing
+	maxLoaderModules = 4096
+)
+
+// 64-bit PEB / loader / TEB field offsets
+const (
+	pebLdrOffset               = 0x18
+	pebImageBaseOffset         = 0x10
+	pebProcessParametersOffset = 0x20
+
+	paramsImagePathNameOffset = 0x60
+	paramsCommandLineOffset   = 0x70
+
+	ldrInLoadOrderModuleListOffset = 0x10
+
+	ldrEntryDllBaseOffset     = 0x30
+	ldrEntryEntryPointOffset  = 0x38
+	ldrEntrySizeOfImageOffset = 0x40
+	ldrEntryFullDllNameOffset = 0x48
+	ldrEntryBaseDllNameOffset = 0x58
+
+	tebStackBaseOffset  = 0x08
+	tebStackLimitOffset = 0x10
+	tebTLSPointerOffset = 0x58
+	tebPEBOffset        = 0x60
+)
+
+// processBasicInformation mirrors PROCESS_BASIC_INFORMATION
+type processBasicInformation struct {
+	ExitStatus                   uintptr
+	PebBaseAddress               uintptr
+	AffinityMask                 uintptr
+	BasePriority                 uintptr
+	UniqueProcessId              uintptr
+	InheritedFromUniqueProcessId uintptr
+}
+
+// threadBasicInformation mirrors THREAD_BASIC_INFORMATION
+type threadBasicInformation struct {
+	ExitStatus     uintptr
+	TebBaseAddress uintptr
+	UniqueProcess  uintptr
+	UniqueThread   uintptr
+	AffinityMask   uintptr
+	Priority       int32
+	BasePriority   int32
+}
+
+// threadEntry32 mirrors THREADENTRY32
+type threadEntry32 struct {
+	Size           uint32
+	Usage          uint32
+	ThreadID       uint32
+	OwnerProcessID uint32
+	BasePri        int32
+	DeltaPri       int32
+	Flags          uint32
+}
+
+// LoaderModule is a module from the PEB loader list
+type LoaderModule struct {
+	Base       process.ProcessMemoryAddress
+	EntryPoint process.ProcessMemoryAddress
+	Size       uint32
+	Name       string // e.g. "kernel32.dll"
+	Path       string // e.g. "C:\Windows\System32\kernel32.dll"
+}
+
+// PEBInfo is the decoded remote Process Environment Block
+type PEBInfo struct {
+	Address           process.ProcessMemoryAddress
+	ImageBase         process.ProcessMemoryAddress
+	Ldr               process.ProcessMemoryAddress
+	ProcessParameters process.ProcessMemoryAddress
+	ImagePath         string
+	CommandLine       string
+	Modules           []LoaderModule // In load order, the executable first
+}
+
+// TEBInfo is the decoded Thread Environment Block of one thread
+type TEBInfo struct {
+	ThreadID   uint32
+	Address    process.ProcessMemoryAddress
+	StackBase  process.ProcessMemoryAddress // Highest address of the stack
+	StackLimit process.ProcessMemoryAddress // Lowest committed address of the stack
+	TLSPointer process.ProcessMemoryAddress // ThreadLocalStoragePointer, array of TLS blocks
+}
+
+// PEBAddress returns the address of the remote PEB
+func (p *WindowsProcess) PEBAddress() (process.ProcessMemoryAddress, error) {
+	p.mu.RLock()
+	handle := p.handle
+	p.mu.RUnlock()
+
+	if handle == 0 {
+		return 0, process.ErrProcessNotOpen
+	}
+
+	var pbi processBasicInformation
+	status, _, _ := procNtQueryInformationProcess.Call(
+		uintptr(handle),
+		processBasicInformationClass,
+		uintptr(unsafe.Pointer(&pbi)),
+		unsafe.Sizeof(pbi),
+		0,
+	)
+	if status != 0 {
+		return 0, fmt.Errorf("NtQueryInformationProcess failed: NTSTATUS 0x%X", uint32(status))
+	}
+
+	return process.ProcessMemoryAddress(pbi.PebBaseAddress), nil
+}
+
+// ReadPEB locates and decodes the remote PEB: image base, image path, command line and loader module list.
+// This only needs PROCESS_QUERY_INFORMATION and PROCESS_VM_READ, not PSAPI.
+func (p *WindowsProcess) ReadPEB() (*PEBInfo, error) {
+	pebAddress, err := p.PEBAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	peb := &PEBInfo{Address: pebAddress}
+
+	if peb.ImageBase, err = p.ReadPOINTER(pebAddress + pebImageBaseOffset); err != nil {
+		return nil, fmt.Errorf("failed to read PEB image base: %w", err)
+	}
+	if peb.Ldr, err = p.ReadPOINTER(pebAddress + pebLdrOffset); err != nil {
+		return nil, fmt.Errorf("failed to read PEB loader data: %w", err)
+	}
+	if peb.ProcessParameters, err = p.ReadPOINTER(pebAddress + pebProcessParametersOffset); err != nil {
+		return nil, fmt.Errorf("failed to read PEB process parameters: %w", err)
+	}
+
+	if peb.ProcessParameters != 0 {
+		if peb.ImagePath, err = p.readUnicodeString(peb.ProcessParameters + paramsImagePathNameOffset); err != nil {
+			return nil, fmt.Errorf("failed to read image path: %w", err)
+		}
+		if peb.CommandLine, err = p.readUnicodeString(peb.ProcessParameters + paramsCommandLineOffset); err != nil {
+			return nil, fmt.Errorf("failed to read command line: %w", err)
+		}
+	}
+
+	if peb.Ldr != 0 {
+		if peb.Modules, err = p.readLoaderModules(peb.Ldr); err != nil {
+			return nil, err
+		}
+	}
+
+	return peb, nil
+}
+
+// CommandLine returns the command line of the process from its PEB
+func (p *WindowsProcess) CommandLine() (string, error) {
+	peb, err := p.ReadPEB()
+	if err != nil {
+		return "", err
+	}
+	return peb.CommandLine, nil
+}
+
+// LoaderModules returns the modules of the process from the PEB loader list
+func (p *WindowsProcess) LoaderModules() ([]LoaderModule, error) {
+	peb, err := p.ReadPEB()
+	if err != nil {
+		return nil, err
+	}
+	return peb.Modules, nil
+}
+
+// readLoaderModules walks InLoadOrderModuleList of PEB_LDR_DATA
+func (p *WindowsProcess) readLoaderModules(ldr process.ProcessMemoryAddress) ([]LoaderModule, error) {
+	head := ldr + ldrInLoadOrderModuleListOffset
+
+	entry, err := p.ReadPOINTER(head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read loader list head: %w", err)
+	}
+
+	var modules []LoaderModule
+	for entry != head && entry != 0 {
+		if len(modules) >= maxLoaderModules {
+			return modules, fmt.Errorf("loader list exceeds %d entries", maxLoaderModules)
+		}
+
+		// InLoadOrderLinks is the first field of LDR_DATA_TABLE_ENTRY
+		data, err := p.ReadMemory(entry, ldrEntryBaseDllNameOffset+16)
+		if err != nil {
+			return modules, fmt.Errorf("failed to read loader entry at 0x%X: %w", uint64(entry), err)
+		}
+
+		module := LoaderModule{
+			Base:       process.ProcessMemoryAddress(binary.LittleEndian.Uint64(data[ldrEntryDllBaseOffset:])),
+			EntryPoint: process.ProcessMemoryAddress(binary.LittleEndian.Uint64(data[ldrEntryEntryPointOffset:])),
+			Size:       binary.LittleEndian.Uint32(data[ldrEntrySizeOfImageOffset:]),
+		}
+		module.Path, _ = p.readUnicodeString(entry + ldrEntryFullDllNameOffset)
+		module.Name, _ = p.readUnicodeString(entry + ldrEntryBaseDllNameOffset)
+		modules = append(modules, module)
+
+		entry = process.ProcessMemoryAddress(binary.LittleEndian.Uint64(data[0:]))
+	}
+
+	return modules, nil
+}
+
+// readUnicodeString decodes a remote UNICODE_STRING (Length, MaximumLength, Buffer)
+func (p *WindowsProcess) readUnicodeString(addr process.ProcessMemoryAddress) (string, error) {
+	header, err := p.ReadMemory(addr, 16)
+	if err != nil {
+		return "", err
+	}
+
+	length := binary.LittleEndian.Uint16(header[0:])
+	buffer := process.ProcessMemoryAddress(binary.LittleEndian.Uint64(header[8:]))
+	if length == 0 || buffer == 0 {
+		return "", nil
+	}
+
+	data, err := p.ReadMemory(buffer, process.ProcessMemorySize(length))
+	if err != nil {
+		return "", err
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// TEBs returns the Thread Environment Block of every thread of the process
+func (p *WindowsProcess) TEBs() ([]TEBInfo, error) {
+	pid := uint32(p.GetPID())
+	if pid == 0 {
+		return nil, process.ErrProcessNotOpen
+	}
+
+	snapshot, _, err := procCreateToolhelp32Snapshot.Call(TH32CS_SNAPTHREAD, 0)
+	if syscall.Handle(snapshot) == syscall.InvalidHandle {
+		return nil, fmt.Errorf("CreateToolhelp32Snapshot failed: %v", err)
+	}
+	defer procCloseHandle.Call(snapshot)
+
+	var tebs []TEBInfo
+
+	entry := threadEntry32{Size: uint32(unsafe.Sizeof(threadEntry32{}))}
+	ret, _, _ := procThread32First.Call(snapshot, uintptr(unsafe.Pointer(&entry)))
+	for ret != 0 {
+		if entry.OwnerProcessID == pid {
+			teb, err := p.readTEB(entry.ThreadID)
+			if err != nil {
+				p.getLog().Debugln("Failed to read TEB of thread", entry.ThreadID, err)
+			} else {
+				tebs = append(tebs, teb)
+			}
+		}
+		ret, _, _ = procThread32Next.Call(snapshot, uintptr(unsafe.Pointer(&entry)))
+	}
+
+	return tebs, nil
+}
+
+// GetThreads returns the threads of the process with the committed stack range of their TEB
+func (p *WindowsProcess) GetThreads() ([]process.Thread, error) {
+	tebs, err := p.TEBs()
+	if err != nil {
+		return nil, err
+	}
+
+	threads := make([]process.Thread, 0, len(tebs))
+	for _, teb := range tebs {
+		thread := process.Thread{ID: int(teb.ThreadID)}
+		if teb.StackBase > teb.StackLimit {
+			thread.StackBase = teb.StackLimit
+			thread.StackSize = uint64(teb.StackBase - teb.StackLimit)
+		}
+		threads = append(threads, thread)
+	}
+	return threads, nil
+}
+
+// readTEB locates and decodes the TEB of a thread
+func (p *WindowsProcess) readTEB(threadID uint32) (TEBInfo, error) {
+	teb := TEBInfo{ThreadID: threadID}
+
+	thread, _, err := procOpenThread.Call(THREAD_QUERY_INFORMATION|THREAD_QUERY_LIMITED_INFORMATION, 0, uintptr(threadID))
+	if thread == 0 {
+		return teb, fmt.Errorf("OpenThread failed: %v", err)
+	}
+	defer procCloseHandle.Call(thread)
+
+	var tbi threadBasicInformation
+	status, _, _ := procNtQueryInformationThread.Call(
+		thread,
+		threadBasicInformationClass,
+		uintptr(unsafe.Pointer(&tbi)),
+		unsafe.Sizeof(tbi),
+		0,
+	)
+	if status != 0 {
+		return teb, fmt.Errorf("NtQueryInformationThread failed: NTSTATUS 0x%X", uint32(status))
+	}
+
+	teb.Address = process.ProcessMemoryAddress(tbi.TebBaseAddress)
+
+	data, err := p.ReadMemory(teb.Address, tebPEBOffset+8)
+	if err != nil {
+		return teb, fmt.Errorf("failed to read TEB at 0x%X: %w", uint64(teb.Address), err)
+	}
+
+	teb.StackBase = process.ProcessMemoryAddress(binary.LittleEndian.Uint64(data[tebStackBaseOffset:]))
+	teb.StackLimit = process.ProcessMemoryAddress(binary.LittleEndian.Uint64(data[tebStackLimitOffset:]))
+	teb.TLSPointer = process.ProcessMemoryAddress(binary.LittleEndian.Uint64(data[tebTLSPointerOffset:]))
+
+	return teb, nil
+}
@@ -3,6 +3,7 @@
 package process_windows
 
 import (
+	"encoding/binary"
 	"fmt"
 	"sync"
 	"syscall"
@@ -21,7 +22,10 @@ var (
 	procOpenProcess       = modkernel32.NewProc("OpenProcess")
 	procReadProcessMemory = modkernel32.NewProc("ReadProcessMemory")
 	procCloseHandle       = modkernel32.NewProc("CloseHandle")
-	procVirtualQueryEx    = modkernel32.NewProc("VirtualQueryEx")
+
+	procWriteProcessMemory    = modkernel32.NewProc("WriteProcessMemory")
+	procVirtualProtectEx      = modkernel32.NewProc("VirtualProtectEx")
+	procFlushInstructionCache = modkernel32.NewProc("FlushInstructionCache")
 )
 
 const (
@@ -30,13 +34,29 @@ const (
 	PROCESS_QUERY_INFORMATION = 0x0400
 )
 
-// WindowsProcess implements the process.Process interface for Windows systems
+// WindowsProcess implements the process.Process interface for Windows systems.
+//
+// It is safe for concurrent use. The handle, logger and memory map are guarded by an
+// RWMutex; the memory map is never modified in place, UpdateMemoryMap swaps in a new slice,
+// so read paths take a read lock only long enough to grab the current snapshot and then work
+// on it without holding the lock (reads, validation and scans run in parallel).
 type WindowsProcess struct {
 	pid    process.ProcessID
 	handle syscall.Handle
 	log    *logger.Logger
 	mm     []memory_map.MemoryMapItem
-	mu     sync.Mutex
+	mu     sync.RWMutex
+
+	// queries caches on-demand VirtualQueryEx results for addresses outside the memory map
+	queries queryCache
+
+	limits process.ReadLimits
+
+	// order is the byte order of the target memory, nil for little-endian
+	order binary.ByteOrder
+
+	// protectFallback lets WriteMemory make read-only pages writable with VirtualProtectEx
+	protectFallback bool
 }
 
 // New creates a new WindowsProcess instance
@@ -67,6 +87,7 @@ func (p *WindowsProcess) Open(pid process.ProcessID) error {
 
 	p.pid = pid
 	p.handle = syscall.Handle(handle)
+	p.queries.reset()
 	p.log = logger.NewLogger(coloransi.Color(coloransi.ColorPurple, coloransi.ColorOrange, fmt.Sprintf("process-%d", pid)))
 
 	// Initialize memory map
@@ -92,6 +113,7 @@ func (p *WindowsProcess) Close() error {
 
 	p.pid = 0
 	p.mm = nil
+	p.queries.reset()
 	p.log = logger.NewLogger(coloransi.Color(coloransi.Red, coloransi.ColorOrange, "process-not-open"))
 	p.log.Infoln("Process closed")
 
@@ -99,81 +121,183 @@ func (p *WindowsProcess) Close() error {
 }
 
 func (p *WindowsProcess) GetPID() process.ProcessID {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	return p.pid
 }
 
 func (p *WindowsProcess) UpdateMemoryMap() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	p.queries.reset()
 	return p.updateMemoryMapInternal()
 }
 
 func (p *WindowsProcess) updateMemoryMapInternal() error {
 	if p.handle == 0 {
-		return fmt.Errorf("process not opened")
+		return process.ErrProcessNotOpen
+	}
+
+	mm, err := memory_map.NewWindowsMemoryMap().ReadMemoryMapHandle(p.handle)
+	if err != nil {
+		return fmt.Errorf("failed to read memory map: %w", err)
 	}
 
-	// TODO: Implement VirtualQueryEx loop to populate p.mm
-	// For now, we leave it empty or implement a basic version
+	p.mm = mm
 	return nil
 }
 
+// IsValidAddress checks the address against the memory map snapshot, and falls back to
+// querying the address with VirtualQueryEx (cached per region for a short time) when it
+// is not in the snapshot, so newly allocated memory is seen without a full map refresh.
 func (p *WindowsProcess) IsValidAddress(addr process.ProcessMemoryAddress) bool {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	p.mu.RLock()
+	handle, mm := p.handle, p.mm
+	p.mu.RUnlock()
+
+	if handle == 0 {
+		return false
+	}
+
 	// Check against memory map
-	return memory_map.IsValidAddress(uint64(addr), p.mm)
+	if item := memory_map.IsValidAddress2(uint64(addr), mm); item != nil && item.IsReadable() {
+		return true
+	}
+
+	return p.isValidAddressQuery(handle, addr)
 }
 
 func (p *WindowsProcess) GetMemoryMap() ([]memory_map.MemoryMapItem, error) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	if p.handle == 0 {
-		return nil, fmt.Errorf("process not opened")
+		return nil, process.ErrProcessNotOpen
 	}
 	result := make([]memory_map.MemoryMapItem, len(p.mm))
 	copy(result, p.mm)
 	return result, nil
 }
 
+// getLog returns the current logger, which is replaced on Open and Close
+func (p *WindowsProcess) getLog() *logger.Logger {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.log
+}
+
+// SetReadLimits sets the size cap and deadline applied to every read, see process.ReadLimits
+func (p *WindowsProcess) SetReadLimits(limits process.ReadLimits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limits = limits
+}
+
+// GetReadLimits returns the limits applied to every read
+func (p *WindowsProcess) GetReadLimits() process.ReadLimits {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.limits
+}
+
+// SetByteOrder sets the byte order typed reads and pod decoding use for the target memory,
+// e.g. binary.BigEndian for the guest memory of an emulator. The default is little-endian.
+func (p *WindowsProcess) SetByteOrder(order binary.ByteOrder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.order = order
+}
+
+// ByteOrder returns the byte order of the target memory
+func (p *WindowsProcess) ByteOrder() binary.ByteOrder {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.order == nil {
+		return binary.LittleEndian
+	}
+	return p.order
+}
+
+// ReadMemory reads size bytes at addr.
+// If the range runs into an unreadable page (guard page, PAGE_NOACCESS hole), the readable
+// prefix is returned together with an error wrapping process.ErrPartialRead.
 func (p *WindowsProcess) ReadMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ([]byte, error) {
 	if size == 0 {
 		return []byte{}, nil
 	}
 
-	p.mu.Lock()
-	handle := p.handle
-	p.mu.Unlock()
+	p.mu.RLock()
+	handle, limits := p.handle, p.limits
+	p.mu.RUnlock()
 
 	if handle == 0 {
-		return nil, fmt.Errorf("process not opened")
+		return nil, process.ErrProcessNotOpen
 	}
 
+	return limits.Read(size, func() ([]byte, error) {
+		return readMemory(handle, addr, size)
+	})
+}
+
+// ReadMemoryPartial reads size bytes at addr, keeping the bytes that can be read around
+// guard pages and holes, see process.ReadPartial
+func (p *WindowsProcess) ReadMemoryPartial(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) process.PartialRead {
+	return process.ReadPartial(p.ReadMemory, addr, size)
+}
+
+// readMemory reads size bytes at addr, salvaging the readable prefix of a partial read
+func readMemory(handle syscall.Handle, addr process.ProcessMemoryAddress, size process.ProcessMemorySize) ([]byte, error) {
 	buf := make([]byte, size)
+	n, err := readProcessMemory(handle, uintptr(addr), buf)
+	if err == nil && n == len(buf) {
+		return buf, nil
+	}
+
+	// ReadProcessMemory fails the whole range on a single bad page, salvage what precedes it
+	n = readProcessMemoryPrefix(handle, uintptr(addr), buf, n)
+	if n == 0 {
+		if err == nil {
+			err = fmt.Errorf("read incomplete: expected %d, got 0", size)
+		}
+		return nil, fmt.Errorf("ReadProcessMemory failed: %v", err)
+	}
+
+	return buf[:n], fmt.Errorf("%w: read %d of %d bytes at 0x%X", process.ErrPartialRead, n, size, uint64(addr))
+}
+
+// windowsPageSize is the granularity at which reads are retried after a partial copy
+const windowsPageSize = 0x1000
+
+// readProcessMemory performs a single ReadProcessMemory call and returns the number of bytes copied
+func readProcessMemory(handle syscall.Handle, addr uintptr, buf []byte) (int, error) {
 	var bytesRead uintptr
 	ret, _, err := procReadProcessMemory.Call(
 		uintptr(handle),
-		uintptr(addr),
+		addr,
 		uintptr(unsafe.Pointer(&buf[0])),
-		uintptr(size),
+		uintptr(len(buf)),
 		uintptr(unsafe.Pointer(&bytesRead)),
 	)
 
 	if ret == 0 {
-		return nil, fmt.Errorf("ReadProcessMemory failed: %v", err)
-	}
-
-	if bytesRead != uintptr(size) {
-		return nil, fmt.Errorf("read incomplete: expected %d, got %d", size, bytesRead)
+		return int(bytesRead), err
 	}
-
-	return buf, nil
+	return int(bytesRead), nil
 }
 
-func (p *WindowsProcess) WriteMemory(addr process.ProcessMemoryAddress, data []byte) error {
-	return fmt.Errorf("WriteMemory not implemented")
+// readProcessMemoryPrefix continues a read that stopped after done bytes page by page,
+// and returns the length of the contiguous prefix of buf that could be read
+func readProcessMemoryPrefix(handle syscall.Handle, addr uintptr, buf []byte, done int) int {
+	for done < len(buf) {
+		cur := addr + uintptr(done)
+		chunk := min(int(windowsPageSize-cur%windowsPageSize), len(buf)-done)
+
+		n, err := readProcessMemory(handle, cur, buf[done:done+chunk])
+		done += n
+		if err != nil || n != chunk {
+			break
+		}
+	}
+	return done
 }
 
 func (p *WindowsProcess) Save(dirname string) error {
@@ -183,32 +307,3 @@ func (p *WindowsProcess) Save(dirname string) error {
 func (p *WindowsProcess) Load(dirname string) error {
 	return fmt.Errorf("Load not implemented")
 }
-
-// MemoryScanner implementation (placeholders)
-func (p *WindowsProcess) Scan(aob process.AOB) ([]process.ProcessMemoryAddress, error) {
-	return nil, fmt.Errorf("Scan not implemented")
-}
-
-func (p *WindowsProcess) ScanParallel(aob process.AOB, maxdop uint) ([]process.ProcessMemoryAddress, error) {
-	return nil, fmt.Errorf("ScanParallel not implemented")
-}
-
-func (p *WindowsProcess) ScanFirst(aob process.AOB) (process.ProcessMemoryAddress, error) {
-	return 0, fmt.Errorf("ScanFirst not implemented")
-}
-
-func (p *WindowsProcess) ScanFirstParallel(aob process.AOB, maxdop uint) (process.ProcessMemoryAddress, error) {
-	return 0, fmt.Errorf("ScanFirstParallel not implemented")
-}
-
-func (p *WindowsProcess) ScanInteger(value int64, size uint) ([]process.ProcessMemoryAddress, error) {
-	return nil, fmt.Errorf("ScanInteger not implemented")
-}
-
-func (p *WindowsProcess) ScanFloat(value float64, isFloat32 bool) ([]process.ProcessMemoryAddress, error) {
-	return nil, fmt.Errorf("ScanFloat not implemented")
-}
-
-func (p *WindowsProcess) ScanString(value string, isUTF16 bool) ([]process.ProcessMemoryAddress, error) {
-	return nil, fmt.Errorf("ScanString not implemented")
-}
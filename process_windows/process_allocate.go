@@ -0,0 +1,73 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"fmt"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+var (
+	procVirtualAllocEx = modkernel32.NewProc("VirtualAllocEx")
+	procVirtualFreeEx  = modkernel32.NewProc("VirtualFreeEx")
+)
+
+// MEM_RELEASE frees a whole allocation with VirtualFreeEx
+const MEM_RELEASE = 0x8000
+
+// AllocateMemory commits size bytes of zeroed memory with VirtualAllocEx, see
+// process.MemoryAllocator. perms is a permission string such as "rw-" or "rwx"; the size is
+// rounded up to whole pages. The memory map is refreshed afterwards.
+func (p *WindowsProcess) AllocateMemory(size process.ProcessMemorySize, perms string) (process.ProcessMemoryAddress, error) {
+	p.mu.RLock()
+	handle := p.handle
+	p.mu.RUnlock()
+
+	if handle == 0 {
+		return 0, process.ErrProcessNotOpen
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("can't allocate 0 bytes")
+	}
+
+	read, write, exec, err := process.ParsePerms(perms)
+	if err != nil {
+		return 0, err
+	}
+
+	addr, _, err := procVirtualAllocEx.Call(
+		uintptr(handle),
+		0,
+		uintptr(size),
+		memory_map.MEM_COMMIT|memory_map.MEM_RESERVE,
+		uintptr(permsToProtect(read, write, exec)),
+	)
+	if addr == 0 {
+		return 0, fmt.Errorf("VirtualAllocEx of %d bytes %s failed: %v", uint64(size), perms, err)
+	}
+
+	if err := p.UpdateMemoryMap(); err != nil {
+		return process.ProcessMemoryAddress(addr), err
+	}
+	return process.ProcessMemoryAddress(addr), nil
+}
+
+// FreeMemory releases an allocation of AllocateMemory with VirtualFreeEx. MEM_RELEASE always
+// frees the whole allocation, so size is not used.
+func (p *WindowsProcess) FreeMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize) error {
+	p.mu.RLock()
+	handle := p.handle
+	p.mu.RUnlock()
+
+	if handle == 0 {
+		return process.ErrProcessNotOpen
+	}
+
+	if ret, _, err := procVirtualFreeEx.Call(uintptr(handle), uintptr(addr), 0, MEM_RELEASE); ret == 0 {
+		return fmt.Errorf("VirtualFreeEx at %x failed: %v", uint64(addr), err)
+	}
+
+	return p.UpdateMemoryMap()
+}
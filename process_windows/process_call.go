@@ -0,0 +1,127 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"encoding/binary"
+	"fmt"
+	"runtime"
+	"time"
+
+	"gomem/process"
+)
+
+var (
+	procCreateRemoteThread  = modkernel32.NewProc("CreateRemoteThread")
+	procWaitForSingleObject = modkernel32.NewProc("WaitForSingleObject")
+)
+
+const (
+	WAIT_OBJECT_0 = 0x00000000
+	WAIT_TIMEOUT  = 0x00000102
+)
+
+// callTimeout is how long CallFunction waits for the called function to return
+const callTimeout = 10 * time.Second
+
+// callResultOffset is the offset of the return registers in the call stub page
+const callResultOffset = 0x100
+
+// CallFunction calls the function at addr in a new thread of the target, see
+// process.FunctionCaller. A stub is written to a page allocated with AllocateMemory; it
+// passes the arguments in the Microsoft x64 registers and stack slots, calls the function
+// and stores RAX and XMM0 for reading back. The stub runs with CreateRemoteThread, which
+// needs PROCESS_CREATE_THREAD access; only 64-bit targets are supported.
+//
+// The function runs concurrently with the threads of the target, so it must be safe to call
+// from any thread. When it doesn't return within the timeout the stub page is left
+// allocated, as the thread may still use it.
+func (p *WindowsProcess) CallFunction(addr process.ProcessMemoryAddress, args ...uint64) (process.CallResult, error) {
+	p.mu.RLock()
+	handle := p.handle
+	p.mu.RUnlock()
+
+	if handle == 0 {
+		return process.CallResult{}, process.ErrProcessNotOpen
+	}
+	if runtime.GOARCH != "amd64" {
+		return process.CallResult{}, fmt.Errorf("calling functions is not implemented on %s", runtime.GOARCH)
+	}
+	if len(args) > process.MaxCallArgs {
+		return process.CallResult{}, fmt.Errorf("at most %d arguments can be passed, got %d", process.MaxCallArgs, len(args))
+	}
+
+	page, err := p.AllocateMemory(0x1000, "rwx")
+	if err != nil {
+		return process.CallResult{}, err
+	}
+	freePage := true
+	defer func() {
+		if freePage {
+			p.FreeMemory(page, 0x1000)
+		}
+	}()
+
+	result := page + callResultOffset
+	if err := p.WriteMemory(page, callStub(uint64(addr), uint64(result), args)); err != nil {
+		return process.CallResult{}, fmt.Errorf("failed to write call stub: %w", err)
+	}
+
+	thread, _, err := procCreateRemoteThread.Call(uintptr(handle), 0, 0, uintptr(page), 0, 0, 0)
+	if thread == 0 {
+		return process.CallResult{}, fmt.Errorf("CreateRemoteThread failed: %v", err)
+	}
+	defer procCloseHandle.Call(thread)
+
+	ret, _, err := procWaitForSingleObject.Call(thread, uintptr(callTimeout.Milliseconds()))
+	switch ret {
+	case WAIT_OBJECT_0:
+	case WAIT_TIMEOUT:
+		freePage = false
+		return process.CallResult{}, fmt.Errorf("call of 0x%x did not return within %v", uint64(addr), callTimeout)
+	default:
+		return process.CallResult{}, fmt.Errorf("WaitForSingleObject failed: %v", err)
+	}
+
+	data, err := p.ReadMemory(result, 16)
+	if err != nil {
+		return process.CallResult{}, fmt.Errorf("failed to read call result: %w", err)
+	}
+	r := process.CallResult{
+		Int:   binary.LittleEndian.Uint64(data[0:]),
+		Float: binary.LittleEndian.Uint64(data[8:]),
+	}
+
+	p.getLog().Debugf("Called 0x%x, returned 0x%x", uint64(addr), r.Int)
+	return r, nil
+}
+
+// callStub assembles the thread routine calling fn with args and storing RAX and the low
+// half of XMM0 at result
+func callStub(fn, result uint64, args []uint64) []byte {
+	var a [process.MaxCallArgs]uint64
+	copy(a[:], args)
+
+	imm := func(code []byte, v uint64) []byte {
+		return binary.LittleEndian.AppendUint64(code, v)
+	}
+
+	code := []byte{0x48, 0x83, 0xEC, 0x38}            // sub rsp, 0x38: shadow space, args 5 and 6, alignment
+	code = imm(append(code, 0x48, 0xB9), a[0])        // mov rcx, arg1
+	code = imm(append(code, 0x48, 0xBA), a[1])        // mov rdx, arg2
+	code = imm(append(code, 0x49, 0xB8), a[2])        // mov r8, arg3
+	code = imm(append(code, 0x49, 0xB9), a[3])        // mov r9, arg4
+	code = imm(append(code, 0x48, 0xB8), a[4])        // mov rax, arg5
+	code = append(code, 0x48, 0x89, 0x44, 0x24, 0x20) // mov [rsp+0x20], rax
+	code = imm(append(code, 0x48, 0xB8), a[5])        // mov rax, arg6
+	code = append(code, 0x48, 0x89, 0x44, 0x24, 0x28) // mov [rsp+0x28], rax
+	code = imm(append(code, 0x48, 0xB8), fn)          // mov rax, fn
+	code = append(code, 0xFF, 0xD0)                   // call rax
+	code = imm(append(code, 0x48, 0xB9), result)      // mov rcx, result
+	code = append(code, 0x48, 0x89, 0x01)             // mov [rcx], rax
+	code = append(code, 0x66, 0x0F, 0xD6, 0x41, 0x08) // movq [rcx+8], xmm0
+	code = append(code, 0x31, 0xC0)                   // xor eax, eax
+	code = append(code, 0x48, 0x83, 0xC4, 0x38)       // add rsp, 0x38
+	code = append(code, 0xC3)                         // ret
+	return code
+}
@@ -0,0 +1,477 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"gomem/process"
+)
+
+var (
+	procProcess32FirstW            = modkernel32.NewProc("Process32FirstW")
+	procProcess32NextW             = modkernel32.NewProc("Process32NextW")
+	procQueryFullProcessImageNameW = modkernel32.NewProc("QueryFullProcessImageNameW")
+	procK32GetProcessMemoryInfo    = modkernel32.NewProc("K32GetProcessMemoryInfo")
+)
+
+const (
+	TH32CS_SNAPPROCESS                = 0x2
+	PROCESS_QUERY_LIMITED_INFORMATION = 0x1000
+
+	processCommandLineInformationClass = 60
+
+	maxPath = 260
+)
+
+// DefaultProcessListMaxAge is a reasonable MaxAge for NewProcessFinderWithMaxAge when the
+// process list is queried repeatedly, e.g. to walk a process tree
+const DefaultProcessListMaxAge = time.Second
+
+// processEntry32 mirrors PROCESSENTRY32W
+type processEntry32 struct {
+	Size            uint32
+	Usage           uint32
+	ProcessID       uint32
+	DefaultHeapID   uintptr
+	ModuleID        uint32
+	Threads         uint32
+	ParentProcessID uint32
+	PriClassBase    int32
+	Flags           uint32
+	ExeFile         [maxPath]uint16
+}
+
+// unicodeString mirrors UNICODE_STRING (64-bit layout)
+type unicodeString struct {
+	Length        uint16
+	MaximumLength uint16
+	_             uint32
+	Buffer        uintptr
+}
+
+// processMemoryCounters mirrors PROCESS_MEMORY_COUNTERS
+type processMemoryCounters struct {
+	Size                       uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+// WindowsProcessFinder implements the process.ProcessFinder interface.
+// Listing and hierarchy queries share a single toolhelp enumeration which can be reused
+// for MaxAge, so walking a process tree or filtering repeatedly does not re-open
+// every process each time.
+type WindowsProcessFinder struct {
+	// MaxAge is how long an enumeration is reused, 0 disables caching
+	MaxAge time.Duration
+
+	mu         sync.Mutex
+	snapshot   []process.ProcessInfo
+	snapshotAt time.Time
+}
+
+// NewProcessFinder creates a new WindowsProcessFinder enumerating the processes on every
+// query, see NewProcessFinderWithMaxAge to reuse enumerations
+func NewProcessFinder() process.ProcessFinder {
+	return &WindowsProcessFinder{}
+}
+
+// NewProcessFinderWithMaxAge creates a new WindowsProcessFinder reusing enumerations for maxAge (0 disables caching)
+func NewProcessFinderWithMaxAge(maxAge time.Duration) process.ProcessFinder {
+	return &WindowsProcessFinder{MaxAge: maxAge}
+}
+
+// FindProcess finds a process by name and returns its PID
+func FindProcess(name string) (process.ProcessID, error) {
+	processes, err := NewProcessFinder().FindProcessByName(name)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(processes) == 0 {
+		return 0, fmt.Errorf("no process found with name '%s'", name)
+	}
+
+	return processes[0].PID, nil
+}
+
+// Invalidate drops the cached enumeration so the next query enumerates the processes again
+func (f *WindowsProcessFinder) Invalidate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.snapshot = nil
+	f.snapshotAt = time.Time{}
+}
+
+// processes returns the cached enumeration, refreshing it if it is older than MaxAge.
+// The returned slice is shared and must not be modified.
+func (f *WindowsProcessFinder) processes() ([]process.ProcessInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.snapshot != nil && f.MaxAge > 0 && time.Since(f.snapshotAt) < f.MaxAge {
+		return f.snapshot, nil
+	}
+
+	snapshot, err := enumerateProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	f.snapshot = snapshot
+	f.snapshotAt = time.Now()
+	return snapshot, nil
+}
+
+// FindProcessByPID finds a process by its PID
+func (f *WindowsProcessFinder) FindProcessByPID(pid process.ProcessID) (*process.ProcessInfo, error) {
+	// Always enumerate, the process may have started after the cached snapshot
+	processes, err := enumerateProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range processes {
+		if info.PID == pid {
+			return &info, nil
+		}
+	}
+
+	return nil, fmt.Errorf("process with PID %d does not exist", pid)
+}
+
+// FindProcessByName finds processes by their image name (exact, case-insensitive match, e.g. "game.exe")
+func (f *WindowsProcessFinder) FindProcessByName(name string) ([]process.ProcessInfo, error) {
+	return f.findProcessesByNamePattern("(?i)^" + regexp.QuoteMeta(name) + "$")
+}
+
+// FindProcessByNamePattern finds processes by their image name (pattern match)
+func (f *WindowsProcessFinder) FindProcessByNamePattern(pattern string) ([]process.ProcessInfo, error) {
+	return f.findProcessesByNamePattern(pattern)
+}
+
+// FindAllProcesses returns information about all running processes
+func (f *WindowsProcessFinder) FindAllProcesses() ([]process.ProcessInfo, error) {
+	allProcesses, err := f.processes()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]process.ProcessInfo, len(allProcesses))
+	copy(results, allProcesses)
+	return results, nil
+}
+
+// findProcessesByNamePattern returns the processes whose name matches pattern
+func (f *WindowsProcessFinder) findProcessesByNamePattern(pattern string) ([]process.ProcessInfo, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	allProcesses, err := f.processes()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []process.ProcessInfo
+	for _, info := range allProcesses {
+		if re.MatchString(info.Name) {
+			results = append(results, info)
+		}
+	}
+
+	return results, nil
+}
+
+// FindProcessByCommandLine finds processes that have a specific argument in their command line
+func (f *WindowsProcessFinder) FindProcessByCommandLine(arg string) ([]process.ProcessInfo, error) {
+	return f.findProcessesByCommandLinePattern(regexp.QuoteMeta(arg))
+}
+
+// FindProcessByCommandLinePattern finds processes with command line arguments matching a pattern
+func (f *WindowsProcessFinder) FindProcessByCommandLinePattern(pattern string) ([]process.ProcessInfo, error) {
+	return f.findProcessesByCommandLinePattern(pattern)
+}
+
+// findProcessesByCommandLinePattern returns the processes with an argument matching pattern
+func (f *WindowsProcessFinder) findProcessesByCommandLinePattern(pattern string) ([]process.ProcessInfo, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	allProcesses, err := f.processes()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []process.ProcessInfo
+	for _, proc := range allProcesses {
+		for _, arg := range proc.Cmdline {
+			if re.MatchString(arg) {
+				results = append(results, proc)
+				break
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// FindChildProcesses finds all child processes of a given PID
+func (f *WindowsProcessFinder) FindChildProcesses(parentPID process.ProcessID) ([]process.ProcessInfo, error) {
+	allProcesses, err := f.processes()
+	if err != nil {
+		return nil, err
+	}
+
+	var children []process.ProcessInfo
+	for _, proc := range allProcesses {
+		if proc.PPID == parentPID && proc.PID != parentPID {
+			children = append(children, proc)
+		}
+	}
+
+	return children, nil
+}
+
+// FindDescendantProcesses finds all descendant processes (children, grandchildren, etc.) of a given PID
+func (f *WindowsProcessFinder) FindDescendantProcesses(rootPID process.ProcessID) ([]process.ProcessInfo, error) {
+	allProcesses, err := f.processes()
+	if err != nil {
+		return nil, err
+	}
+
+	childrenMap, processMap := processRelations(allProcesses)
+
+	// Windows reuses PIDs and keeps stale parent PIDs, so guard against cycles
+	var descendants []process.ProcessInfo
+	queue := append([]process.ProcessID(nil), childrenMap[rootPID]...)
+	visited := map[process.ProcessID]bool{rootPID: true}
+
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+
+		if visited[pid] {
+			continue
+		}
+		visited[pid] = true
+
+		if proc, exists := processMap[pid]; exists {
+			descendants = append(descendants, proc)
+			queue = append(queue, childrenMap[pid]...)
+		}
+	}
+
+	return descendants, nil
+}
+
+// GetProcessTree returns a tree-like representation of processes starting from a root PID
+func (f *WindowsProcessFinder) GetProcessTree(rootPID process.ProcessID) (*process.ProcessTreeNode, error) {
+	allProcesses, err := f.processes()
+	if err != nil {
+		return nil, err
+	}
+
+	childrenMap, processMap := processRelations(allProcesses)
+
+	// Check if the root process exists, it may have started after the enumeration
+	rootProcess, exists := processMap[rootPID]
+	if !exists {
+		info, err := f.FindProcessByPID(rootPID)
+		if err != nil {
+			return nil, err
+		}
+		rootProcess = *info
+	}
+
+	return buildProcessTree(rootProcess, childrenMap, processMap, map[process.ProcessID]bool{}), nil
+}
+
+// processRelations indexes processes by PID and by parent PID
+func processRelations(processes []process.ProcessInfo) (map[process.ProcessID][]process.ProcessID, map[process.ProcessID]process.ProcessInfo) {
+	childrenMap := make(map[process.ProcessID][]process.ProcessID)
+	processMap := make(map[process.ProcessID]process.ProcessInfo)
+
+	for _, proc := range processes {
+		processMap[proc.PID] = proc
+		if proc.PPID != proc.PID {
+			childrenMap[proc.PPID] = append(childrenMap[proc.PPID], proc.PID)
+		}
+	}
+
+	return childrenMap, processMap
+}
+
+// buildProcessTree builds a process tree recursively, visiting each process once
+func buildProcessTree(procInfo process.ProcessInfo, childrenMap map[process.ProcessID][]process.ProcessID, processMap map[process.ProcessID]process.ProcessInfo, visited map[process.ProcessID]bool) *process.ProcessTreeNode {
+	visited[procInfo.PID] = true
+	node := &process.ProcessTreeNode{
+		Process:  procInfo,
+		Children: []*process.ProcessTreeNode{},
+	}
+
+	for _, childPID := range childrenMap[procInfo.PID] {
+		if childProc, exists := processMap[childPID]; exists && !visited[childPID] {
+			node.Children = append(node.Children, buildProcessTree(childProc, childrenMap, processMap, visited))
+		}
+	}
+
+	return node
+}
+
+// enumerateProcesses lists every process with a toolhelp snapshot and completes the
+// information of the processes that can be opened for querying
+func enumerateProcesses() ([]process.ProcessInfo, error) {
+	snapshot, _, err := procCreateToolhelp32Snapshot.Call(TH32CS_SNAPPROCESS, 0)
+	if syscall.Handle(snapshot) == syscall.InvalidHandle {
+		return nil, fmt.Errorf("CreateToolhelp32Snapshot failed: %v", err)
+	}
+	defer procCloseHandle.Call(snapshot)
+
+	var processes []process.ProcessInfo
+	users := make(map[string]string)
+
+	entry := processEntry32{Size: uint32(unsafe.Sizeof(processEntry32{}))}
+	ret, _, _ := procProcess32FirstW.Call(snapshot, uintptr(unsafe.Pointer(&entry)))
+	for ret != 0 {
+		info := process.ProcessInfo{
+			PID:     process.ProcessID(entry.ProcessID),
+			PPID:    process.ProcessID(entry.ParentProcessID),
+			Name:    syscall.UTF16ToString(entry.ExeFile[:]),
+			State:   process.ProcessRunning,
+			Threads: int(entry.Threads),
+		}
+		queryProcessInfo(&info, users)
+		processes = append(processes, info)
+
+		ret, _, _ = procProcess32NextW.Call(snapshot, uintptr(unsafe.Pointer(&entry)))
+	}
+
+	return processes, nil
+}
+
+// queryProcessInfo fills the executable path, command line, user and memory usage of a
+// process. Fields are left empty when the process cannot be opened (protected or system processes).
+func queryProcessInfo(info *process.ProcessInfo, users map[string]string) {
+	h, _, _ := procOpenProcess.Call(PROCESS_QUERY_LIMITED_INFORMATION|PROCESS_VM_READ, 0, uintptr(info.PID))
+	if h == 0 {
+		h, _, _ = procOpenProcess.Call(PROCESS_QUERY_LIMITED_INFORMATION, 0, uintptr(info.PID))
+		if h == 0 {
+			return
+		}
+	}
+	handle := syscall.Handle(h)
+	defer procCloseHandle.Call(h)
+
+	info.Exe = queryImageName(handle)
+	info.Cmdline = queryCommandLine(handle)
+	info.User = queryUser(handle, users)
+
+	counters := processMemoryCounters{Size: uint32(unsafe.Sizeof(processMemoryCounters{}))}
+	if ret, _, _ := procK32GetProcessMemoryInfo.Call(h, uintptr(unsafe.Pointer(&counters)), uintptr(counters.Size)); ret != 0 {
+		info.Memory = uint64(counters.WorkingSetSize)
+	}
+}
+
+// queryImageName returns the full path of the executable of a process
+func queryImageName(handle syscall.Handle) string {
+	buf := make([]uint16, 1024)
+	size := uint32(len(buf))
+	ret, _, _ := procQueryFullProcessImageNameW.Call(uintptr(handle), 0, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if ret == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf[:size])
+}
+
+// queryCommandLine returns the arguments of a process, split the way the C runtime does
+func queryCommandLine(handle syscall.Handle) []string {
+	var length uint32
+	procNtQueryInformationProcess.Call(uintptr(handle), processCommandLineInformationClass, 0, 0, uintptr(unsafe.Pointer(&length)))
+	if length == 0 {
+		return nil
+	}
+
+	buf := make([]byte, length)
+	status, _, _ := procNtQueryInformationProcess.Call(
+		uintptr(handle),
+		processCommandLineInformationClass,
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(length),
+		uintptr(unsafe.Pointer(&length)),
+	)
+	if status != 0 {
+		return nil
+	}
+
+	// The buffer starts with a UNICODE_STRING pointing into the rest of the buffer
+	us := (*unicodeString)(unsafe.Pointer(&buf[0]))
+	if us.Length == 0 || us.Buffer == 0 {
+		return nil
+	}
+	start := us.Buffer - uintptr(unsafe.Pointer(&buf[0]))
+	if start+uintptr(us.Length) > uintptr(len(buf)) {
+		return nil
+	}
+	units := unsafe.Slice((*uint16)(unsafe.Pointer(&buf[start])), us.Length/2)
+	cmdline := append(append([]uint16(nil), units...), 0)
+
+	var argc int32
+	argv, err := syscall.CommandLineToArgv(&cmdline[0], &argc)
+	if err != nil {
+		return []string{syscall.UTF16ToString(cmdline)}
+	}
+	defer syscall.LocalFree(syscall.Handle(uintptr(unsafe.Pointer(argv))))
+
+	args := make([]string, argc)
+	for i := range args {
+		args[i] = syscall.UTF16ToString((*argv[i])[:])
+	}
+	return args
+}
+
+// queryUser returns the DOMAIN\user owning a process, caching account lookups by SID
+func queryUser(handle syscall.Handle, users map[string]string) string {
+	var token syscall.Token
+	if err := syscall.OpenProcessToken(handle, syscall.TOKEN_QUERY, &token); err != nil {
+		return ""
+	}
+	defer token.Close()
+
+	tokenUser, err := token.GetTokenUser()
+	if err != nil {
+		return ""
+	}
+
+	sid, err := tokenUser.User.Sid.String()
+	if err != nil {
+		return ""
+	}
+	if user, ok := users[sid]; ok {
+		return user
+	}
+
+	account, domain, _, err := tokenUser.User.Sid.LookupAccount("")
+	user := sid
+	if err == nil {
+		user = domain + `\` + account
+	}
+	users[sid] = user
+	return user
+}
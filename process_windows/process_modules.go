@@ -0,0 +1,39 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"sort"
+
+	"gomem/process"
+)
+
+// GetModules returns the modules of the process from the PEB loader list, the list
+// EnumProcessModules reads, sorted by base address. Modules mapped without the loader
+// (manually mapped images) are not listed. When the loader list can't be read, e.g. for
+// a 32-bit target, the modules are derived from the image regions of the memory map.
+func (p *WindowsProcess) GetModules() ([]process.Module, error) {
+	loaded, err := p.LoaderModules()
+	if err != nil || len(loaded) == 0 {
+		mm, mmErr := p.GetMemoryMap()
+		if mmErr != nil {
+			return nil, mmErr
+		}
+		return process.ModulesFromMemoryMap(mm), nil
+	}
+
+	modules := make([]process.Module, 0, len(loaded))
+	for _, m := range loaded {
+		modules = append(modules, process.Module{
+			Name: m.Name,
+			Path: m.Path,
+			Base: m.Base,
+			Size: uint64(m.Size),
+		})
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Base < modules[j].Base
+	})
+	return modules, nil
+}
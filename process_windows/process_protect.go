@@ -0,0 +1,70 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"fmt"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// ProtectMemory changes the protection of the pages covering [addr, addr+size) with
+// VirtualProtectEx. perms is a permission string such as "rw-" or "r-x", the previous
+// permissions of the page at addr are returned so they can be restored. Write access is
+// granted as PAGE_READWRITE (PAGE_EXECUTE_READWRITE with execute), "---" is PAGE_NOACCESS.
+func (p *WindowsProcess) ProtectMemory(addr process.ProcessMemoryAddress, size process.ProcessMemorySize, perms string) (string, error) {
+	p.mu.RLock()
+	handle := p.handle
+	p.mu.RUnlock()
+
+	if handle == 0 {
+		return "", process.ErrProcessNotOpen
+	}
+
+	read, write, exec, err := process.ParsePerms(perms)
+	if err != nil {
+		return "", err
+	}
+
+	old, err := virtualProtectEx(handle, uintptr(addr), uintptr(size), permsToProtect(read, write, exec))
+	if err != nil {
+		return "", fmt.Errorf("VirtualProtectEx failed at %x: %v", uint64(addr), err)
+	}
+
+	// Refresh the permissions of the memory map and drop the cached queries
+	if err := p.UpdateMemoryMap(); err != nil {
+		return protectToPerms(old), err
+	}
+
+	return protectToPerms(old), nil
+}
+
+// permsToProtect returns the page protection granting the given access
+func permsToProtect(read, write, exec bool) uint32 {
+	switch {
+	case exec && write:
+		return memory_map.PAGE_EXECUTE_READWRITE
+	case exec && read:
+		return memory_map.PAGE_EXECUTE_READ
+	case exec:
+		return memory_map.PAGE_EXECUTE
+	case write:
+		return memory_map.PAGE_READWRITE
+	case read:
+		return memory_map.PAGE_READONLY
+	}
+	return memory_map.PAGE_NOACCESS
+}
+
+// protectToPerms returns the permission string of a page protection, copy-on-write counts as writable
+func protectToPerms(protect uint32) string {
+	if protect&(memory_map.PAGE_GUARD|memory_map.PAGE_NOACCESS) != 0 {
+		return "---"
+	}
+	return process.FormatPerms(
+		isReadableProtect(protect),
+		isWritableProtect(protect),
+		isExecutableProtect(protect),
+	)
+}
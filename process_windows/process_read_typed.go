@@ -3,7 +3,6 @@
 package process_windows
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"sync"
@@ -29,7 +28,7 @@ func (p *WindowsProcess) ReadUINT16(addr process.ProcessMemoryAddress) (uint16,
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint16(data), nil
+	return p.ByteOrder().Uint16(data), nil
 }
 
 // ReadUINT32 reads an unsigned 32-bit integer from the specified address
@@ -38,7 +37,7 @@ func (p *WindowsProcess) ReadUINT32(addr process.ProcessMemoryAddress) (uint32,
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint32(data), nil
+	return p.ByteOrder().Uint32(data), nil
 }
 
 // ReadUINT64 reads an unsigned 64-bit integer from the specified address
@@ -47,7 +46,7 @@ func (p *WindowsProcess) ReadUINT64(addr process.ProcessMemoryAddress) (uint64,
 	if err != nil {
 		return 0, err
 	}
-	return binary.LittleEndian.Uint64(data), nil
+	return p.ByteOrder().Uint64(data), nil
 }
 
 // ReadINT8 reads a signed 8-bit integer from the specified address
@@ -65,7 +64,7 @@ func (p *WindowsProcess) ReadINT16(addr process.ProcessMemoryAddress) (int16, er
 	if err != nil {
 		return 0, err
 	}
-	return int16(binary.LittleEndian.Uint16(data)), nil
+	return int16(p.ByteOrder().Uint16(data)), nil
 }
 
 // ReadINT32 reads a signed 32-bit integer from the specified address
@@ -74,7 +73,7 @@ func (p *WindowsProcess) ReadINT32(addr process.ProcessMemoryAddress) (int32, er
 	if err != nil {
 		return 0, err
 	}
-	return int32(binary.LittleEndian.Uint32(data)), nil
+	return int32(p.ByteOrder().Uint32(data)), nil
 }
 
 // ReadINT64 reads a signed 64-bit integer from the specified address
@@ -83,7 +82,7 @@ func (p *WindowsProcess) ReadINT64(addr process.ProcessMemoryAddress) (int64, er
 	if err != nil {
 		return 0, err
 	}
-	return int64(binary.LittleEndian.Uint64(data)), nil
+	return int64(p.ByteOrder().Uint64(data)), nil
 }
 
 // ReadFLOAT32 reads a 32-bit floating point number from the specified address
@@ -92,7 +91,7 @@ func (p *WindowsProcess) ReadFLOAT32(addr process.ProcessMemoryAddress) (float32
 	if err != nil {
 		return 0, err
 	}
-	bits := binary.LittleEndian.Uint32(data)
+	bits := p.ByteOrder().Uint32(data)
 	return *(*float32)(unsafe.Pointer(&bits)), nil
 }
 
@@ -102,7 +101,7 @@ func (p *WindowsProcess) ReadFLOAT64(addr process.ProcessMemoryAddress) (float64
 	if err != nil {
 		return 0, err
 	}
-	bits := binary.LittleEndian.Uint64(data)
+	bits := p.ByteOrder().Uint64(data)
 	return *(*float64)(unsafe.Pointer(&bits)), nil
 }
 
@@ -141,7 +140,7 @@ func (p *WindowsProcess) ReadPOINTER(addr process.ProcessMemoryAddress) (process
 	}
 
 	// Read as uint64 for 64-bit pointers
-	ptr := binary.LittleEndian.Uint64(data)
+	ptr := p.ByteOrder().Uint64(data)
 	return process.ProcessMemoryAddress(ptr), nil
 }
 
@@ -167,7 +166,7 @@ func (p *WindowsProcess) ReadBlob(addr process.ProcessMemoryAddress, size proces
 		return nil, errors.New("read less data than requested")
 	}
 
-	return process_blob.NewProcessBlob(addr, data[:size]), nil
+	return process_blob.NewProcessBlobWithByteOrder(addr, data[:size], p.ByteOrder()), nil
 }
 
 func (p *WindowsProcess) ReadPointers(base process.ProcessMemoryAddress, count int) (results []process.ProcessMemoryAddress, err error) {
@@ -186,7 +185,7 @@ func (p *WindowsProcess) ReadPointers(base process.ProcessMemoryAddress, count i
 		if offset+8 > len(data) {
 			return nil, errors.New("not enough data read for pointers")
 		}
-		ptr := binary.LittleEndian.Uint64(data[offset : offset+8])
+		ptr := p.ByteOrder().Uint64(data[offset : offset+8])
 
 		if memory_map.IsValidAddress2(ptr, p.mm) != nil {
 			results = append(results, process.ProcessMemoryAddress(ptr))
@@ -395,12 +394,17 @@ func (p *WindowsProcess) ReadBlobs(list []process.ProcessMemoryAddress, blobRead
 			combinedData, err := p.ReadBlob(g.CombinedReadStart, sizeForCombinedRead)
 
 			if err != nil {
-				wrappedErr := fmt.Errorf("%w for addresses in range [0x%X-0x%X]: %v", ErrGroupReadFailed, g.CombinedReadStart, g.CombinedReadEnd, err)
+				// The combined range has holes (guard pages, PAGE_NOACCESS), degrade to
+				// per-request reads so only the requests that hit a hole fail
 				for _, req := range g.Requests {
+					blob, err := p.ReadBlob(req.Address, req.Size)
+					if err != nil {
+						err = fmt.Errorf("%w for address 0x%X: %v", ErrGroupReadFailed, req.Address, err)
+					}
 					results[req.Index] = process.ReadBlobsResult{
 						Address: req.Address,
-						Blob:    nil, // No data if group read failed
-						Err:     wrappedErr,
+						Blob:    blob,
+						Err:     err,
 					}
 				}
 				return
@@ -441,7 +445,7 @@ func (p *WindowsProcess) ReadBlobs(list []process.ProcessMemoryAddress, blobRead
 
 				results[req.Index] = process.ReadBlobsResult{
 					Address: req.Address,
-					Blob:    process_blob.NewProcessBlob(req.Address, blobForRequest),
+					Blob:    process_blob.NewProcessBlobWithByteOrder(req.Address, blobForRequest, p.ByteOrder()),
 					Err:     nil,
 				}
 			}
@@ -452,10 +456,14 @@ func (p *WindowsProcess) ReadBlobs(list []process.ProcessMemoryAddress, blobRead
 	return results
 }
 
+// ReadPointerChain walks pointer fields at all offsets except the last,
+// which is treated as a raw byte offset into the final struct, and then
+// reads `size` bytes starting there.
 func (p *WindowsProcess) ReadPointerChain(base process.ProcessMemoryAddress, size process.ProcessMemorySize, offsets ...process.ProcessMemorySize) (process.ProcessReadOffset, error) {
-	return nil, fmt.Errorf("ReadPointerChain not implemented for WindowsProcess")
+	return process.ReadPointerChainTransform(p, nil, base, size, offsets...)
 }
 
+// ReadPointerChainDebug does the same as ReadPointerChain
 func (p *WindowsProcess) ReadPointerChainDebug(base process.ProcessMemoryAddress, size process.ProcessMemorySize, offsets ...process.ProcessMemorySize) (process.ProcessReadOffset, error) {
-	return nil, fmt.Errorf("ReadPointerChainDebug not implemented for WindowsProcess")
+	return p.ReadPointerChain(base, size, offsets...)
 }
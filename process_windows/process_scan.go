@@ -0,0 +1,111 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"context"
+	"iter"
+
+	"gomem/process"
+)
+
+// scanner returns the shared scan implementation reading this process, the scan methods
+// below delegate to it, see process.LiveScanner
+func (p *WindowsProcess) scanner() process.LiveScanner {
+	return process.LiveScanner{Target: p, Log: p.getLog()}
+}
+
+// Scan searches for the given pattern in the process memory
+// and returns all matching addresses
+func (p *WindowsProcess) Scan(aob process.AOB) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().Scan(aob)
+}
+
+// ScanParallel searches for the given pattern in parallel
+// maxdop controls the maximum degree of parallelism
+func (p *WindowsProcess) ScanParallel(aob process.AOB, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanParallel(aob, maxdop)
+}
+
+// ScanRange searches for the given pattern between start and end (exclusive)
+func (p *WindowsProcess) ScanRange(aob process.AOB, start, end process.ProcessMemoryAddress) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanRange(aob, start, end)
+}
+
+// ScanRangeParallel searches for the given pattern between start and end in parallel
+func (p *WindowsProcess) ScanRangeParallel(aob process.AOB, start, end process.ProcessMemoryAddress, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanRangeParallel(aob, start, end, maxdop)
+}
+
+// ScanCtx searches for the given pattern until ctx is done, see ScanWithOptionsCtx
+func (p *WindowsProcess) ScanCtx(ctx context.Context, aob process.AOB) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanCtx(ctx, aob)
+}
+
+// ScanWithOptions searches for the given pattern in the readable regions of the process
+// and returns the matches sorted by address, see process.LiveScanner.ScanWithOptions
+func (p *WindowsProcess) ScanWithOptions(aob process.AOB, options process.ScanOptions) ([]process.ScanMatch, error) {
+	return p.scanner().ScanWithOptions(aob, options)
+}
+
+// ScanWithOptionsCtx is ScanWithOptions stopping when ctx is done, see
+// process.LiveScanner.ScanWithOptionsCtx
+func (p *WindowsProcess) ScanWithOptionsCtx(ctx context.Context, aob process.AOB, options process.ScanOptions) ([]process.ScanMatch, error) {
+	return p.scanner().ScanWithOptionsCtx(ctx, aob, options)
+}
+
+// ScanIter searches for the given pattern and yields the addresses in order while the scan
+// runs, see ScanWithOptionsIter
+func (p *WindowsProcess) ScanIter(aob process.AOB) iter.Seq2[process.ProcessMemoryAddress, error] {
+	return p.scanner().ScanIter(aob)
+}
+
+// ScanWithOptionsIter is ScanWithOptionsCtx yielding the matches in address order while the
+// scan runs, see process.LiveScanner.ScanWithOptionsIter
+func (p *WindowsProcess) ScanWithOptionsIter(ctx context.Context, aob process.AOB, options process.ScanOptions) iter.Seq2[process.ScanMatch, error] {
+	return p.scanner().ScanWithOptionsIter(ctx, aob, options)
+}
+
+// ScanFirst searches for the first occurrence of the pattern
+func (p *WindowsProcess) ScanFirst(aob process.AOB) (process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanFirst(aob)
+}
+
+// ScanFirstParallel searches for the first occurrence of the pattern in parallel, the lowest
+// address
+func (p *WindowsProcess) ScanFirstParallel(aob process.AOB, maxdop uint) (process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanFirstParallel(aob, maxdop)
+}
+
+// ScanInteger searches for an integer value in memory
+func (p *WindowsProcess) ScanInteger(value int64, size uint) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanInteger(value, size)
+}
+
+// ScanIntegerParallel searches for an integer value in memory in parallel, encoded in the byte
+// order of the process
+func (p *WindowsProcess) ScanIntegerParallel(value int64, size uint, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanIntegerParallel(value, size, maxdop)
+}
+
+// ScanFloat searches for a float value in memory
+func (p *WindowsProcess) ScanFloat(value float64, isFloat32 bool) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanFloat(value, isFloat32)
+}
+
+// ScanFloatParallel searches for a float value in memory in parallel, encoded in the byte order
+// of the process
+func (p *WindowsProcess) ScanFloatParallel(value float64, isFloat32 bool, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanFloatParallel(value, isFloat32, maxdop)
+}
+
+// ScanString searches for a string in memory.
+// In UTF-16 mode the string is encoded as UTF-16LE and '?' matches any single code unit, see process.UTF16AOB.
+func (p *WindowsProcess) ScanString(value string, isUTF16 bool) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanString(value, isUTF16)
+}
+
+// ScanStringParallel searches for a string in memory in parallel, see ScanString
+func (p *WindowsProcess) ScanStringParallel(value string, isUTF16 bool, maxdop uint) ([]process.ProcessMemoryAddress, error) {
+	return p.scanner().ScanStringParallel(value, isUTF16, maxdop)
+}
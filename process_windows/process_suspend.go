@@ -0,0 +1,49 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"fmt"
+
+	"gomem/process"
+)
+
+var (
+	procNtSuspendProcess = modntdll.NewProc("NtSuspendProcess")
+	procNtResumeProcess  = modntdll.NewProc("NtResumeProcess")
+)
+
+// Suspend suspends every thread of the process with NtSuspendProcess, see process.Suspender.
+// Suspensions nest: each Suspend needs a matching Resume.
+func (p *WindowsProcess) Suspend() error {
+	p.mu.RLock()
+	handle := p.handle
+	p.mu.RUnlock()
+
+	if handle == 0 {
+		return process.ErrProcessNotOpen
+	}
+
+	status, _, _ := procNtSuspendProcess.Call(uintptr(handle))
+	if status != 0 {
+		return fmt.Errorf("NtSuspendProcess failed: NTSTATUS 0x%X", uint32(status))
+	}
+	return nil
+}
+
+// Resume resumes the threads suspended by Suspend with NtResumeProcess
+func (p *WindowsProcess) Resume() error {
+	p.mu.RLock()
+	handle := p.handle
+	p.mu.RUnlock()
+
+	if handle == 0 {
+		return process.ErrProcessNotOpen
+	}
+
+	status, _, _ := procNtResumeProcess.Call(uintptr(handle))
+	if status != 0 {
+		return fmt.Errorf("NtResumeProcess failed: NTSTATUS 0x%X", uint32(status))
+	}
+	return nil
+}
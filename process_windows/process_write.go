@@ -0,0 +1,168 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// protectChange is a protection temporarily changed by WriteMemory, restored after the write
+type protectChange struct {
+	addr    uintptr
+	size    uintptr
+	protect uint32
+}
+
+// SetWriteProtectFallback enables or disables the VirtualProtectEx fallback of WriteMemory.
+// When enabled, writes to committed pages that are not writable (e.g. code or read-only data)
+// temporarily change their protection, and restore it once the write is done.
+func (p *WindowsProcess) SetWriteProtectFallback(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.protectFallback = enabled
+}
+
+// WriteMemory writes data at addr with WriteProcessMemory.
+// Every page of the range must be committed and accessible. Pages that are not writable are
+// rejected unless the protection fallback is enabled, see SetWriteProtectFallback.
+func (p *WindowsProcess) WriteMemory(addr process.ProcessMemoryAddress, data []byte) error {
+	p.mu.RLock()
+	handle, fallback := p.handle, p.protectFallback
+	p.mu.RUnlock()
+
+	if handle == 0 {
+		return process.ErrProcessNotOpen
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	start := uintptr(addr)
+	end := start + uintptr(len(data))
+
+	// Check the protection of every region in the range before writing anything
+	var changes []protectChange
+	executable := false
+	for cur := start; cur < end; {
+		mbi, err := memory_map.VirtualQueryEx(handle, cur)
+		if err != nil || mbi.RegionSize == 0 || mbi.State != memory_map.MEM_COMMIT {
+			return fmt.Errorf("invalid memory address %x", cur)
+		}
+		if mbi.Protect&(memory_map.PAGE_GUARD|memory_map.PAGE_NOACCESS) != 0 {
+			return fmt.Errorf("memory region at %x is not accessible", mbi.BaseAddress)
+		}
+
+		regionEnd := min(mbi.BaseAddress+mbi.RegionSize, end)
+		if isExecutableProtect(mbi.Protect) {
+			executable = true
+		}
+
+		if !isWritableProtect(mbi.Protect) {
+			if !fallback {
+				return fmt.Errorf("memory region at %x is not writable", mbi.BaseAddress)
+			}
+			changes = append(changes, protectChange{
+				addr:    cur,
+				size:    regionEnd - cur,
+				protect: writableProtect(mbi.Protect),
+			})
+		}
+
+		cur = regionEnd
+	}
+
+	// Make the read-only parts writable, restoring them whatever the outcome of the write
+	for i, change := range changes {
+		old, err := virtualProtectEx(handle, change.addr, change.size, change.protect)
+		if err != nil {
+			restoreProtect(handle, changes[:i])
+			return fmt.Errorf("VirtualProtectEx failed at %x: %v", change.addr, err)
+		}
+		changes[i].protect = old
+	}
+	defer restoreProtect(handle, changes)
+
+	// Create a copy of the data to avoid potential modification during the write
+	dataCopy := make([]byte, len(data))
+	copy(dataCopy, data)
+
+	written, err := writeProcessMemory(handle, start, dataCopy)
+
+	if executable && written > 0 {
+		procFlushInstructionCache.Call(uintptr(handle), start, uintptr(written))
+	}
+
+	if err != nil {
+		return fmt.Errorf("WriteProcessMemory failed: %v", err)
+	}
+	if written != len(data) {
+		return fmt.Errorf("write incomplete: expected %d, got %d", len(data), written)
+	}
+
+	return nil
+}
+
+// writeProcessMemory performs a single WriteProcessMemory call and returns the number of bytes written
+func writeProcessMemory(handle syscall.Handle, addr uintptr, buf []byte) (int, error) {
+	var bytesWritten uintptr
+	ret, _, err := procWriteProcessMemory.Call(
+		uintptr(handle),
+		addr,
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+		uintptr(unsafe.Pointer(&bytesWritten)),
+	)
+
+	if ret == 0 {
+		return int(bytesWritten), err
+	}
+	return int(bytesWritten), nil
+}
+
+// virtualProtectEx changes the protection of a range and returns the previous protection
+func virtualProtectEx(handle syscall.Handle, addr, size uintptr, protect uint32) (uint32, error) {
+	var old uint32
+	ret, _, err := procVirtualProtectEx.Call(
+		uintptr(handle),
+		addr,
+		size,
+		uintptr(protect),
+		uintptr(unsafe.Pointer(&old)),
+	)
+	if ret == 0 {
+		return 0, err
+	}
+	return old, nil
+}
+
+// restoreProtect puts back the protections saved in changes
+func restoreProtect(handle syscall.Handle, changes []protectChange) {
+	for _, change := range changes {
+		virtualProtectEx(handle, change.addr, change.size, change.protect)
+	}
+}
+
+// isWritableProtect reports whether a committed region with the given protection can be written
+func isWritableProtect(protect uint32) bool {
+	return protect&(memory_map.PAGE_READWRITE|memory_map.PAGE_WRITECOPY|
+		memory_map.PAGE_EXECUTE_READWRITE|memory_map.PAGE_EXECUTE_WRITECOPY) != 0
+}
+
+// isExecutableProtect reports whether a region with the given protection can be executed
+func isExecutableProtect(protect uint32) bool {
+	return protect&(memory_map.PAGE_EXECUTE|memory_map.PAGE_EXECUTE_READ|
+		memory_map.PAGE_EXECUTE_READWRITE|memory_map.PAGE_EXECUTE_WRITECOPY) != 0
+}
+
+// writableProtect returns the writable protection matching protect, keeping execute access
+func writableProtect(protect uint32) uint32 {
+	if isExecutableProtect(protect) {
+		return memory_map.PAGE_EXECUTE_READWRITE
+	}
+	return memory_map.PAGE_READWRITE
+}
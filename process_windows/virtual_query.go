@@ -0,0 +1,105 @@
+//go:build windows
+
+package process_windows
+
+import (
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// isReadableProtect reports whether a committed region with the given protection can be read
+func isReadableProtect(protect uint32) bool {
+	if protect&(memory_map.PAGE_GUARD|memory_map.PAGE_NOACCESS) != 0 {
+		return false
+	}
+	return protect&(memory_map.PAGE_READONLY|memory_map.PAGE_READWRITE|memory_map.PAGE_WRITECOPY|
+		memory_map.PAGE_EXECUTE_READ|memory_map.PAGE_EXECUTE_READWRITE|memory_map.PAGE_EXECUTE_WRITECOPY) != 0
+}
+
+// queryCacheTTL is how long an on-demand VirtualQueryEx result is trusted
+const queryCacheTTL = time.Second
+
+// queriedRegion is a cached VirtualQueryEx result
+type queriedRegion struct {
+	handle   syscall.Handle // Process handle the region was queried with
+	start    uint64
+	end      uint64
+	readable bool
+	queried  time.Time
+}
+
+// queryCache caches VirtualQueryEx results per region, sorted by start address. It has its
+// own lock so concurrent IsValidAddress calls only hold the process lock for the snapshot.
+type queryCache struct {
+	mu      sync.Mutex
+	regions []queriedRegion
+}
+
+// lookup returns the fresh cached region of handle containing addr
+func (c *queryCache) lookup(handle syscall.Handle, addr uint64, now time.Time) (queriedRegion, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := sort.Search(len(c.regions), func(i int) bool {
+		return c.regions[i].end > addr
+	})
+	if i < len(c.regions) && c.regions[i].handle == handle && c.regions[i].start <= addr && now.Sub(c.regions[i].queried) < queryCacheTTL {
+		return c.regions[i], true
+	}
+	return queriedRegion{}, false
+}
+
+// insert adds a region, replacing the cached regions it overlaps
+func (c *queryCache) insert(region queriedRegion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	kept := c.regions[:0]
+	for _, r := range c.regions {
+		if r.end <= region.start || r.start >= region.end {
+			kept = append(kept, r)
+		}
+	}
+	kept = append(kept, region)
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].start < kept[j].start
+	})
+	c.regions = kept
+}
+
+// reset drops every cached region
+func (c *queryCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.regions = nil
+}
+
+// isValidAddressQuery checks addr with VirtualQueryEx on handle, caching the result for the
+// whole region
+func (p *WindowsProcess) isValidAddressQuery(handle syscall.Handle, addr process.ProcessMemoryAddress) bool {
+	now := time.Now()
+	if region, ok := p.queries.lookup(handle, uint64(addr), now); ok {
+		return region.readable
+	}
+
+	mbi, err := memory_map.VirtualQueryEx(handle, uintptr(addr))
+	if err != nil || mbi.RegionSize == 0 {
+		return false
+	}
+
+	region := queriedRegion{
+		handle:   handle,
+		start:    uint64(mbi.BaseAddress),
+		end:      uint64(mbi.BaseAddress) + uint64(mbi.RegionSize),
+		readable: mbi.State == memory_map.MEM_COMMIT && isReadableProtect(mbi.Protect),
+		queried:  now,
+	}
+	p.queries.insert(region)
+
+	return region.readable
+}
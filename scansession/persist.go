@@ -0,0 +1,280 @@
+package scansession
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// sessionFileVersion is the version of the format written by Save
+const sessionFileVersion = 1
+
+// Info describes a session, as stored with it by Save
+type Info struct {
+	Type       ValueType
+	Alignment  uint
+	ByteOrder  string // "little" or "big"
+	PID        process.ProcessID
+	Scans      int
+	Candidates int
+	Created    time.Time // Time of the first scan
+	Updated    time.Time // Time of the last scan
+
+	// Dropped is the number of candidates of a loaded session that did not resolve in the
+	// process it was loaded for, e.g. in a module that is not loaded anymore
+	Dropped int
+}
+
+// Info returns the description of the session
+func (s *Session) Info() Info {
+	return Info{
+		Type:       s.typ,
+		Alignment:  s.options.Alignment,
+		ByteOrder:  process.ByteOrderName(s.order),
+		PID:        s.proc.GetPID(),
+		Scans:      s.scans,
+		Candidates: s.Count(),
+		Created:    s.created,
+		Updated:    s.updated,
+		Dropped:    s.dropped,
+	}
+}
+
+// sessionFile is the JSON form of a session. Addresses are stored as AddressRefs, so
+// candidates in modules survive ASLR when the session is loaded for a restarted process;
+// other candidates are absolute and need the same process, or a dump of it.
+type sessionFile struct {
+	Version   int               `json:"version"`
+	Type      string            `json:"type"`
+	Alignment uint              `json:"alignment"`
+	ByteOrder string            `json:"byte_order"`
+	PID       process.ProcessID `json:"pid"`
+	Scans     int               `json:"scans"`
+	Created   time.Time         `json:"created"`
+	Updated   time.Time         `json:"updated"`
+	Results   []resultRecord    `json:"results,omitempty"`
+	Snapshots []snapshotRecord  `json:"snapshots,omitempty"`
+}
+
+type resultRecord struct {
+	Ref   process.AddressRef `json:"ref"`
+	Value []byte             `json:"value"`
+}
+
+type snapshotRecord struct {
+	Ref  process.AddressRef `json:"ref"`
+	Data []byte             `json:"data"`
+}
+
+// Save writes the candidates with their values at the last scan and the session info as
+// JSON. Sessions holding the snapshots of an unknown value first scan are as large as the
+// memory copied; save them after a next scan to keep the file small.
+func (s *Session) Save(w io.Writer) error {
+	if !s.scanned {
+		return ErrNoScan
+	}
+
+	mm, err := s.proc.GetMemoryMap()
+	if err != nil {
+		return err
+	}
+	refs := newRefMapper(mm)
+
+	file := sessionFile{
+		Version:   sessionFileVersion,
+		Type:      s.typ.String(),
+		Alignment: s.options.Alignment,
+		ByteOrder: process.ByteOrderName(s.order),
+		PID:       s.proc.GetPID(),
+		Scans:     s.scans,
+		Created:   s.created,
+		Updated:   s.updated,
+	}
+
+	size := s.typ.Size()
+	if s.snapshots != nil {
+		file.Snapshots = make([]snapshotRecord, len(s.snapshots))
+		for i, snap := range s.snapshots {
+			file.Snapshots[i] = snapshotRecord{Ref: refs.ref(process.ProcessMemoryAddress(snap.Address)), Data: snap.Data}
+		}
+	} else {
+		file.Results = make([]resultRecord, len(s.addresses))
+		for i, addr := range s.addresses {
+			file.Results[i] = resultRecord{Ref: refs.ref(addr), Value: s.values[i*size : (i+1)*size]}
+		}
+	}
+
+	return json.NewEncoder(w).Encode(file)
+}
+
+// SaveFile writes the session to filename, see Save
+func (s *Session) SaveFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := s.Save(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save session to %s: %w", filename, err)
+	}
+	return f.Close()
+}
+
+// Load reads a session written by Save and resolves its candidates in proc, the process
+// it was saved for, the same program restarted or a dump. The stored alignment replaces
+// options.Alignment. Candidates that don't resolve are dropped, see Info.Dropped; the
+// next scan compares the others with their stored values.
+func Load(proc process.Process, r io.Reader, options Options) (*Session, error) {
+	var file sessionFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	if file.Version != sessionFileVersion {
+		return nil, fmt.Errorf("unsupported session version %d", file.Version)
+	}
+	typ, err := ParseValueType(file.Type)
+	if err != nil {
+		return nil, err
+	}
+	order, err := process.ParseByteOrder(file.ByteOrder)
+	if err != nil {
+		return nil, err
+	}
+	if process.ByteOrderName(order) != process.ByteOrderName(process.ByteOrderOf(proc)) {
+		return nil, fmt.Errorf("session is %s-endian, the process is %s-endian",
+			file.ByteOrder, process.ByteOrderName(process.ByteOrderOf(proc)))
+	}
+
+	options.Alignment = file.Alignment
+	s := New(proc, typ, options)
+	s.scans = file.Scans
+	s.created = file.Created
+	s.updated = file.Updated
+	s.scanned = true
+
+	mm, err := proc.GetMemoryMap()
+	if err != nil {
+		return nil, err
+	}
+	resolver := newRefResolver(mm)
+
+	size := typ.Size()
+	if file.Snapshots != nil {
+		s.snapshots = []snapshot{}
+		for _, record := range file.Snapshots {
+			addr, err := resolver.resolve(record.Ref)
+			if err != nil {
+				s.dropped += snapshotCount(uint64(addr), len(record.Data), size, s.options.Alignment)
+				continue
+			}
+			s.snapshots = append(s.snapshots, snapshot{Address: uint64(addr), Data: record.Data})
+		}
+		return s, nil
+	}
+
+	s.addresses = make([]process.ProcessMemoryAddress, 0, len(file.Results))
+	s.values = make([]byte, 0, len(file.Results)*size)
+	for _, record := range file.Results {
+		addr, err := resolver.resolve(record.Ref)
+		if err != nil || len(record.Value) != size {
+			s.dropped++
+			continue
+		}
+		s.addresses = append(s.addresses, addr)
+		s.values = append(s.values, record.Value...)
+	}
+	return s, nil
+}
+
+// LoadFile reads a session from filename, see Load
+func LoadFile(proc process.Process, filename string, options Options) (*Session, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s, err := Load(proc, f, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session from %s: %w", filename, err)
+	}
+	return s, nil
+}
+
+// snapshotCount returns the number of aligned candidates in size bytes at addr
+func snapshotCount(addr uint64, length, size int, alignment uint) int {
+	step := uint64(max(alignment, 1))
+	start := int((step - addr%step) % step)
+	if start+size > length {
+		return 0
+	}
+	return (length-size-start)/int(step) + 1
+}
+
+// refMapper turns sorted addresses into AddressRefs, describing each region once
+type refMapper struct {
+	mm     []memory_map.MemoryMapItem
+	region *memory_map.MemoryMapItem
+	module string
+	base   uint64
+}
+
+func newRefMapper(mm []memory_map.MemoryMapItem) *refMapper {
+	return &refMapper{mm: mm}
+}
+
+func (m *refMapper) ref(addr process.ProcessMemoryAddress) process.AddressRef {
+	a := uint64(addr)
+	if m.region == nil || a < m.region.Address || a >= m.region.Address+uint64(m.region.Size) {
+		m.region = memory_map.IsValidAddress2(a, m.mm)
+		if m.region == nil {
+			return process.AddressRef{Offset: a}
+		}
+		ref := process.NewAddressRef(addr, m.mm)
+		m.module, m.base = ref.Module, a-ref.Offset
+	}
+	if m.module == "" {
+		return process.AddressRef{Offset: a}
+	}
+	return process.ModuleRef(m.module, a-m.base)
+}
+
+// refResolver resolves AddressRefs, looking up each module base once
+type refResolver struct {
+	mm    []memory_map.MemoryMapItem
+	bases map[string]moduleBase
+}
+
+type moduleBase struct {
+	base  uint64
+	found bool
+}
+
+func newRefResolver(mm []memory_map.MemoryMapItem) *refResolver {
+	return &refResolver{mm: mm, bases: make(map[string]moduleBase)}
+}
+
+func (r *refResolver) resolve(ref process.AddressRef) (process.ProcessMemoryAddress, error) {
+	if ref.IsChain() {
+		return 0, errors.New("pointer chain candidates are not supported")
+	}
+	if ref.Module == "" {
+		return process.ProcessMemoryAddress(ref.Offset), nil
+	}
+
+	base, ok := r.bases[ref.Module]
+	if !ok {
+		base.base, base.found = memory_map.ModuleBase(ref.Module, r.mm)
+		r.bases[ref.Module] = base
+	}
+	if !base.found {
+		return 0, fmt.Errorf("module %s not found", ref.Module)
+	}
+	return process.ProcessMemoryAddress(base.base + ref.Offset), nil
+}
@@ -0,0 +1,459 @@
+// Package scansession finds values whose address is unknown by narrowing down scans, as
+// memory editors do: a first scan captures every candidate (the addresses holding a value,
+// or the whole writable memory when the value is unknown), then each next scan keeps the
+// candidates whose value changed, increased, decreased, ... since the previous scan.
+//
+//	s := scansession.New(proc, scansession.Int32, scansession.Options{})
+//	s.FirstScanUnknown()
+//	// ... let the value decrease in the target
+//	s.NextScan(scansession.Decreased, nil)
+//	// ... let it increase by 5
+//	s.NextScan(scansession.IncreasedBy, s.Value(5))
+//	for _, r := range s.Results(0, 10) {
+//		fmt.Println(r.Address.ToString(), s.Format(r.Value))
+//	}
+package scansession
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// ErrNoScan is returned by NextScan before a first scan
+var ErrNoScan = errors.New("no first scan")
+
+// Condition selects the candidates a next scan keeps, by comparing the current value of
+// each candidate with its value at the previous scan or with an operand
+type Condition int
+
+const (
+	Changed     Condition = iota // Value differs from the previous scan
+	Unchanged                    // Value is the same as at the previous scan
+	Increased                    // Value is greater than at the previous scan
+	Decreased                    // Value is less than at the previous scan
+	IncreasedBy                  // Value is the previous value plus the operand
+	DecreasedBy                  // Value is the previous value minus the operand
+	Exact                        // Value equals the operand
+)
+
+var conditionNames = [...]string{"changed", "unchanged", "increased", "decreased", "increased-by", "decreased-by", "exact"}
+
+func (c Condition) String() string {
+	if c >= 0 && int(c) < len(conditionNames) {
+		return conditionNames[c]
+	}
+	return fmt.Sprintf("Condition(%d)", int(c))
+}
+
+// ParseCondition parses a condition name as returned by Condition.String
+func ParseCondition(s string) (Condition, error) {
+	for i, name := range conditionNames {
+		if s == name {
+			return Condition(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown condition: %q", s)
+}
+
+// hasOperand reports whether the condition compares with an operand
+func (c Condition) hasOperand() bool {
+	return c == IncreasedBy || c == DecreasedBy || c == Exact
+}
+
+// Options configures a session
+type Options struct {
+	// Filter restricts the first scan to the regions it accepts, nil scans the writable
+	// regions, where values that change live
+	Filter memory_map.RegionFilter
+
+	// Alignment only considers addresses that are multiples of Alignment, 0 aligns to the
+	// size of the value type and 1 considers every address
+	Alignment uint
+
+	// MaxDOP is the degree of parallelism of the first scan for a known value
+	MaxDOP uint
+
+	// Suspend pauses the target during each scan, see process.Suspend
+	Suspend bool
+}
+
+// Result is a candidate address and its value at the last scan
+type Result struct {
+	Address process.ProcessMemoryAddress
+	Value   []byte
+}
+
+// snapshot is a copy of a memory range taken by an unknown value first scan, every aligned
+// address in it is a candidate
+type snapshot struct {
+	Address uint64
+	Data    []byte
+}
+
+// Session is a value search refined over several scans. It is not safe for concurrent use.
+type Session struct {
+	proc    process.Process
+	typ     ValueType
+	options Options
+	order   binary.ByteOrder
+
+	// Candidates are snapshots until the first next scan after FirstScanUnknown, then
+	// addresses with their values, size bytes per address
+	snapshots []snapshot
+	addresses []process.ProcessMemoryAddress
+	values    []byte
+	scanned   bool
+
+	scans   int
+	created time.Time // First scan
+	updated time.Time // Last scan
+	dropped int       // Candidates of a loaded session that did not resolve
+}
+
+// maxSnapshotChunk is the largest single read of an unknown value scan
+const maxSnapshotChunk = 16 << 20
+
+// maxSpanGap is the largest gap between candidates read together by a next scan
+const maxSpanGap = 4096
+
+// maxSpanSize is the largest single read of a next scan
+const maxSpanSize = 1 << 20
+
+// New creates a session searching proc for values of type typ
+func New(proc process.Process, typ ValueType, options Options) *Session {
+	if options.Alignment == 0 {
+		options.Alignment = uint(typ.Size())
+	}
+	if options.Filter == nil {
+		options.Filter = func(item memory_map.MemoryMapItem) bool { return item.IsWritable() }
+	}
+	return &Session{
+		proc:    proc,
+		typ:     typ,
+		options: options,
+		order:   process.ByteOrderOf(proc),
+	}
+}
+
+// Type returns the value type of the session
+func (s *Session) Type() ValueType {
+	return s.typ
+}
+
+// Scans returns the number of scans made, the first scan included
+func (s *Session) Scans() int {
+	return s.scans
+}
+
+// Value returns the bytes of v as a value of the session type, see ValueType.Encode
+func (s *Session) Value(v float64) []byte {
+	return s.typ.Encode(v, s.order)
+}
+
+// ParseValue parses a value of the session type, see ValueType.ParseValue
+func (s *Session) ParseValue(str string) ([]byte, error) {
+	return s.typ.ParseValue(str, s.order)
+}
+
+// Format returns a value of the session type as a string
+func (s *Session) Format(value []byte) string {
+	return s.typ.Format(value, s.order)
+}
+
+// Reset discards the candidates, the next scan must be a first scan
+func (s *Session) Reset() {
+	s.snapshots = nil
+	s.addresses = nil
+	s.values = nil
+	s.scanned = false
+	s.scans = 0
+	s.dropped = 0
+}
+
+// FirstScan starts the session over with the addresses holding value
+func (s *Session) FirstScan(value []byte) error {
+	if !s.typ.valid() {
+		return fmt.Errorf("invalid value type %v", s.typ)
+	}
+	if len(value) != s.typ.Size() {
+		return fmt.Errorf("%s value must be %d bytes, got %d", s.typ, s.typ.Size(), len(value))
+	}
+
+	var matches []process.ScanMatch
+	err := s.whileSuspended(func() error {
+		if err := s.proc.UpdateMemoryMap(); err != nil {
+			return err
+		}
+		var err error
+		matches, err = s.proc.ScanWithOptions(process.AOB{Pattern: value}, process.ScanOptions{
+			MaxDOP:    s.options.MaxDOP,
+			Filter:    s.options.Filter,
+			Alignment: s.options.Alignment,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("first scan failed: %w", err)
+	}
+
+	s.Reset()
+	s.addresses = process.ScanMatchAddresses(matches)
+	s.values = make([]byte, 0, len(matches)*len(value))
+	for range matches {
+		s.values = append(s.values, value...)
+	}
+	s.scanned = true
+	s.touch()
+	return nil
+}
+
+// FirstScanUnknown starts the session over with every aligned address of the regions
+// accepted by the filter, for values whose current value is not known. It copies these
+// regions, which may take as much memory as the target uses.
+func (s *Session) FirstScanUnknown() error {
+	if !s.typ.valid() {
+		return fmt.Errorf("invalid value type %v", s.typ)
+	}
+
+	var snapshots []snapshot
+	err := s.whileSuspended(func() error {
+		if err := s.proc.UpdateMemoryMap(); err != nil {
+			return err
+		}
+		mm, err := s.proc.GetMemoryMap()
+		if err != nil {
+			return err
+		}
+
+		for _, region := range mm {
+			if !region.IsReadable() || !s.options.Filter(region) {
+				continue
+			}
+			for offset := uint64(0); offset < uint64(region.Size); offset += maxSnapshotChunk {
+				addr := region.Address + offset
+				size := min(uint64(region.Size)-offset, maxSnapshotChunk)
+				data, err := s.proc.ReadMemory(process.ProcessMemoryAddress(addr), process.ProcessMemorySize(size))
+				if err != nil && !(errors.Is(err, process.ErrPartialRead) && len(data) > 0) {
+					continue
+				}
+				snapshots = append(snapshots, snapshot{Address: addr, Data: data})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("first scan failed: %w", err)
+	}
+
+	s.Reset()
+	s.snapshots = snapshots
+	s.scanned = true
+	s.touch()
+	return nil
+}
+
+// NextScan keeps the candidates whose current value meets cond, and records their
+// current values for the next scan. operand is the value of IncreasedBy, DecreasedBy
+// and Exact, nil for the other conditions. Candidates that can't be read anymore are
+// dropped.
+func (s *Session) NextScan(cond Condition, operand []byte) error {
+	if !s.scanned {
+		return ErrNoScan
+	}
+	if cond < Changed || cond > Exact {
+		return fmt.Errorf("invalid condition %v", cond)
+	}
+	if cond.hasOperand() && len(operand) != s.typ.Size() {
+		return fmt.Errorf("%v needs a %s operand of %d bytes, got %d", cond, s.typ, s.typ.Size(), len(operand))
+	}
+
+	err := s.whileSuspended(func() error {
+		if s.snapshots != nil {
+			s.nextFromSnapshots(cond, operand)
+		} else {
+			s.nextFromResults(cond, operand)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("next scan failed: %w", err)
+	}
+	s.touch()
+	return nil
+}
+
+// nextFromSnapshots filters every aligned address of the snapshots and replaces them with
+// the matching addresses
+func (s *Session) nextFromSnapshots(cond Condition, operand []byte) {
+	size := s.typ.Size()
+	addresses := []process.ProcessMemoryAddress{}
+	var values []byte
+
+	for _, snap := range s.snapshots {
+		current, err := s.proc.ReadMemory(process.ProcessMemoryAddress(snap.Address), process.ProcessMemorySize(len(snap.Data)))
+		if err != nil && !(errors.Is(err, process.ErrPartialRead) && len(current) > 0) {
+			continue
+		}
+		n := min(len(current), len(snap.Data))
+
+		for offset := s.alignedStart(snap.Address); offset+size <= n; offset += int(s.options.Alignment) {
+			previous, value := snap.Data[offset:offset+size], current[offset:offset+size]
+			if s.matches(cond, previous, value, operand) {
+				addresses = append(addresses, process.ProcessMemoryAddress(snap.Address+uint64(offset)))
+				values = append(values, value...)
+			}
+		}
+	}
+
+	s.snapshots = nil
+	s.addresses = addresses
+	s.values = values
+}
+
+// nextFromResults rereads the candidate addresses, grouped into spans, and keeps the matching ones
+func (s *Session) nextFromResults(cond Condition, operand []byte) {
+	size := s.typ.Size()
+	addresses := s.addresses[:0]
+	values := s.values[:0]
+
+	for start := 0; start < len(s.addresses); {
+		// Group the following candidates that are close enough into one read
+		end := start + 1
+		first := uint64(s.addresses[start])
+		for end < len(s.addresses) {
+			addr := uint64(s.addresses[end])
+			last := uint64(s.addresses[end-1])
+			if addr-last > maxSpanGap || addr+uint64(size)-first > maxSpanSize {
+				break
+			}
+			end++
+		}
+
+		spanSize := uint64(s.addresses[end-1]) + uint64(size) - first
+		data, err := s.proc.ReadMemory(process.ProcessMemoryAddress(first), process.ProcessMemorySize(spanSize))
+		if errors.Is(err, process.ErrPartialRead) {
+			err = nil
+		}
+
+		for i := start; i < end && err == nil; i++ {
+			offset := int(uint64(s.addresses[i]) - first)
+			if offset+size > len(data) {
+				break
+			}
+			// Values are compacted in place, they are always behind the read position
+			previous, value := s.values[i*size:(i+1)*size], data[offset:offset+size]
+			if s.matches(cond, previous, value, operand) {
+				addresses = append(addresses, s.addresses[i])
+				values = append(values, value...)
+			}
+		}
+		start = end
+	}
+
+	s.addresses = addresses
+	s.values = values
+}
+
+// matches reports whether value meets cond given the previous value
+func (s *Session) matches(cond Condition, previous, value, operand []byte) bool {
+	switch cond {
+	case Changed:
+		return s.typ.compare(value, previous, s.order) != 0
+	case Unchanged:
+		return s.typ.compare(value, previous, s.order) == 0
+	case Increased:
+		return s.typ.compare(value, previous, s.order) == 1
+	case Decreased:
+		return s.typ.compare(value, previous, s.order) == -1
+	case IncreasedBy:
+		return s.typ.differsBy(previous, value, operand, s.order)
+	case DecreasedBy:
+		return s.typ.differsBy(value, previous, operand, s.order)
+	case Exact:
+		return s.typ.compare(value, operand, s.order) == 0
+	}
+	return false
+}
+
+// alignedStart returns the offset of the first aligned address of a snapshot at addr
+func (s *Session) alignedStart(addr uint64) int {
+	alignment := uint64(max(s.options.Alignment, 1))
+	return int((alignment - addr%alignment) % alignment)
+}
+
+// Count returns the number of candidates
+func (s *Session) Count() int {
+	if s.snapshots == nil {
+		return len(s.addresses)
+	}
+
+	count := 0
+	for _, snap := range s.snapshots {
+		count += snapshotCount(snap.Address, len(snap.Data), s.typ.Size(), s.options.Alignment)
+	}
+	return count
+}
+
+// Results returns up to limit candidates from the index offset on, sorted by address, with
+// their values at the last scan. A limit of 0 returns every candidate from offset on.
+func (s *Session) Results(offset, limit int) []Result {
+	var results []Result
+	add := func(addr process.ProcessMemoryAddress, value []byte) bool {
+		if offset > 0 {
+			offset--
+			return true
+		}
+		results = append(results, Result{Address: addr, Value: append([]byte(nil), value...)})
+		return limit == 0 || len(results) < limit
+	}
+
+	size := s.typ.Size()
+	if s.snapshots == nil {
+		for i, addr := range s.addresses {
+			if !add(addr, s.values[i*size:(i+1)*size]) {
+				break
+			}
+		}
+		return results
+	}
+
+	for _, snap := range s.snapshots {
+		for i := s.alignedStart(snap.Address); i+size <= len(snap.Data); i += int(s.options.Alignment) {
+			if !add(process.ProcessMemoryAddress(snap.Address+uint64(i)), snap.Data[i:i+size]) {
+				return results
+			}
+		}
+	}
+	return results
+}
+
+// Addresses returns the candidate addresses after a next scan, and nil while the candidates
+// are the snapshots of an unknown value first scan
+func (s *Session) Addresses() []process.ProcessMemoryAddress {
+	if s.snapshots != nil {
+		return nil
+	}
+	return append([]process.ProcessMemoryAddress(nil), s.addresses...)
+}
+
+// whileSuspended runs fn with the target suspended if the options ask for it
+func (s *Session) whileSuspended(fn func() error) error {
+	if !s.options.Suspend {
+		return fn()
+	}
+	return process.WhileSuspended(s.proc, fn)
+}
+
+// touch counts a scan
+func (s *Session) touch() {
+	s.updated = time.Now()
+	if s.scans == 0 {
+		s.created = s.updated
+	}
+	s.scans++
+}
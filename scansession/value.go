@@ -0,0 +1,254 @@
+package scansession
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ValueType is the type of the values a session scans for
+type ValueType int
+
+const (
+	Int8 ValueType = iota
+	Int16
+	Int32
+	Int64
+	Uint8
+	Uint16
+	Uint32
+	Uint64
+	Float32
+	Float64
+)
+
+var valueTypeNames = [...]string{"int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64", "float32", "float64"}
+
+func (t ValueType) String() string {
+	if t >= 0 && int(t) < len(valueTypeNames) {
+		return valueTypeNames[t]
+	}
+	return fmt.Sprintf("ValueType(%d)", int(t))
+}
+
+// ParseValueType parses a type name such as "int32", "u16" or "float", case-insensitive
+func ParseValueType(s string) (ValueType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "int8", "i8", "byte":
+		return Int8, nil
+	case "int16", "i16", "short":
+		return Int16, nil
+	case "int32", "i32", "int":
+		return Int32, nil
+	case "int64", "i64", "long":
+		return Int64, nil
+	case "uint8", "u8":
+		return Uint8, nil
+	case "uint16", "u16":
+		return Uint16, nil
+	case "uint32", "u32":
+		return Uint32, nil
+	case "uint64", "u64":
+		return Uint64, nil
+	case "float32", "f32", "float":
+		return Float32, nil
+	case "float64", "f64", "double":
+		return Float64, nil
+	}
+	return 0, fmt.Errorf("unknown value type: %q", s)
+}
+
+// Size returns the size of a value in bytes
+func (t ValueType) Size() int {
+	switch t {
+	case Int8, Uint8:
+		return 1
+	case Int16, Uint16:
+		return 2
+	case Int32, Uint32, Float32:
+		return 4
+	}
+	return 8
+}
+
+// IsFloat reports whether t is a floating point type
+func (t ValueType) IsFloat() bool {
+	return t == Float32 || t == Float64
+}
+
+// IsSigned reports whether t is a signed integer type
+func (t ValueType) IsSigned() bool {
+	return t <= Int64
+}
+
+// valid reports whether t is one of the defined types
+func (t ValueType) valid() bool {
+	return t >= Int8 && t <= Float64
+}
+
+// raw reads the bits of a value of type t from data in the byte order order
+func (t ValueType) raw(data []byte, order binary.ByteOrder) uint64 {
+	switch t.Size() {
+	case 1:
+		return uint64(data[0])
+	case 2:
+		return uint64(order.Uint16(data))
+	case 4:
+		return uint64(order.Uint32(data))
+	}
+	return order.Uint64(data)
+}
+
+// put returns the low bits of a value of type t in the byte order order
+func (t ValueType) put(bits uint64, order binary.ByteOrder) []byte {
+	data := make([]byte, t.Size())
+	switch t.Size() {
+	case 1:
+		data[0] = byte(bits)
+	case 2:
+		order.PutUint16(data, uint16(bits))
+	case 4:
+		order.PutUint32(data, uint32(bits))
+	default:
+		order.PutUint64(data, bits)
+	}
+	return data
+}
+
+// Float decodes a value of type t as a float64, for display and float comparisons
+func (t ValueType) Float(data []byte, order binary.ByteOrder) float64 {
+	bits := t.raw(data, order)
+	switch {
+	case t == Float32:
+		return float64(math.Float32frombits(uint32(bits)))
+	case t == Float64:
+		return math.Float64frombits(bits)
+	case t.IsSigned():
+		return float64(t.signed(bits))
+	}
+	return float64(bits)
+}
+
+// signed sign-extends the bits of a signed integer of type t
+func (t ValueType) signed(bits uint64) int64 {
+	shift := 64 - 8*t.Size()
+	return int64(bits<<shift) >> shift
+}
+
+// Encode returns the bytes of v converted to type t in the byte order order. Integers are
+// truncated to the size of t; 64-bit integers beyond 2^53 don't survive the float64.
+func (t ValueType) Encode(v float64, order binary.ByteOrder) []byte {
+	var bits uint64
+	switch {
+	case t == Float32:
+		bits = uint64(math.Float32bits(float32(v)))
+	case t == Float64:
+		bits = math.Float64bits(v)
+	case t.IsSigned() || v < 0:
+		bits = uint64(int64(v))
+	default:
+		bits = uint64(v)
+	}
+
+	return t.put(bits, order)
+}
+
+// Format returns a value of type t as a decimal string
+func (t ValueType) Format(data []byte, order binary.ByteOrder) string {
+	if len(data) < t.Size() {
+		return "?"
+	}
+	bits := t.raw(data, order)
+	switch {
+	case t == Float32:
+		return strconv.FormatFloat(float64(math.Float32frombits(uint32(bits))), 'g', -1, 32)
+	case t == Float64:
+		return strconv.FormatFloat(math.Float64frombits(bits), 'g', -1, 64)
+	case t.IsSigned():
+		return strconv.FormatInt(t.signed(bits), 10)
+	}
+	return strconv.FormatUint(bits, 10)
+}
+
+// ParseValue parses a decimal or 0x prefixed hex number for t: integers are parsed exactly,
+// so ParseValue(Uint64, "0xFFFFFFFFFFFFFFFF") works where Encode would round
+func (t ValueType) ParseValue(s string, order binary.ByteOrder) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if t.IsFloat() {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", t, s)
+		}
+		return t.Encode(v, order), nil
+	}
+
+	var bits uint64
+	if t.IsSigned() || strings.HasPrefix(s, "-") {
+		v, err := strconv.ParseInt(s, 0, 8*t.Size())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", t, s)
+		}
+		bits = uint64(v)
+	} else {
+		v, err := strconv.ParseUint(s, 0, 8*t.Size())
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", t, s)
+		}
+		bits = v
+	}
+
+	return t.put(bits, order), nil
+}
+
+// compare returns -1, 0 or 1 as a is less than, equal to or greater than b. Comparisons
+// with NaN return 2, they match no condition but Changed.
+func (t ValueType) compare(a, b []byte, order binary.ByteOrder) int {
+	x, y := t.raw(a, order), t.raw(b, order)
+	switch {
+	case t.IsFloat():
+		fx, fy := t.Float(a, order), t.Float(b, order)
+		switch {
+		case math.IsNaN(fx) || math.IsNaN(fy):
+			return 2
+		case fx < fy:
+			return -1
+		case fx > fy:
+			return 1
+		}
+		return 0
+	case t.IsSigned():
+		sx, sy := t.signed(x), t.signed(y)
+		switch {
+		case sx < sy:
+			return -1
+		case sx > sy:
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// differsBy reports whether to - from equals delta: exactly, wrapping around, for
+// integers, and within a relative tolerance for floats, whose arithmetic rounds
+func (t ValueType) differsBy(from, to, delta []byte, order binary.ByteOrder) bool {
+	if t.IsFloat() {
+		a, b, d := t.Float(from, order), t.Float(to, order), t.Float(delta, order)
+		tolerance := 1e-9
+		if t == Float32 {
+			tolerance = 1e-5
+		}
+		return math.Abs((b-a)-d) <= tolerance*max(1, math.Abs(a), math.Abs(b))
+	}
+
+	mask := ^uint64(0) >> (64 - 8*t.Size())
+	return (t.raw(to, order)-t.raw(from, order))&mask == t.raw(delta, order)&mask
+}
@@ -0,0 +1,228 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// StrideCluster is a run of scan hits spaced by a constant stride inside one region,
+// strong evidence of an array of structs holding the scanned value at the same field offset
+type StrideCluster struct {
+	Base    process.ProcessMemoryAddress   // First hit; the array starts at Base minus the offset of the matched field
+	Stride  uint64                         // Distance between hits, the probable element size
+	Count   int                            // Number of elements spanned from the first to the last hit
+	Hits    []process.ProcessMemoryAddress // Hits belonging to the run, sorted
+	Region  *memory_map.MemoryMapItem      // Region containing the run, nil without a memory map
+	Missing int                            // Elements of the span without a hit
+}
+
+// String returns a one line summary of the cluster
+func (c StrideCluster) String() string {
+	s := fmt.Sprintf("0x%X stride 0x%X x %d (%d hits)", uint64(c.Base), c.Stride, c.Count, len(c.Hits))
+	if c.Region != nil && c.Region.Path != "" {
+		s += " in " + c.Region.Path
+	}
+	return s
+}
+
+// End returns the address just past the last element of the run
+func (c StrideCluster) End() process.ProcessMemoryAddress {
+	return c.Base + process.ProcessMemoryAddress(uint64(c.Count)*c.Stride)
+}
+
+// StrideAnalyzer holds configuration for ClusterByStride
+type StrideAnalyzer struct {
+	MinCount   int    // Minimum number of hits in a cluster
+	MinStride  uint64 // Smallest element size considered
+	MaxStride  uint64 // Largest element size considered
+	MaxMissing int    // Consecutive elements without a hit tolerated inside a run
+	Neighbors  int    // Number of following hits paired with each hit to collect stride candidates
+}
+
+// StrideOption is a function that configures ClusterByStride
+type StrideOption func(*StrideAnalyzer)
+
+// WithMinCount sets the minimum number of hits in a cluster (default 3)
+func WithMinCount(count int) StrideOption {
+	return func(s *StrideAnalyzer) {
+		s.MinCount = count
+	}
+}
+
+// WithStrideRange sets the range of element sizes considered (default 4 to 0x10000)
+func WithStrideRange(minStride, maxStride uint64) StrideOption {
+	return func(s *StrideAnalyzer) {
+		s.MinStride = minStride
+		s.MaxStride = maxStride
+	}
+}
+
+// WithMaxMissing tolerates up to count consecutive elements without a hit inside a run,
+// for arrays whose scanned value differs in a few elements (default 0)
+func WithMaxMissing(count int) StrideOption {
+	return func(s *StrideAnalyzer) {
+		s.MaxMissing = count
+	}
+}
+
+// ClusterByStride groups scan hits into runs of constant stride within a region and returns
+// them sorted by number of hits, largest first. Each hit belongs to at most one cluster;
+// strides supported by the most hit pairs are claimed first, so an array with stride 0x250
+// is reported as such rather than as two interleaved arrays with stride 0x4A0.
+//
+// mm may be nil, runs are then only bounded by the stride range.
+func ClusterByStride(hits []process.ProcessMemoryAddress, mm []memory_map.MemoryMapItem, options ...StrideOption) []StrideCluster {
+	s := &StrideAnalyzer{
+		MinCount:  3,       // Default
+		MinStride: 4,       // Default
+		MaxStride: 0x10000, // Default
+		Neighbors: 8,       // Default
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+	s.MinCount = max(s.MinCount, 2)
+	s.MinStride = max(s.MinStride, 1)
+
+	var clusters []StrideCluster
+	for _, group := range groupHitsByRegion(hits, mm) {
+		clusters = append(clusters, s.cluster(group.hits, group.region)...)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].Hits) != len(clusters[j].Hits) {
+			return len(clusters[i].Hits) > len(clusters[j].Hits)
+		}
+		return clusters[i].Base < clusters[j].Base
+	})
+
+	return clusters
+}
+
+// regionHits are the sorted, unique hits inside one region
+type regionHits struct {
+	region *memory_map.MemoryMapItem
+	hits   []process.ProcessMemoryAddress
+}
+
+// groupHitsByRegion splits hits by the region containing them, hits outside the memory map
+// are dropped. Without a memory map all hits form one group.
+func groupHitsByRegion(hits []process.ProcessMemoryAddress, mm []memory_map.MemoryMapItem) []regionHits {
+	sorted := slices.Clone(hits)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	if mm == nil {
+		return []regionHits{{hits: sorted}}
+	}
+
+	var groups []regionHits
+	for _, hit := range sorted {
+		region := memory_map.IsValidAddress2(uint64(hit), mm)
+		if region == nil {
+			continue
+		}
+		if n := len(groups); n > 0 && groups[n-1].region.Address == region.Address {
+			groups[n-1].hits = append(groups[n-1].hits, hit)
+			continue
+		}
+		groups = append(groups, regionHits{region: region, hits: []process.ProcessMemoryAddress{hit}})
+	}
+	return groups
+}
+
+// cluster extracts the runs of one region
+func (s *StrideAnalyzer) cluster(hits []process.ProcessMemoryAddress, region *memory_map.MemoryMapItem) []StrideCluster {
+	if len(hits) < s.MinCount {
+		return nil
+	}
+
+	// Count the hit pairs supporting each stride
+	support := make(map[uint64]int)
+	for i := range hits {
+		for j := i + 1; j < len(hits) && j <= i+s.Neighbors; j++ {
+			d := uint64(hits[j] - hits[i])
+			if d > s.MaxStride {
+				break
+			}
+			if d >= s.MinStride {
+				support[d]++
+			}
+		}
+	}
+
+	strides := make([]uint64, 0, len(support))
+	for stride, pairs := range support {
+		if pairs >= s.MinCount-1 {
+			strides = append(strides, stride)
+		}
+	}
+	sort.Slice(strides, func(i, j int) bool {
+		if support[strides[i]] != support[strides[j]] {
+			return support[strides[i]] > support[strides[j]]
+		}
+		return strides[i] < strides[j]
+	})
+
+	index := make(map[process.ProcessMemoryAddress]bool, len(hits))
+	for _, hit := range hits {
+		index[hit] = true
+	}
+	used := make(map[process.ProcessMemoryAddress]bool)
+
+	var clusters []StrideCluster
+	for _, stride := range strides {
+		for _, start := range hits {
+			if used[start] {
+				continue
+			}
+			// Only start runs at their first element
+			if prev := start - process.ProcessMemoryAddress(stride); start >= process.ProcessMemoryAddress(stride) && index[prev] && !used[prev] {
+				continue
+			}
+
+			run := s.follow(start, stride, index, used)
+			if len(run) < s.MinCount {
+				continue
+			}
+
+			count := int(uint64(run[len(run)-1]-run[0])/stride) + 1
+			for _, hit := range run {
+				used[hit] = true
+			}
+			clusters = append(clusters, StrideCluster{
+				Base:    run[0],
+				Stride:  stride,
+				Count:   count,
+				Hits:    run,
+				Region:  region,
+				Missing: count - len(run),
+			})
+		}
+	}
+
+	return clusters
+}
+
+// follow collects the unused hits at start + k*stride, tolerating MaxMissing consecutive gaps
+func (s *StrideAnalyzer) follow(start process.ProcessMemoryAddress, stride uint64, index, used map[process.ProcessMemoryAddress]bool) []process.ProcessMemoryAddress {
+	run := []process.ProcessMemoryAddress{start}
+	missing := 0
+	for addr := start + process.ProcessMemoryAddress(stride); addr > start; addr += process.ProcessMemoryAddress(stride) {
+		if index[addr] && !used[addr] {
+			run = append(run, addr)
+			missing = 0
+			continue
+		}
+		missing++
+		if missing > s.MaxMissing {
+			break
+		}
+	}
+	return run
+}
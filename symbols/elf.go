@@ -0,0 +1,109 @@
+package symbols
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gomem/process"
+)
+
+// openModuleFile opens the file backing a module, through /proc/[pid]/root when the
+// process runs in another mount namespace (containers) and the path does not resolve here
+func openModuleFile(proc process.Process, path string) (*os.File, error) {
+	f, err := os.Open(path)
+	if err == nil {
+		return f, nil
+	}
+	if alt, altErr := os.Open(filepath.Join("/proc", fmt.Sprint(proc.GetPID()), "root", path)); altErr == nil {
+		return alt, nil
+	}
+	return nil, err
+}
+
+// elfSymbols reads the dynamic symbol table of an ELF module from its file, plus the static
+// symbol table when the file is not stripped, relocated to where the module is mapped
+func elfSymbols(proc process.Process, m process.Module) ([]Symbol, error) {
+	f, err := openModuleFile(proc, m.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ef, err := elf.NewFile(f)
+	if err != nil {
+		return nil, err
+	}
+	defer ef.Close()
+
+	bias, err := loadBias(ef, m.Base)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamic, err := ef.DynamicSymbols()
+	if err != nil && err != elf.ErrNoSymbols {
+		return nil, err
+	}
+	// Not stripped binaries also carry their internal symbols
+	static, err := ef.Symbols()
+	if err != nil && err != elf.ErrNoSymbols {
+		return nil, err
+	}
+
+	var symbols []Symbol
+	for _, s := range append(dynamic, static...) {
+		if s.Name == "" || s.Value == 0 || s.Section == elf.SHN_UNDEF || s.Section == elf.SHN_ABS {
+			continue
+		}
+
+		var kind SymbolKind
+		switch elf.ST_TYPE(s.Info) {
+		case elf.STT_FUNC:
+			kind = SymbolFunc
+		case elf.STT_OBJECT, elf.STT_COMMON:
+			kind = SymbolObject
+		case elf.STT_GNU_IFUNC:
+			kind = SymbolIFunc
+		case elf.STT_NOTYPE:
+			kind = SymbolOther
+		default:
+			// Sections, files and TLS offsets are not addresses
+			continue
+		}
+
+		symbols = append(symbols, Symbol{
+			Name:    s.Name,
+			Module:  m.Name,
+			Address: process.ProcessMemoryAddress(s.Value + bias),
+			Size:    s.Size,
+			Kind:    kind,
+		})
+	}
+
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols")
+	}
+	return symbols, nil
+}
+
+// loadBias returns the difference between the addresses a module is mapped at and the
+// virtual addresses of its ELF file, 0 for executables loaded at their link address. The
+// lowest mapping of the module is the page of its first PT_LOAD segment.
+func loadBias(ef *elf.File, base process.ProcessMemoryAddress) (uint64, error) {
+	first := uint64(0)
+	found := false
+	for _, p := range ef.Progs {
+		if p.Type == elf.PT_LOAD && (!found || p.Vaddr < first) {
+			first = p.Vaddr
+			found = true
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("no PT_LOAD segment")
+	}
+
+	pageMask := uint64(os.Getpagesize()) - 1
+	return uint64(base) - first&^pageMask, nil
+}
@@ -0,0 +1,257 @@
+package symbols
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"gomem/process"
+	"gomem/process/memory_map"
+)
+
+// PE header offsets, all values are little-endian
+const (
+	peLfanewOffset      = 0x3C // e_lfanew in the DOS header, offset of the NT headers
+	peOptionalOffset    = 0x18 // Optional header from the NT headers (signature + file header)
+	peMagic32           = 0x10B
+	peMagic64           = 0x20B
+	peExportDir32       = 0x60 // Export data directory in the 32-bit optional header
+	peExportDir64       = 0x70 // Export data directory in the 64-bit optional header
+	peExportHeaderSize  = 0x28 // IMAGE_EXPORT_DIRECTORY
+	peMaxForwarderDepth = 4
+	peMaxNameLength     = 512
+)
+
+// isPEModule reports whether the module starts with a DOS header in memory
+func isPEModule(proc process.Process, m process.Module) bool {
+	data, err := proc.ReadMemory(m.Base, 2)
+	return err == nil && string(data) == "MZ"
+}
+
+// peImage reads the headers and export tables of a PE module mapped in a process, with the
+// export directory cached as it holds most of the tables and names
+type peImage struct {
+	proc   process.Process
+	module process.Module
+	dir    []byte // Export directory data, starting at dirRVA
+	dirRVA uint32
+}
+
+// read returns size bytes at rva, from the cached export directory when it covers them
+func (img *peImage) read(rva uint32, size uint32) ([]byte, error) {
+	if rva >= img.dirRVA && uint64(rva)+uint64(size) <= uint64(img.dirRVA)+uint64(len(img.dir)) {
+		off := rva - img.dirRVA
+		return img.dir[off : off+size], nil
+	}
+	if uint64(rva)+uint64(size) > img.module.Size {
+		return nil, fmt.Errorf("rva 0x%x+0x%x is outside the module", rva, size)
+	}
+	return img.proc.ReadMemory(img.module.Address(uint64(rva)), process.ProcessMemorySize(size))
+}
+
+func (img *peImage) u16(rva uint32) (uint16, error) {
+	data, err := img.read(rva, 2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(data), nil
+}
+
+func (img *peImage) u32(rva uint32) (uint32, error) {
+	data, err := img.read(rva, 4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(data), nil
+}
+
+// cstring returns the NUL terminated string at rva
+func (img *peImage) cstring(rva uint32) (string, error) {
+	if rva >= img.dirRVA && uint64(rva) < uint64(img.dirRVA)+uint64(len(img.dir)) {
+		data := img.dir[rva-img.dirRVA:]
+		if i := bytes.IndexByte(data, 0); i >= 0 {
+			return string(data[:i]), nil
+		}
+	}
+
+	size := uint32(min(uint64(peMaxNameLength), img.module.Size-min(img.module.Size, uint64(rva))))
+	data, err := img.read(rva, size)
+	if err != nil {
+		return "", err
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return string(data[:i]), nil
+	}
+	return "", fmt.Errorf("unterminated name at rva 0x%x", rva)
+}
+
+// exportDirectory locates the export data directory from the PE headers
+func (img *peImage) exportDirectory() (rva uint32, size uint32, err error) {
+	lfanew, err := img.u32(peLfanewOffset)
+	if err != nil {
+		return 0, 0, err
+	}
+	signature, err := img.u32(lfanew)
+	if err != nil {
+		return 0, 0, err
+	}
+	if signature != 0x00004550 { // "PE\0\0"
+		return 0, 0, fmt.Errorf("invalid PE signature 0x%x", signature)
+	}
+
+	optional := lfanew + peOptionalOffset
+	magic, err := img.u16(optional)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var dir uint32
+	switch magic {
+	case peMagic32:
+		dir = optional + peExportDir32
+	case peMagic64:
+		dir = optional + peExportDir64
+	default:
+		return 0, 0, fmt.Errorf("unknown optional header magic 0x%x", magic)
+	}
+
+	if rva, err = img.u32(dir); err != nil {
+		return 0, 0, err
+	}
+	if size, err = img.u32(dir + 4); err != nil {
+		return 0, 0, err
+	}
+	return rva, size, nil
+}
+
+// peSymbols reads the named exports of a PE module from the export directory in process
+// memory. Exports forwarded to another module ("NTDLL.RtlAllocateHeap") are resolved to the
+// address in that module when it is loaded and skipped otherwise.
+func peSymbols(proc process.Process, m process.Module) ([]Symbol, error) {
+	return peSymbolsDepth(proc, m, 0)
+}
+
+func peSymbolsDepth(proc process.Process, m process.Module, depth int) ([]Symbol, error) {
+	img := &peImage{proc: proc, module: m}
+
+	dirRVA, dirSize, err := img.exportDirectory()
+	if err != nil {
+		return nil, err
+	}
+	if dirRVA == 0 || dirSize < peExportHeaderSize {
+		return nil, fmt.Errorf("no export directory")
+	}
+
+	dir, err := img.read(dirRVA, dirSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export directory: %w", err)
+	}
+	img.dir, img.dirRVA = dir, dirRVA
+
+	numFunctions := binary.LittleEndian.Uint32(dir[0x14:])
+	numNames := binary.LittleEndian.Uint32(dir[0x18:])
+	functionsRVA := binary.LittleEndian.Uint32(dir[0x1C:])
+	namesRVA := binary.LittleEndian.Uint32(dir[0x20:])
+	ordinalsRVA := binary.LittleEndian.Uint32(dir[0x24:])
+
+	if uint64(numFunctions)*4 > m.Size || uint64(numNames)*4 > m.Size {
+		return nil, fmt.Errorf("invalid export directory (%d functions, %d names)", numFunctions, numNames)
+	}
+
+	functions, err := img.read(functionsRVA, numFunctions*4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export address table: %w", err)
+	}
+	names, err := img.read(namesRVA, numNames*4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export name table: %w", err)
+	}
+	ordinals, err := img.read(ordinalsRVA, numNames*2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export ordinal table: %w", err)
+	}
+
+	mm, _ := proc.GetMemoryMap()
+	forwarded := make(map[string]*Table) // Tables of the forwarder target modules
+	var symbols []Symbol
+	for i := uint32(0); i < numNames; i++ {
+		name, err := img.cstring(binary.LittleEndian.Uint32(names[i*4:]))
+		if err != nil || name == "" {
+			continue
+		}
+		ordinal := uint32(binary.LittleEndian.Uint16(ordinals[i*2:]))
+		if ordinal >= numFunctions {
+			continue
+		}
+		rva := binary.LittleEndian.Uint32(functions[ordinal*4:])
+		if rva == 0 {
+			continue
+		}
+
+		// An address inside the export directory is a forwarder string
+		if rva >= dirRVA && rva < dirRVA+dirSize {
+			target, err := img.cstring(rva)
+			if err != nil {
+				continue
+			}
+			if s, ok := resolveForwarder(proc, target, forwarded, depth); ok {
+				s.Name, s.Module = name, m.Name
+				symbols = append(symbols, s)
+			}
+			continue
+		}
+
+		symbols = append(symbols, Symbol{
+			Name:    name,
+			Module:  m.Name,
+			Address: m.Address(uint64(rva)),
+			Kind:    peSymbolKind(mm, m.Address(uint64(rva))),
+		})
+	}
+
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no exports")
+	}
+	return symbols, nil
+}
+
+// resolveForwarder resolves a forwarder string "MODULE.Name" against the loaded modules,
+// tables caches the target modules already read. Forwarders by ordinal ("MODULE.#12") and
+// to modules that are not loaded are not resolved.
+func resolveForwarder(proc process.Process, target string, tables map[string]*Table, depth int) (Symbol, bool) {
+	if depth >= peMaxForwarderDepth {
+		return Symbol{}, false
+	}
+	dot := strings.LastIndexByte(target, '.')
+	if dot <= 0 || strings.HasPrefix(target[dot+1:], "#") {
+		return Symbol{}, false
+	}
+	module, name := target[:dot]+".dll", target[dot+1:]
+
+	table, ok := tables[module]
+	if !ok {
+		if m, err := process.FindModule(proc, module); err == nil {
+			if symbols, err := peSymbolsDepth(proc, m, depth+1); err == nil {
+				table = newTable(m, symbols)
+			}
+		}
+		tables[module] = table
+	}
+	if table == nil {
+		return Symbol{}, false
+	}
+	return table.Lookup(name)
+}
+
+// peSymbolKind tells functions from data by the permissions of the region holding addr
+func peSymbolKind(mm []memory_map.MemoryMapItem, addr process.ProcessMemoryAddress) SymbolKind {
+	region := memory_map.GetMemoryRegionForAddress(uint64(addr), mm)
+	switch {
+	case region == nil:
+		return SymbolOther
+	case region.IsExecutable():
+		return SymbolFunc
+	}
+	return SymbolObject
+}
@@ -0,0 +1,131 @@
+package symbols
+
+import (
+	"fmt"
+	"sort"
+
+	"gomem/process"
+)
+
+// SymbolKind is what a symbol refers to
+type SymbolKind int
+
+const (
+	SymbolFunc   SymbolKind = iota // Function
+	SymbolObject                   // Data object (variable, table, ...)
+	SymbolIFunc                    // GNU indirect function, the address is the resolver
+	SymbolOther                    // Anything else with an address
+)
+
+func (k SymbolKind) String() string {
+	switch k {
+	case SymbolFunc:
+		return "func"
+	case SymbolObject:
+		return "object"
+	case SymbolIFunc:
+		return "ifunc"
+	case SymbolOther:
+		return "other"
+	}
+	return fmt.Sprintf("SymbolKind(%d)", int(k))
+}
+
+// Symbol is an exported function or data object of a module, relocated to the process
+type Symbol struct {
+	Name    string
+	Module  string // Base name of the module
+	Address process.ProcessMemoryAddress
+	Size    uint64 // Size in bytes, 0 when unknown
+	Kind    SymbolKind
+}
+
+// String returns a one line description of the symbol
+func (s Symbol) String() string {
+	return fmt.Sprintf("%016x %-6s %s!%s", uint64(s.Address), s.Kind, s.Module, s.Name)
+}
+
+// Table is the symbol table of one module mapped in a process
+type Table struct {
+	Module  process.Module
+	Symbols []Symbol // Sorted by address
+
+	byName map[string]int
+}
+
+// newTable indexes symbols, the first symbol of a name wins
+func newTable(module process.Module, symbols []Symbol) *Table {
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return symbols[i].Address < symbols[j].Address
+	})
+
+	t := &Table{Module: module, Symbols: symbols, byName: make(map[string]int, len(symbols))}
+	for i, s := range symbols {
+		if _, ok := t.byName[s.Name]; !ok {
+			t.byName[s.Name] = i
+		}
+	}
+	return t
+}
+
+// Lookup returns the symbol called name
+func (t *Table) Lookup(name string) (Symbol, bool) {
+	i, ok := t.byName[name]
+	if !ok {
+		return Symbol{}, false
+	}
+	return t.Symbols[i], true
+}
+
+// Nearest returns the symbol containing addr, or the closest symbol below it when the sizes
+// are unknown, and the offset of addr from it
+func (t *Table) Nearest(addr process.ProcessMemoryAddress) (Symbol, uint64, bool) {
+	if !t.Module.Contains(addr) {
+		return Symbol{}, 0, false
+	}
+	i := sort.Search(len(t.Symbols), func(i int) bool {
+		return t.Symbols[i].Address > addr
+	})
+	if i == 0 {
+		return Symbol{}, 0, false
+	}
+	s := t.Symbols[i-1]
+	return s, uint64(addr - s.Address), true
+}
+
+// LoadModule reads the symbol table of the module of proc named module (matched like
+// process.FindModule). PE modules are read from the export directory in process memory, only
+// named exports are listed. ELF modules are parsed from their file on disk and relocated by
+// the load bias of the mapping.
+func LoadModule(proc process.Process, module string) (*Table, error) {
+	m, err := process.FindModule(proc, module)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []Symbol
+	if isPEModule(proc, m) {
+		symbols, err = peSymbols(proc, m)
+	} else {
+		symbols, err = elfSymbols(proc, m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read symbols of %s: %w", m.Name, err)
+	}
+	return newTable(m, symbols), nil
+}
+
+// ResolveSymbol returns the address of the exported symbol name of module in proc, e.g.
+// ResolveSymbol(proc, "libc.so.6", "malloc"). Use LoadModule to look up several symbols of
+// the same module without parsing it each time.
+func ResolveSymbol(proc process.Process, module, name string) (process.ProcessMemoryAddress, error) {
+	table, err := LoadModule(proc, module)
+	if err != nil {
+		return 0, err
+	}
+	s, ok := table.Lookup(name)
+	if !ok {
+		return 0, fmt.Errorf("symbol %s not found in %s", name, table.Module.Name)
+	}
+	return s.Address, nil
+}